@@ -0,0 +1,74 @@
+package adapters
+
+import (
+	"brokerx/models"
+	"brokerx/ports"
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FakePaymentProvider is a deterministic payment provider for local testing. The cents of
+// the amount of a deposit or a withdrawal select the outcome of its payment:
+//   - .01 declines the payment right away
+//   - .02 approves the payment once Delay has passed
+//   - .03 declines the payment once Delay has passed
+//   - any other amount approves the payment right away
+//
+// The outcome of a delayed payment is encoded in its reference, so pending payments
+// survive a restart of the server.
+type FakePaymentProvider struct {
+	Delay time.Duration
+}
+
+const fakeDeclineReason = "payment declined"
+
+func (provider *FakePaymentProvider) Charge(ctx context.Context, deposit *models.Deposit) (*models.PaymentResult, error) {
+	return provider.pay(fmt.Sprintf("fake-%d", deposit.ID), deposit.Amount), nil
+}
+
+func (provider *FakePaymentProvider) Payout(ctx context.Context, withdrawal *models.Withdrawal) (*models.PaymentResult, error) {
+	return provider.pay(fmt.Sprintf("fake-w%d", withdrawal.ID), withdrawal.Amount), nil
+}
+
+func (provider *FakePaymentProvider) Status(ctx context.Context, reference string) (*models.PaymentResult, error) {
+	parts := strings.Split(reference, "-")
+	if len(parts) != 4 || parts[0] != "fake" {
+		return nil, errors.New("unknown payment reference")
+	}
+	resolvesAt, err := strconv.ParseInt(parts[3], 10, 64)
+	if err != nil {
+		return nil, errors.New("unknown payment reference")
+	}
+
+	if time.Now().Unix() < resolvesAt {
+		return &models.PaymentResult{Reference: reference, Status: "pending"}, nil
+	}
+	if parts[2] == "declined" {
+		return &models.PaymentResult{Reference: reference, Status: "declined", Reason: fakeDeclineReason}, nil
+	}
+	return &models.PaymentResult{Reference: reference, Status: "approved"}, nil
+}
+
+func (provider *FakePaymentProvider) pay(reference string, amount models.Money) *models.PaymentResult {
+	cents := amount.StringFixed(2)
+	switch cents[len(cents)-2:] {
+	case "01":
+		return &models.PaymentResult{Reference: reference, Status: "declined", Reason: fakeDeclineReason}
+	case "02":
+		return provider.pending(reference, "approved")
+	case "03":
+		return provider.pending(reference, "declined")
+	}
+	return &models.PaymentResult{Reference: reference, Status: "approved"}
+}
+
+func (provider *FakePaymentProvider) pending(reference string, outcome string) *models.PaymentResult {
+	resolvesAt := time.Now().Add(provider.Delay).Unix()
+	return &models.PaymentResult{Reference: fmt.Sprintf("%s-%s-%d", reference, outcome, resolvesAt), Status: "pending"}
+}
+
+var _ ports.PaymentProvider = (*FakePaymentProvider)(nil) // Ensure interface is implemented at compile time
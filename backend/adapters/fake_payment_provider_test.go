@@ -0,0 +1,76 @@
+package adapters
+
+import (
+	"brokerx/models"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFakePaymentProviderImmediateOutcomes(t *testing.T) {
+	provider := &FakePaymentProvider{Delay: time.Minute}
+
+	result, err := provider.Charge(context.Background(), &models.Deposit{ID: 1, Amount: models.NewMoney(100)})
+	require.NoError(t, err)
+	require.Equal(t, "approved", result.Status)
+	require.Equal(t, "fake-1", result.Reference)
+
+	result, err = provider.Charge(context.Background(), &models.Deposit{ID: 2, Amount: models.MustParseMoney("100.01")})
+	require.NoError(t, err)
+	require.Equal(t, "declined", result.Status)
+	require.Equal(t, fakeDeclineReason, result.Reason)
+}
+
+func TestFakePaymentProviderDelayedOutcomes(t *testing.T) {
+	provider := &FakePaymentProvider{Delay: time.Hour}
+
+	approved, err := provider.Charge(context.Background(), &models.Deposit{ID: 3, Amount: models.MustParseMoney("50.02")})
+	require.NoError(t, err)
+	require.Equal(t, "pending", approved.Status)
+
+	result, err := provider.Status(context.Background(), approved.Reference)
+	require.NoError(t, err)
+	require.Equal(t, "pending", result.Status)
+
+	provider.Delay = -time.Second
+	approved, _ = provider.Charge(context.Background(), &models.Deposit{ID: 3, Amount: models.MustParseMoney("50.02")})
+	declined, _ := provider.Charge(context.Background(), &models.Deposit{ID: 4, Amount: models.MustParseMoney("50.03")})
+
+	result, err = provider.Status(context.Background(), approved.Reference)
+	require.NoError(t, err)
+	require.Equal(t, "approved", result.Status)
+
+	result, err = provider.Status(context.Background(), declined.Reference)
+	require.NoError(t, err)
+	require.Equal(t, "declined", result.Status)
+	require.Equal(t, fakeDeclineReason, result.Reason)
+}
+
+func TestFakePaymentProviderPayout(t *testing.T) {
+	provider := &FakePaymentProvider{Delay: time.Hour}
+
+	result, err := provider.Payout(context.Background(), &models.Withdrawal{ID: 5, Amount: models.NewMoney(80)})
+	require.NoError(t, err)
+	require.Equal(t, "approved", result.Status)
+	require.Equal(t, "fake-w5", result.Reference)
+
+	result, err = provider.Payout(context.Background(), &models.Withdrawal{ID: 6, Amount: models.MustParseMoney("80.03")})
+	require.NoError(t, err)
+	require.Equal(t, "pending", result.Status)
+
+	result, err = provider.Status(context.Background(), result.Reference)
+	require.NoError(t, err)
+	require.Equal(t, "pending", result.Status)
+}
+
+func TestFakePaymentProviderUnknownReference(t *testing.T) {
+	provider := &FakePaymentProvider{}
+
+	_, err := provider.Status(context.Background(), "fake-1")
+	require.Error(t, err)
+
+	_, err = provider.Status(context.Background(), "other-1-approved-soon")
+	require.Error(t, err)
+}
@@ -12,6 +12,7 @@ type contextKey string
 
 const USER_ID_KEY contextKey = "user_id"
 const USER_EMAIL_KEY contextKey = "email"
+const USER_ROLE_KEY contextKey = "role"
 
 type AuthHandler struct {
 	Service ports.AuthService
@@ -25,13 +26,13 @@ func (handler *AuthHandler) Login(writer http.ResponseWriter, request *http.Requ
 		return
 	}
 
-	user, e := handler.Service.Authenticate(request.FormValue("email"), request.FormValue("password"))
+	user, e := handler.Service.Authenticate(request.Context(), request.FormValue("email"), request.FormValue("password"))
 	if e != nil {
 		http.Error(writer, "unauthorized: " + e.Error(), http.StatusUnauthorized)
 		return
 	}
 
-    if err := handler.initSession(request, writer, user.ID, user.Email); err != nil {
+    if err := handler.initSession(request, writer, user.ID, user.Email, user.Role); err != nil {
 		http.Error(writer, "failed to save session: " + err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -41,26 +42,64 @@ func (handler *AuthHandler) Login(writer http.ResponseWriter, request *http.Requ
 
 func (handler *AuthHandler) Middleware(next http.Handler) http.Handler {
     return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-        session, _ := handler.SessionStore.Get(r, "brokerx-session")
-        userID, idOk := session.Values["user_id"].(string)
-        userEmail, ok := session.Values["email"].(string)
-        if !idOk || !ok || userID == "" {
+        ctx, ok := handler.authenticatedContext(r)
+        if !ok {
             http.Redirect(w, r, "/login", http.StatusFound)
             return
         }
-        
-        ctx := context.WithValue(r.Context(), USER_ID_KEY, userID)
-        ctx = context.WithValue(ctx, USER_EMAIL_KEY, userEmail)
-        r = r.WithContext(ctx)
+
+        next.ServeHTTP(w, r.WithContext(ctx))
+    })
+}
+
+// APIMiddleware authenticates API requests like Middleware, but answers unauthenticated
+// requests with a JSON error instead of redirecting them to the login page.
+func (handler *AuthHandler) APIMiddleware(next http.Handler) http.Handler {
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        ctx, ok := handler.authenticatedContext(r)
+        if !ok {
+            writeAPIError(w, http.StatusUnauthorized, "unauthorized", "authentication required")
+            return
+        }
+
+        next.ServeHTTP(w, r.WithContext(ctx))
+    })
+}
+
+// BackOfficeMiddleware only lets through the API requests of back-office users. It must
+// run after APIMiddleware.
+func (handler *AuthHandler) BackOfficeMiddleware(next http.Handler) http.Handler {
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        if role, _ := r.Context().Value(USER_ROLE_KEY).(string); role != "back_office" {
+            writeAPIError(w, http.StatusForbidden, "forbidden", "back-office access required")
+            return
+        }
 
         next.ServeHTTP(w, r)
     })
 }
 
-func (handler *AuthHandler) initSession(r *http.Request, w http.ResponseWriter, userId string, userEmail string) error {
+func (handler *AuthHandler) authenticatedContext(r *http.Request) (context.Context, bool) {
+    session, _ := handler.SessionStore.Get(r, "brokerx-session")
+    userID, idOk := session.Values["user_id"].(string)
+    userEmail, ok := session.Values["email"].(string)
+    if !idOk || !ok || userID == "" {
+        return nil, false
+    }
+
+    ctx := context.WithValue(r.Context(), USER_ID_KEY, userID)
+    ctx = context.WithValue(ctx, USER_EMAIL_KEY, userEmail)
+    if role, ok := session.Values["role"].(string); ok {
+        ctx = context.WithValue(ctx, USER_ROLE_KEY, role)
+    }
+    return ctx, true
+}
+
+func (handler *AuthHandler) initSession(r *http.Request, w http.ResponseWriter, userId string, userEmail string, userRole string) error {
 	session, _ := handler.SessionStore.Get(r, "brokerx-session")
     session.Values["user_id"] = userId
     session.Values["email"] = userEmail
+    session.Values["role"] = userRole
     session.Options = &sessions.Options{
         Path:     "/",
         MaxAge:   600,
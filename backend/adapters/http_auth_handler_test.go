@@ -1,6 +1,7 @@
 package adapters
 
 import (
+	"context"
 	"brokerx/models"
 	"bytes"
 	"database/sql"
@@ -22,8 +23,8 @@ type MockAuthService struct {
 	mock.Mock
 }
 
-func (m *MockAuthService) Authenticate(email, password string) (*models.User, error) {
-	args := m.Called(email, password)
+func (m *MockAuthService) Authenticate(ctx context.Context, email, password string) (*models.User, error) {
+	args := m.Called(ctx, email, password)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -59,7 +60,7 @@ func (s *HttpAuthHandlerTestSuite) SetupTest() {
 
 func (s *HttpAuthHandlerTestSuite) TestLoginSuccess() {
 	user := &models.User{Email: "test@x.com", Password: "hashed", FailedAttempts: 0, LockedUntil: sql.NullTime{Valid: false}}
-	s.mockService.On("Authenticate", "test@x.com", "pw").Return(user, nil)
+	s.mockService.On("Authenticate", mock.Anything, "test@x.com", "pw").Return(user, nil)
 
 	req := httptest.NewRequest(http.MethodPost, LOGIN_ENDPOINT, bytes.NewBufferString("email=test@x.com&password=pw"))
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
@@ -85,7 +86,7 @@ func (s *HttpAuthHandlerTestSuite) TestLoginBadRequest() {
 }
 
 func (s *HttpAuthHandlerTestSuite) TestLoginUnauthorized() {
-	s.mockService.On("Authenticate", "bad@x.com", "wrong").Return(nil, assert.AnError)
+	s.mockService.On("Authenticate", mock.Anything, "bad@x.com", "wrong").Return(nil, assert.AnError)
 
 	req := httptest.NewRequest(http.MethodPost, LOGIN_ENDPOINT, bytes.NewBufferString("email=bad@x.com&password=wrong"))
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
@@ -109,6 +110,38 @@ func (s *HttpAuthHandlerTestSuite) TestMiddlewareUnauthenticated() {
 	s.Equal("/login", w.Result().Header.Get("Location"))
 }
 
+func (s *HttpAuthHandlerTestSuite) TestAPIMiddlewareUnauthenticated() {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/orders", nil)
+	w := httptest.NewRecorder()
+
+	protected := s.handler.APIMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	protected.ServeHTTP(w, req)
+
+	s.Equal(http.StatusUnauthorized, w.Result().StatusCode)
+	s.Equal("application/json", w.Result().Header.Get("Content-Type"))
+	s.JSONEq(`{"error":{"code":"unauthorized","message":"authentication required"}}`, w.Body.String())
+}
+
+func (s *HttpAuthHandlerTestSuite) TestBackOfficeMiddleware() {
+	protected := s.handler.BackOfficeMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/back-office/withdrawals", nil)
+	w := httptest.NewRecorder()
+	protected.ServeHTTP(w, req.WithContext(context.WithValue(req.Context(), USER_ROLE_KEY, "customer")))
+
+	s.Equal(http.StatusForbidden, w.Result().StatusCode)
+	s.JSONEq(`{"error":{"code":"forbidden","message":"back-office access required"}}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	protected.ServeHTTP(w, req.WithContext(context.WithValue(req.Context(), USER_ROLE_KEY, "back_office")))
+
+	s.Equal(http.StatusOK, w.Result().StatusCode)
+}
+
 func (s *HttpAuthHandlerTestSuite) TestMiddlewareAuthenticated() {
 	expectedMessage := []byte("user is authenticated!")
 	req := httptest.NewRequest(http.MethodGet, "/", nil)
@@ -133,7 +166,7 @@ func (s *HttpAuthHandlerTestSuite) TestMiddlewareAuthenticated() {
 
 func (s *HttpAuthHandlerTestSuite) TestInitSessionFailure() {
 	user := &models.User{Email: "test@x.com", Password: "hashed", FailedAttempts: 0, LockedUntil: sql.NullTime{Valid: false}}
-	s.mockService.On("Authenticate", "test@x.com", "pw").Return(user, nil)
+	s.mockService.On("Authenticate", mock.Anything, "test@x.com", "pw").Return(user, nil)
 
     s.handler.SessionStore = &FailingStore{}
     req := httptest.NewRequest(http.MethodPost, LOGIN_ENDPOINT, bytes.NewBufferString("email=test@x.com&password=pw"))
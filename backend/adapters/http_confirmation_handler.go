@@ -0,0 +1,95 @@
+package adapters
+
+import (
+	"brokerx/models"
+	"brokerx/ports"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// ConfirmationHandler exposes the trade confirmations of the authenticated user as a JSON
+// API.
+type ConfirmationHandler struct {
+	Service ports.ConfirmationService
+}
+
+type confirmationResponse struct {
+	Number         string       `json:"number"`
+	ExecutionID    int          `json:"execution_id"`
+	OrderID        int          `json:"order_id"`
+	Symbol         string       `json:"symbol"`
+	Side           string       `json:"side"`
+	Quantity       int          `json:"quantity"`
+	Price          models.Money `json:"price"`
+	Currency       string       `json:"currency"`
+	Commission     models.Money `json:"commission"`
+	RegulatoryFee  models.Money `json:"regulatory_fee"`
+	NetAmount      models.Money `json:"net_amount"`
+	ExecutedAt     time.Time    `json:"executed_at"`
+	SettlementDate string       `json:"settlement_date"`
+	IssuedAt       *time.Time   `json:"issued_at,omitempty"`
+}
+
+type confirmationsResponse struct {
+	Confirmations []confirmationResponse `json:"confirmations"`
+}
+
+// ListConfirmations returns the confirmations of the user, most recent first.
+func (handler *ConfirmationHandler) ListConfirmations(writer http.ResponseWriter, request *http.Request) {
+	userID := request.Context().Value(USER_ID_KEY).(string)
+	confirmations, err := handler.Service.ListConfirmations(request.Context(), userID)
+	if err != nil {
+		writeConfirmationAPIError(writer, err)
+		return
+	}
+
+	response := confirmationsResponse{Confirmations: make([]confirmationResponse, 0, len(confirmations))}
+	for _, confirmation := range confirmations {
+		response.Confirmations = append(response.Confirmations, newConfirmationResponse(confirmation))
+	}
+	writeJSON(writer, http.StatusOK, response)
+}
+
+func (handler *ConfirmationHandler) GetConfirmation(writer http.ResponseWriter, request *http.Request) {
+	userID := request.Context().Value(USER_ID_KEY).(string)
+	confirmation, err := handler.Service.GetConfirmation(request.Context(), userID, chi.URLParam(request, "number"))
+	if err != nil {
+		writeConfirmationAPIError(writer, err)
+		return
+	}
+
+	writeJSON(writer, http.StatusOK, newConfirmationResponse(confirmation))
+}
+
+func newConfirmationResponse(confirmation *models.Confirmation) confirmationResponse {
+	return confirmationResponse{
+		Number:         confirmation.Number,
+		ExecutionID:    confirmation.ExecutionID,
+		OrderID:        confirmation.OrderID,
+		Symbol:         confirmation.Symbol,
+		Side:           confirmation.Side,
+		Quantity:       confirmation.Quantity,
+		Price:          confirmation.Price,
+		Currency:       confirmation.Currency,
+		Commission:     confirmation.Commission,
+		RegulatoryFee:  confirmation.RegulatoryFee,
+		NetAmount:      confirmation.NetAmount(),
+		ExecutedAt:     confirmation.ExecutedAt,
+		SettlementDate: confirmation.SettlementDate.Format(statementDateLayout),
+		IssuedAt:       nullTimeToPointer(confirmation.IssuedAt),
+	}
+}
+
+func writeConfirmationAPIError(writer http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ports.ErrConfirmationNotFound):
+		writeAPIError(writer, http.StatusNotFound, "confirmation_not_found", err.Error())
+	case errors.Is(err, ports.ErrConfirmationNotOwned):
+		writeAPIError(writer, http.StatusForbidden, "confirmation_not_owned", err.Error())
+	default:
+		writeAPIError(writer, http.StatusInternalServerError, "internal_error", "internal server error")
+	}
+}
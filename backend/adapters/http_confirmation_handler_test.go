@@ -0,0 +1,141 @@
+package adapters
+
+import (
+	"brokerx/models"
+	"brokerx/ports"
+	"context"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type MockConfirmationService struct {
+	mock.Mock
+}
+
+func (m *MockConfirmationService) GetConfirmation(ctx context.Context, userID string, number string) (*models.Confirmation, error) {
+	args := m.Called(ctx, userID, number)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Confirmation), args.Error(1)
+}
+
+func (m *MockConfirmationService) ListConfirmations(ctx context.Context, userID string) ([]*models.Confirmation, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Confirmation), args.Error(1)
+}
+
+func newConfirmationRequest(userID string, number string) *http.Request {
+	request := newAPIRequest(http.MethodGet, "/api/v1/confirmations/"+number, "", userID, "")
+	routeContext := chi.NewRouteContext()
+	routeContext.URLParams.Add("number", number)
+	return request.WithContext(context.WithValue(request.Context(), chi.RouteCtxKey, routeContext))
+}
+
+// ---------------------------
+// Test Suite
+// ---------------------------
+
+type HttpConfirmationHandlerTestSuite struct {
+	suite.Suite
+	mockService  *MockConfirmationService
+	handler      *ConfirmationHandler
+	UserID       string
+	confirmation *models.Confirmation
+}
+
+func (s *HttpConfirmationHandlerTestSuite) SetupTest() {
+	s.mockService = new(MockConfirmationService)
+	s.handler = &ConfirmationHandler{Service: s.mockService}
+	s.UserID = "user"
+	s.confirmation = &models.Confirmation{ID: 1, Number: "TC-20260918-00000007-S", ExecutionID: 7, OrderID: 9, UserID: s.UserID, Symbol: "AAPL", Side: "sell",
+		Quantity: 10, Price: models.NewMoney(148), Currency: "USD", Commission: models.MustParseMoney("2.48"), RegulatoryFee: models.MustParseMoney("0.05"),
+		ExecutedAt: time.Date(2026, 9, 18, 14, 30, 0, 0, time.UTC), SettlementDate: time.Date(2026, 9, 21, 0, 0, 0, 0, time.UTC),
+		IssuedAt: sql.NullTime{Time: time.Date(2026, 9, 18, 14, 30, 1, 0, time.UTC), Valid: true}}
+}
+
+// ---------------------------
+// Tests
+// ---------------------------
+
+func (s *HttpConfirmationHandlerTestSuite) TestListConfirmations() {
+	s.mockService.On("ListConfirmations", mock.Anything, s.UserID).Return([]*models.Confirmation{s.confirmation}, nil)
+	w := httptest.NewRecorder()
+
+	s.handler.ListConfirmations(w, newAPIRequest(http.MethodGet, "/api/v1/confirmations", "", s.UserID, ""))
+
+	s.Equal(http.StatusOK, w.Code)
+	s.JSONEq(`{"confirmations":[{"number":"TC-20260918-00000007-S","execution_id":7,"order_id":9,"symbol":"AAPL","side":"sell","quantity":10,"price":148,"currency":"USD",
+		"commission":2.48,"regulatory_fee":0.05,"net_amount":1477.47,"executed_at":"2026-09-18T14:30:00Z","settlement_date":"2026-09-21","issued_at":"2026-09-18T14:30:01Z"}]}`, w.Body.String())
+}
+
+func (s *HttpConfirmationHandlerTestSuite) TestListConfirmationsWithoutConfirmations() {
+	s.mockService.On("ListConfirmations", mock.Anything, s.UserID).Return(nil, nil)
+	w := httptest.NewRecorder()
+
+	s.handler.ListConfirmations(w, newAPIRequest(http.MethodGet, "/api/v1/confirmations", "", s.UserID, ""))
+
+	s.Equal(http.StatusOK, w.Code)
+	s.JSONEq(`{"confirmations":[]}`, w.Body.String())
+}
+
+func (s *HttpConfirmationHandlerTestSuite) TestListConfirmationsFailure() {
+	s.mockService.On("ListConfirmations", mock.Anything, s.UserID).Return(nil, assert.AnError)
+	w := httptest.NewRecorder()
+
+	s.handler.ListConfirmations(w, newAPIRequest(http.MethodGet, "/api/v1/confirmations", "", s.UserID, ""))
+
+	s.Equal(http.StatusInternalServerError, w.Code)
+	s.Equal("internal_error", decodeAPIError(&s.Suite, w).Code)
+}
+
+func (s *HttpConfirmationHandlerTestSuite) TestGetConfirmation() {
+	s.mockService.On("GetConfirmation", mock.Anything, s.UserID, s.confirmation.Number).Return(s.confirmation, nil)
+	w := httptest.NewRecorder()
+
+	s.handler.GetConfirmation(w, newConfirmationRequest(s.UserID, s.confirmation.Number))
+
+	s.Equal(http.StatusOK, w.Code)
+	s.Contains(w.Body.String(), `"number":"TC-20260918-00000007-S"`)
+	s.Contains(w.Body.String(), `"net_amount":1477.47`)
+}
+
+func (s *HttpConfirmationHandlerTestSuite) TestGetConfirmationErrors() {
+	cases := []struct {
+		err    error
+		status int
+		code   string
+	}{
+		{ports.ErrConfirmationNotFound, http.StatusNotFound, "confirmation_not_found"},
+		{ports.ErrConfirmationNotOwned, http.StatusForbidden, "confirmation_not_owned"},
+		{assert.AnError, http.StatusInternalServerError, "internal_error"},
+	}
+	for _, c := range cases {
+		s.SetupTest()
+		s.mockService.On("GetConfirmation", mock.Anything, s.UserID, "TC-20260918-00000008-B").Return(nil, c.err)
+		w := httptest.NewRecorder()
+
+		s.handler.GetConfirmation(w, newConfirmationRequest(s.UserID, "TC-20260918-00000008-B"))
+
+		s.Equal(c.status, w.Code)
+		s.Equal(c.code, decodeAPIError(&s.Suite, w).Code)
+	}
+}
+
+// ---------------------------
+// Run the suite
+// ---------------------------
+func TestHttpConfirmationHandlerTestSuite(t *testing.T) {
+	suite.Run(t, new(HttpConfirmationHandlerTestSuite))
+}
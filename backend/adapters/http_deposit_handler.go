@@ -0,0 +1,110 @@
+package adapters
+
+import (
+	"brokerx/models"
+	"brokerx/ports"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// DepositHandler exposes the deposits of the authenticated user as a JSON API.
+type DepositHandler struct {
+	Service ports.DepositService
+}
+
+type depositRequest struct {
+	Amount models.Money `json:"amount"`
+}
+
+type depositResponse struct {
+	ID            int          `json:"id"`
+	Amount        models.Money `json:"amount"`
+	Status        string       `json:"status"`
+	FailureReason string       `json:"failure_reason,omitempty"`
+	CreatedAt     *time.Time   `json:"created_at,omitempty"`
+	UpdatedAt     *time.Time   `json:"updated_at,omitempty"`
+}
+
+type depositsResponse struct {
+	Deposits []depositResponse `json:"deposits"`
+}
+
+// CreateDeposit initiates a deposit. The deposit is returned pending when the payment
+// provider has not answered yet.
+func (handler *DepositHandler) CreateDeposit(writer http.ResponseWriter, request *http.Request) {
+	var body depositRequest
+	if err := json.NewDecoder(request.Body).Decode(&body); err != nil {
+		writeAPIError(writer, http.StatusBadRequest, "invalid_request", "badly formed deposit")
+		return
+	}
+
+	userID := request.Context().Value(USER_ID_KEY).(string)
+	deposit, err := handler.Service.InitiateDeposit(request.Context(), userID, body.Amount)
+	if err != nil {
+		writeDepositAPIError(writer, err)
+		return
+	}
+
+	writeJSON(writer, http.StatusCreated, newDepositResponse(deposit))
+}
+
+func (handler *DepositHandler) ListDeposits(writer http.ResponseWriter, request *http.Request) {
+	userID := request.Context().Value(USER_ID_KEY).(string)
+	deposits, err := handler.Service.ListDeposits(request.Context(), userID)
+	if err != nil {
+		writeDepositAPIError(writer, err)
+		return
+	}
+
+	response := depositsResponse{Deposits: make([]depositResponse, 0, len(deposits))}
+	for _, deposit := range deposits {
+		response.Deposits = append(response.Deposits, newDepositResponse(deposit))
+	}
+	writeJSON(writer, http.StatusOK, response)
+}
+
+func (handler *DepositHandler) GetDeposit(writer http.ResponseWriter, request *http.Request) {
+	depositID, err := strconv.Atoi(chi.URLParam(request, "id"))
+	if err != nil {
+		writeAPIError(writer, http.StatusBadRequest, "invalid_deposit_id", "invalid deposit id")
+		return
+	}
+
+	userID := request.Context().Value(USER_ID_KEY).(string)
+	deposit, err := handler.Service.GetDeposit(request.Context(), userID, depositID)
+	if err != nil {
+		writeDepositAPIError(writer, err)
+		return
+	}
+
+	writeJSON(writer, http.StatusOK, newDepositResponse(deposit))
+}
+
+func newDepositResponse(deposit *models.Deposit) depositResponse {
+	return depositResponse{
+		ID:            deposit.ID,
+		Amount:        deposit.Amount,
+		Status:        deposit.Status,
+		FailureReason: deposit.FailureReason,
+		CreatedAt:     nullTimeToPointer(deposit.CreatedAt),
+		UpdatedAt:     nullTimeToPointer(deposit.UpdatedAt),
+	}
+}
+
+func writeDepositAPIError(writer http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ports.ErrInvalidAmount):
+		writeAPIError(writer, http.StatusBadRequest, "invalid_amount", err.Error())
+	case errors.Is(err, ports.ErrDepositNotFound):
+		writeAPIError(writer, http.StatusNotFound, "deposit_not_found", err.Error())
+	case errors.Is(err, ports.ErrDepositNotOwned):
+		writeAPIError(writer, http.StatusForbidden, "deposit_not_owned", err.Error())
+	default:
+		writeAPIError(writer, http.StatusInternalServerError, "internal_error", "internal server error")
+	}
+}
@@ -0,0 +1,173 @@
+package adapters
+
+import (
+	"brokerx/models"
+	"brokerx/ports"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type MockDepositService struct {
+	mock.Mock
+}
+
+func (m *MockDepositService) InitiateDeposit(ctx context.Context, userID string, amount models.Money) (*models.Deposit, error) {
+	args := m.Called(ctx, userID, amount)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Deposit), args.Error(1)
+}
+
+func (m *MockDepositService) GetDeposit(ctx context.Context, userID string, depositID int) (*models.Deposit, error) {
+	args := m.Called(ctx, userID, depositID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Deposit), args.Error(1)
+}
+
+func (m *MockDepositService) ListDeposits(ctx context.Context, userID string) ([]*models.Deposit, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Deposit), args.Error(1)
+}
+
+func (m *MockDepositService) RefreshPendingDeposits(ctx context.Context) (int, error) {
+	args := m.Called(ctx)
+	return args.Int(0), args.Error(1)
+}
+
+// ---------------------------
+// Test Suite
+// ---------------------------
+
+type HttpDepositHandlerTestSuite struct {
+	suite.Suite
+	mockService *MockDepositService
+	handler     *DepositHandler
+	UserID      string
+}
+
+func (s *HttpDepositHandlerTestSuite) SetupTest() {
+	s.mockService = new(MockDepositService)
+	s.handler = &DepositHandler{Service: s.mockService}
+	s.UserID = "user"
+}
+
+// ---------------------------
+// Tests
+// ---------------------------
+
+func (s *HttpDepositHandlerTestSuite) TestCreateDeposit() {
+	deposit := &models.Deposit{ID: 1, UserID: s.UserID, Amount: models.NewMoney(100), Status: "settled"}
+	s.mockService.On("InitiateDeposit", mock.Anything, s.UserID, models.NewMoney(100)).Return(deposit, nil)
+	w := httptest.NewRecorder()
+
+	s.handler.CreateDeposit(w, newAPIRequest(http.MethodPost, "/api/v1/deposits", `{"amount":100}`, s.UserID, ""))
+
+	s.Equal(http.StatusCreated, w.Code)
+	var response depositResponse
+	s.Require().NoError(json.NewDecoder(w.Body).Decode(&response))
+	s.Equal(1, response.ID)
+	s.Equal("settled", response.Status)
+}
+
+func (s *HttpDepositHandlerTestSuite) TestCreateDepositBadlyFormed() {
+	w := httptest.NewRecorder()
+
+	s.handler.CreateDeposit(w, newAPIRequest(http.MethodPost, "/api/v1/deposits", `{"amount":`, s.UserID, ""))
+
+	s.Equal(http.StatusBadRequest, w.Code)
+	s.Equal("invalid_request", decodeAPIError(&s.Suite, w).Code)
+	s.mockService.AssertNotCalled(s.T(), "InitiateDeposit", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *HttpDepositHandlerTestSuite) TestCreateDepositInvalidAmount() {
+	s.mockService.On("InitiateDeposit", mock.Anything, s.UserID, models.NewMoney(-5)).Return(nil, ports.ErrInvalidAmount)
+	w := httptest.NewRecorder()
+
+	s.handler.CreateDeposit(w, newAPIRequest(http.MethodPost, "/api/v1/deposits", `{"amount":-5}`, s.UserID, ""))
+
+	s.Equal(http.StatusBadRequest, w.Code)
+	s.Equal("invalid_amount", decodeAPIError(&s.Suite, w).Code)
+}
+
+func (s *HttpDepositHandlerTestSuite) TestListDeposits() {
+	deposits := []*models.Deposit{{ID: 2, Amount: models.NewMoney(50), Status: "pending"}, {ID: 1, Amount: models.NewMoney(10), Status: "failed", FailureReason: "card declined"}}
+	s.mockService.On("ListDeposits", mock.Anything, s.UserID).Return(deposits, nil)
+	w := httptest.NewRecorder()
+
+	s.handler.ListDeposits(w, newAPIRequest(http.MethodGet, "/api/v1/deposits", "", s.UserID, ""))
+
+	s.Equal(http.StatusOK, w.Code)
+	var response depositsResponse
+	s.Require().NoError(json.NewDecoder(w.Body).Decode(&response))
+	s.Require().Len(response.Deposits, 2)
+	s.Equal("card declined", response.Deposits[1].FailureReason)
+}
+
+func (s *HttpDepositHandlerTestSuite) TestListDepositsInternalError() {
+	s.mockService.On("ListDeposits", mock.Anything, s.UserID).Return(nil, assert.AnError)
+	w := httptest.NewRecorder()
+
+	s.handler.ListDeposits(w, newAPIRequest(http.MethodGet, "/api/v1/deposits", "", s.UserID, ""))
+
+	s.Equal(http.StatusInternalServerError, w.Code)
+	s.Equal("internal_error", decodeAPIError(&s.Suite, w).Code)
+}
+
+func (s *HttpDepositHandlerTestSuite) TestGetDeposit() {
+	s.mockService.On("GetDeposit", mock.Anything, s.UserID, 1).Return(&models.Deposit{ID: 1, Amount: models.NewMoney(100), Status: "settled"}, nil)
+	w := httptest.NewRecorder()
+
+	s.handler.GetDeposit(w, newAPIRequest(http.MethodGet, "/api/v1/deposits/1", "", s.UserID, "1"))
+
+	s.Equal(http.StatusOK, w.Code)
+}
+
+func (s *HttpDepositHandlerTestSuite) TestGetDepositInvalidID() {
+	w := httptest.NewRecorder()
+
+	s.handler.GetDeposit(w, newAPIRequest(http.MethodGet, "/api/v1/deposits/abc", "", s.UserID, "abc"))
+
+	s.Equal(http.StatusBadRequest, w.Code)
+	s.Equal("invalid_deposit_id", decodeAPIError(&s.Suite, w).Code)
+}
+
+func (s *HttpDepositHandlerTestSuite) TestGetDepositErrors() {
+	cases := []struct {
+		err    error
+		status int
+		code   string
+	}{
+		{ports.ErrDepositNotFound, http.StatusNotFound, "deposit_not_found"},
+		{ports.ErrDepositNotOwned, http.StatusForbidden, "deposit_not_owned"},
+	}
+	for _, c := range cases {
+		s.SetupTest()
+		s.mockService.On("GetDeposit", mock.Anything, s.UserID, 7).Return(nil, c.err)
+		w := httptest.NewRecorder()
+
+		s.handler.GetDeposit(w, newAPIRequest(http.MethodGet, "/api/v1/deposits/7", "", s.UserID, "7"))
+
+		s.Equal(c.status, w.Code)
+		s.Equal(c.code, decodeAPIError(&s.Suite, w).Code)
+	}
+}
+
+// ---------------------------
+// Run the suite
+// ---------------------------
+func TestHttpDepositHandlerTestSuite(t *testing.T) {
+	suite.Run(t, new(HttpDepositHandlerTestSuite))
+}
@@ -0,0 +1,95 @@
+package adapters
+
+import (
+	"brokerx/models"
+	"brokerx/ports"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// FXHandler exposes the currency conversions of the authenticated user as a JSON API.
+type FXHandler struct {
+	Service ports.FXService
+}
+
+type fxConversionRequest struct {
+	FromCurrency string       `json:"from_currency"`
+	ToCurrency   string       `json:"to_currency"`
+	Amount       models.Money `json:"amount"`
+}
+
+type fxConversionResponse struct {
+	ID              int          `json:"id"`
+	FromCurrency    string       `json:"from_currency"`
+	ToCurrency      string       `json:"to_currency"`
+	Amount          models.Money `json:"amount"`
+	ConvertedAmount models.Money `json:"converted_amount"`
+	Rate            string       `json:"rate"`
+	CreatedAt       *time.Time   `json:"created_at,omitempty"`
+}
+
+type fxConversionsResponse struct {
+	Conversions []fxConversionResponse `json:"conversions"`
+}
+
+// CreateConversion converts available funds of the user from one currency to another.
+func (handler *FXHandler) CreateConversion(writer http.ResponseWriter, request *http.Request) {
+	var body fxConversionRequest
+	if err := json.NewDecoder(request.Body).Decode(&body); err != nil {
+		writeAPIError(writer, http.StatusBadRequest, "invalid_request", "badly formed conversion")
+		return
+	}
+
+	userID := request.Context().Value(USER_ID_KEY).(string)
+	conversion, err := handler.Service.Convert(request.Context(), userID, body.FromCurrency, body.ToCurrency, body.Amount)
+	if err != nil {
+		writeFXAPIError(writer, err)
+		return
+	}
+
+	writeJSON(writer, http.StatusCreated, newFXConversionResponse(conversion))
+}
+
+func (handler *FXHandler) ListConversions(writer http.ResponseWriter, request *http.Request) {
+	userID := request.Context().Value(USER_ID_KEY).(string)
+	conversions, err := handler.Service.ListConversions(request.Context(), userID)
+	if err != nil {
+		writeFXAPIError(writer, err)
+		return
+	}
+
+	response := fxConversionsResponse{Conversions: make([]fxConversionResponse, 0, len(conversions))}
+	for _, conversion := range conversions {
+		response.Conversions = append(response.Conversions, newFXConversionResponse(conversion))
+	}
+	writeJSON(writer, http.StatusOK, response)
+}
+
+func newFXConversionResponse(conversion *models.FXConversion) fxConversionResponse {
+	return fxConversionResponse{
+		ID:              conversion.ID,
+		FromCurrency:    conversion.FromCurrency,
+		ToCurrency:      conversion.ToCurrency,
+		Amount:          conversion.Amount,
+		ConvertedAmount: conversion.ConvertedAmount,
+		Rate:            conversion.Rate,
+		CreatedAt:       nullTimeToPointer(conversion.CreatedAt),
+	}
+}
+
+func writeFXAPIError(writer http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ports.ErrInvalidAmount):
+		writeAPIError(writer, http.StatusBadRequest, "invalid_amount", err.Error())
+	case errors.Is(err, ports.ErrUnsupportedCurrency):
+		writeAPIError(writer, http.StatusBadRequest, "unsupported_currency", err.Error())
+	case errors.Is(err, ports.ErrRateNotFound):
+		writeAPIError(writer, http.StatusUnprocessableEntity, "rate_not_found", err.Error())
+	case errors.Is(err, ports.ErrInsufficientFunds):
+		writeAPIError(writer, http.StatusUnprocessableEntity, "insufficient_funds", err.Error())
+	default:
+		writeAPIError(writer, http.StatusInternalServerError, "internal_error", "internal server error")
+	}
+}
@@ -0,0 +1,136 @@
+package adapters
+
+import (
+	"brokerx/models"
+	"brokerx/ports"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type MockFXService struct {
+	mock.Mock
+}
+
+func (m *MockFXService) Convert(ctx context.Context, userID string, fromCurrency string, toCurrency string, amount models.Money) (*models.FXConversion, error) {
+	args := m.Called(ctx, userID, fromCurrency, toCurrency, amount)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.FXConversion), args.Error(1)
+}
+
+func (m *MockFXService) ListConversions(ctx context.Context, userID string) ([]*models.FXConversion, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.FXConversion), args.Error(1)
+}
+
+// ---------------------------
+// Test Suite
+// ---------------------------
+
+type HttpFXHandlerTestSuite struct {
+	suite.Suite
+	mockService *MockFXService
+	handler     *FXHandler
+	UserID      string
+}
+
+func (s *HttpFXHandlerTestSuite) SetupTest() {
+	s.mockService = new(MockFXService)
+	s.handler = &FXHandler{Service: s.mockService}
+	s.UserID = "user"
+}
+
+// ---------------------------
+// Tests
+// ---------------------------
+
+func (s *HttpFXHandlerTestSuite) TestCreateConversion() {
+	conversion := &models.FXConversion{ID: 3, UserID: s.UserID, FromCurrency: "USD", ToCurrency: "CAD", Amount: models.NewMoney(100), ConvertedAmount: models.NewMoney(137), Rate: "1.37000000"}
+	s.mockService.On("Convert", mock.Anything, s.UserID, "USD", "CAD", models.NewMoney(100)).Return(conversion, nil)
+	w := httptest.NewRecorder()
+
+	s.handler.CreateConversion(w, newAPIRequest(http.MethodPost, "/api/v1/fx/conversions", `{"from_currency":"USD","to_currency":"CAD","amount":100}`, s.UserID, ""))
+
+	s.Equal(http.StatusCreated, w.Code)
+	s.JSONEq(`{"id":3,"from_currency":"USD","to_currency":"CAD","amount":100,"converted_amount":137,"rate":"1.37000000"}`, w.Body.String())
+}
+
+func (s *HttpFXHandlerTestSuite) TestCreateConversionBadlyFormed() {
+	w := httptest.NewRecorder()
+
+	s.handler.CreateConversion(w, newAPIRequest(http.MethodPost, "/api/v1/fx/conversions", `{"amount":`, s.UserID, ""))
+
+	s.Equal(http.StatusBadRequest, w.Code)
+	s.Equal("invalid_request", decodeAPIError(&s.Suite, w).Code)
+	s.mockService.AssertNotCalled(s.T(), "Convert", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *HttpFXHandlerTestSuite) TestCreateConversionErrors() {
+	cases := []struct {
+		err    error
+		status int
+		code   string
+	}{
+		{ports.ErrInvalidAmount, http.StatusBadRequest, "invalid_amount"},
+		{ports.ErrUnsupportedCurrency, http.StatusBadRequest, "unsupported_currency"},
+		{ports.ErrRateNotFound, http.StatusUnprocessableEntity, "rate_not_found"},
+		{ports.ErrInsufficientFunds, http.StatusUnprocessableEntity, "insufficient_funds"},
+		{assert.AnError, http.StatusInternalServerError, "internal_error"},
+	}
+	for _, c := range cases {
+		s.SetupTest()
+		s.mockService.On("Convert", mock.Anything, s.UserID, "USD", "EUR", models.NewMoney(10)).Return(nil, c.err)
+		w := httptest.NewRecorder()
+
+		s.handler.CreateConversion(w, newAPIRequest(http.MethodPost, "/api/v1/fx/conversions", `{"from_currency":"USD","to_currency":"EUR","amount":10}`, s.UserID, ""))
+
+		s.Equal(c.status, w.Code)
+		s.Equal(c.code, decodeAPIError(&s.Suite, w).Code)
+	}
+}
+
+func (s *HttpFXHandlerTestSuite) TestListConversions() {
+	conversions := []*models.FXConversion{
+		{ID: 2, FromCurrency: "CAD", ToCurrency: "USD", Amount: models.NewMoney(100), ConvertedAmount: models.MustParseMoney("72.99"), Rate: "0.72992701"},
+		{ID: 1, FromCurrency: "USD", ToCurrency: "CAD", Amount: models.NewMoney(100), ConvertedAmount: models.NewMoney(137), Rate: "1.37000000"},
+	}
+	s.mockService.On("ListConversions", mock.Anything, s.UserID).Return(conversions, nil)
+	w := httptest.NewRecorder()
+
+	s.handler.ListConversions(w, newAPIRequest(http.MethodGet, "/api/v1/fx/conversions", "", s.UserID, ""))
+
+	s.Equal(http.StatusOK, w.Code)
+	var response fxConversionsResponse
+	s.Require().NoError(json.NewDecoder(w.Body).Decode(&response))
+	s.Require().Len(response.Conversions, 2)
+	s.Equal(models.MustParseMoney("72.99"), response.Conversions[0].ConvertedAmount)
+	s.Equal("1.37000000", response.Conversions[1].Rate)
+}
+
+func (s *HttpFXHandlerTestSuite) TestListConversionsInternalError() {
+	s.mockService.On("ListConversions", mock.Anything, s.UserID).Return(nil, assert.AnError)
+	w := httptest.NewRecorder()
+
+	s.handler.ListConversions(w, newAPIRequest(http.MethodGet, "/api/v1/fx/conversions", "", s.UserID, ""))
+
+	s.Equal(http.StatusInternalServerError, w.Code)
+	s.Equal("internal_error", decodeAPIError(&s.Suite, w).Code)
+}
+
+// ---------------------------
+// Run the suite
+// ---------------------------
+func TestHttpFXHandlerTestSuite(t *testing.T) {
+	suite.Run(t, new(HttpFXHandlerTestSuite))
+}
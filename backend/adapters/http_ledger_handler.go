@@ -0,0 +1,132 @@
+package adapters
+
+import (
+	"brokerx/models"
+	"brokerx/ports"
+	"net/http"
+	"time"
+)
+
+// LedgerHandler exposes the wallets and the cash history of the authenticated user, and
+// the reconciliation of the wallets for the back office, as a JSON API.
+type LedgerHandler struct {
+	Service ports.LedgerService
+}
+
+type postingResponse struct {
+	Account  string       `json:"account"`
+	Currency string       `json:"currency"`
+	Amount   models.Money `json:"amount"`
+}
+
+type journalEntryResponse struct {
+	ID        int               `json:"id"`
+	Type      string            `json:"type"`
+	Reference string            `json:"reference"`
+	Postings  []postingResponse `json:"postings"`
+	CreatedAt *time.Time        `json:"created_at,omitempty"`
+}
+
+type journalEntriesResponse struct {
+	Entries []journalEntryResponse `json:"entries"`
+}
+
+type walletResponse struct {
+	Currency       string       `json:"currency"`
+	AvailableFunds models.Money `json:"available_funds"`
+	OnHoldFunds    models.Money `json:"funds_on_hold"`
+	UnsettledFunds models.Money `json:"unsettled_funds"`
+}
+
+type walletsResponse struct {
+	Wallets []walletResponse `json:"wallets"`
+}
+
+type discrepancyResponse struct {
+	UserID               string       `json:"user_id"`
+	Currency             string       `json:"currency"`
+	StoredAvailableFunds models.Money `json:"stored_available_funds"`
+	LedgerAvailableFunds models.Money `json:"ledger_available_funds"`
+	StoredOnHoldFunds    models.Money `json:"stored_funds_on_hold"`
+	LedgerOnHoldFunds    models.Money `json:"ledger_funds_on_hold"`
+	StoredUnsettledFunds models.Money `json:"stored_unsettled_funds"`
+	LedgerUnsettledFunds models.Money `json:"ledger_unsettled_funds"`
+}
+
+type reconciliationResponse struct {
+	Discrepancies []discrepancyResponse `json:"discrepancies"`
+}
+
+func (handler *LedgerHandler) ListEntries(writer http.ResponseWriter, request *http.Request) {
+	userID := request.Context().Value(USER_ID_KEY).(string)
+	entries, err := handler.Service.ListEntries(request.Context(), userID)
+	if err != nil {
+		writeAPIError(writer, http.StatusInternalServerError, "internal_error", "internal server error")
+		return
+	}
+
+	response := journalEntriesResponse{Entries: make([]journalEntryResponse, 0, len(entries))}
+	for _, entry := range entries {
+		response.Entries = append(response.Entries, newJournalEntryResponse(entry))
+	}
+	writeJSON(writer, http.StatusOK, response)
+}
+
+// ListWallets returns the balances of the user, one wallet per currency.
+func (handler *LedgerHandler) ListWallets(writer http.ResponseWriter, request *http.Request) {
+	userID := request.Context().Value(USER_ID_KEY).(string)
+	wallets, err := handler.Service.ListWallets(request.Context(), userID)
+	if err != nil {
+		writeAPIError(writer, http.StatusInternalServerError, "internal_error", "internal server error")
+		return
+	}
+
+	response := walletsResponse{Wallets: make([]walletResponse, 0, len(wallets))}
+	for _, wallet := range wallets {
+		response.Wallets = append(response.Wallets, walletResponse{
+			Currency:       wallet.Currency,
+			AvailableFunds: wallet.AvailableFunds,
+			OnHoldFunds:    wallet.OnHoldFunds,
+			UnsettledFunds: wallet.UnsettledFunds,
+		})
+	}
+	writeJSON(writer, http.StatusOK, response)
+}
+
+// Reconcile returns the wallets whose stored balances disagree with the ledger.
+func (handler *LedgerHandler) Reconcile(writer http.ResponseWriter, request *http.Request) {
+	discrepancies, err := handler.Service.Reconcile(request.Context())
+	if err != nil {
+		writeAPIError(writer, http.StatusInternalServerError, "internal_error", "internal server error")
+		return
+	}
+
+	response := reconciliationResponse{Discrepancies: make([]discrepancyResponse, 0, len(discrepancies))}
+	for _, discrepancy := range discrepancies {
+		response.Discrepancies = append(response.Discrepancies, discrepancyResponse{
+			UserID:               discrepancy.UserID,
+			Currency:             discrepancy.Currency,
+			StoredAvailableFunds: discrepancy.StoredAvailableFunds,
+			LedgerAvailableFunds: discrepancy.LedgerAvailableFunds,
+			StoredOnHoldFunds:    discrepancy.StoredOnHoldFunds,
+			LedgerOnHoldFunds:    discrepancy.LedgerOnHoldFunds,
+			StoredUnsettledFunds: discrepancy.StoredUnsettledFunds,
+			LedgerUnsettledFunds: discrepancy.LedgerUnsettledFunds,
+		})
+	}
+	writeJSON(writer, http.StatusOK, response)
+}
+
+func newJournalEntryResponse(entry *models.JournalEntry) journalEntryResponse {
+	response := journalEntryResponse{
+		ID:        entry.ID,
+		Type:      entry.Type,
+		Reference: entry.Reference,
+		Postings:  make([]postingResponse, 0, len(entry.Postings)),
+		CreatedAt: nullTimeToPointer(entry.CreatedAt),
+	}
+	for _, posting := range entry.Postings {
+		response.Postings = append(response.Postings, postingResponse{Account: posting.Account, Currency: posting.Currency, Amount: posting.Amount})
+	}
+	return response
+}
@@ -0,0 +1,143 @@
+package adapters
+
+import (
+	"brokerx/models"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type MockLedgerService struct {
+	mock.Mock
+}
+
+func (m *MockLedgerService) ListEntries(ctx context.Context, userID string) ([]*models.JournalEntry, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.JournalEntry), args.Error(1)
+}
+
+func (m *MockLedgerService) ListWallets(ctx context.Context, userID string) ([]*models.Wallet, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Wallet), args.Error(1)
+}
+
+func (m *MockLedgerService) Reconcile(ctx context.Context) ([]*models.WalletDiscrepancy, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.WalletDiscrepancy), args.Error(1)
+}
+
+// ---------------------------
+// Test Suite
+// ---------------------------
+
+type HttpLedgerHandlerTestSuite struct {
+	suite.Suite
+	mockService *MockLedgerService
+	handler     *LedgerHandler
+	UserID      string
+}
+
+func (s *HttpLedgerHandlerTestSuite) SetupTest() {
+	s.mockService = new(MockLedgerService)
+	s.handler = &LedgerHandler{Service: s.mockService}
+	s.UserID = "user"
+}
+
+// ---------------------------
+// Tests
+// ---------------------------
+
+func (s *HttpLedgerHandlerTestSuite) TestListEntries() {
+	entries := []*models.JournalEntry{{ID: 2, Type: "hold", Reference: "order:4", Postings: []models.LedgerPosting{
+		{Account: "available", UserID: s.UserID, Currency: "CAD", Amount: models.NewMoney(-150)},
+		{Account: "on_hold", UserID: s.UserID, Currency: "CAD", Amount: models.NewMoney(150)},
+	}}}
+	s.mockService.On("ListEntries", mock.Anything, s.UserID).Return(entries, nil)
+	w := httptest.NewRecorder()
+
+	s.handler.ListEntries(w, newAPIRequest(http.MethodGet, "/api/v1/ledger", "", s.UserID, ""))
+
+	s.Equal(http.StatusOK, w.Code)
+	var response journalEntriesResponse
+	s.Require().NoError(json.NewDecoder(w.Body).Decode(&response))
+	s.Require().Len(response.Entries, 1)
+	s.Equal("order:4", response.Entries[0].Reference)
+	s.Equal([]postingResponse{{Account: "available", Currency: "CAD", Amount: models.NewMoney(-150)}, {Account: "on_hold", Currency: "CAD", Amount: models.NewMoney(150)}}, response.Entries[0].Postings)
+}
+
+func (s *HttpLedgerHandlerTestSuite) TestListEntriesFailure() {
+	s.mockService.On("ListEntries", mock.Anything, s.UserID).Return(nil, assert.AnError)
+	w := httptest.NewRecorder()
+
+	s.handler.ListEntries(w, newAPIRequest(http.MethodGet, "/api/v1/ledger", "", s.UserID, ""))
+
+	s.Equal(http.StatusInternalServerError, w.Code)
+	s.Equal("internal_error", decodeAPIError(&s.Suite, w).Code)
+}
+
+func (s *HttpLedgerHandlerTestSuite) TestListWallets() {
+	wallets := []*models.Wallet{
+		{UserId: s.UserID, Currency: "CAD", AvailableFunds: models.MustParseMoney("136.99")},
+		{UserId: s.UserID, Currency: "USD", AvailableFunds: models.NewMoney(900), OnHoldFunds: models.NewMoney(100), UnsettledFunds: models.NewMoney(250)},
+	}
+	s.mockService.On("ListWallets", mock.Anything, s.UserID).Return(wallets, nil)
+	w := httptest.NewRecorder()
+
+	s.handler.ListWallets(w, newAPIRequest(http.MethodGet, "/api/v1/wallets", "", s.UserID, ""))
+
+	s.Equal(http.StatusOK, w.Code)
+	s.JSONEq(`{"wallets":[{"currency":"CAD","available_funds":136.99,"funds_on_hold":0,"unsettled_funds":0},{"currency":"USD","available_funds":900,"funds_on_hold":100,"unsettled_funds":250}]}`, w.Body.String())
+}
+
+func (s *HttpLedgerHandlerTestSuite) TestListWalletsFailure() {
+	s.mockService.On("ListWallets", mock.Anything, s.UserID).Return(nil, assert.AnError)
+	w := httptest.NewRecorder()
+
+	s.handler.ListWallets(w, newAPIRequest(http.MethodGet, "/api/v1/wallets", "", s.UserID, ""))
+
+	s.Equal(http.StatusInternalServerError, w.Code)
+	s.Equal("internal_error", decodeAPIError(&s.Suite, w).Code)
+}
+
+func (s *HttpLedgerHandlerTestSuite) TestReconcile() {
+	discrepancies := []*models.WalletDiscrepancy{{UserID: "drifted", Currency: "USD", StoredAvailableFunds: models.NewMoney(1000), LedgerAvailableFunds: models.NewMoney(900), LedgerOnHoldFunds: models.NewMoney(100)}}
+	s.mockService.On("Reconcile", mock.Anything).Return(discrepancies, nil)
+	w := httptest.NewRecorder()
+
+	s.handler.Reconcile(w, newAPIRequest(http.MethodGet, "/api/v1/back-office/reconciliation", "", s.UserID, ""))
+
+	s.Equal(http.StatusOK, w.Code)
+	s.JSONEq(`{"discrepancies":[{"user_id":"drifted","currency":"USD","stored_available_funds":1000,"ledger_available_funds":900,"stored_funds_on_hold":0,"ledger_funds_on_hold":100,"stored_unsettled_funds":0,"ledger_unsettled_funds":0}]}`, w.Body.String())
+}
+
+func (s *HttpLedgerHandlerTestSuite) TestReconcileNoDiscrepancy() {
+	s.mockService.On("Reconcile", mock.Anything).Return([]*models.WalletDiscrepancy{}, nil)
+	w := httptest.NewRecorder()
+
+	s.handler.Reconcile(w, newAPIRequest(http.MethodGet, "/api/v1/back-office/reconciliation", "", s.UserID, ""))
+
+	s.Equal(http.StatusOK, w.Code)
+	s.JSONEq(`{"discrepancies":[]}`, w.Body.String())
+}
+
+// ---------------------------
+// Run the suite
+// ---------------------------
+func TestHttpLedgerHandlerTestSuite(t *testing.T) {
+	suite.Run(t, new(HttpLedgerHandlerTestSuite))
+}
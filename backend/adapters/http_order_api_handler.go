@@ -0,0 +1,270 @@
+package adapters
+
+import (
+	"brokerx/models"
+	"brokerx/ports"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// OrderAPIHandler exposes the orders of the authenticated user as a JSON API.
+type OrderAPIHandler struct {
+	Service ports.OrderService
+}
+
+type orderRequest struct {
+	Symbol    string       `json:"symbol"`
+	Type      string       `json:"type"`
+	Action    string       `json:"action"`
+	Quantity  int          `json:"quantity"`
+	UnitPrice models.Money `json:"unit_price"`
+	StopPrice models.Money `json:"stop_price"`
+	Timing    string       `json:"timing"`
+	ExpiresAt *time.Time   `json:"expires_at"`
+	LotID     int          `json:"lot_id"`
+}
+
+type orderResponse struct {
+	ID               int          `json:"id"`
+	Symbol           string       `json:"symbol"`
+	Currency         string       `json:"currency"`
+	Type             string       `json:"type"`
+	Action           string       `json:"action"`
+	Quantity         int          `json:"quantity"`
+	UnitPrice        models.Money `json:"unit_price"`
+	StopPrice        models.Money `json:"stop_price,omitzero"`
+	Timing           string       `json:"timing"`
+	ExpiresAt        *time.Time   `json:"expires_at,omitempty"`
+	LotID            int          `json:"lot_id,omitempty"`
+	Status           string       `json:"status"`
+	FilledQuantity   int          `json:"filled_quantity"`
+	AverageFillPrice models.Money `json:"average_fill_price"`
+	Commission       models.Money `json:"commission"`
+	Version          int          `json:"version"`
+	CreatedAt        *time.Time   `json:"created_at,omitempty"`
+	UpdatedAt        *time.Time   `json:"updated_at,omitempty"`
+}
+
+type orderPageResponse struct {
+	Orders     []orderResponse `json:"orders"`
+	NextCursor int             `json:"next_cursor,omitempty"`
+}
+
+type apiError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+type apiErrorResponse struct {
+	Error apiError `json:"error"`
+}
+
+func (handler *OrderAPIHandler) CreateOrder(writer http.ResponseWriter, request *http.Request) {
+	var body orderRequest
+	if err := json.NewDecoder(request.Body).Decode(&body); err != nil {
+		writeAPIError(writer, http.StatusBadRequest, "invalid_request", "badly formed order")
+		return
+	}
+
+	order := body.toOrder(request.Context().Value(USER_ID_KEY).(string))
+	if !isValidOrder(order) {
+		writeAPIError(writer, http.StatusBadRequest, "invalid_order", "invalid order")
+		return
+	}
+
+	if err := handler.Service.PlaceOrder(request.Context(), order); err != nil {
+		writeOrderAPIError(writer, err)
+		return
+	}
+
+	writeJSON(writer, http.StatusCreated, newOrderResponse(order))
+}
+
+// ListOrders returns a page of the orders of the user. The orders can be filtered with
+// the status, symbol, side, from and to (RFC 3339) query parameters, and paged with the
+// cursor and limit query parameters.
+func (handler *OrderAPIHandler) ListOrders(writer http.ResponseWriter, request *http.Request) {
+	filter, err := parseOrderFilter(request.URL.Query())
+	if err != nil {
+		writeAPIError(writer, http.StatusBadRequest, "invalid_filter", err.Error())
+		return
+	}
+
+	userID := request.Context().Value(USER_ID_KEY).(string)
+	page, err := handler.Service.ListOrders(request.Context(), userID, filter)
+	if err != nil {
+		writeOrderAPIError(writer, err)
+		return
+	}
+
+	response := orderPageResponse{Orders: make([]orderResponse, 0, len(page.Orders)), NextCursor: page.NextCursor}
+	for _, order := range page.Orders {
+		response.Orders = append(response.Orders, newOrderResponse(order))
+	}
+	writeJSON(writer, http.StatusOK, response)
+}
+
+func (handler *OrderAPIHandler) GetOrder(writer http.ResponseWriter, request *http.Request) {
+	orderID, err := strconv.Atoi(chi.URLParam(request, "id"))
+	if err != nil {
+		writeAPIError(writer, http.StatusBadRequest, "invalid_order_id", "invalid order id")
+		return
+	}
+
+	userID := request.Context().Value(USER_ID_KEY).(string)
+	order, err := handler.Service.GetOrder(request.Context(), userID, orderID)
+	if err != nil {
+		writeOrderAPIError(writer, err)
+		return
+	}
+
+	writeJSON(writer, http.StatusOK, newOrderResponse(order))
+}
+
+// CancelOrder cancels the order and returns it in its canceled state.
+func (handler *OrderAPIHandler) CancelOrder(writer http.ResponseWriter, request *http.Request) {
+	orderID, err := strconv.Atoi(chi.URLParam(request, "id"))
+	if err != nil {
+		writeAPIError(writer, http.StatusBadRequest, "invalid_order_id", "invalid order id")
+		return
+	}
+
+	userID := request.Context().Value(USER_ID_KEY).(string)
+	if err = handler.Service.CancelOrder(request.Context(), userID, orderID); err != nil {
+		writeOrderAPIError(writer, err)
+		return
+	}
+
+	order, err := handler.Service.GetOrder(request.Context(), userID, orderID)
+	if err != nil {
+		writeOrderAPIError(writer, err)
+		return
+	}
+
+	writeJSON(writer, http.StatusOK, newOrderResponse(order))
+}
+
+func (body *orderRequest) toOrder(userID string) *models.Order {
+	order := &models.Order{
+		UserID:    userID,
+		Symbol:    body.Symbol,
+		Type:      body.Type,
+		Action:    body.Action,
+		Quantity:  body.Quantity,
+		UnitPrice: body.UnitPrice,
+		StopPrice: body.StopPrice,
+		Timing:    body.Timing,
+		LotID:     body.LotID,
+		Status:    "open",
+	}
+	if body.ExpiresAt != nil {
+		order.ExpiresAt = sql.NullTime{Time: *body.ExpiresAt, Valid: true}
+	}
+	return order
+}
+
+func parseOrderFilter(query url.Values) (models.OrderFilter, error) {
+	filter := models.OrderFilter{
+		Status: query.Get("status"),
+		Symbol: query.Get("symbol"),
+		Action: query.Get("side"),
+	}
+	if filter.Action != "" && filter.Action != "buy" && filter.Action != "sell" {
+		return filter, errors.New("side must be buy or sell")
+	}
+
+	var err error
+	if value := query.Get("from"); value != "" {
+		if filter.From, err = time.Parse(time.RFC3339, value); err != nil {
+			return filter, errors.New("from must be an RFC 3339 timestamp")
+		}
+	}
+	if value := query.Get("to"); value != "" {
+		if filter.To, err = time.Parse(time.RFC3339, value); err != nil {
+			return filter, errors.New("to must be an RFC 3339 timestamp")
+		}
+	}
+	if value := query.Get("cursor"); value != "" {
+		if filter.Cursor, err = strconv.Atoi(value); err != nil || filter.Cursor < 0 {
+			return filter, errors.New("cursor must be a positive integer")
+		}
+	}
+	if value := query.Get("limit"); value != "" {
+		if filter.Limit, err = strconv.Atoi(value); err != nil || filter.Limit < 0 {
+			return filter, errors.New("limit must be a positive integer")
+		}
+	}
+
+	return filter, nil
+}
+
+func newOrderResponse(order *models.Order) orderResponse {
+	return orderResponse{
+		ID:               order.ID,
+		Symbol:           order.Symbol,
+		Currency:         order.Currency,
+		Type:             order.Type,
+		Action:           order.Action,
+		Quantity:         order.Quantity,
+		UnitPrice:        order.UnitPrice,
+		StopPrice:        order.StopPrice,
+		Timing:           order.Timing,
+		ExpiresAt:        nullTimeToPointer(order.ExpiresAt),
+		LotID:            order.LotID,
+		Status:           order.Status,
+		FilledQuantity:   order.FilledQuantity,
+		AverageFillPrice: order.AverageFillPrice,
+		Commission:       order.Commission,
+		Version:          order.Version,
+		CreatedAt:        nullTimeToPointer(order.CreatedAt),
+		UpdatedAt:        nullTimeToPointer(order.UpdatedAt),
+	}
+}
+
+func nullTimeToPointer(value sql.NullTime) *time.Time {
+	if !value.Valid {
+		return nil
+	}
+	return &value.Time
+}
+
+func writeOrderAPIError(writer http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ports.ErrOrderNotFound):
+		writeAPIError(writer, http.StatusNotFound, "order_not_found", err.Error())
+	case errors.Is(err, ports.ErrOrderNotOwned):
+		writeAPIError(writer, http.StatusForbidden, "order_not_owned", err.Error())
+	case errors.Is(err, ports.ErrOrderNotCancelable):
+		writeAPIError(writer, http.StatusConflict, "order_not_cancelable", err.Error())
+	case errors.Is(err, ports.ErrInsufficientFunds):
+		writeAPIError(writer, http.StatusUnprocessableEntity, "insufficient_funds", err.Error())
+	case errors.Is(err, ports.ErrInsufficientShares):
+		writeAPIError(writer, http.StatusUnprocessableEntity, "insufficient_shares", err.Error())
+	case errors.Is(err, ports.ErrLotNotFound):
+		writeAPIError(writer, http.StatusUnprocessableEntity, "lot_not_found", err.Error())
+	case errors.Is(err, ports.ErrFractionalCents):
+		writeAPIError(writer, http.StatusBadRequest, "invalid_order", "price is not in the minor unit of the trading currency")
+	default:
+		writeAPIError(writer, http.StatusInternalServerError, "internal_error", "internal server error")
+	}
+}
+
+func writeAPIError(writer http.ResponseWriter, status int, code string, message string) {
+	writeJSON(writer, status, apiErrorResponse{Error: apiError{Code: code, Message: message}})
+}
+
+func writeJSON(writer http.ResponseWriter, status int, body any) {
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(status)
+	if err := json.NewEncoder(writer).Encode(body); err != nil {
+		log.Printf("Failed to encode response: %v", err)
+	}
+}
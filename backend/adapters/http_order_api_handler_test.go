@@ -0,0 +1,268 @@
+package adapters
+
+import (
+	"brokerx/models"
+	"brokerx/ports"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+func newAPIRequest(method string, target string, body string, userID string, orderID string) *http.Request {
+	req := httptest.NewRequest(method, target, bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	ctx := context.WithValue(req.Context(), USER_ID_KEY, userID)
+	if orderID != "" {
+		routeContext := chi.NewRouteContext()
+		routeContext.URLParams.Add("id", orderID)
+		ctx = context.WithValue(ctx, chi.RouteCtxKey, routeContext)
+	}
+	return req.WithContext(ctx)
+}
+
+func decodeAPIError(s *suite.Suite, w *httptest.ResponseRecorder) apiError {
+	var body apiErrorResponse
+	s.Require().NoError(json.NewDecoder(w.Body).Decode(&body))
+	return body.Error
+}
+
+// ---------------------------
+// Test Suite
+// ---------------------------
+
+type HttpOrderAPIHandlerTestSuite struct {
+	suite.Suite
+	mockService *MockOrderService
+	handler     *OrderAPIHandler
+	UserID      string
+}
+
+func (s *HttpOrderAPIHandlerTestSuite) SetupTest() {
+	s.mockService = new(MockOrderService)
+	s.handler = &OrderAPIHandler{Service: s.mockService}
+	s.UserID = "user"
+}
+
+// ---------------------------
+// Tests
+// ---------------------------
+
+func (s *HttpOrderAPIHandlerTestSuite) TestCreateOrderSuccess() {
+	s.mockService.On("PlaceOrder", mock.Anything, mock.AnythingOfType("*models.Order")).Return(nil).Run(func(args mock.Arguments) {
+		order := args.Get(1).(*models.Order)
+		order.ID, order.Version = 12, 1
+	})
+	body := `{"symbol":"AAPL","type":"limit","action":"buy","quantity":10,"unit_price":150.5,"timing":"day"}`
+	w := httptest.NewRecorder()
+
+	s.handler.CreateOrder(w, newAPIRequest(http.MethodPost, "/api/v1/orders", body, s.UserID, ""))
+
+	s.Equal(http.StatusCreated, w.Code)
+	s.Equal("application/json", w.Header().Get("Content-Type"))
+	var response orderResponse
+	s.Require().NoError(json.NewDecoder(w.Body).Decode(&response))
+	s.Equal(12, response.ID)
+	s.Equal("AAPL", response.Symbol)
+	s.Equal(models.MustParseMoney("150.5"), response.UnitPrice)
+	s.Equal("open", response.Status)
+	s.mockService.AssertCalled(s.T(), "PlaceOrder", mock.Anything, mock.MatchedBy(func(order *models.Order) bool {
+		return order.UserID == s.UserID
+	}))
+}
+
+func (s *HttpOrderAPIHandlerTestSuite) TestCreateOrderMalformedBody() {
+	w := httptest.NewRecorder()
+
+	s.handler.CreateOrder(w, newAPIRequest(http.MethodPost, "/api/v1/orders", `{"quantity":"ten"}`, s.UserID, ""))
+
+	s.Equal(http.StatusBadRequest, w.Code)
+	s.Equal("invalid_request", decodeAPIError(&s.Suite, w).Code)
+}
+
+func (s *HttpOrderAPIHandlerTestSuite) TestCreateOrderInvalidOrder() {
+	w := httptest.NewRecorder()
+
+	s.handler.CreateOrder(w, newAPIRequest(http.MethodPost, "/api/v1/orders", `{"symbol":"AAPL","type":"limit","action":"buy","quantity":0,"unit_price":150,"timing":"day"}`, s.UserID, ""))
+
+	s.Equal(http.StatusBadRequest, w.Code)
+	s.Equal("invalid_order", decodeAPIError(&s.Suite, w).Code)
+	s.mockService.AssertNotCalled(s.T(), "PlaceOrder", mock.Anything, mock.Anything)
+}
+
+func (s *HttpOrderAPIHandlerTestSuite) TestCreateOrderFractionalCentPrice() {
+	w := httptest.NewRecorder()
+
+	s.handler.CreateOrder(w, newAPIRequest(http.MethodPost, "/api/v1/orders", `{"symbol":"AAPL","type":"limit","action":"buy","quantity":10,"unit_price":150.005,"timing":"day"}`, s.UserID, ""))
+
+	s.Equal(http.StatusBadRequest, w.Code)
+	s.Equal("invalid_order", decodeAPIError(&s.Suite, w).Code)
+	s.mockService.AssertNotCalled(s.T(), "PlaceOrder", mock.Anything, mock.Anything)
+}
+
+func (s *HttpOrderAPIHandlerTestSuite) TestCreateOrderLotOnBuyOrder() {
+	w := httptest.NewRecorder()
+
+	s.handler.CreateOrder(w, newAPIRequest(http.MethodPost, "/api/v1/orders", `{"symbol":"AAPL","type":"limit","action":"buy","quantity":10,"unit_price":150,"timing":"day","lot_id":3}`, s.UserID, ""))
+
+	s.Equal(http.StatusBadRequest, w.Code)
+	s.Equal("invalid_order", decodeAPIError(&s.Suite, w).Code)
+}
+
+func (s *HttpOrderAPIHandlerTestSuite) TestCreateOrderUnknownLot() {
+	s.mockService.On("PlaceOrder", mock.Anything, mock.MatchedBy(func(order *models.Order) bool {
+		return order.LotID == 3
+	})).Return(ports.ErrLotNotFound)
+	body := `{"symbol":"AAPL","type":"limit","action":"sell","quantity":10,"unit_price":150,"timing":"day","lot_id":3}`
+	w := httptest.NewRecorder()
+
+	s.handler.CreateOrder(w, newAPIRequest(http.MethodPost, "/api/v1/orders", body, s.UserID, ""))
+
+	s.Equal(http.StatusUnprocessableEntity, w.Code)
+	s.Equal("lot_not_found", decodeAPIError(&s.Suite, w).Code)
+}
+
+func (s *HttpOrderAPIHandlerTestSuite) TestCreateOrderInsufficientFunds() {
+	s.mockService.On("PlaceOrder", mock.Anything, mock.Anything).Return(ports.ErrInsufficientFunds)
+	body := `{"symbol":"AAPL","type":"limit","action":"buy","quantity":10,"unit_price":150,"timing":"day"}`
+	w := httptest.NewRecorder()
+
+	s.handler.CreateOrder(w, newAPIRequest(http.MethodPost, "/api/v1/orders", body, s.UserID, ""))
+
+	s.Equal(http.StatusUnprocessableEntity, w.Code)
+	s.Equal(apiError{Code: "insufficient_funds", Message: ports.ErrInsufficientFunds.Error()}, decodeAPIError(&s.Suite, w))
+}
+
+func (s *HttpOrderAPIHandlerTestSuite) TestListOrders() {
+	orders := []*models.Order{{ID: 5, Symbol: "AAPL", Status: "open"}, {ID: 4, Symbol: "AAPL", Status: "filled", FilledQuantity: 10, AverageFillPrice: models.MustParseMoney("149.5")}}
+	filter := models.OrderFilter{
+		Status: "filled",
+		Symbol: "AAPL",
+		Action: "buy",
+		From:   time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC),
+		To:     time.Date(2025, 10, 2, 0, 0, 0, 0, time.UTC),
+		Cursor: 6,
+		Limit:  2,
+	}
+	s.mockService.On("ListOrders", mock.Anything, s.UserID, filter).Return(&models.OrderPage{Orders: orders, NextCursor: 4}, nil)
+	target := "/api/v1/orders?status=filled&symbol=AAPL&side=buy&from=2025-10-01T00:00:00Z&to=2025-10-02T00:00:00Z&cursor=6&limit=2"
+	w := httptest.NewRecorder()
+
+	s.handler.ListOrders(w, newAPIRequest(http.MethodGet, target, "", s.UserID, ""))
+
+	s.Equal(http.StatusOK, w.Code)
+	var response orderPageResponse
+	s.Require().NoError(json.NewDecoder(w.Body).Decode(&response))
+	s.Require().Len(response.Orders, 2)
+	s.Equal(5, response.Orders[0].ID)
+	s.Equal(10, response.Orders[1].FilledQuantity)
+	s.Equal(models.MustParseMoney("149.5"), response.Orders[1].AverageFillPrice)
+	s.Equal(4, response.NextCursor)
+}
+
+func (s *HttpOrderAPIHandlerTestSuite) TestListOrdersInvalidFilter() {
+	for _, query := range []string{"side=short", "from=yesterday", "to=2025-10-02", "cursor=-1", "limit=ten"} {
+		w := httptest.NewRecorder()
+
+		s.handler.ListOrders(w, newAPIRequest(http.MethodGet, "/api/v1/orders?"+query, "", s.UserID, ""))
+
+		s.Equal(http.StatusBadRequest, w.Code, query)
+		s.Equal("invalid_filter", decodeAPIError(&s.Suite, w).Code, query)
+	}
+	s.mockService.AssertNotCalled(s.T(), "ListOrders", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *HttpOrderAPIHandlerTestSuite) TestListOrdersEmpty() {
+	s.mockService.On("ListOrders", mock.Anything, s.UserID, models.OrderFilter{}).Return(&models.OrderPage{}, nil)
+	w := httptest.NewRecorder()
+
+	s.handler.ListOrders(w, newAPIRequest(http.MethodGet, "/api/v1/orders", "", s.UserID, ""))
+
+	s.Equal(http.StatusOK, w.Code)
+	s.JSONEq(`{"orders":[]}`, w.Body.String())
+}
+
+func (s *HttpOrderAPIHandlerTestSuite) TestListOrdersInternalError() {
+	s.mockService.On("ListOrders", mock.Anything, s.UserID, mock.Anything).Return(nil, assert.AnError)
+	w := httptest.NewRecorder()
+
+	s.handler.ListOrders(w, newAPIRequest(http.MethodGet, "/api/v1/orders", "", s.UserID, ""))
+
+	s.Equal(http.StatusInternalServerError, w.Code)
+	s.Equal("internal_error", decodeAPIError(&s.Suite, w).Code)
+}
+
+func (s *HttpOrderAPIHandlerTestSuite) TestGetOrder() {
+	s.mockService.On("GetOrder", mock.Anything, s.UserID, 7).Return(&models.Order{ID: 7, Symbol: "AAPL", Status: "partially filled", FilledQuantity: 3, Commission: models.NewMoney(1)}, nil)
+	w := httptest.NewRecorder()
+
+	s.handler.GetOrder(w, newAPIRequest(http.MethodGet, "/api/v1/orders/7", "", s.UserID, "7"))
+
+	s.Equal(http.StatusOK, w.Code)
+	var response orderResponse
+	s.Require().NoError(json.NewDecoder(w.Body).Decode(&response))
+	s.Equal(7, response.ID)
+	s.Equal(3, response.FilledQuantity)
+	s.Equal(models.NewMoney(1), response.Commission)
+}
+
+func (s *HttpOrderAPIHandlerTestSuite) TestGetOrderErrors() {
+	s.mockService.On("GetOrder", mock.Anything, s.UserID, 404).Return(nil, ports.ErrOrderNotFound)
+	s.mockService.On("GetOrder", mock.Anything, s.UserID, 403).Return(nil, ports.ErrOrderNotOwned)
+	cases := map[string]struct {
+		status int
+		code   string
+	}{
+		"abc": {http.StatusBadRequest, "invalid_order_id"},
+		"404": {http.StatusNotFound, "order_not_found"},
+		"403": {http.StatusForbidden, "order_not_owned"},
+	}
+
+	for orderID, expected := range cases {
+		w := httptest.NewRecorder()
+
+		s.handler.GetOrder(w, newAPIRequest(http.MethodGet, "/api/v1/orders/"+orderID, "", s.UserID, orderID))
+
+		s.Equal(expected.status, w.Code, orderID)
+		s.Equal(expected.code, decodeAPIError(&s.Suite, w).Code, orderID)
+	}
+}
+
+func (s *HttpOrderAPIHandlerTestSuite) TestCancelOrderReturnsCanceledOrder() {
+	s.mockService.On("CancelOrder", mock.Anything, s.UserID, 7).Return(nil)
+	s.mockService.On("GetOrder", mock.Anything, s.UserID, 7).Return(&models.Order{ID: 7, Status: "canceled"}, nil)
+	w := httptest.NewRecorder()
+
+	s.handler.CancelOrder(w, newAPIRequest(http.MethodDelete, "/api/v1/orders/7", "", s.UserID, "7"))
+
+	s.Equal(http.StatusOK, w.Code)
+	var response orderResponse
+	s.Require().NoError(json.NewDecoder(w.Body).Decode(&response))
+	s.Equal("canceled", response.Status)
+}
+
+func (s *HttpOrderAPIHandlerTestSuite) TestCancelOrderNotCancelable() {
+	s.mockService.On("CancelOrder", mock.Anything, s.UserID, 7).Return(ports.ErrOrderNotCancelable)
+	w := httptest.NewRecorder()
+
+	s.handler.CancelOrder(w, newAPIRequest(http.MethodDelete, "/api/v1/orders/7", "", s.UserID, "7"))
+
+	s.Equal(http.StatusConflict, w.Code)
+	s.Equal("order_not_cancelable", decodeAPIError(&s.Suite, w).Code)
+	s.mockService.AssertNotCalled(s.T(), "GetOrder", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// ---------------------------
+// Run the suite
+// ---------------------------
+func TestHttpOrderAPIHandlerTestSuite(t *testing.T) {
+	suite.Run(t, new(HttpOrderAPIHandlerTestSuite))
+}
@@ -3,9 +3,15 @@ package adapters
 import (
 	"brokerx/models"
 	"brokerx/ports"
+	"database/sql"
+	"errors"
 	"log"
 	"net/http"
+	"reflect"
+	"strconv"
+	"time"
 
+	"github.com/go-chi/chi/v5"
 	"github.com/gorilla/schema"
 )
 
@@ -22,7 +28,7 @@ func (handler *OrderHandler) PlaceOrder(writer http.ResponseWriter, request *htt
 		return
 	}
 
-	err = handler.Service.PlaceOrder(order)
+	err = handler.Service.PlaceOrder(request.Context(), order)
 	if err != nil {
 		writer.WriteHeader(http.StatusInternalServerError)
 		http.ServeFile(writer, request, "./frontend/order_failed.html")
@@ -33,26 +39,137 @@ func (handler *OrderHandler) PlaceOrder(writer http.ResponseWriter, request *htt
 	http.ServeFile(writer, request, "./frontend/order_created.html")
 }
 
+func (handler *OrderHandler) CancelOrder(writer http.ResponseWriter, request *http.Request) {
+	orderID, err := strconv.Atoi(chi.URLParam(request, "id"))
+	if err != nil {
+		http.Error(writer, "invalid order id", http.StatusBadRequest)
+		return
+	}
+
+	userID := request.Context().Value(USER_ID_KEY).(string)
+	if err = handler.Service.CancelOrder(request.Context(), userID, orderID); err != nil {
+		writeOrderChangeError(writer, err, "failed to cancel order")
+		return
+	}
+
+	writer.WriteHeader(http.StatusOK)
+	http.ServeFile(writer, request, "./frontend/order_canceled.html")
+}
+
+func (handler *OrderHandler) ModifyOrder(writer http.ResponseWriter, request *http.Request) {
+	orderID, idErr := strconv.Atoi(chi.URLParam(request, "id"))
+	quantity, quantityErr := strconv.Atoi(request.FormValue("quantity"))
+	unitPrice, priceErr := models.ParseMoney(request.FormValue("unit_price"))
+	if idErr != nil || quantityErr != nil || priceErr != nil {
+		http.Error(writer, "badly formed order modification", http.StatusBadRequest)
+		return
+	}
+
+	userID := request.Context().Value(USER_ID_KEY).(string)
+	if err := handler.Service.ModifyOrder(request.Context(), userID, orderID, quantity, unitPrice); err != nil {
+		writeOrderChangeError(writer, err, "failed to modify order")
+		return
+	}
+
+	writer.WriteHeader(http.StatusOK)
+	http.ServeFile(writer, request, "./frontend/order_modified.html")
+}
+
+func writeOrderChangeError(writer http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, ports.ErrOrderNotFound):
+		http.Error(writer, err.Error(), http.StatusNotFound)
+	case errors.Is(err, ports.ErrOrderNotOwned):
+		http.Error(writer, err.Error(), http.StatusForbidden)
+	case errors.Is(err, ports.ErrOrderNotCancelable), errors.Is(err, ports.ErrOrderNotModifiable):
+		http.Error(writer, err.Error(), http.StatusConflict)
+	case errors.Is(err, ports.ErrInvalidModification):
+		http.Error(writer, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(writer, message, http.StatusInternalServerError)
+	}
+}
+
 func validateOrderForm(request *http.Request) (*models.Order, error) {
 	var order models.Order
 	decoder := schema.NewDecoder()
+	decoder.RegisterConverter(sql.NullTime{}, convertNullTime)
+	decoder.RegisterConverter(models.Money{}, convertMoney)
 	err := decoder.Decode(&order, request.PostForm);
 	order.UserID = request.Context().Value(USER_ID_KEY).(string)
 	
-	if err != nil || !isValidOrder(&order) {
+	if err != nil {
 		return nil, err
 	}
+	if !isValidOrder(&order) {
+		return nil, errors.New("invalid order")
+	}
 	return &order, nil
 }
 
+// convertNullTime decodes an RFC 3339 timestamp. An empty value decodes to a null time.
+func convertNullTime(value string) reflect.Value {
+	if value == "" {
+		return reflect.ValueOf(sql.NullTime{})
+	}
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return reflect.Value{}
+	}
+	return reflect.ValueOf(sql.NullTime{Time: parsed, Valid: true})
+}
+
+// convertMoney decodes a decimal amount without going through float64. An empty value
+// decodes to zero.
+func convertMoney(value string) reflect.Value {
+	if value == "" {
+		return reflect.ValueOf(models.Money{})
+	}
+	parsed, err := models.ParseMoney(value)
+	if err != nil {
+		return reflect.Value{}
+	}
+	return reflect.ValueOf(parsed)
+}
+
 func isValidOrder(order *models.Order) bool {
 	log.Printf("Validating order: %+v", order)
     return order.UserID != "" &&
         order.Symbol != "" &&
-        order.Type != "" &&
+        isValidType(order) &&
         order.Action != "" &&
         order.Quantity > 0 &&
-        order.UnitPrice > 0 &&
-        order.Timing != "" &&
+        isValidPrice(order.UnitPrice) &&
+        isValidTiming(order) &&
+        (order.LotID == 0 || order.LotID > 0 && order.Action == "sell") &&
         order.Status != ""
+}
+
+// isValidType checks the type of the order. Only stop orders carry a stop price.
+func isValidType(order *models.Order) bool {
+	switch order.Type {
+	case "market", "limit":
+		return order.StopPrice.IsZero()
+	case "stop", "stop_limit":
+		return isValidPrice(order.StopPrice)
+	}
+	return false
+}
+
+// isValidPrice checks that a price is positive and in whole cents, the precision the
+// orders are stored with.
+func isValidPrice(price models.Money) bool {
+	return price.IsPositive() && price.FitsCurrency(models.BaseCurrency)
+}
+
+// isValidTiming checks the time in force of the order. Only GTD orders carry an expiry,
+// which must be in the future.
+func isValidTiming(order *models.Order) bool {
+	switch order.Timing {
+	case "day", "ioc", "gtc", "fok":
+		return !order.ExpiresAt.Valid
+	case "gtd":
+		return order.ExpiresAt.Valid && order.ExpiresAt.Time.After(time.Now())
+	}
+	return false
 }
\ No newline at end of file
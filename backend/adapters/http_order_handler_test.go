@@ -2,13 +2,18 @@ package adapters
 
 import (
 	"brokerx/models"
+	"brokerx/ports"
 	"bytes"
 	"context"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -21,11 +26,64 @@ type MockOrderService struct {
 	mock.Mock
 }
 
-func (m *MockOrderService) PlaceOrder(order *models.Order) error {
-	args := m.Called(order)
+func (m *MockOrderService) PlaceOrder(ctx context.Context, order *models.Order) error {
+	args := m.Called(ctx, order)
 	return args.Error(0)
 }
 
+func (m *MockOrderService) CancelOrder(ctx context.Context, userID string, orderID int) error {
+	args := m.Called(ctx, userID, orderID)
+	return args.Error(0)
+}
+
+func (m *MockOrderService) ModifyOrder(ctx context.Context, userID string, orderID int, quantity int, unitPrice models.Money) error {
+	args := m.Called(ctx, userID, orderID, quantity, unitPrice)
+	return args.Error(0)
+}
+
+func (m *MockOrderService) ExpireDayOrders(ctx context.Context, sessionClose time.Time) (int, error) {
+	args := m.Called(ctx, sessionClose)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockOrderService) ExpireGoodTillDateOrders(ctx context.Context, now time.Time) (int, error) {
+	args := m.Called(ctx, now)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockOrderService) GetOrder(ctx context.Context, userID string, orderID int) (*models.Order, error) {
+	args := m.Called(ctx, userID, orderID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Order), args.Error(1)
+}
+
+func (m *MockOrderService) ListOrders(ctx context.Context, userID string, filter models.OrderFilter) (*models.OrderPage, error) {
+	args := m.Called(ctx, userID, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.OrderPage), args.Error(1)
+}
+
+func newModifyOrderRequest(orderID string, userID string, body string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/order/"+orderID+"/modify", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	routeContext := chi.NewRouteContext()
+	routeContext.URLParams.Add("id", orderID)
+	ctx := context.WithValue(req.Context(), chi.RouteCtxKey, routeContext)
+	return req.WithContext(context.WithValue(ctx, USER_ID_KEY, userID))
+}
+
+func newCancelOrderRequest(orderID string, userID string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/order/"+orderID+"/cancel", nil)
+	routeContext := chi.NewRouteContext()
+	routeContext.URLParams.Add("id", orderID)
+	ctx := context.WithValue(req.Context(), chi.RouteCtxKey, routeContext)
+	return req.WithContext(context.WithValue(ctx, USER_ID_KEY, userID))
+}
+
 // ---------------------------
 // Test Suite
 // ---------------------------
@@ -39,7 +97,7 @@ type HttpOrderHandlerTestSuite struct {
 	Type   string
 	Action string
 	Quantity  int
-	UnitPrice float64
+	UnitPrice models.Money
 	Timing    string
 	Status    string
 	RequestString string
@@ -53,16 +111,17 @@ func (s *HttpOrderHandlerTestSuite) SetupTest() {
 	s.Type = "market"
 	s.Action = "buy"
 	s.Quantity = 10
-	s.UnitPrice = 150.00
+	s.UnitPrice = models.NewMoney(150)
 	s.Timing = "day"
 	s.Status = "open"
-	s.RequestString = fmt.Sprintf("user_id=%s&symbol=%s&type=%s&action=%s&quantity=%d&unit_price=%.2f&timing=%s&status=%s",
-		s.UserID, s.Symbol, s.Type, s.Action, s.Quantity, s.UnitPrice, s.Timing, s.Status)
+	s.RequestString = fmt.Sprintf("user_id=%s&symbol=%s&type=%s&action=%s&quantity=%d&unit_price=%s&timing=%s&status=%s",
+		s.UserID, s.Symbol, s.Type, s.Action, s.Quantity, s.UnitPrice.StringFixed(2), s.Timing, s.Status)
 }
 
 func (s *HttpOrderHandlerTestSuite) TestPlaceOrderSuccess() {
 	s.SetupTest()
-	s.mockService.On("PlaceOrder", mock.AnythingOfType("*models.Order")).Return(nil)
+	requestContext := mock.MatchedBy(func(ctx context.Context) bool { return ctx.Value(USER_ID_KEY) == s.UserID })
+	s.mockService.On("PlaceOrder", requestContext, mock.AnythingOfType("*models.Order")).Return(nil)
 
 	req := httptest.NewRequest(http.MethodPost, PLACE_ORDER_ENDPOINT, bytes.NewBufferString(s.RequestString))
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
@@ -91,9 +150,69 @@ func (s *HttpOrderHandlerTestSuite) TestPlaceOrderBadRequest() {
 	s.Equal(http.StatusBadRequest, res.StatusCode)
 }
 
+func (s *HttpOrderHandlerTestSuite) TestPlaceOrderGoodTillDate() {
+	s.SetupTest()
+	s.mockService.On("PlaceOrder", mock.Anything, mock.MatchedBy(func(order *models.Order) bool {
+		return order.Timing == "gtd" && order.ExpiresAt.Valid
+	})).Return(nil)
+	expiresAt := time.Now().Add(24 * time.Hour).Format(time.RFC3339)
+	body := strings.Replace(s.RequestString, "timing=day", "timing=gtd&expires_at="+url.QueryEscape(expiresAt), 1)
+
+	req := httptest.NewRequest(http.MethodPost, PLACE_ORDER_ENDPOINT, bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req = req.WithContext(context.WithValue(req.Context(), USER_ID_KEY, s.UserID))
+	w := httptest.NewRecorder()
+
+	s.handler.PlaceOrder(w, req)
+	res := w.Result()
+	defer res.Body.Close()
+
+	s.Equal(http.StatusCreated, res.StatusCode)
+}
+
+func (s *HttpOrderHandlerTestSuite) TestPlaceOrderInvalidTiming() {
+	s.SetupTest()
+	pastExpiry := url.QueryEscape(time.Now().Add(-time.Hour).Format(time.RFC3339))
+	futureExpiry := url.QueryEscape(time.Now().Add(time.Hour).Format(time.RFC3339))
+
+	for _, timing := range []string{"timing=gtd", "timing=gtd&expires_at=" + pastExpiry, "timing=gtc&expires_at=" + futureExpiry, "timing=weekly"} {
+		body := strings.Replace(s.RequestString, "timing=day", timing, 1)
+		req := httptest.NewRequest(http.MethodPost, PLACE_ORDER_ENDPOINT, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req = req.WithContext(context.WithValue(req.Context(), USER_ID_KEY, s.UserID))
+		w := httptest.NewRecorder()
+
+		s.handler.PlaceOrder(w, req)
+		res := w.Result()
+		res.Body.Close()
+
+		s.Equal(http.StatusBadRequest, res.StatusCode, timing)
+	}
+	s.mockService.AssertNotCalled(s.T(), "PlaceOrder", mock.Anything, mock.Anything)
+}
+
+func (s *HttpOrderHandlerTestSuite) TestPlaceOrderInvalidStopPrice() {
+	s.SetupTest()
+
+	for _, orderType := range []string{"type=market&stop_price=140.00", "type=stop", "type=stop_limit&stop_price=-1", "type=trailing"} {
+		body := strings.Replace(s.RequestString, "type=market", orderType, 1)
+		req := httptest.NewRequest(http.MethodPost, PLACE_ORDER_ENDPOINT, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req = req.WithContext(context.WithValue(req.Context(), USER_ID_KEY, s.UserID))
+		w := httptest.NewRecorder()
+
+		s.handler.PlaceOrder(w, req)
+		res := w.Result()
+		res.Body.Close()
+
+		s.Equal(http.StatusBadRequest, res.StatusCode, orderType)
+	}
+	s.mockService.AssertNotCalled(s.T(), "PlaceOrder", mock.Anything, mock.Anything)
+}
+
 func (s *HttpOrderHandlerTestSuite) TestPlaceOrderInternalError() {
 	s.SetupTest()
-	s.mockService.On("PlaceOrder", mock.AnythingOfType("*models.Order")).Return(assert.AnError)
+	s.mockService.On("PlaceOrder", mock.Anything, mock.AnythingOfType("*models.Order")).Return(assert.AnError)
 
 	req := httptest.NewRequest(http.MethodPost, PLACE_ORDER_ENDPOINT, bytes.NewBufferString(s.RequestString))
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
@@ -107,6 +226,81 @@ func (s *HttpOrderHandlerTestSuite) TestPlaceOrderInternalError() {
 	s.Equal(http.StatusInternalServerError, res.StatusCode)
 }
 
+func (s *HttpOrderHandlerTestSuite) TestCancelOrderSuccess() {
+	s.mockService.On("CancelOrder", mock.Anything, s.UserID, 12).Return(nil)
+	w := httptest.NewRecorder()
+
+	s.handler.CancelOrder(w, newCancelOrderRequest("12", s.UserID))
+
+	s.Equal(http.StatusOK, w.Result().StatusCode)
+}
+
+func (s *HttpOrderHandlerTestSuite) TestCancelOrderInvalidId() {
+	w := httptest.NewRecorder()
+
+	s.handler.CancelOrder(w, newCancelOrderRequest("twelve", s.UserID))
+
+	s.Equal(http.StatusBadRequest, w.Result().StatusCode)
+	s.mockService.AssertNotCalled(s.T(), "CancelOrder", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *HttpOrderHandlerTestSuite) TestCancelOrderErrors() {
+	cases := map[error]int{
+		ports.ErrOrderNotFound:      http.StatusNotFound,
+		ports.ErrOrderNotOwned:      http.StatusForbidden,
+		ports.ErrOrderNotCancelable: http.StatusConflict,
+		assert.AnError:              http.StatusInternalServerError,
+	}
+
+	for err, expectedStatus := range cases {
+		s.mockService = new(MockOrderService)
+		s.handler.Service = s.mockService
+		s.mockService.On("CancelOrder", mock.Anything, s.UserID, 12).Return(err)
+		w := httptest.NewRecorder()
+
+		s.handler.CancelOrder(w, newCancelOrderRequest("12", s.UserID))
+
+		s.Equal(expectedStatus, w.Result().StatusCode)
+	}
+}
+
+func (s *HttpOrderHandlerTestSuite) TestModifyOrderSuccess() {
+	s.mockService.On("ModifyOrder", mock.Anything, s.UserID, 12, 5, models.MustParseMoney("151.5")).Return(nil)
+	w := httptest.NewRecorder()
+
+	s.handler.ModifyOrder(w, newModifyOrderRequest("12", s.UserID, "quantity=5&unit_price=151.50"))
+
+	s.Equal(http.StatusOK, w.Result().StatusCode)
+}
+
+func (s *HttpOrderHandlerTestSuite) TestModifyOrderBadRequest() {
+	w := httptest.NewRecorder()
+
+	s.handler.ModifyOrder(w, newModifyOrderRequest("12", s.UserID, "quantity=five&unit_price=151.50"))
+
+	s.Equal(http.StatusBadRequest, w.Result().StatusCode)
+	s.mockService.AssertNotCalled(s.T(), "ModifyOrder", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *HttpOrderHandlerTestSuite) TestModifyOrderErrors() {
+	cases := map[error]int{
+		ports.ErrOrderNotModifiable:  http.StatusConflict,
+		ports.ErrInvalidModification: http.StatusBadRequest,
+		assert.AnError:               http.StatusInternalServerError,
+	}
+
+	for err, expectedStatus := range cases {
+		s.mockService = new(MockOrderService)
+		s.handler.Service = s.mockService
+		s.mockService.On("ModifyOrder", mock.Anything, s.UserID, 12, 5, models.MustParseMoney("151.5")).Return(err)
+		w := httptest.NewRecorder()
+
+		s.handler.ModifyOrder(w, newModifyOrderRequest("12", s.UserID, "quantity=5&unit_price=151.50"))
+
+		s.Equal(expectedStatus, w.Result().StatusCode)
+	}
+}
+
 // ---------------------------
 // Run the suite
 // ---------------------------
@@ -0,0 +1,78 @@
+package adapters
+
+import (
+	"brokerx/models"
+	"brokerx/ports"
+	"net/http"
+)
+
+// PortfolioHandler exposes the portfolio of the authenticated user as a JSON API.
+type PortfolioHandler struct {
+	Service ports.PortfolioService
+}
+
+type holdingResponse struct {
+	Symbol            string       `json:"symbol"`
+	Currency          string       `json:"currency"`
+	Quantity          int          `json:"quantity"`
+	UnsettledQuantity int          `json:"unsettled_quantity"`
+	AverageCost       models.Money `json:"average_cost"`
+	CostBasis         models.Money `json:"cost_basis"`
+	LastPrice         models.Money `json:"last_price"`
+	MarketValue       models.Money `json:"market_value"`
+	UnrealizedPnL     models.Money `json:"unrealized_pnl"`
+}
+
+type portfolioTotalResponse struct {
+	Currency      string       `json:"currency"`
+	CostBasis     models.Money `json:"cost_basis"`
+	MarketValue   models.Money `json:"market_value"`
+	UnrealizedPnL models.Money `json:"unrealized_pnl"`
+	RealizedPnL   models.Money `json:"realized_pnl"`
+}
+
+type portfolioResponse struct {
+	Holdings []holdingResponse        `json:"holdings"`
+	Totals   []portfolioTotalResponse `json:"totals"`
+}
+
+func (handler *PortfolioHandler) GetPortfolio(writer http.ResponseWriter, request *http.Request) {
+	userID := request.Context().Value(USER_ID_KEY).(string)
+	portfolio, err := handler.Service.GetPortfolio(request.Context(), userID)
+	if err != nil {
+		writeAPIError(writer, http.StatusInternalServerError, "internal_error", "internal server error")
+		return
+	}
+
+	writeJSON(writer, http.StatusOK, newPortfolioResponse(portfolio))
+}
+
+func newPortfolioResponse(portfolio *models.Portfolio) portfolioResponse {
+	response := portfolioResponse{
+		Holdings: make([]holdingResponse, 0, len(portfolio.Holdings)),
+		Totals:   make([]portfolioTotalResponse, 0, len(portfolio.Totals)),
+	}
+	for _, holding := range portfolio.Holdings {
+		response.Holdings = append(response.Holdings, holdingResponse{
+			Symbol:            holding.Symbol,
+			Currency:          holding.Currency,
+			Quantity:          holding.Quantity,
+			UnsettledQuantity: holding.UnsettledQuantity,
+			AverageCost:       holding.AverageCost,
+			CostBasis:         holding.CostBasis,
+			LastPrice:         holding.LastPrice,
+			MarketValue:       holding.MarketValue,
+			UnrealizedPnL:     holding.UnrealizedPnL,
+		})
+	}
+	for _, total := range portfolio.Totals {
+		response.Totals = append(response.Totals, portfolioTotalResponse{
+			Currency:      total.Currency,
+			CostBasis:     total.CostBasis,
+			MarketValue:   total.MarketValue,
+			UnrealizedPnL: total.UnrealizedPnL,
+			RealizedPnL:   total.RealizedPnL,
+		})
+	}
+	return response
+}
@@ -0,0 +1,103 @@
+package adapters
+
+import (
+	"brokerx/models"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type MockPortfolioService struct {
+	mock.Mock
+}
+
+func (m *MockPortfolioService) GetPortfolio(ctx context.Context, userID string) (*models.Portfolio, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Portfolio), args.Error(1)
+}
+
+// ---------------------------
+// Test Suite
+// ---------------------------
+
+type HttpPortfolioHandlerTestSuite struct {
+	suite.Suite
+	mockService *MockPortfolioService
+	handler     *PortfolioHandler
+	UserID      string
+}
+
+func (s *HttpPortfolioHandlerTestSuite) SetupTest() {
+	s.mockService = new(MockPortfolioService)
+	s.handler = &PortfolioHandler{Service: s.mockService}
+	s.UserID = "user"
+}
+
+// ---------------------------
+// Tests
+// ---------------------------
+
+func (s *HttpPortfolioHandlerTestSuite) TestGetPortfolio() {
+	portfolio := &models.Portfolio{
+		Holdings: []*models.Holding{{Symbol: "AAPL", Currency: "USD", Quantity: 40, UnsettledQuantity: 5, AverageCost: models.NewMoney(115), CostBasis: models.NewMoney(4600), LastPrice: models.NewMoney(125), MarketValue: models.NewMoney(5000), UnrealizedPnL: models.NewMoney(400)}},
+		Totals: []*models.PortfolioTotal{
+			{Currency: "JPY", CostBasis: models.NewMoney(30000), MarketValue: models.NewMoney(32000), UnrealizedPnL: models.NewMoney(2000)},
+			{Currency: "USD", CostBasis: models.NewMoney(4600), MarketValue: models.NewMoney(5000), UnrealizedPnL: models.NewMoney(400), RealizedPnL: models.NewMoney(75)},
+		},
+	}
+	s.mockService.On("GetPortfolio", mock.Anything, s.UserID).Return(portfolio, nil)
+	w := httptest.NewRecorder()
+
+	s.handler.GetPortfolio(w, newAPIRequest(http.MethodGet, "/api/v1/portfolio", "", s.UserID, ""))
+
+	s.Equal(http.StatusOK, w.Code)
+	var response portfolioResponse
+	s.Require().NoError(json.NewDecoder(w.Body).Decode(&response))
+	s.Require().Len(response.Holdings, 1)
+	s.Equal("AAPL", response.Holdings[0].Symbol)
+	s.Equal("USD", response.Holdings[0].Currency)
+	s.Equal(5, response.Holdings[0].UnsettledQuantity)
+	s.Equal(models.NewMoney(115), response.Holdings[0].AverageCost)
+	s.Equal(models.NewMoney(400), response.Holdings[0].UnrealizedPnL)
+	s.Require().Len(response.Totals, 2)
+	s.Equal("JPY", response.Totals[0].Currency)
+	s.Equal(models.NewMoney(32000), response.Totals[0].MarketValue)
+	s.Equal(models.NewMoney(5000), response.Totals[1].MarketValue)
+	s.Equal(models.NewMoney(75), response.Totals[1].RealizedPnL)
+}
+
+func (s *HttpPortfolioHandlerTestSuite) TestGetPortfolioEmpty() {
+	s.mockService.On("GetPortfolio", mock.Anything, s.UserID).Return(&models.Portfolio{}, nil)
+	w := httptest.NewRecorder()
+
+	s.handler.GetPortfolio(w, newAPIRequest(http.MethodGet, "/api/v1/portfolio", "", s.UserID, ""))
+
+	s.Equal(http.StatusOK, w.Code)
+	s.JSONEq(`{"holdings":[],"totals":[]}`, w.Body.String())
+}
+
+func (s *HttpPortfolioHandlerTestSuite) TestGetPortfolioInternalError() {
+	s.mockService.On("GetPortfolio", mock.Anything, s.UserID).Return(nil, assert.AnError)
+	w := httptest.NewRecorder()
+
+	s.handler.GetPortfolio(w, newAPIRequest(http.MethodGet, "/api/v1/portfolio", "", s.UserID, ""))
+
+	s.Equal(http.StatusInternalServerError, w.Code)
+	s.Equal("internal_error", decodeAPIError(&s.Suite, w).Code)
+}
+
+// ---------------------------
+// Run the suite
+// ---------------------------
+func TestHttpPortfolioHandlerTestSuite(t *testing.T) {
+	suite.Run(t, new(HttpPortfolioHandlerTestSuite))
+}
@@ -0,0 +1,217 @@
+package adapters
+
+import (
+	"brokerx/models"
+	"brokerx/ports"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const statementDateLayout = "2006-01-02"
+
+// StatementHandler lets the authenticated user download their account statements.
+type StatementHandler struct {
+	Service ports.StatementService
+}
+
+// statementSection is a table of the statement, rendered as rows of a CSV file or as
+// columns of text in a PDF.
+type statementSection struct {
+	title  string
+	header []string
+	rows   [][]string
+}
+
+// GetStatement downloads the statement of the user for the month query parameter
+// (YYYY-MM), or from the from date to the to date included (YYYY-MM-DD), in UTC. Without
+// a period it covers the previous month. The format query parameter selects csv, the
+// default, or pdf.
+func (handler *StatementHandler) GetStatement(writer http.ResponseWriter, request *http.Request) {
+	query := request.URL.Query()
+	format := query.Get("format")
+	if format == "" {
+		format = "csv"
+	}
+	if format != "csv" && format != "pdf" {
+		writeAPIError(writer, http.StatusBadRequest, "invalid_format", "format must be csv or pdf")
+		return
+	}
+	from, to, err := parseStatementPeriod(query, time.Now().UTC())
+	if err != nil {
+		writeAPIError(writer, http.StatusBadRequest, "invalid_period", err.Error())
+		return
+	}
+
+	userID := request.Context().Value(USER_ID_KEY).(string)
+	statement, err := handler.Service.GenerateStatement(request.Context(), userID, from, to)
+	if errors.Is(err, ports.ErrInvalidPeriod) {
+		writeAPIError(writer, http.StatusBadRequest, "invalid_period", err.Error())
+		return
+	}
+	if err != nil {
+		writeAPIError(writer, http.StatusInternalServerError, "internal_error", "internal server error")
+		return
+	}
+
+	filename := fmt.Sprintf("statement-%s-%s.%s", from.Format(statementDateLayout), lastStatementDay(statement).Format(statementDateLayout), format)
+	writer.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+	if format == "pdf" {
+		writer.Header().Set("Content-Type", "application/pdf")
+		err = writeStatementPDF(writer, statement)
+	} else {
+		writer.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		err = writeStatementCSV(writer, statement)
+	}
+	if err != nil {
+		log.Errorf("Failed to write the statement of user %s: %v", userID, err)
+	}
+}
+
+func parseStatementPeriod(query url.Values, now time.Time) (time.Time, time.Time, error) {
+	if value := query.Get("month"); value != "" {
+		month, err := time.Parse("2006-01", value)
+		if err != nil {
+			return time.Time{}, time.Time{}, errors.New("month must be formatted as YYYY-MM")
+		}
+		return month, month.AddDate(0, 1, 0), nil
+	}
+
+	fromValue, toValue := query.Get("from"), query.Get("to")
+	if fromValue == "" && toValue == "" {
+		month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+		return month.AddDate(0, -1, 0), month, nil
+	}
+	from, err := time.Parse(statementDateLayout, fromValue)
+	if err != nil {
+		return time.Time{}, time.Time{}, errors.New("from must be a date formatted as YYYY-MM-DD")
+	}
+	to, err := time.Parse(statementDateLayout, toValue)
+	if err != nil {
+		return time.Time{}, time.Time{}, errors.New("to must be a date formatted as YYYY-MM-DD")
+	}
+	return from, to.AddDate(0, 0, 1), nil
+}
+
+// lastStatementDay is the last day covered by the statement.
+func lastStatementDay(statement *models.Statement) time.Time {
+	return statement.To.Add(-time.Nanosecond)
+}
+
+func writeStatementCSV(writer io.Writer, statement *models.Statement) error {
+	csvWriter := csv.NewWriter(writer)
+	for i, section := range statementSections(statement) {
+		if i > 0 {
+			csvWriter.Write([]string{})
+		}
+		csvWriter.Write([]string{section.title})
+		if section.header != nil {
+			csvWriter.Write(section.header)
+		}
+		csvWriter.WriteAll(section.rows)
+	}
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
+
+// writeStatementPDF lays the sections out as columns padded to their widest cell.
+func writeStatementPDF(writer io.Writer, statement *models.Statement) error {
+	document := &pdfDocument{}
+	for i, section := range statementSections(statement) {
+		if i > 0 {
+			document.addLine("")
+		}
+		document.addLine("%s", strings.ToUpper(section.title))
+
+		rows := section.rows
+		if section.header != nil {
+			rows = append([][]string{section.header}, rows...)
+		}
+		widths := map[int]int{}
+		for _, row := range rows {
+			for column, cell := range row {
+				widths[column] = max(widths[column], len(cell))
+			}
+		}
+		for _, row := range rows {
+			cells := make([]string, len(row))
+			for column, cell := range row {
+				cells[column] = fmt.Sprintf("%-*s", widths[column], cell)
+			}
+			document.addLine("%s", strings.TrimRight(strings.Join(cells, "  "), " "))
+		}
+	}
+	_, err := document.WriteTo(writer)
+	return err
+}
+
+func statementSections(statement *models.Statement) []statementSection {
+	summary := statementSection{title: "Statement", rows: [][]string{
+		{"User", statement.UserID},
+		{"From", statement.From.Format(statementDateLayout)},
+		{"To", lastStatementDay(statement).Format(statementDateLayout)},
+		{"Generated at", statement.GeneratedAt.Format(time.RFC3339)},
+	}}
+
+	cash := statementSection{title: "Cash", header: []string{"Currency", "Opening", "Closing"}}
+	for _, wallet := range statement.Cash {
+		cash.rows = append(cash.rows, []string{wallet.Currency, formatAmount(wallet.Opening, wallet.Currency), formatAmount(wallet.Closing, wallet.Currency)})
+	}
+
+	deposits := statementSection{title: "Deposits", header: []string{"Date", "Deposit", "Amount", "Status"}}
+	for _, deposit := range statement.Deposits {
+		deposits.rows = append(deposits.rows, []string{deposit.CreatedAt.Time.UTC().Format(time.RFC3339), strconv.Itoa(deposit.ID),
+			formatAmount(deposit.Amount, models.BaseCurrency), deposit.Status})
+	}
+
+	withdrawals := statementSection{title: "Withdrawals", header: []string{"Date", "Withdrawal", "Amount", "Status"}}
+	for _, withdrawal := range statement.Withdrawals {
+		withdrawals.rows = append(withdrawals.rows, []string{withdrawal.CreatedAt.Time.UTC().Format(time.RFC3339), strconv.Itoa(withdrawal.ID),
+			formatAmount(withdrawal.Amount, models.BaseCurrency), withdrawal.Status})
+	}
+
+	trades := statementSection{title: "Trades", header: []string{"Date", "Execution", "Order", "Symbol", "Side", "Quantity", "Price", "Currency", "Commission", "Regulatory fee", "Amount", "Settles"}}
+	for _, trade := range statement.Trades {
+		settles := ""
+		if !trade.SettlementDate.IsZero() {
+			settles = trade.SettlementDate.Format(statementDateLayout)
+		}
+		trades.rows = append(trades.rows, []string{trade.ExecutedAt.UTC().Format(time.RFC3339), strconv.Itoa(trade.ExecutionID), strconv.Itoa(trade.OrderID),
+			trade.Symbol, trade.Side, strconv.Itoa(trade.Quantity), formatAmount(trade.Price, trade.Currency), trade.Currency,
+			formatAmount(trade.Commission, trade.Currency), formatAmount(trade.RegulatoryFee, trade.Currency), formatAmount(trade.Amount(), trade.Currency), settles})
+	}
+
+	holdings := statementSection{title: "Positions", header: []string{"Symbol", "Currency", "Quantity", "Unsettled", "Average cost", "Cost basis", "Last price", "Market value", "Unrealized P&L"}}
+	for _, holding := range statement.Holdings {
+		holdings.rows = append(holdings.rows, []string{holding.Symbol, holding.Currency, strconv.Itoa(holding.Quantity), strconv.Itoa(holding.UnsettledQuantity),
+			holding.AverageCost.String(), formatAmount(holding.CostBasis, holding.Currency), formatAmount(holding.LastPrice, holding.Currency),
+			formatAmount(holding.MarketValue, holding.Currency), formatAmount(holding.UnrealizedPnL, holding.Currency)})
+	}
+	for _, total := range statement.MarketValues {
+		holdings.rows = append(holdings.rows, []string{"Total", total.Currency, "", "", "", "", "", formatAmount(total.Amount, total.Currency), ""})
+	}
+
+	gains := statementSection{title: "Realized P&L", header: []string{"Date", "Execution", "Symbol", "Currency", "Quantity", "Cost basis", "Proceeds", "Realized gain", "Term"}}
+	for _, gain := range statement.Gains {
+		gains.rows = append(gains.rows, []string{gain.RelievedAt.UTC().Format(time.RFC3339), strconv.Itoa(gain.ExecutionID), gain.Symbol, gain.Currency, strconv.Itoa(gain.Quantity),
+			formatAmount(gain.CostBasis, gain.Currency), formatAmount(gain.Proceeds, gain.Currency), formatAmount(gain.RealizedGain, gain.Currency), gain.Term})
+	}
+	for _, total := range statement.RealizedPnL {
+		gains.rows = append(gains.rows, []string{"Total", "", "", total.Currency, "", "", "", formatAmount(total.Amount, total.Currency), ""})
+	}
+
+	return []statementSection{summary, cash, deposits, withdrawals, trades, holdings, gains}
+}
+
+// formatAmount formats the amount in the minor unit of the currency.
+func formatAmount(amount models.Money, currency string) string {
+	return amount.StringFixed(models.CurrencyScale(currency))
+}
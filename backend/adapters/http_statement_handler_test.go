@@ -0,0 +1,193 @@
+package adapters
+
+import (
+	"brokerx/models"
+	"brokerx/ports"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type MockStatementService struct {
+	mock.Mock
+}
+
+func (m *MockStatementService) GenerateStatement(ctx context.Context, userID string, from time.Time, to time.Time) (*models.Statement, error) {
+	args := m.Called(ctx, userID, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Statement), args.Error(1)
+}
+
+// ---------------------------
+// Test Suite
+// ---------------------------
+
+type HttpStatementHandlerTestSuite struct {
+	suite.Suite
+	mockService *MockStatementService
+	handler     *StatementHandler
+	UserID      string
+	from        time.Time
+	to          time.Time
+}
+
+func (s *HttpStatementHandlerTestSuite) SetupTest() {
+	s.mockService = new(MockStatementService)
+	s.handler = &StatementHandler{Service: s.mockService}
+	s.UserID = "user"
+	s.from = time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	s.to = time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+}
+
+func (s *HttpStatementHandlerTestSuite) statement() *models.Statement {
+	executedAt := time.Date(2026, 9, 3, 14, 30, 0, 0, time.UTC)
+	return &models.Statement{
+		UserID:      s.UserID,
+		From:        s.from,
+		To:          s.to,
+		GeneratedAt: time.Date(2026, 10, 2, 8, 0, 0, 0, time.UTC),
+		Cash: []*models.StatementCash{
+			{Currency: "JPY", Opening: models.NewMoney(0), Closing: models.NewMoney(15000)},
+			{Currency: "USD", Opening: models.NewMoney(1000), Closing: models.MustParseMoney("2497.95")},
+		},
+		Deposits: []*models.Deposit{{ID: 4, Amount: models.NewMoney(500), Status: "settled", CreatedAt: sql.NullTime{Time: executedAt, Valid: true}}},
+		Trades: []*models.StatementTrade{{ExecutionID: 7, OrderID: 2, ExecutedAt: executedAt, SettlementDate: time.Date(2026, 9, 4, 0, 0, 0, 0, time.UTC),
+			Symbol: "AAPL", Side: "sell", Quantity: 10, Price: models.NewMoney(150), Currency: "USD", Commission: models.NewMoney(2), RegulatoryFee: models.MustParseMoney("0.05")}},
+		Holdings:     []*models.Holding{{Symbol: "MSFT", Currency: "USD", Quantity: 2, AverageCost: models.NewMoney(290), CostBasis: models.NewMoney(580), LastPrice: models.NewMoney(300), MarketValue: models.NewMoney(600), UnrealizedPnL: models.NewMoney(20)}},
+		MarketValues: []*models.StatementTotal{{Currency: "USD", Amount: models.NewMoney(600)}},
+		Gains: []*models.StatementGain{
+			{ExecutionID: 7, Symbol: "AAPL", Currency: "USD", Quantity: 10, CostBasis: models.NewMoney(1400), Proceeds: models.MustParseMoney("1497.95"), RealizedGain: models.MustParseMoney("97.95"), Term: "short", RelievedAt: executedAt},
+			{ExecutionID: 8, Symbol: "7203", Currency: "JPY", Quantity: 100, CostBasis: models.NewMoney(200000), Proceeds: models.NewMoney(210000), RealizedGain: models.NewMoney(10000), Term: "long", RelievedAt: executedAt},
+		},
+		RealizedPnL: []*models.StatementTotal{{Currency: "JPY", Amount: models.NewMoney(10000)}, {Currency: "USD", Amount: models.MustParseMoney("97.95")}},
+	}
+}
+
+// ---------------------------
+// Tests
+// ---------------------------
+
+func (s *HttpStatementHandlerTestSuite) TestGetStatementCSV() {
+	s.mockService.On("GenerateStatement", mock.Anything, s.UserID, s.from, s.to).Return(s.statement(), nil)
+	w := httptest.NewRecorder()
+
+	s.handler.GetStatement(w, newAPIRequest(http.MethodGet, "/api/v1/statements?month=2026-09", "", s.UserID, ""))
+
+	s.Equal(http.StatusOK, w.Code)
+	s.Equal("text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+	s.Equal(`attachment; filename="statement-2026-09-01-2026-09-30.csv"`, w.Header().Get("Content-Disposition"))
+	reader := csv.NewReader(w.Body)
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	s.Require().NoError(err)
+	s.Contains(records, []string{"To", "2026-09-30"})
+	s.Contains(records, []string{"JPY", "0", "15000"})
+	s.Contains(records, []string{"USD", "1000.00", "2497.95"})
+	s.Contains(records, []string{"2026-09-03T14:30:00Z", "4", "500.00", "settled"})
+	s.Contains(records, []string{"2026-09-03T14:30:00Z", "7", "2", "AAPL", "sell", "10", "150.00", "USD", "2.00", "0.05", "1497.95", "2026-09-04"})
+	s.Contains(records, []string{"MSFT", "USD", "2", "0", "290.0000", "580.00", "300.00", "600.00", "20.00"})
+	s.Contains(records, []string{"Total", "USD", "", "", "", "", "", "600.00", ""})
+	s.Contains(records, []string{"2026-09-03T14:30:00Z", "8", "7203", "JPY", "100", "200000", "210000", "10000", "long"})
+	s.Contains(records, []string{"Total", "", "", "JPY", "", "", "", "10000", ""})
+	s.Contains(records, []string{"Total", "", "", "USD", "", "", "", "97.95", ""})
+}
+
+func (s *HttpStatementHandlerTestSuite) TestGetStatementPDF() {
+	s.mockService.On("GenerateStatement", mock.Anything, s.UserID, s.from, s.to).Return(s.statement(), nil)
+	w := httptest.NewRecorder()
+
+	s.handler.GetStatement(w, newAPIRequest(http.MethodGet, "/api/v1/statements?format=pdf&from=2026-09-01&to=2026-09-30", "", s.UserID, ""))
+
+	s.Equal(http.StatusOK, w.Code)
+	s.Equal("application/pdf", w.Header().Get("Content-Type"))
+	s.Equal(`attachment; filename="statement-2026-09-01-2026-09-30.pdf"`, w.Header().Get("Content-Disposition"))
+	pdf := w.Body.String()
+	s.True(strings.HasPrefix(pdf, "%PDF-"))
+	s.Contains(pdf, "(TRADES) Tj")
+	s.Contains(pdf, "(Symbol  Currency  Quantity  Unsettled  Average cost  Cost basis  Last price  Market value  Unrealized P&L) Tj")
+	s.Contains(pdf, "(MSFT    USD       2         0          290.0000      580.00      300.00      600.00        20.00) Tj")
+}
+
+func (s *HttpStatementHandlerTestSuite) TestGetStatementDefaultsToThePreviousMonth() {
+	now := time.Now().UTC()
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	statement := &models.Statement{UserID: s.UserID, From: month.AddDate(0, -1, 0), To: month}
+	s.mockService.On("GenerateStatement", mock.Anything, s.UserID, month.AddDate(0, -1, 0), month).Return(statement, nil)
+	w := httptest.NewRecorder()
+
+	s.handler.GetStatement(w, newAPIRequest(http.MethodGet, "/api/v1/statements", "", s.UserID, ""))
+
+	s.Equal(http.StatusOK, w.Code)
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *HttpStatementHandlerTestSuite) TestGetStatementInvalidFormat() {
+	w := httptest.NewRecorder()
+
+	s.handler.GetStatement(w, newAPIRequest(http.MethodGet, "/api/v1/statements?format=xlsx", "", s.UserID, ""))
+
+	s.Equal(http.StatusBadRequest, w.Code)
+	s.Equal("invalid_format", decodeAPIError(&s.Suite, w).Code)
+	s.mockService.AssertNotCalled(s.T(), "GenerateStatement")
+}
+
+func (s *HttpStatementHandlerTestSuite) TestGetStatementInvalidMonth() {
+	w := httptest.NewRecorder()
+
+	s.handler.GetStatement(w, newAPIRequest(http.MethodGet, "/api/v1/statements?month=09-2026", "", s.UserID, ""))
+
+	s.Equal(http.StatusBadRequest, w.Code)
+	s.Equal("invalid_period", decodeAPIError(&s.Suite, w).Code)
+	s.mockService.AssertNotCalled(s.T(), "GenerateStatement")
+}
+
+func (s *HttpStatementHandlerTestSuite) TestGetStatementPeriodEndingBeforeItStarts() {
+	s.mockService.On("GenerateStatement", mock.Anything, s.UserID, s.to, s.to).Return(nil, ports.ErrInvalidPeriod)
+	w := httptest.NewRecorder()
+
+	s.handler.GetStatement(w, newAPIRequest(http.MethodGet, "/api/v1/statements?from=2026-10-01&to=2026-09-30", "", s.UserID, ""))
+
+	s.Equal(http.StatusBadRequest, w.Code)
+	s.Equal("invalid_period", decodeAPIError(&s.Suite, w).Code)
+}
+
+func (s *HttpStatementHandlerTestSuite) TestGetStatementFailure() {
+	s.mockService.On("GenerateStatement", mock.Anything, s.UserID, s.from, s.to).Return(nil, assert.AnError)
+	w := httptest.NewRecorder()
+
+	s.handler.GetStatement(w, newAPIRequest(http.MethodGet, "/api/v1/statements?month=2026-09", "", s.UserID, ""))
+
+	s.Equal(http.StatusInternalServerError, w.Code)
+	s.Equal("internal_error", decodeAPIError(&s.Suite, w).Code)
+}
+
+func (s *HttpStatementHandlerTestSuite) TestParseStatementPeriod() {
+	now := time.Date(2027, 1, 15, 0, 0, 0, 0, time.UTC)
+
+	from, to, err := parseStatementPeriod(url.Values{}, now)
+	s.Require().NoError(err)
+	s.Equal(time.Date(2026, 12, 1, 0, 0, 0, 0, time.UTC), from)
+	s.Equal(time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC), to)
+
+	_, _, err = parseStatementPeriod(url.Values{"from": {"2026-09-01"}}, now)
+	s.EqualError(err, "to must be a date formatted as YYYY-MM-DD")
+}
+
+// ---------------------------
+// Run the suite
+// ---------------------------
+func TestHttpStatementHandlerTestSuite(t *testing.T) {
+	suite.Run(t, new(HttpStatementHandlerTestSuite))
+}
@@ -0,0 +1,130 @@
+package adapters
+
+import (
+	"brokerx/models"
+	"brokerx/ports"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// TaxLotHandler exposes the tax lots, the realized gains and the lot relief method of the
+// authenticated user as a JSON API.
+type TaxLotHandler struct {
+	Service ports.TaxLotService
+}
+
+type taxLotResponse struct {
+	ID                int          `json:"id"`
+	Symbol            string       `json:"symbol"`
+	Quantity          int          `json:"quantity"`
+	RemainingQuantity int          `json:"remaining_quantity"`
+	UnitPrice         models.Money `json:"unit_price"`
+	AcquiredAt        time.Time    `json:"acquired_at"`
+}
+
+type taxLotsResponse struct {
+	ReliefMethod string           `json:"relief_method"`
+	Lots         []taxLotResponse `json:"lots"`
+}
+
+type realizedGainResponse struct {
+	LotID        int          `json:"lot_id"`
+	ExecutionID  int          `json:"execution_id"`
+	Symbol       string       `json:"symbol"`
+	Quantity     int          `json:"quantity"`
+	CostBasis    models.Money `json:"cost_basis"`
+	Proceeds     models.Money `json:"proceeds"`
+	RealizedGain models.Money `json:"realized_gain"`
+	Term         string       `json:"term"`
+	RelievedAt   time.Time    `json:"relieved_at"`
+}
+
+type realizedGainsResponse struct {
+	Gains []realizedGainResponse `json:"gains"`
+}
+
+type reliefMethodRequest struct {
+	Method string `json:"method"`
+}
+
+// GetLots returns the tax lots of the user along with the relief method of the account.
+func (handler *TaxLotHandler) GetLots(writer http.ResponseWriter, request *http.Request) {
+	userID := request.Context().Value(USER_ID_KEY).(string)
+	method, err := handler.Service.GetReliefMethod(request.Context(), userID)
+	if err != nil {
+		writeAPIError(writer, http.StatusInternalServerError, "internal_error", "internal server error")
+		return
+	}
+	lots, err := handler.Service.GetLots(request.Context(), userID)
+	if err != nil {
+		writeAPIError(writer, http.StatusInternalServerError, "internal_error", "internal server error")
+		return
+	}
+
+	response := taxLotsResponse{ReliefMethod: method, Lots: make([]taxLotResponse, 0, len(lots))}
+	for _, lot := range lots {
+		response.Lots = append(response.Lots, newTaxLotResponse(lot))
+	}
+	writeJSON(writer, http.StatusOK, response)
+}
+
+func (handler *TaxLotHandler) GetRealizedGains(writer http.ResponseWriter, request *http.Request) {
+	userID := request.Context().Value(USER_ID_KEY).(string)
+	reliefs, err := handler.Service.GetRealizedGains(request.Context(), userID)
+	if err != nil {
+		writeAPIError(writer, http.StatusInternalServerError, "internal_error", "internal server error")
+		return
+	}
+
+	response := realizedGainsResponse{Gains: make([]realizedGainResponse, 0, len(reliefs))}
+	for _, relief := range reliefs {
+		response.Gains = append(response.Gains, realizedGainResponse{
+			LotID:        relief.LotID,
+			ExecutionID:  relief.ExecutionID,
+			Symbol:       relief.Symbol,
+			Quantity:     relief.Quantity,
+			CostBasis:    relief.CostBasis,
+			Proceeds:     relief.Proceeds,
+			RealizedGain: relief.RealizedGain,
+			Term:         relief.Term,
+			RelievedAt:   relief.RelievedAt,
+		})
+	}
+	writeJSON(writer, http.StatusOK, response)
+}
+
+// SetReliefMethod changes the lot relief method of the account. It applies to the fills
+// that happen after the change.
+func (handler *TaxLotHandler) SetReliefMethod(writer http.ResponseWriter, request *http.Request) {
+	var body reliefMethodRequest
+	if err := json.NewDecoder(request.Body).Decode(&body); err != nil {
+		writeAPIError(writer, http.StatusBadRequest, "invalid_request", "badly formed relief method")
+		return
+	}
+
+	userID := request.Context().Value(USER_ID_KEY).(string)
+	err := handler.Service.SetReliefMethod(request.Context(), userID, body.Method)
+	if errors.Is(err, ports.ErrInvalidReliefMethod) {
+		writeAPIError(writer, http.StatusBadRequest, "invalid_relief_method", err.Error())
+		return
+	}
+	if err != nil {
+		writeAPIError(writer, http.StatusInternalServerError, "internal_error", "internal server error")
+		return
+	}
+
+	writeJSON(writer, http.StatusOK, reliefMethodRequest{Method: body.Method})
+}
+
+func newTaxLotResponse(lot *models.TaxLot) taxLotResponse {
+	return taxLotResponse{
+		ID:                lot.ID,
+		Symbol:            lot.Symbol,
+		Quantity:          lot.Quantity,
+		RemainingQuantity: lot.RemainingQuantity,
+		UnitPrice:         lot.UnitPrice,
+		AcquiredAt:        lot.AcquiredAt,
+	}
+}
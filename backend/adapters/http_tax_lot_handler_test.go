@@ -0,0 +1,134 @@
+package adapters
+
+import (
+	"brokerx/models"
+	"brokerx/ports"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type MockTaxLotService struct {
+	mock.Mock
+}
+
+func (m *MockTaxLotService) GetLots(ctx context.Context, userID string) ([]*models.TaxLot, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.TaxLot), args.Error(1)
+}
+
+func (m *MockTaxLotService) GetRealizedGains(ctx context.Context, userID string) ([]*models.LotRelief, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.LotRelief), args.Error(1)
+}
+
+func (m *MockTaxLotService) GetReliefMethod(ctx context.Context, userID string) (string, error) {
+	args := m.Called(ctx, userID)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockTaxLotService) SetReliefMethod(ctx context.Context, userID string, method string) error {
+	args := m.Called(ctx, userID, method)
+	return args.Error(0)
+}
+
+// ---------------------------
+// Test Suite
+// ---------------------------
+
+type HttpTaxLotHandlerTestSuite struct {
+	suite.Suite
+	mockService *MockTaxLotService
+	handler     *TaxLotHandler
+	UserID      string
+}
+
+func (s *HttpTaxLotHandlerTestSuite) SetupTest() {
+	s.mockService = new(MockTaxLotService)
+	s.handler = &TaxLotHandler{Service: s.mockService}
+	s.UserID = "user"
+}
+
+// ---------------------------
+// Tests
+// ---------------------------
+
+func (s *HttpTaxLotHandlerTestSuite) TestGetLots() {
+	acquiredAt := time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)
+	s.mockService.On("GetReliefMethod", mock.Anything, s.UserID).Return("lifo", nil)
+	s.mockService.On("GetLots", mock.Anything, s.UserID).Return([]*models.TaxLot{{ID: 3, Symbol: "AAPL", Quantity: 10, RemainingQuantity: 4, UnitPrice: models.NewMoney(120), AcquiredAt: acquiredAt}}, nil)
+	w := httptest.NewRecorder()
+
+	s.handler.GetLots(w, newAPIRequest(http.MethodGet, "/api/v1/tax-lots", "", s.UserID, ""))
+
+	s.Equal(http.StatusOK, w.Code)
+	var response taxLotsResponse
+	s.Require().NoError(json.NewDecoder(w.Body).Decode(&response))
+	s.Equal("lifo", response.ReliefMethod)
+	s.Require().Len(response.Lots, 1)
+	s.Equal(taxLotResponse{ID: 3, Symbol: "AAPL", Quantity: 10, RemainingQuantity: 4, UnitPrice: models.NewMoney(120), AcquiredAt: acquiredAt}, response.Lots[0])
+}
+
+func (s *HttpTaxLotHandlerTestSuite) TestGetRealizedGains() {
+	s.mockService.On("GetRealizedGains", mock.Anything, s.UserID).Return([]*models.LotRelief{{LotID: 3, ExecutionID: 9, Symbol: "AAPL", Quantity: 4, RealizedGain: models.NewMoney(120), Term: "long"}}, nil)
+	w := httptest.NewRecorder()
+
+	s.handler.GetRealizedGains(w, newAPIRequest(http.MethodGet, "/api/v1/realized-gains", "", s.UserID, ""))
+
+	s.Equal(http.StatusOK, w.Code)
+	var response realizedGainsResponse
+	s.Require().NoError(json.NewDecoder(w.Body).Decode(&response))
+	s.Require().Len(response.Gains, 1)
+	s.Equal(models.NewMoney(120), response.Gains[0].RealizedGain)
+	s.Equal("long", response.Gains[0].Term)
+}
+
+func (s *HttpTaxLotHandlerTestSuite) TestGetRealizedGainsInternalError() {
+	s.mockService.On("GetRealizedGains", mock.Anything, s.UserID).Return(nil, assert.AnError)
+	w := httptest.NewRecorder()
+
+	s.handler.GetRealizedGains(w, newAPIRequest(http.MethodGet, "/api/v1/realized-gains", "", s.UserID, ""))
+
+	s.Equal(http.StatusInternalServerError, w.Code)
+	s.Equal("internal_error", decodeAPIError(&s.Suite, w).Code)
+}
+
+func (s *HttpTaxLotHandlerTestSuite) TestSetReliefMethod() {
+	s.mockService.On("SetReliefMethod", mock.Anything, s.UserID, "highest_cost").Return(nil)
+	w := httptest.NewRecorder()
+
+	s.handler.SetReliefMethod(w, newAPIRequest(http.MethodPut, "/api/v1/account/lot-relief-method", `{"method":"highest_cost"}`, s.UserID, ""))
+
+	s.Equal(http.StatusOK, w.Code)
+	s.JSONEq(`{"method":"highest_cost"}`, w.Body.String())
+}
+
+func (s *HttpTaxLotHandlerTestSuite) TestSetReliefMethodInvalid() {
+	s.mockService.On("SetReliefMethod", mock.Anything, s.UserID, "average").Return(ports.ErrInvalidReliefMethod)
+	w := httptest.NewRecorder()
+
+	s.handler.SetReliefMethod(w, newAPIRequest(http.MethodPut, "/api/v1/account/lot-relief-method", `{"method":"average"}`, s.UserID, ""))
+
+	s.Equal(http.StatusBadRequest, w.Code)
+	s.Equal("invalid_relief_method", decodeAPIError(&s.Suite, w).Code)
+}
+
+// ---------------------------
+// Run the suite
+// ---------------------------
+func TestHttpTaxLotHandlerTestSuite(t *testing.T) {
+	suite.Run(t, new(HttpTaxLotHandlerTestSuite))
+}
@@ -0,0 +1,180 @@
+package adapters
+
+import (
+	"brokerx/models"
+	"brokerx/ports"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// WithdrawalHandler exposes the withdrawals of the authenticated user, and their review by
+// the back office, as a JSON API.
+type WithdrawalHandler struct {
+	Service ports.WalletService
+}
+
+type withdrawalRequest struct {
+	Amount models.Money `json:"amount"`
+}
+
+type rejectionRequest struct {
+	Reason string `json:"reason"`
+}
+
+type withdrawalResponse struct {
+	ID            int          `json:"id"`
+	UserID        string       `json:"user_id"`
+	Amount        models.Money `json:"amount"`
+	Status        string       `json:"status"`
+	FailureReason string       `json:"failure_reason,omitempty"`
+	CreatedAt     *time.Time   `json:"created_at,omitempty"`
+	UpdatedAt     *time.Time   `json:"updated_at,omitempty"`
+}
+
+type withdrawalsResponse struct {
+	Withdrawals []withdrawalResponse `json:"withdrawals"`
+}
+
+// CreateWithdrawal requests a withdrawal. The withdrawal is returned pending approval when
+// its amount needs a back-office review.
+func (handler *WithdrawalHandler) CreateWithdrawal(writer http.ResponseWriter, request *http.Request) {
+	var body withdrawalRequest
+	if err := json.NewDecoder(request.Body).Decode(&body); err != nil {
+		writeAPIError(writer, http.StatusBadRequest, "invalid_request", "badly formed withdrawal")
+		return
+	}
+
+	userID := request.Context().Value(USER_ID_KEY).(string)
+	withdrawal, err := handler.Service.Withdraw(request.Context(), userID, body.Amount)
+	if err != nil {
+		writeWithdrawalAPIError(writer, err)
+		return
+	}
+
+	writeJSON(writer, http.StatusCreated, newWithdrawalResponse(withdrawal))
+}
+
+func (handler *WithdrawalHandler) ListWithdrawals(writer http.ResponseWriter, request *http.Request) {
+	userID := request.Context().Value(USER_ID_KEY).(string)
+	withdrawals, err := handler.Service.ListWithdrawals(request.Context(), userID)
+	if err != nil {
+		writeWithdrawalAPIError(writer, err)
+		return
+	}
+
+	writeJSON(writer, http.StatusOK, newWithdrawalsResponse(withdrawals))
+}
+
+func (handler *WithdrawalHandler) GetWithdrawal(writer http.ResponseWriter, request *http.Request) {
+	withdrawalID, err := strconv.Atoi(chi.URLParam(request, "id"))
+	if err != nil {
+		writeAPIError(writer, http.StatusBadRequest, "invalid_withdrawal_id", "invalid withdrawal id")
+		return
+	}
+
+	userID := request.Context().Value(USER_ID_KEY).(string)
+	withdrawal, err := handler.Service.GetWithdrawal(request.Context(), userID, withdrawalID)
+	if err != nil {
+		writeWithdrawalAPIError(writer, err)
+		return
+	}
+
+	writeJSON(writer, http.StatusOK, newWithdrawalResponse(withdrawal))
+}
+
+// ListPendingApprovals returns the withdrawals waiting for a back-office review.
+func (handler *WithdrawalHandler) ListPendingApprovals(writer http.ResponseWriter, request *http.Request) {
+	withdrawals, err := handler.Service.ListPendingApprovals(request.Context())
+	if err != nil {
+		writeWithdrawalAPIError(writer, err)
+		return
+	}
+
+	writeJSON(writer, http.StatusOK, newWithdrawalsResponse(withdrawals))
+}
+
+func (handler *WithdrawalHandler) ApproveWithdrawal(writer http.ResponseWriter, request *http.Request) {
+	withdrawalID, err := strconv.Atoi(chi.URLParam(request, "id"))
+	if err != nil {
+		writeAPIError(writer, http.StatusBadRequest, "invalid_withdrawal_id", "invalid withdrawal id")
+		return
+	}
+
+	reviewerID := request.Context().Value(USER_ID_KEY).(string)
+	withdrawal, err := handler.Service.ApproveWithdrawal(request.Context(), reviewerID, withdrawalID)
+	if err != nil {
+		writeWithdrawalAPIError(writer, err)
+		return
+	}
+
+	writeJSON(writer, http.StatusOK, newWithdrawalResponse(withdrawal))
+}
+
+func (handler *WithdrawalHandler) RejectWithdrawal(writer http.ResponseWriter, request *http.Request) {
+	withdrawalID, err := strconv.Atoi(chi.URLParam(request, "id"))
+	if err != nil {
+		writeAPIError(writer, http.StatusBadRequest, "invalid_withdrawal_id", "invalid withdrawal id")
+		return
+	}
+
+	var body rejectionRequest
+	if err := json.NewDecoder(request.Body).Decode(&body); err != nil || body.Reason == "" {
+		writeAPIError(writer, http.StatusBadRequest, "invalid_request", "a rejection reason is required")
+		return
+	}
+
+	reviewerID := request.Context().Value(USER_ID_KEY).(string)
+	withdrawal, err := handler.Service.RejectWithdrawal(request.Context(), reviewerID, withdrawalID, body.Reason)
+	if err != nil {
+		writeWithdrawalAPIError(writer, err)
+		return
+	}
+
+	writeJSON(writer, http.StatusOK, newWithdrawalResponse(withdrawal))
+}
+
+func newWithdrawalsResponse(withdrawals []*models.Withdrawal) withdrawalsResponse {
+	response := withdrawalsResponse{Withdrawals: make([]withdrawalResponse, 0, len(withdrawals))}
+	for _, withdrawal := range withdrawals {
+		response.Withdrawals = append(response.Withdrawals, newWithdrawalResponse(withdrawal))
+	}
+	return response
+}
+
+func newWithdrawalResponse(withdrawal *models.Withdrawal) withdrawalResponse {
+	return withdrawalResponse{
+		ID:            withdrawal.ID,
+		UserID:        withdrawal.UserID,
+		Amount:        withdrawal.Amount,
+		Status:        withdrawal.Status,
+		FailureReason: withdrawal.FailureReason,
+		CreatedAt:     nullTimeToPointer(withdrawal.CreatedAt),
+		UpdatedAt:     nullTimeToPointer(withdrawal.UpdatedAt),
+	}
+}
+
+func writeWithdrawalAPIError(writer http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ports.ErrInvalidAmount):
+		writeAPIError(writer, http.StatusBadRequest, "invalid_amount", err.Error())
+	case errors.Is(err, ports.ErrInsufficientFunds):
+		writeAPIError(writer, http.StatusUnprocessableEntity, "insufficient_funds", err.Error())
+	case errors.Is(err, ports.ErrDailyLimitExceeded):
+		writeAPIError(writer, http.StatusUnprocessableEntity, "daily_limit_exceeded", err.Error())
+	case errors.Is(err, ports.ErrMonthlyLimitExceeded):
+		writeAPIError(writer, http.StatusUnprocessableEntity, "monthly_limit_exceeded", err.Error())
+	case errors.Is(err, ports.ErrWithdrawalNotFound):
+		writeAPIError(writer, http.StatusNotFound, "withdrawal_not_found", err.Error())
+	case errors.Is(err, ports.ErrWithdrawalNotOwned):
+		writeAPIError(writer, http.StatusForbidden, "withdrawal_not_owned", err.Error())
+	case errors.Is(err, ports.ErrWithdrawalNotPending):
+		writeAPIError(writer, http.StatusConflict, "withdrawal_not_pending", err.Error())
+	default:
+		writeAPIError(writer, http.StatusInternalServerError, "internal_error", "internal server error")
+	}
+}
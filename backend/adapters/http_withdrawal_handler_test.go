@@ -0,0 +1,220 @@
+package adapters
+
+import (
+	"brokerx/models"
+	"brokerx/ports"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type MockWalletService struct {
+	mock.Mock
+}
+
+func (m *MockWalletService) Withdraw(ctx context.Context, userID string, amount models.Money) (*models.Withdrawal, error) {
+	args := m.Called(ctx, userID, amount)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Withdrawal), args.Error(1)
+}
+
+func (m *MockWalletService) GetWithdrawal(ctx context.Context, userID string, withdrawalID int) (*models.Withdrawal, error) {
+	args := m.Called(ctx, userID, withdrawalID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Withdrawal), args.Error(1)
+}
+
+func (m *MockWalletService) ListWithdrawals(ctx context.Context, userID string) ([]*models.Withdrawal, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Withdrawal), args.Error(1)
+}
+
+func (m *MockWalletService) ListPendingApprovals(ctx context.Context) ([]*models.Withdrawal, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Withdrawal), args.Error(1)
+}
+
+func (m *MockWalletService) ApproveWithdrawal(ctx context.Context, reviewerID string, withdrawalID int) (*models.Withdrawal, error) {
+	args := m.Called(ctx, reviewerID, withdrawalID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Withdrawal), args.Error(1)
+}
+
+func (m *MockWalletService) RejectWithdrawal(ctx context.Context, reviewerID string, withdrawalID int, reason string) (*models.Withdrawal, error) {
+	args := m.Called(ctx, reviewerID, withdrawalID, reason)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Withdrawal), args.Error(1)
+}
+
+func (m *MockWalletService) RefreshProcessingWithdrawals(ctx context.Context) (int, error) {
+	args := m.Called(ctx)
+	return args.Int(0), args.Error(1)
+}
+
+// ---------------------------
+// Test Suite
+// ---------------------------
+
+type HttpWithdrawalHandlerTestSuite struct {
+	suite.Suite
+	mockService *MockWalletService
+	handler     *WithdrawalHandler
+	UserID      string
+}
+
+func (s *HttpWithdrawalHandlerTestSuite) SetupTest() {
+	s.mockService = new(MockWalletService)
+	s.handler = &WithdrawalHandler{Service: s.mockService}
+	s.UserID = "user"
+}
+
+// ---------------------------
+// Tests
+// ---------------------------
+
+func (s *HttpWithdrawalHandlerTestSuite) TestCreateWithdrawal() {
+	withdrawal := &models.Withdrawal{ID: 1, UserID: s.UserID, Amount: models.NewMoney(1500), Status: "pending_approval"}
+	s.mockService.On("Withdraw", mock.Anything, s.UserID, models.NewMoney(1500)).Return(withdrawal, nil)
+	w := httptest.NewRecorder()
+
+	s.handler.CreateWithdrawal(w, newAPIRequest(http.MethodPost, "/api/v1/withdrawals", `{"amount":1500}`, s.UserID, ""))
+
+	s.Equal(http.StatusCreated, w.Code)
+	var response withdrawalResponse
+	s.Require().NoError(json.NewDecoder(w.Body).Decode(&response))
+	s.Equal(1, response.ID)
+	s.Equal("pending_approval", response.Status)
+}
+
+func (s *HttpWithdrawalHandlerTestSuite) TestCreateWithdrawalBadlyFormed() {
+	w := httptest.NewRecorder()
+
+	s.handler.CreateWithdrawal(w, newAPIRequest(http.MethodPost, "/api/v1/withdrawals", `{"amount":`, s.UserID, ""))
+
+	s.Equal(http.StatusBadRequest, w.Code)
+	s.Equal("invalid_request", decodeAPIError(&s.Suite, w).Code)
+	s.mockService.AssertNotCalled(s.T(), "Withdraw", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *HttpWithdrawalHandlerTestSuite) TestCreateWithdrawalErrors() {
+	cases := []struct {
+		err    error
+		status int
+		code   string
+	}{
+		{ports.ErrInvalidAmount, http.StatusBadRequest, "invalid_amount"},
+		{ports.ErrInsufficientFunds, http.StatusUnprocessableEntity, "insufficient_funds"},
+		{ports.ErrDailyLimitExceeded, http.StatusUnprocessableEntity, "daily_limit_exceeded"},
+		{ports.ErrMonthlyLimitExceeded, http.StatusUnprocessableEntity, "monthly_limit_exceeded"},
+		{assert.AnError, http.StatusInternalServerError, "internal_error"},
+	}
+	for _, c := range cases {
+		s.SetupTest()
+		s.mockService.On("Withdraw", mock.Anything, s.UserID, models.NewMoney(200)).Return(nil, c.err)
+		w := httptest.NewRecorder()
+
+		s.handler.CreateWithdrawal(w, newAPIRequest(http.MethodPost, "/api/v1/withdrawals", `{"amount":200}`, s.UserID, ""))
+
+		s.Equal(c.status, w.Code)
+		s.Equal(c.code, decodeAPIError(&s.Suite, w).Code)
+	}
+}
+
+func (s *HttpWithdrawalHandlerTestSuite) TestListWithdrawals() {
+	withdrawals := []*models.Withdrawal{{ID: 2, Amount: models.NewMoney(50), Status: "processing"}, {ID: 1, Amount: models.NewMoney(10), Status: "failed", FailureReason: "account closed"}}
+	s.mockService.On("ListWithdrawals", mock.Anything, s.UserID).Return(withdrawals, nil)
+	w := httptest.NewRecorder()
+
+	s.handler.ListWithdrawals(w, newAPIRequest(http.MethodGet, "/api/v1/withdrawals", "", s.UserID, ""))
+
+	s.Equal(http.StatusOK, w.Code)
+	var response withdrawalsResponse
+	s.Require().NoError(json.NewDecoder(w.Body).Decode(&response))
+	s.Require().Len(response.Withdrawals, 2)
+	s.Equal("account closed", response.Withdrawals[1].FailureReason)
+}
+
+func (s *HttpWithdrawalHandlerTestSuite) TestGetWithdrawalNotOwned() {
+	s.mockService.On("GetWithdrawal", mock.Anything, s.UserID, 7).Return(nil, ports.ErrWithdrawalNotOwned)
+	w := httptest.NewRecorder()
+
+	s.handler.GetWithdrawal(w, newAPIRequest(http.MethodGet, "/api/v1/withdrawals/7", "", s.UserID, "7"))
+
+	s.Equal(http.StatusForbidden, w.Code)
+	s.Equal("withdrawal_not_owned", decodeAPIError(&s.Suite, w).Code)
+}
+
+func (s *HttpWithdrawalHandlerTestSuite) TestListPendingApprovals() {
+	s.mockService.On("ListPendingApprovals", mock.Anything).Return([]*models.Withdrawal{}, nil)
+	w := httptest.NewRecorder()
+
+	s.handler.ListPendingApprovals(w, newAPIRequest(http.MethodGet, "/api/v1/back-office/withdrawals", "", s.UserID, ""))
+
+	s.Equal(http.StatusOK, w.Code)
+	s.JSONEq(`{"withdrawals":[]}`, w.Body.String())
+}
+
+func (s *HttpWithdrawalHandlerTestSuite) TestApproveWithdrawal() {
+	s.mockService.On("ApproveWithdrawal", mock.Anything, s.UserID, 3).Return(&models.Withdrawal{ID: 3, Amount: models.NewMoney(1500), Status: "completed"}, nil)
+	w := httptest.NewRecorder()
+
+	s.handler.ApproveWithdrawal(w, newAPIRequest(http.MethodPost, "/api/v1/back-office/withdrawals/3/approve", "", s.UserID, "3"))
+
+	s.Equal(http.StatusOK, w.Code)
+}
+
+func (s *HttpWithdrawalHandlerTestSuite) TestApproveWithdrawalNotPending() {
+	s.mockService.On("ApproveWithdrawal", mock.Anything, s.UserID, 3).Return(nil, ports.ErrWithdrawalNotPending)
+	w := httptest.NewRecorder()
+
+	s.handler.ApproveWithdrawal(w, newAPIRequest(http.MethodPost, "/api/v1/back-office/withdrawals/3/approve", "", s.UserID, "3"))
+
+	s.Equal(http.StatusConflict, w.Code)
+	s.Equal("withdrawal_not_pending", decodeAPIError(&s.Suite, w).Code)
+}
+
+func (s *HttpWithdrawalHandlerTestSuite) TestRejectWithdrawal() {
+	s.mockService.On("RejectWithdrawal", mock.Anything, s.UserID, 3, "suspicious activity").Return(&models.Withdrawal{ID: 3, Status: "rejected"}, nil)
+	w := httptest.NewRecorder()
+
+	s.handler.RejectWithdrawal(w, newAPIRequest(http.MethodPost, "/api/v1/back-office/withdrawals/3/reject", `{"reason":"suspicious activity"}`, s.UserID, "3"))
+
+	s.Equal(http.StatusOK, w.Code)
+}
+
+func (s *HttpWithdrawalHandlerTestSuite) TestRejectWithdrawalWithoutReason() {
+	w := httptest.NewRecorder()
+
+	s.handler.RejectWithdrawal(w, newAPIRequest(http.MethodPost, "/api/v1/back-office/withdrawals/3/reject", `{}`, s.UserID, "3"))
+
+	s.Equal(http.StatusBadRequest, w.Code)
+	s.Equal("invalid_request", decodeAPIError(&s.Suite, w).Code)
+	s.mockService.AssertNotCalled(s.T(), "RejectWithdrawal", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+// ---------------------------
+// Run the suite
+// ---------------------------
+func TestHttpWithdrawalHandlerTestSuite(t *testing.T) {
+	suite.Run(t, new(HttpWithdrawalHandlerTestSuite))
+}
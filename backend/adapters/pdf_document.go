@@ -0,0 +1,102 @@
+package adapters
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Landscape letter pages in points, written in the standard Courier font so that no font
+// has to be embedded and columns padded with spaces line up.
+const (
+	pdfPageWidth    = 792
+	pdfPageHeight   = 612
+	pdfMargin       = 40
+	pdfFontSize     = 8
+	pdfLeading      = 10
+	pdfLinesPerPage = (pdfPageHeight - 2*pdfMargin) / pdfLeading
+)
+
+// pdfDocument is a text-only PDF document: lines of text flowing from page to page.
+type pdfDocument struct {
+	lines []string
+}
+
+func (document *pdfDocument) addLine(format string, args ...any) {
+	document.lines = append(document.lines, fmt.Sprintf(format, args...))
+}
+
+// WriteTo renders the document: the catalog, the page tree and the font, then a page and
+// its content stream for every page, followed by the cross-reference table that gives
+// the byte offset of each object.
+func (document *pdfDocument) WriteTo(writer io.Writer) (int64, error) {
+	pages := document.pages()
+
+	var buffer bytes.Buffer
+	var offsets []int
+	addObject := func(body string) {
+		offsets = append(offsets, buffer.Len())
+		fmt.Fprintf(&buffer, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	buffer.WriteString("%PDF-1.4\n")
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 4+2*i)
+	}
+	addObject("<< /Type /Catalog /Pages 2 0 R >>")
+	addObject(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)))
+	addObject("<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>")
+	for i, lines := range pages {
+		addObject(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>",
+			pdfPageWidth, pdfPageHeight, 5+2*i))
+
+		var content strings.Builder
+		fmt.Fprintf(&content, "BT\n/F1 %d Tf\n%d TL\n%d %d Td\n", pdfFontSize, pdfLeading, pdfMargin, pdfPageHeight-pdfMargin-pdfFontSize)
+		for _, line := range lines {
+			fmt.Fprintf(&content, "(%s) Tj T*\n", escapePDFText(line))
+		}
+		content.WriteString("ET")
+		addObject(fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", content.Len(), content.String()))
+	}
+
+	xref := buffer.Len()
+	fmt.Fprintf(&buffer, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buffer, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buffer, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	return buffer.WriteTo(writer)
+}
+
+// pages splits the lines into pages. An empty document still has a blank page.
+func (document *pdfDocument) pages() [][]string {
+	pages := [][]string{}
+	for start := 0; start < len(document.lines); start += pdfLinesPerPage {
+		pages = append(pages, document.lines[start:min(start+pdfLinesPerPage, len(document.lines))])
+	}
+	if len(pages) == 0 {
+		pages = append(pages, nil)
+	}
+	return pages
+}
+
+// escapePDFText escapes the delimiters of a PDF string and replaces the characters that
+// the font cannot show.
+func escapePDFText(text string) string {
+	var escaped strings.Builder
+	for _, char := range text {
+		switch {
+		case char == '\\' || char == '(' || char == ')':
+			escaped.WriteRune('\\')
+			escaped.WriteRune(char)
+		case char < ' ' || char > '~':
+			escaped.WriteRune('?')
+		default:
+			escaped.WriteRune(char)
+		}
+	}
+	return escaped.String()
+}
@@ -0,0 +1,55 @@
+package adapters
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPDFDocumentFlowsLinesAcrossPages(t *testing.T) {
+	document := &pdfDocument{}
+	for i := 0; i < pdfLinesPerPage+1; i++ {
+		document.addLine("line %d", i)
+	}
+
+	var buffer bytes.Buffer
+	_, err := document.WriteTo(&buffer)
+	require.NoError(t, err)
+
+	pdf := buffer.String()
+	require.True(t, strings.HasPrefix(pdf, "%PDF-1.4\n"))
+	require.True(t, strings.HasSuffix(pdf, "%%EOF\n"))
+	require.Contains(t, pdf, "/Kids [4 0 R 6 0 R] /Count 2")
+	require.Contains(t, pdf, fmt.Sprintf("(line %d) Tj T*\nET", pdfLinesPerPage-1))
+	require.Contains(t, pdf, fmt.Sprintf("Td\n(line %d) Tj T*\nET", pdfLinesPerPage))
+
+	// Every entry of the cross-reference table points at the start of its object
+	offsets := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllStringSubmatch(pdf, -1)
+	require.Len(t, offsets, 7)
+	for i, offset := range offsets {
+		position, err := strconv.Atoi(offset[1])
+		require.NoError(t, err)
+		require.True(t, strings.HasPrefix(pdf[position:], fmt.Sprintf("%d 0 obj", i+1)))
+	}
+	startxref := regexp.MustCompile(`startxref\n(\d+)\n`).FindStringSubmatch(pdf)
+	position, err := strconv.Atoi(startxref[1])
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(pdf[position:], "xref\n0 8\n"))
+}
+
+func TestPDFDocumentWithoutLinesHasABlankPage(t *testing.T) {
+	var buffer bytes.Buffer
+	_, err := (&pdfDocument{}).WriteTo(&buffer)
+	require.NoError(t, err)
+
+	require.Contains(t, buffer.String(), "/Count 1")
+}
+
+func TestEscapePDFText(t *testing.T) {
+	require.Equal(t, `Unrealized P&L \(USD\) \\ caf?`, escapePDFText(`Unrealized P&L (USD) \ café`))
+}
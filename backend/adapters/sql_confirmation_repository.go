@@ -0,0 +1,74 @@
+package adapters
+
+import (
+	"brokerx/models"
+	"brokerx/ports"
+	"context"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type SQLConfirmationRepository struct {
+	DB DBTX
+}
+
+// CreateConfirmation inserts the confirmation. The number is unique, so a confirmation
+// already issued under it is never overwritten.
+func (repo *SQLConfirmationRepository) CreateConfirmation(ctx context.Context, confirmation *models.Confirmation) (int, error) {
+	result, err := repo.DB.ExecContext(ctx, "INSERT INTO brokerx.trade_confirmations (number, execution_id, order_id, user_id, symbol, side, quantity, price, currency, commission, regulatory_fee, executed_at, settlement_date) "+
+		"VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		confirmation.Number, confirmation.ExecutionID, confirmation.OrderID, confirmation.UserID, confirmation.Symbol, confirmation.Side, confirmation.Quantity,
+		confirmation.Price, confirmation.Currency, confirmation.Commission, confirmation.RegulatoryFee, confirmation.ExecutedAt, confirmation.SettlementDate)
+	if err != nil {
+		log.Errorf("Error creating trade confirmation %s: %v", confirmation.Number, err)
+		return 0, err
+	}
+	id, _ := result.LastInsertId()
+	return int(id), nil
+}
+
+func (repo *SQLConfirmationRepository) FindByNumber(ctx context.Context, number string) (*models.Confirmation, error) {
+	confirmations, err := repo.queryConfirmations(ctx, "SELECT "+confirmationColumns+" FROM brokerx.trade_confirmations WHERE number=?", number)
+	if err != nil {
+		return nil, err
+	}
+	if len(confirmations) == 0 {
+		return nil, ports.ErrConfirmationNotFound
+	}
+	return confirmations[0], nil
+}
+
+// FindByUserId returns the confirmations of the user, most recent first.
+func (repo *SQLConfirmationRepository) FindByUserId(ctx context.Context, userId string) ([]*models.Confirmation, error) {
+	return repo.queryConfirmations(ctx, "SELECT "+confirmationColumns+" FROM brokerx.trade_confirmations WHERE user_id=? ORDER BY id DESC", userId)
+}
+
+const confirmationColumns = "id, number, execution_id, order_id, user_id, symbol, side, quantity, price, currency, commission, regulatory_fee, executed_at, settlement_date, issued_at"
+
+func (repo *SQLConfirmationRepository) queryConfirmations(ctx context.Context, query string, args ...any) ([]*models.Confirmation, error) {
+	rows, err := repo.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var confirmations []*models.Confirmation
+
+	for rows.Next() {
+		var confirmation models.Confirmation
+		if err := rows.Scan(&confirmation.ID, &confirmation.Number, &confirmation.ExecutionID, &confirmation.OrderID, &confirmation.UserID, &confirmation.Symbol,
+			&confirmation.Side, &confirmation.Quantity, &confirmation.Price, &confirmation.Currency, &confirmation.Commission, &confirmation.RegulatoryFee,
+			&confirmation.ExecutedAt, &confirmation.SettlementDate, &confirmation.IssuedAt); err != nil {
+			return nil, err
+		}
+		confirmations = append(confirmations, &confirmation)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return confirmations, nil
+}
+
+var _ ports.ConfirmationRepository = (*SQLConfirmationRepository)(nil) // Ensure interface is implemented at compile time
@@ -0,0 +1,98 @@
+package adapters
+
+import (
+	"brokerx/models"
+	"brokerx/ports"
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSQLConfirmationRepositoryIntegration(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	buyOrderId, sellOrderId := insertExecutionTestData(t, db)
+	defer cleanup()
+
+	execution := &models.Execution{BuyOrderID: buyOrderId, SellOrderID: sellOrderId, Symbol: symbol, Quantity: 10, Price: models.NewMoney(150), LiquidityFlag: "seller_maker",
+		ExecutedAt: time.Now().UTC(), SettlementDate: time.Date(2026, 10, 21, 0, 0, 0, 0, time.UTC)}
+	executionId, err := (&SQLExecutionRepository{DB: db}).CreateExecution(context.Background(), execution)
+	require.NoError(t, err)
+	execution.ID = executionId
+
+	repo := &SQLConfirmationRepository{DB: db}
+
+	// --- CreateConfirmation ---
+	confirmation := &models.Confirmation{Number: models.ConfirmationNumber(execution, "buy"), ExecutionID: executionId, OrderID: buyOrderId, UserID: userId, Symbol: symbol,
+		Side: "buy", Quantity: 10, Price: models.NewMoney(150), Currency: "USD", Commission: models.MustParseMoney("2.48"), ExecutedAt: execution.ExecutedAt, SettlementDate: execution.SettlementDate}
+	buyId, err := repo.CreateConfirmation(context.Background(), confirmation)
+	require.NoError(t, err)
+	require.Greater(t, buyId, 0)
+
+	// --- CreateConfirmation never overwrites the confirmation already issued ---
+	replayed := *confirmation
+	replayed.Commission = models.NewMoney(99)
+	_, err = repo.CreateConfirmation(context.Background(), &replayed)
+	require.Error(t, err)
+
+	// --- FindByNumber ---
+	found, err := repo.FindByNumber(context.Background(), confirmation.Number)
+	require.NoError(t, err)
+	require.Equal(t, buyId, found.ID)
+	require.Equal(t, buyOrderId, found.OrderID)
+	require.Equal(t, "buy", found.Side)
+	require.Equal(t, models.MustParseMoney("2.48"), found.Commission)
+	require.WithinDuration(t, execution.ExecutedAt, found.ExecutedAt, time.Second)
+	require.True(t, execution.SettlementDate.Equal(found.SettlementDate))
+	require.True(t, found.IssuedAt.Valid)
+
+	// --- FindByNumber unknown confirmation ---
+	_, err = repo.FindByNumber(context.Background(), "unknown")
+	require.ErrorIs(t, err, ports.ErrConfirmationNotFound)
+
+	// --- FindByUserId ---
+	confirmation = &models.Confirmation{Number: models.ConfirmationNumber(execution, "sell"), ExecutionID: executionId, OrderID: sellOrderId, UserID: userId, Symbol: symbol,
+		Side: "sell", Quantity: 10, Price: models.NewMoney(150), Currency: "USD", RegulatoryFee: models.MustParseMoney("0.05"), ExecutedAt: execution.ExecutedAt, SettlementDate: execution.SettlementDate}
+	sellId, err := repo.CreateConfirmation(context.Background(), confirmation)
+	require.NoError(t, err)
+	confirmations, err := repo.FindByUserId(context.Background(), userId)
+	require.NoError(t, err)
+	require.Equal(t, 2, len(confirmations))
+	require.Equal(t, sellId, confirmations[0].ID)
+	require.Equal(t, buyId, confirmations[1].ID)
+
+	// --- FindByUserId without confirmations ---
+	confirmations, err = repo.FindByUserId(context.Background(), "unknown")
+	require.NoError(t, err)
+	require.Empty(t, confirmations)
+}
+
+func TestSQLConfirmationRepositoryErrors(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	repo := &SQLConfirmationRepository{DB: db}
+
+	// --- CreateConfirmation connection error ---
+	mock.ExpectExec("INSERT INTO brokerx.trade_confirmations").WillReturnError(sql.ErrConnDone)
+	_, err := repo.CreateConfirmation(context.Background(), &models.Confirmation{Number: "TC-20260918-00000001-B"})
+	require.ErrorIs(t, err, sql.ErrConnDone)
+
+	// --- FindByNumber connection error ---
+	mock.ExpectQuery(".*").WillReturnError(sql.ErrConnDone)
+	confirmation, err := repo.FindByNumber(context.Background(), "TC-20260918-00000001-B")
+	require.Nil(t, confirmation)
+	require.ErrorIs(t, err, sql.ErrConnDone)
+
+	// --- FindByUserId scan error ---
+	rows := sqlmock.NewRows([]string{"id", "number", "execution_id", "order_id", "user_id", "symbol", "side", "quantity", "price", "currency", "commission", "regulatory_fee", "executed_at", "settlement_date", "issued_at"}).
+		AddRow(1, "TC-20260918-00000001-B", 1, 1, "user", "AAPL", "buy", 10, "bad-data", "USD", 0, 0, time.Now(), time.Now(), nil)
+	mock.ExpectQuery(".*").WillReturnRows(rows)
+	confirmations, err := repo.FindByUserId(context.Background(), "user")
+	require.Nil(t, confirmations)
+	require.Error(t, err)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,103 @@
+package adapters
+
+import (
+	"brokerx/models"
+	"brokerx/ports"
+	"context"
+	"database/sql"
+	"errors"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type SQLDepositRepository struct {
+	DB DBTX
+}
+
+func (repo *SQLDepositRepository) CreateDeposit(ctx context.Context, deposit *models.Deposit) (int, error) {
+	result, err := repo.DB.ExecContext(ctx, "INSERT INTO brokerx.deposits (user_id, amount, status) VALUES (?, ?, ?)",
+		deposit.UserID, deposit.Amount, deposit.Status)
+	if err != nil {
+		log.Errorf("Error creating deposit: %v", err)
+		return 0, err
+	}
+	id, _ := result.LastInsertId()
+	return int(id), nil
+}
+
+// UpdateDeposit only updates pending deposits so that a deposit can never be settled twice.
+func (repo *SQLDepositRepository) UpdateDeposit(ctx context.Context, deposit *models.Deposit) error {
+	result, err := repo.DB.ExecContext(ctx, "UPDATE brokerx.deposits SET status=?, provider_reference=?, failure_reason=? WHERE id=? AND status='pending'",
+		deposit.Status, deposit.ProviderReference, deposit.FailureReason, deposit.ID)
+	if err != nil {
+		log.Errorf("Error updating deposit %d: %v", deposit.ID, err)
+		return err
+	}
+
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return ports.ErrDepositNotPending
+	}
+	return nil
+}
+
+func (repo *SQLDepositRepository) FindById(ctx context.Context, id int) (*models.Deposit, error) {
+	row := repo.DB.QueryRowContext(ctx, "SELECT "+depositColumns+" FROM brokerx.deposits WHERE id=?", id)
+
+	deposit, err := scanDeposit(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ports.ErrDepositNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return deposit, nil
+}
+
+// FindByUserId returns the deposits of the user, most recent first.
+func (repo *SQLDepositRepository) FindByUserId(ctx context.Context, userId string) ([]*models.Deposit, error) {
+	return repo.queryDeposits(ctx, "SELECT "+depositColumns+" FROM brokerx.deposits WHERE user_id=? ORDER BY id DESC", userId)
+}
+
+// FindPending returns the deposits still waiting for the payment provider, oldest first.
+func (repo *SQLDepositRepository) FindPending(ctx context.Context) ([]*models.Deposit, error) {
+	return repo.queryDeposits(ctx, "SELECT "+depositColumns+" FROM brokerx.deposits WHERE status='pending' ORDER BY id")
+}
+
+func (repo *SQLDepositRepository) queryDeposits(ctx context.Context, query string, args ...any) ([]*models.Deposit, error) {
+	rows, err := repo.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deposits []*models.Deposit
+
+	for rows.Next() {
+		deposit, err := scanDeposit(rows)
+		if err != nil {
+			return nil, err
+		}
+		deposits = append(deposits, deposit)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return deposits, nil
+}
+
+const depositColumns = "id, user_id, amount, status, provider_reference, failure_reason, created_at, updated_at"
+
+func scanDeposit(row interface{ Scan(dest ...any) error }) (*models.Deposit, error) {
+	var deposit models.Deposit
+	err := row.Scan(&deposit.ID, &deposit.UserID, &deposit.Amount, &deposit.Status, &deposit.ProviderReference,
+		&deposit.FailureReason, &deposit.CreatedAt, &deposit.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &deposit, nil
+}
+
+var _ ports.DepositRepository = (*SQLDepositRepository)(nil) // Ensure interface is implemented at compile time
@@ -0,0 +1,84 @@
+package adapters
+
+import (
+	"brokerx/models"
+	"brokerx/ports"
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSQLDepositRepositoryIntegration(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	insertOrderTestData(t, db)
+	defer cleanup()
+
+	repo := &SQLDepositRepository{DB: db}
+
+	// --- CreateDeposit ---
+	deposit := &models.Deposit{UserID: userId, Amount: models.NewMoney(250), Status: "pending"}
+	depositId, err := repo.CreateDeposit(context.Background(), deposit)
+	require.NoError(t, err)
+	require.Greater(t, depositId, 0)
+
+	// --- FindPending ---
+	pending, err := repo.FindPending(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, len(pending))
+	require.Equal(t, depositId, pending[0].ID)
+
+	// --- UpdateDeposit ---
+	deposit.ID = depositId
+	deposit.Status = "settled"
+	deposit.ProviderReference = "fake-1"
+	err = repo.UpdateDeposit(context.Background(), deposit)
+	require.NoError(t, err)
+
+	// --- UpdateDeposit once settled ---
+	err = repo.UpdateDeposit(context.Background(), deposit)
+	require.ErrorIs(t, err, ports.ErrDepositNotPending)
+
+	// --- FindById ---
+	found, err := repo.FindById(context.Background(), depositId)
+	require.NoError(t, err)
+	require.Equal(t, userId, found.UserID)
+	require.Equal(t, models.NewMoney(250), found.Amount)
+	require.Equal(t, "settled", found.Status)
+	require.Equal(t, "fake-1", found.ProviderReference)
+
+	// --- FindByUserId ---
+	deposits, err := repo.FindByUserId(context.Background(), userId)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(deposits))
+
+	// --- FindById not found ---
+	_, err = repo.FindById(context.Background(), -1)
+	require.ErrorIs(t, err, ports.ErrDepositNotFound)
+}
+
+func TestSQLDepositRepositoryErrors(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	repo := &SQLDepositRepository{DB: db}
+
+	// --- CreateDeposit connection error ---
+	mock.ExpectExec(".*").WillReturnError(sql.ErrConnDone)
+	_, err := repo.CreateDeposit(context.Background(), &models.Deposit{UserID: "user", Amount: models.NewMoney(10), Status: "pending"})
+	require.ErrorIs(t, err, sql.ErrConnDone)
+
+	// --- UpdateDeposit no pending deposit ---
+	mock.ExpectExec("UPDATE brokerx.deposits").WillReturnResult(sqlmock.NewResult(0, 0))
+	err = repo.UpdateDeposit(context.Background(), &models.Deposit{ID: 1, Status: "settled"})
+	require.ErrorIs(t, err, ports.ErrDepositNotPending)
+
+	// --- FindPending connection error ---
+	mock.ExpectQuery(".*").WillReturnError(sql.ErrConnDone)
+	deposits, err := repo.FindPending(context.Background())
+	require.Nil(t, deposits)
+	require.ErrorIs(t, err, sql.ErrConnDone)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,110 @@
+package adapters
+
+import (
+	"brokerx/models"
+	"brokerx/ports"
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type SQLExecutionRepository struct {
+	DB DBTX
+}
+
+func (repo *SQLExecutionRepository) CreateExecution(ctx context.Context, execution *models.Execution) (int, error) {
+	result, err := repo.DB.ExecContext(ctx, "INSERT INTO executions (buy_order_id, sell_order_id, symbol, quantity, price, liquidity_flag, buy_commission, sell_commission, regulatory_fee, executed_at, settlement_date) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		execution.BuyOrderID, execution.SellOrderID, execution.Symbol, execution.Quantity, execution.Price, execution.LiquidityFlag, execution.BuyCommission, execution.SellCommission, execution.RegulatoryFee, execution.ExecutedAt, execution.SettlementDate)
+	if err != nil {
+		log.Errorf("Error creating execution: %v", err)
+		return 0, err
+	}
+	id, _ := result.LastInsertId()
+	return int(id), nil
+}
+
+func (repo *SQLExecutionRepository) FindByOrderId(ctx context.Context, orderId int) ([]*models.Execution, error) {
+	return repo.queryExecutions(ctx, "SELECT "+executionColumns+" FROM brokerx.executions WHERE buy_order_id=? OR sell_order_id=? ORDER BY executed_at, id", orderId, orderId)
+}
+
+func (repo *SQLExecutionRepository) FindByUserIdAndPeriod(ctx context.Context, userId string, from time.Time, to time.Time) ([]*models.Execution, error) {
+	return repo.queryExecutions(ctx, "SELECT "+executionColumns+" FROM brokerx.executions WHERE executed_at >= ? AND executed_at < ? "+
+		"AND (buy_order_id IN (SELECT id FROM brokerx.orders WHERE user_id=?) OR sell_order_id IN (SELECT id FROM brokerx.orders WHERE user_id=?)) ORDER BY executed_at, id",
+		from, to, userId, userId)
+}
+
+// FindUnsettled returns the unsettled executions due to settle on or before the date,
+// oldest first.
+func (repo *SQLExecutionRepository) FindUnsettled(ctx context.Context, settlementDate time.Time) ([]*models.Execution, error) {
+	return repo.queryExecutions(ctx, "SELECT "+executionColumns+" FROM brokerx.executions WHERE settlement_date <= ? AND settled_at IS NULL ORDER BY settlement_date, id", settlementDate)
+}
+
+// MarkSettled records when the execution settled. It returns ErrAlreadySettled when the
+// execution has already settled.
+func (repo *SQLExecutionRepository) MarkSettled(ctx context.Context, executionId int, settledAt time.Time) error {
+	result, err := repo.DB.ExecContext(ctx, "UPDATE brokerx.executions SET settled_at=? WHERE id=? AND settled_at IS NULL", settledAt, executionId)
+	if err != nil {
+		log.Errorf("Error settling execution %d: %v", executionId, err)
+		return err
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return ports.ErrAlreadySettled
+	}
+	return nil
+}
+
+const executionColumns = "id, buy_order_id, sell_order_id, symbol, quantity, price, liquidity_flag, buy_commission, sell_commission, regulatory_fee, executed_at, settlement_date, settled_at"
+
+func (repo *SQLExecutionRepository) queryExecutions(ctx context.Context, query string, args ...any) ([]*models.Execution, error) {
+	rows, err := repo.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var executions []*models.Execution
+
+	for rows.Next() {
+		var execution models.Execution
+		if err := rows.Scan(&execution.ID, &execution.BuyOrderID, &execution.SellOrderID, &execution.Symbol, &execution.Quantity, &execution.Price, &execution.LiquidityFlag, &execution.BuyCommission, &execution.SellCommission, &execution.RegulatoryFee, &execution.ExecutedAt, &execution.SettlementDate, &execution.SettledAt); err != nil {
+			return nil, err
+		}
+		executions = append(executions, &execution)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return executions, nil
+}
+
+// FindLatestPrice returns the price of the most recent execution of the symbol.
+func (repo *SQLExecutionRepository) FindLatestPrice(ctx context.Context, symbol string) (models.Money, error) {
+	row := repo.DB.QueryRowContext(ctx, "SELECT price FROM brokerx.executions WHERE symbol=? ORDER BY executed_at DESC, id DESC LIMIT 1", symbol)
+
+	var price models.Money
+	err := row.Scan(&price)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Money{}, ports.ErrPriceNotFound
+	}
+	return price, err
+}
+
+// FindLatestPriceBefore returns the price of the last execution of the symbol executed
+// before the time.
+func (repo *SQLExecutionRepository) FindLatestPriceBefore(ctx context.Context, symbol string, before time.Time) (models.Money, error) {
+	row := repo.DB.QueryRowContext(ctx, "SELECT price FROM brokerx.executions WHERE symbol=? AND executed_at < ? ORDER BY executed_at DESC, id DESC LIMIT 1", symbol, before)
+
+	var price models.Money
+	err := row.Scan(&price)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Money{}, ports.ErrPriceNotFound
+	}
+	return price, err
+}
+
+var _ ports.ExecutionRepository = (*SQLExecutionRepository)(nil) // Ensure interface is implemented at compile time
@@ -0,0 +1,158 @@
+package adapters
+
+import (
+	"context"
+	"brokerx/models"
+	"brokerx/ports"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/require"
+)
+
+func insertExecutionTestData(t *testing.T, db *sql.DB) (int, int) {
+	_, err := db.Exec(`INSERT INTO users (id, email, password) 
+                      VALUES (?, 'email', 'hashedpw')`, userId)
+	require.NoError(t, err)
+
+	orderRepo := &SQLOrderRepository{DB: db}
+	buyOrderId, err := orderRepo.CreateOrder(context.Background(), &models.Order{UserID: userId, Symbol: symbol, Type: "limit", Action: "buy",
+		Quantity: 10, UnitPrice: models.NewMoney(150), Timing: "day", Status: "filled"})
+	require.NoError(t, err)
+	sellOrderId, err := orderRepo.CreateOrder(context.Background(), &models.Order{UserID: userId, Symbol: symbol, Type: "limit", Action: "sell",
+		Quantity: 10, UnitPrice: models.NewMoney(150), Timing: "day", Status: "filled"})
+	require.NoError(t, err)
+
+	return buyOrderId, sellOrderId
+}
+
+func TestSQLExecutionRepositoryIntegration(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	buyOrderId, sellOrderId := insertExecutionTestData(t, db)
+	defer cleanup()
+
+	repo := &SQLExecutionRepository{DB: db}
+
+	settlementDate := time.Date(2026, 10, 21, 0, 0, 0, 0, time.UTC)
+
+	// --- Sucessfully create an execution ---
+	execution := &models.Execution{
+		BuyOrderID:     buyOrderId,
+		SellOrderID:    sellOrderId,
+		Symbol:         symbol,
+		Quantity:       10,
+		Price:          models.NewMoney(150),
+		LiquidityFlag:  "seller_maker",
+		BuyCommission:  models.MustParseMoney("2.48"),
+		SellCommission: models.MustParseMoney("2.48"),
+		RegulatoryFee:  models.MustParseMoney("0.05"),
+		ExecutedAt:     time.Now().UTC(),
+		SettlementDate: settlementDate,
+	}
+
+	id, err := repo.CreateExecution(context.Background(), execution)
+	require.NoError(t, err)
+	require.Greater(t, id, 0)
+
+	// --- FindByOrderId from both sides of the trade ---
+	for _, orderId := range []int{buyOrderId, sellOrderId} {
+		executions, err := repo.FindByOrderId(context.Background(), orderId)
+		require.NoError(t, err)
+		require.Equal(t, 1, len(executions))
+		require.Equal(t, id, executions[0].ID)
+		require.Equal(t, execution.Quantity, executions[0].Quantity)
+		require.Equal(t, execution.Price, executions[0].Price)
+		require.Equal(t, execution.LiquidityFlag, executions[0].LiquidityFlag)
+		require.Equal(t, execution.BuyCommission, executions[0].BuyCommission)
+		require.Equal(t, execution.SellCommission, executions[0].SellCommission)
+		require.Equal(t, execution.RegulatoryFee, executions[0].RegulatoryFee)
+		require.WithinDuration(t, execution.ExecutedAt, executions[0].ExecutedAt, time.Second)
+		require.True(t, settlementDate.Equal(executions[0].SettlementDate))
+		require.False(t, executions[0].SettledAt.Valid)
+	}
+
+	// --- FindLatestPrice returns the price of the most recent execution ---
+	execution.Price = models.NewMoney(155)
+	execution.ExecutedAt = execution.ExecutedAt.Add(time.Minute)
+	_, err = repo.CreateExecution(context.Background(), execution)
+	require.NoError(t, err)
+	price, err := repo.FindLatestPrice(context.Background(), symbol)
+	require.NoError(t, err)
+	require.Equal(t, models.NewMoney(155), price)
+
+	// --- FindLatestPriceBefore ignores the executions from the time on ---
+	price, err = repo.FindLatestPriceBefore(context.Background(), symbol, execution.ExecutedAt)
+	require.NoError(t, err)
+	require.Equal(t, models.NewMoney(150), price)
+	_, err = repo.FindLatestPriceBefore(context.Background(), symbol, execution.ExecutedAt.Add(-2*time.Minute))
+	require.ErrorIs(t, err, ports.ErrPriceNotFound)
+
+	// --- FindLatestPrice for a symbol that never traded ---
+	_, err = repo.FindLatestPrice(context.Background(), "stockThatNeverTraded")
+	require.ErrorIs(t, err, ports.ErrPriceNotFound)
+
+	// --- FindUnsettled returns the executions due by the date ---
+	executions, err := repo.FindUnsettled(context.Background(), settlementDate.AddDate(0, 0, -1))
+	require.NoError(t, err)
+	require.Equal(t, 0, len(executions))
+	executions, err = repo.FindUnsettled(context.Background(), settlementDate)
+	require.NoError(t, err)
+	require.Equal(t, 2, len(executions))
+	require.Equal(t, id, executions[0].ID)
+
+	// --- MarkSettled settles an execution once ---
+	err = repo.MarkSettled(context.Background(), id, time.Now().UTC())
+	require.NoError(t, err)
+	err = repo.MarkSettled(context.Background(), id, time.Now().UTC())
+	require.ErrorIs(t, err, ports.ErrAlreadySettled)
+	executions, err = repo.FindUnsettled(context.Background(), settlementDate)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(executions))
+
+	// --- FindByUserIdAndPeriod returns the executions of the period, oldest first ---
+	from := execution.ExecutedAt.Add(-2 * time.Minute)
+	executions, err = repo.FindByUserIdAndPeriod(context.Background(), userId, from, from.Add(time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, 2, len(executions))
+	require.Equal(t, id, executions[0].ID)
+	executions, err = repo.FindByUserIdAndPeriod(context.Background(), userId, from.Add(time.Hour), from.Add(2*time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, 0, len(executions))
+
+	// --- Fail create an execution for unknown orders ---
+	execution.BuyOrderID = -1
+	id, err = repo.CreateExecution(context.Background(), execution)
+	require.Error(t, err)
+	require.Equal(t, 0, id)
+
+	// --- FindByOrderId connection error ---
+	mockDb, mock, _ := sqlmock.New()
+	repo = &SQLExecutionRepository{DB: mockDb}
+	mock.ExpectQuery(".*").WillReturnError(sql.ErrConnDone)
+
+	executions, err = repo.FindByOrderId(context.Background(), buyOrderId)
+	require.Nil(t, executions)
+	require.ErrorIs(t, err, sql.ErrConnDone)
+
+	// --- FindLatestPrice connection error ---
+	mock.ExpectQuery(".*").WillReturnError(sql.ErrConnDone)
+
+	_, err = repo.FindLatestPrice(context.Background(), symbol)
+	require.ErrorIs(t, err, sql.ErrConnDone)
+
+	// --- FindByUserIdAndPeriod connection error ---
+	mock.ExpectQuery(".*").WillReturnError(sql.ErrConnDone)
+
+	executions, err = repo.FindByUserIdAndPeriod(context.Background(), userId, time.Now(), time.Now().Add(time.Hour))
+	require.Nil(t, executions)
+	require.ErrorIs(t, err, sql.ErrConnDone)
+
+	// --- MarkSettled connection error ---
+	mock.ExpectExec(".*").WillReturnError(sql.ErrConnDone)
+
+	err = repo.MarkSettled(context.Background(), id, time.Now().UTC())
+	require.ErrorIs(t, err, sql.ErrConnDone)
+}
@@ -0,0 +1,53 @@
+package adapters
+
+import (
+	"brokerx/models"
+	"brokerx/ports"
+	"context"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type SQLFXConversionRepository struct {
+	DB DBTX
+}
+
+func (repo *SQLFXConversionRepository) CreateConversion(ctx context.Context, conversion *models.FXConversion) (int, error) {
+	result, err := repo.DB.ExecContext(ctx, "INSERT INTO brokerx.fx_conversions (user_id, from_currency, to_currency, amount, converted_amount, rate) VALUES (?, ?, ?, ?, ?, ?)",
+		conversion.UserID, conversion.FromCurrency, conversion.ToCurrency, conversion.Amount, conversion.ConvertedAmount, conversion.Rate)
+	if err != nil {
+		log.Errorf("Error creating fx conversion: %v", err)
+		return 0, err
+	}
+	id, _ := result.LastInsertId()
+	return int(id), nil
+}
+
+// FindByUserId returns the conversions of the user, most recent first.
+func (repo *SQLFXConversionRepository) FindByUserId(ctx context.Context, userId string) ([]*models.FXConversion, error) {
+	rows, err := repo.DB.QueryContext(ctx, "SELECT id, user_id, from_currency, to_currency, amount, converted_amount, rate, created_at "+
+		"FROM brokerx.fx_conversions WHERE user_id=? ORDER BY id DESC", userId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var conversions []*models.FXConversion
+
+	for rows.Next() {
+		var conversion models.FXConversion
+		if err := rows.Scan(&conversion.ID, &conversion.UserID, &conversion.FromCurrency, &conversion.ToCurrency, &conversion.Amount,
+			&conversion.ConvertedAmount, &conversion.Rate, &conversion.CreatedAt); err != nil {
+			return nil, err
+		}
+		conversions = append(conversions, &conversion)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return conversions, nil
+}
+
+var _ ports.FXConversionRepository = (*SQLFXConversionRepository)(nil) // Ensure interface is implemented at compile time
@@ -0,0 +1,63 @@
+package adapters
+
+import (
+	"brokerx/models"
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSQLFXConversionRepositoryIntegration(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	insertOrderTestData(t, db)
+	defer cleanup()
+
+	repo := &SQLFXConversionRepository{DB: db}
+
+	// --- CreateConversion ---
+	conversion := &models.FXConversion{UserID: userId, FromCurrency: "USD", ToCurrency: "CAD", Amount: models.NewMoney(100), ConvertedAmount: models.NewMoney(137), Rate: "1.37000000"}
+	firstId, err := repo.CreateConversion(context.Background(), conversion)
+	require.NoError(t, err)
+	require.Greater(t, firstId, 0)
+
+	conversion = &models.FXConversion{UserID: userId, FromCurrency: "CAD", ToCurrency: "USD", Amount: models.NewMoney(100), ConvertedAmount: models.MustParseMoney("72.99"), Rate: "0.72992701"}
+	secondId, err := repo.CreateConversion(context.Background(), conversion)
+	require.NoError(t, err)
+
+	// --- FindByUserId ---
+	conversions, err := repo.FindByUserId(context.Background(), userId)
+	require.NoError(t, err)
+	require.Equal(t, 2, len(conversions))
+	require.Equal(t, secondId, conversions[0].ID)
+	require.Equal(t, models.MustParseMoney("72.99"), conversions[0].ConvertedAmount)
+	require.Equal(t, "0.72992701", conversions[0].Rate)
+	require.True(t, conversions[0].CreatedAt.Valid)
+	require.Equal(t, firstId, conversions[1].ID)
+
+	// --- FindByUserId without conversions ---
+	conversions, err = repo.FindByUserId(context.Background(), "unknown")
+	require.NoError(t, err)
+	require.Empty(t, conversions)
+}
+
+func TestSQLFXConversionRepositoryErrors(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	repo := &SQLFXConversionRepository{DB: db}
+
+	// --- CreateConversion connection error ---
+	mock.ExpectExec(".*").WillReturnError(sql.ErrConnDone)
+	_, err := repo.CreateConversion(context.Background(), &models.FXConversion{UserID: "user", FromCurrency: "USD", ToCurrency: "CAD"})
+	require.ErrorIs(t, err, sql.ErrConnDone)
+
+	// --- FindByUserId connection error ---
+	mock.ExpectQuery(".*").WillReturnError(sql.ErrConnDone)
+	conversions, err := repo.FindByUserId(context.Background(), "user")
+	require.Nil(t, conversions)
+	require.ErrorIs(t, err, sql.ErrConnDone)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,157 @@
+package adapters
+
+import (
+	"brokerx/models"
+	"brokerx/ports"
+	"context"
+	"database/sql"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type SQLLedgerRepository struct {
+	DB DBTX
+}
+
+// walletColumns maps the accounts of the wallets to the columns holding their balance.
+var walletColumns = map[string]string{
+	"available": "available_funds",
+	"on_hold":   "funds_on_hold",
+	"unsettled": "unsettled_funds",
+}
+
+// Post records the entry and its postings and applies the postings to the wallets in a
+// single transaction. Postings must be in whole minor units of their currency, the
+// precision of the balances, so that the stored balances and the ledger never drift apart.
+func (repo *SQLLedgerRepository) Post(ctx context.Context, entry *models.JournalEntry) error {
+	totals := map[string]models.Money{}
+	for _, posting := range entry.Postings {
+		if !models.IsSupportedCurrency(posting.Currency) {
+			return ports.ErrUnsupportedCurrency
+		}
+		if !posting.Amount.FitsCurrency(posting.Currency) {
+			return ports.ErrFractionalCents
+		}
+		totals[posting.Currency] = totals[posting.Currency].Add(posting.Amount)
+	}
+	if len(entry.Postings) < 2 {
+		return ports.ErrUnbalancedEntry
+	}
+	for _, total := range totals {
+		if !total.IsZero() {
+			return ports.ErrUnbalancedEntry
+		}
+	}
+
+	return inTransaction(ctx, repo.DB, func(tx DBTX) error {
+		result, err := tx.ExecContext(ctx, "INSERT INTO brokerx.journal_entries (type, reference) VALUES (?, ?)", entry.Type, entry.Reference)
+		if err != nil {
+			log.Errorf("Error creating %s journal entry for %s: %v", entry.Type, entry.Reference, err)
+			return err
+		}
+		id, _ := result.LastInsertId()
+		entry.ID = int(id)
+
+		for _, posting := range entry.Postings {
+			if _, err := tx.ExecContext(ctx, "INSERT INTO brokerx.ledger_postings (entry_id, account, user_id, currency, amount) VALUES (?, ?, ?, ?, ?)",
+				entry.ID, posting.Account, sql.NullString{String: posting.UserID, Valid: posting.UserID != ""}, posting.Currency, posting.Amount); err != nil {
+				log.Errorf("Error posting to %s for journal entry %d: %v", posting.Account, entry.ID, err)
+				return err
+			}
+			if err := applyToWallet(ctx, tx, posting); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// applyToWallet updates the balance of the wallet account of the posting. A credit
+// creates the wallet of its currency the first time the user holds that currency. The
+// check that a debit leaves the balance positive is part of the update itself so that
+// concurrent postings can never overspend the wallet.
+func applyToWallet(ctx context.Context, tx DBTX, posting models.LedgerPosting) error {
+	column, ok := walletColumns[posting.Account]
+	if !ok {
+		return nil
+	}
+
+	if !posting.Amount.IsNegative() {
+		_, err := tx.ExecContext(ctx, "INSERT INTO brokerx.wallets (id, user_id, currency, "+column+") VALUES (UUID(), ?, ?, ?) ON DUPLICATE KEY UPDATE "+column+" = "+column+" + ?",
+			posting.UserID, posting.Currency, posting.Amount, posting.Amount)
+		return err
+	}
+
+	result, err := tx.ExecContext(ctx, "UPDATE brokerx.wallets SET "+column+" = "+column+" + ? WHERE user_id=? AND currency=? AND "+column+" + ? >= 0",
+		posting.Amount, posting.UserID, posting.Currency, posting.Amount)
+	if err != nil {
+		return err
+	}
+
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return ports.ErrInsufficientFunds
+	}
+	return nil
+}
+
+func (repo *SQLLedgerRepository) FindEntriesByUserId(ctx context.Context, userId string) ([]*models.JournalEntry, error) {
+	rows, err := repo.DB.QueryContext(ctx, "SELECT e.id, e.type, e.reference, e.created_at, p.account, p.currency, p.amount FROM brokerx.journal_entries e "+
+		"JOIN brokerx.ledger_postings p ON p.entry_id = e.id WHERE p.user_id=? ORDER BY e.id DESC, p.id", userId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []*models.JournalEntry
+
+	for rows.Next() {
+		var entry models.JournalEntry
+		posting := models.LedgerPosting{UserID: userId}
+		if err := rows.Scan(&entry.ID, &entry.Type, &entry.Reference, &entry.CreatedAt, &posting.Account, &posting.Currency, &posting.Amount); err != nil {
+			return nil, err
+		}
+
+		if len(entries) == 0 || entries[len(entries)-1].ID != entry.ID {
+			entries = append(entries, &entry)
+		}
+		last := entries[len(entries)-1]
+		last.Postings = append(last.Postings, posting)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// FindBalances returns the balances of the wallets, per user and currency, according to
+// the ledger.
+func (repo *SQLLedgerRepository) FindBalances(ctx context.Context) ([]*models.LedgerBalance, error) {
+	rows, err := repo.DB.QueryContext(ctx, "SELECT user_id, currency, "+
+		"COALESCE(SUM(CASE WHEN account='available' THEN amount END), 0), COALESCE(SUM(CASE WHEN account='on_hold' THEN amount END), 0), "+
+		"COALESCE(SUM(CASE WHEN account='unsettled' THEN amount END), 0) "+
+		"FROM brokerx.ledger_postings WHERE user_id IS NOT NULL GROUP BY user_id, currency ORDER BY user_id, currency")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var balances []*models.LedgerBalance
+
+	for rows.Next() {
+		var balance models.LedgerBalance
+		if err := rows.Scan(&balance.UserID, &balance.Currency, &balance.AvailableFunds, &balance.OnHoldFunds, &balance.UnsettledFunds); err != nil {
+			return nil, err
+		}
+		balances = append(balances, &balance)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return balances, nil
+}
+
+var _ ports.LedgerRepository = (*SQLLedgerRepository)(nil) // Ensure interface is implemented at compile time
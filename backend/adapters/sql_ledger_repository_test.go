@@ -0,0 +1,136 @@
+package adapters
+
+import (
+	"brokerx/models"
+	"brokerx/ports"
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSQLLedgerRepositoryIntegration(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	insertUnitOfWorkTestData(t, db)
+	defer cleanup()
+
+	repo := &SQLLedgerRepository{DB: db}
+	walletRepo := &SQLWalletRepository{DB: db}
+
+	// --- Post ---
+	hold := testHoldEntry(models.NewMoney(400))
+	err := repo.Post(context.Background(), hold)
+	require.NoError(t, err)
+	require.Greater(t, hold.ID, 0)
+	wallet, err := walletRepo.FindByUserIdAndCurrency(context.Background(), userId, "USD")
+	require.NoError(t, err)
+	require.Equal(t, models.NewMoney(600), wallet.AvailableFunds)
+	require.Equal(t, models.NewMoney(400), wallet.OnHoldFunds)
+
+	// --- Post insufficient funds ---
+	err = repo.Post(context.Background(), testHoldEntry(models.MustParseMoney("600.01")))
+	require.ErrorIs(t, err, ports.ErrInsufficientFunds)
+
+	// --- Post unbalanced entry ---
+	err = repo.Post(context.Background(), &models.JournalEntry{Type: "deposit", Reference: "deposit:1", Postings: []models.LedgerPosting{
+		{Account: "available", UserID: userId, Currency: "USD", Amount: models.NewMoney(100)},
+		{Account: "payment_provider", Currency: "USD", Amount: models.NewMoney(-90)},
+	}})
+	require.ErrorIs(t, err, ports.ErrUnbalancedEntry)
+
+	// --- Post to an account outside the wallets ---
+	err = repo.Post(context.Background(), &models.JournalEntry{Type: "deposit", Reference: "deposit:1", Postings: []models.LedgerPosting{
+		{Account: "available", UserID: userId, Currency: "USD", Amount: models.NewMoney(100)},
+		{Account: "payment_provider", Currency: "USD", Amount: models.NewMoney(-100)},
+	}})
+	require.NoError(t, err)
+
+	// --- Post a credit in a currency the user never held creates its wallet ---
+	err = repo.Post(context.Background(), &models.JournalEntry{Type: "fx_conversion", Reference: "fx_conversion:1", Postings: []models.LedgerPosting{
+		{Account: "available", UserID: userId, Currency: "USD", Amount: models.NewMoney(-100)},
+		{Account: "fx", Currency: "USD", Amount: models.NewMoney(100)},
+		{Account: "fx", Currency: "CAD", Amount: models.NewMoney(-137)},
+		{Account: "available", UserID: userId, Currency: "CAD", Amount: models.NewMoney(137)},
+	}})
+	require.NoError(t, err)
+	wallet, err = walletRepo.FindByUserIdAndCurrency(context.Background(), userId, "CAD")
+	require.NoError(t, err)
+	require.Equal(t, models.NewMoney(137), wallet.AvailableFunds)
+	require.NotEmpty(t, wallet.ID)
+
+	// --- FindEntriesByUserId ---
+	entries, err := repo.FindEntriesByUserId(context.Background(), userId)
+	require.NoError(t, err)
+	require.Equal(t, 3, len(entries))
+	require.Equal(t, "fx_conversion", entries[0].Type)
+	require.Equal(t, []models.LedgerPosting{
+		{Account: "available", UserID: userId, Currency: "USD", Amount: models.NewMoney(-100)},
+		{Account: "available", UserID: userId, Currency: "CAD", Amount: models.NewMoney(137)},
+	}, entries[0].Postings)
+	require.Equal(t, "deposit", entries[1].Type)
+	require.Equal(t, 1, len(entries[1].Postings))
+	require.Equal(t, "hold", entries[2].Type)
+	require.Equal(t, 2, len(entries[2].Postings))
+	require.True(t, entries[2].CreatedAt.Valid)
+
+	// --- FindBalances ---
+	balances, err := repo.FindBalances(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 2, len(balances))
+	require.Equal(t, &models.LedgerBalance{UserID: userId, Currency: "CAD", AvailableFunds: models.NewMoney(137)}, balances[0])
+	require.Equal(t, &models.LedgerBalance{UserID: userId, Currency: "USD", AvailableFunds: models.NewMoney(-400), OnHoldFunds: models.NewMoney(400)}, balances[1])
+}
+
+func TestSQLLedgerRepositoryErrors(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	repo := &SQLLedgerRepository{DB: db}
+
+	// --- Post connection error ---
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO brokerx.journal_entries").WillReturnError(sql.ErrConnDone)
+	mock.ExpectRollback()
+	err := repo.Post(context.Background(), testHoldEntry(models.NewMoney(10)))
+	require.ErrorIs(t, err, sql.ErrConnDone)
+
+	// --- Post single posting ---
+	err = repo.Post(context.Background(), &models.JournalEntry{Type: "hold", Postings: []models.LedgerPosting{{Account: "available", UserID: "user", Currency: "USD"}}})
+	require.ErrorIs(t, err, ports.ErrUnbalancedEntry)
+
+	// --- Post balanced overall but not in each currency ---
+	err = repo.Post(context.Background(), &models.JournalEntry{Type: "fx_conversion", Postings: []models.LedgerPosting{
+		{Account: "available", UserID: "user", Currency: "USD", Amount: models.NewMoney(-100)},
+		{Account: "available", UserID: "user", Currency: "CAD", Amount: models.NewMoney(100)},
+	}})
+	require.ErrorIs(t, err, ports.ErrUnbalancedEntry)
+
+	// --- Post in an unsupported currency ---
+	err = repo.Post(context.Background(), &models.JournalEntry{Type: "hold", Postings: []models.LedgerPosting{
+		{Account: "available", UserID: "user", Amount: models.NewMoney(-100)},
+		{Account: "on_hold", UserID: "user", Amount: models.NewMoney(100)},
+	}})
+	require.ErrorIs(t, err, ports.ErrUnsupportedCurrency)
+
+	// --- Post a fraction of a yen ---
+	err = repo.Post(context.Background(), &models.JournalEntry{Type: "hold", Postings: []models.LedgerPosting{
+		{Account: "available", UserID: "user", Currency: "JPY", Amount: models.MustParseMoney("-0.5")},
+		{Account: "on_hold", UserID: "user", Currency: "JPY", Amount: models.MustParseMoney("0.5")},
+	}})
+	require.ErrorIs(t, err, ports.ErrFractionalCents)
+
+	// --- FindEntriesByUserId connection error ---
+	mock.ExpectQuery(".*").WillReturnError(sql.ErrConnDone)
+	entries, err := repo.FindEntriesByUserId(context.Background(), "user")
+	require.Nil(t, entries)
+	require.ErrorIs(t, err, sql.ErrConnDone)
+
+	// --- FindBalances connection error ---
+	mock.ExpectQuery(".*").WillReturnError(sql.ErrConnDone)
+	balances, err := repo.FindBalances(context.Background())
+	require.Nil(t, balances)
+	require.ErrorIs(t, err, sql.ErrConnDone)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
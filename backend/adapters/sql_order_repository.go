@@ -3,18 +3,21 @@ package adapters
 import (
 	"brokerx/models"
 	"brokerx/ports"
+	"context"
 	"database/sql"
+	"errors"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 )
 
 type SQLOrderRepository struct {
-	DB *sql.DB
+	DB DBTX
 }
 
-func (repo * SQLOrderRepository) CreateOrder(order *models.Order) (int, error) {
-	result, err := repo.DB.Exec("INSERT INTO orders (user_id, symbol, type, action, quantity, unit_price, timing, status) VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
-		order.UserID, order.Symbol, order.Type, order.Action, order.Quantity, order.UnitPrice, order.Timing, order.Status)
+func (repo * SQLOrderRepository) CreateOrder(ctx context.Context, order *models.Order) (int, error) {
+	result, err := repo.DB.ExecContext(ctx, "INSERT INTO orders (user_id, symbol, currency, type, action, quantity, unit_price, stop_price, timing, expires_at, lot_id, status, fees_on_hold, queued_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		order.UserID, order.Symbol, order.Currency, order.Type, order.Action, order.Quantity, order.UnitPrice, order.StopPrice, order.Timing, order.ExpiresAt, order.LotID, order.Status, order.FeesOnHold, order.QueuedAt)
 	if err != nil {
 		log.Errorf("Error creating order: %v", err)
 		return 0, err
@@ -23,4 +26,155 @@ func (repo * SQLOrderRepository) CreateOrder(order *models.Order) (int, error) {
 	return int(id), nil
 }
 
+func (repo * SQLOrderRepository) UpdateOrder(ctx context.Context, order *models.Order) error {
+	_, err := repo.DB.ExecContext(ctx, "UPDATE orders SET type=?, quantity=?, unit_price=?, status=?, filled_quantity=?, average_fill_price=?, commission=?, fees_on_hold=?, version=?, queued_at=? WHERE id=?",
+		order.Type, order.Quantity, order.UnitPrice, order.Status, order.FilledQuantity, order.AverageFillPrice, order.Commission, order.FeesOnHold, order.Version, order.QueuedAt, order.ID)
+	if err != nil {
+		log.Errorf("Error updating order %d: %v", order.ID, err)
+	}
+	return err
+}
+
+func (repo * SQLOrderRepository) FindById(ctx context.Context, id int) (*models.Order, error) {
+	row := repo.DB.QueryRowContext(ctx, "SELECT "+orderColumns+" FROM brokerx.orders WHERE id=?", id)
+
+	order, err := scanOrder(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ports.ErrOrderNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return order, nil
+}
+
+// FindByUserId returns the orders of the user that match the filter, most recent first.
+func (repo * SQLOrderRepository) FindByUserId(ctx context.Context, userId string, filter models.OrderFilter) ([]*models.Order, error) {
+	query := "SELECT " + orderColumns + " FROM brokerx.orders WHERE user_id=?"
+	args := []any{userId}
+
+	if filter.Status != "" {
+		query += " AND status=?"
+		args = append(args, filter.Status)
+	}
+	if filter.Symbol != "" {
+		query += " AND symbol=?"
+		args = append(args, filter.Symbol)
+	}
+	if filter.Action != "" {
+		query += " AND action=?"
+		args = append(args, filter.Action)
+	}
+	if !filter.From.IsZero() {
+		query += " AND created_at >= ?"
+		args = append(args, filter.From)
+	}
+	if !filter.To.IsZero() {
+		query += " AND created_at < ?"
+		args = append(args, filter.To)
+	}
+	if filter.Cursor > 0 {
+		query += " AND id < ?"
+		args = append(args, filter.Cursor)
+	}
+
+	query += " ORDER BY id DESC"
+	if filter.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, filter.Limit)
+	}
+
+	return repo.queryOrders(ctx, query, args...)
+}
+
+// FindExpirableOrders returns the active orders with the given timing that were placed
+// before the given time, oldest first.
+func (repo * SQLOrderRepository) FindExpirableOrders(ctx context.Context, timing string, createdBefore time.Time) ([]*models.Order, error) {
+	return repo.queryOrders(ctx, "SELECT "+orderColumns+" FROM brokerx.orders WHERE timing=? AND status IN ('open', 'partially filled') AND created_at < ? ORDER BY id",
+		timing, createdBefore)
+}
+
+// FindExpiredGoodTillDateOrders returns the active GTD orders whose expiry is at or
+// before the given time, oldest first.
+func (repo * SQLOrderRepository) FindExpiredGoodTillDateOrders(ctx context.Context, now time.Time) ([]*models.Order, error) {
+	return repo.queryOrders(ctx, "SELECT "+orderColumns+" FROM brokerx.orders WHERE timing='gtd' AND status IN ('open', 'partially filled') AND expires_at <= ? ORDER BY id",
+		now)
+}
+
+// FindActiveOrders returns the open and partially filled orders in their time priority on
+// the book. Orders that were never queued fall back to their creation time.
+func (repo * SQLOrderRepository) FindActiveOrders(ctx context.Context) ([]*models.Order, error) {
+	return repo.queryOrders(ctx, "SELECT "+orderColumns+" FROM brokerx.orders WHERE status IN ('open', 'partially filled') ORDER BY COALESCE(queued_at, created_at), id")
+}
+
+func (repo * SQLOrderRepository) queryOrders(ctx context.Context, query string, args ...any) ([]*models.Order, error) {
+	rows, err := repo.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var orders []*models.Order
+
+	for rows.Next() {
+		order, err := scanOrder(rows)
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, order)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return orders, nil
+}
+
+func (repo * SQLOrderRepository) SaveOrderVersion(ctx context.Context, order *models.Order) error {
+	_, err := repo.DB.ExecContext(ctx, "INSERT INTO order_versions (order_id, version, quantity, unit_price) VALUES (?, ?, ?, ?)",
+		order.ID, order.Version, order.Quantity, order.UnitPrice)
+	if err != nil {
+		log.Errorf("Error saving version %d of order %d: %v", order.Version, order.ID, err)
+	}
+	return err
+}
+
+func (repo * SQLOrderRepository) FindOrderVersions(ctx context.Context, orderId int) ([]*models.OrderVersion, error) {
+	rows, err := repo.DB.QueryContext(ctx, "SELECT order_id, version, quantity, unit_price, created_at FROM brokerx.order_versions WHERE order_id=? ORDER BY version", orderId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var versions []*models.OrderVersion
+
+	for rows.Next() {
+		var version models.OrderVersion
+		if err := rows.Scan(&version.OrderID, &version.Version, &version.Quantity, &version.UnitPrice, &version.CreatedAt); err != nil {
+			return nil, err
+		}
+		versions = append(versions, &version)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return versions, nil
+}
+
+const orderColumns = "id, user_id, symbol, currency, type, action, quantity, unit_price, stop_price, timing, expires_at, lot_id, status, filled_quantity, average_fill_price, commission, fees_on_hold, version, queued_at, created_at, updated_at"
+
+func scanOrder(row interface{ Scan(dest ...any) error }) (*models.Order, error) {
+	var order models.Order
+	err := row.Scan(&order.ID, &order.UserID, &order.Symbol, &order.Currency, &order.Type, &order.Action, &order.Quantity, &order.UnitPrice,
+		&order.StopPrice, &order.Timing, &order.ExpiresAt, &order.LotID, &order.Status, &order.FilledQuantity, &order.AverageFillPrice, &order.Commission, &order.FeesOnHold, &order.Version, &order.QueuedAt, &order.CreatedAt, &order.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
 var _ ports.OrderRepository = (*SQLOrderRepository)(nil) // Ensure interface is implemented at compile time
\ No newline at end of file
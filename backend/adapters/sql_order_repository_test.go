@@ -1,9 +1,12 @@
 package adapters
 
 import (
+	"context"
 	"brokerx/models"
+	"brokerx/ports"
 	"database/sql"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
@@ -18,7 +21,7 @@ func insertOrderTestData(t *testing.T, db *sql.DB) {
 	require.NoError(t, err)
 
     _, err = db.Query(`INSERT INTO orders (user_id, symbol, type, action, quantity, unit_price, timing, status) VALUES(?, ?, ?, ?, ?, ?, ?, ?)`, 
-		userId, symbol, "buy", "market", 10, 150.00, "day", "open")
+		userId, symbol, "market", "buy", 10, models.NewMoney(150), "day", "open")
     require.NoError(t, err)
 }
 
@@ -33,32 +36,186 @@ func TestSQLOrderRepositoryIntegration(t *testing.T) {
 	order := &models.Order{
 		UserID:    userId,
 		Symbol:    "AAPL",
-		Type:      "buy",
-		Action:    "market",
+		Type:      "market",
+		Action:    "buy",
 		Quantity:  10,
-		UnitPrice: 150.00,
+		UnitPrice: models.NewMoney(150),
 		Timing:    "day",
 		Status:    "open",
 	}
 
-	id, err := repo.CreateOrder(order)
+	id, err := repo.CreateOrder(context.Background(), order)
 
 	require.Nil(t, err)
 	require.Greater(t, id, 0)
 
+	// --- Sucessfully update an order ---
+	order.ID = id
+	order.Status = "partially filled"
+	order.FilledQuantity = 4
+	order.AverageFillPrice = models.MustParseMoney("149.25")
+	order.Commission = models.NewMoney(1)
+	order.FeesOnHold = models.MustParseMoney("0.50")
+
+	err = repo.UpdateOrder(context.Background(), order)
+
+	require.Nil(t, err)
+
+	// --- Sucessfully find an order ---
+	found, err := repo.FindById(context.Background(), id)
+
+	require.Nil(t, err)
+	require.Equal(t, order.Symbol, found.Symbol)
+	require.Equal(t, order.Type, found.Type)
+	require.Equal(t, order.Action, found.Action)
+	require.Equal(t, "partially filled", found.Status)
+	require.Equal(t, 4, found.FilledQuantity)
+	require.Equal(t, models.MustParseMoney("149.25"), found.AverageFillPrice)
+	require.Equal(t, models.NewMoney(1), found.Commission)
+	require.Equal(t, models.MustParseMoney("0.50"), found.FeesOnHold)
+
+	// --- Sucessfully save and find order versions ---
+	order.Version = 1
+	require.Nil(t, repo.SaveOrderVersion(context.Background(), order))
+	order.Version = 2
+	order.Quantity = 8
+	require.Nil(t, repo.SaveOrderVersion(context.Background(), order))
+
+	versions, err := repo.FindOrderVersions(context.Background(), id)
+
+	require.Nil(t, err)
+	require.Equal(t, 2, len(versions))
+	require.Equal(t, 10, versions[0].Quantity)
+	require.Equal(t, 8, versions[1].Quantity)
+
+	// --- Fail save an existing order version ---
+	err = repo.SaveOrderVersion(context.Background(), order)
+
+	require.NotNil(t, err)
+
+	// --- Fail find a non-existent order ---
+	found, err = repo.FindById(context.Background(), -1)
+
+	require.ErrorIs(t, err, ports.ErrOrderNotFound)
+	require.Nil(t, found)
+
+	// --- Sucessfully find the orders of a user ---
+	userOrders, err := repo.FindByUserId(context.Background(), userId, models.OrderFilter{})
+
+	require.Nil(t, err)
+	require.Equal(t, 2, len(userOrders))
+	require.Equal(t, id, userOrders[0].ID)
+
+	userOrders, err = repo.FindByUserId(context.Background(), userId, models.OrderFilter{Status: "partially filled", Symbol: "AAPL", Action: "buy", From: time.Now().Add(-time.Hour), To: time.Now().Add(time.Hour)})
+
+	require.Nil(t, err)
+	require.Equal(t, 1, len(userOrders))
+	require.Equal(t, id, userOrders[0].ID)
+
+	userOrders, err = repo.FindByUserId(context.Background(), userId, models.OrderFilter{Cursor: id, Limit: 5})
+
+	require.Nil(t, err)
+	require.Equal(t, 1, len(userOrders))
+	require.Less(t, userOrders[0].ID, id)
+
+	userOrders, err = repo.FindByUserId(context.Background(), userId, models.OrderFilter{Action: "sell"})
+
+	require.Nil(t, err)
+	require.Empty(t, userOrders)
+
+	// --- Sucessfully find expirable orders ---
+	expirable, err := repo.FindExpirableOrders(context.Background(), "day", time.Now().Add(time.Minute))
+
+	require.Nil(t, err)
+	require.Equal(t, 2, len(expirable))
+
+	order.Status = "expired"
+	require.Nil(t, repo.UpdateOrder(context.Background(), order))
+	expirable, err = repo.FindExpirableOrders(context.Background(), "day", time.Now().Add(time.Minute))
+
+	require.Nil(t, err)
+	require.Equal(t, 1, len(expirable))
+
+	expirable, err = repo.FindExpirableOrders(context.Background(), "day", time.Now().Add(-time.Hour))
+
+	require.Nil(t, err)
+	require.Empty(t, expirable)
+
+	// --- Sucessfully find expired GTD orders ---
+	gtdOrder := &models.Order{
+		UserID:    userId,
+		Symbol:    "AAPL",
+		Type:      "limit",
+		Action:    "buy",
+		Quantity:  10,
+		UnitPrice: models.NewMoney(150),
+		Timing:    "gtd",
+		ExpiresAt: sql.NullTime{Time: time.Now().Add(time.Hour).UTC().Truncate(time.Second), Valid: true},
+		Status:    "open",
+	}
+	gtdOrder.ID, err = repo.CreateOrder(context.Background(), gtdOrder)
+	require.Nil(t, err)
+
+	found, err = repo.FindById(context.Background(), gtdOrder.ID)
+	require.Nil(t, err)
+	require.True(t, gtdOrder.ExpiresAt.Time.Equal(found.ExpiresAt.Time))
+
+	expirable, err = repo.FindExpiredGoodTillDateOrders(context.Background(), time.Now())
+	require.Nil(t, err)
+	require.Empty(t, expirable)
+
+	expirable, err = repo.FindExpiredGoodTillDateOrders(context.Background(), time.Now().Add(2*time.Hour))
+	require.Nil(t, err)
+	require.Equal(t, 1, len(expirable))
+	require.Equal(t, gtdOrder.ID, expirable[0].ID)
+
+	// --- Sucessfully persist a stop order and its activation ---
+	stopOrder := &models.Order{
+		UserID:    userId,
+		Symbol:    "AAPL",
+		Type:      "stop_limit",
+		Action:    "sell",
+		Quantity:  10,
+		UnitPrice: models.NewMoney(140),
+		StopPrice: models.NewMoney(145),
+		Timing:    "gtc",
+		Status:    "open",
+	}
+	stopOrder.ID, err = repo.CreateOrder(context.Background(), stopOrder)
+	require.Nil(t, err)
+
+	stopOrder.Type = "limit"
+	require.Nil(t, repo.UpdateOrder(context.Background(), stopOrder))
+
+	found, err = repo.FindById(context.Background(), stopOrder.ID)
+	require.Nil(t, err)
+	require.Equal(t, "limit", found.Type)
+	require.Equal(t, models.NewMoney(145), found.StopPrice)
+
+	// --- Sucessfully find the active orders in their time priority ---
+	gtdOrder.QueuedAt = sql.NullTime{Time: time.Now().Add(time.Minute), Valid: true}
+	require.Nil(t, repo.UpdateOrder(context.Background(), gtdOrder))
+
+	active, err := repo.FindActiveOrders(context.Background())
+	require.Nil(t, err)
+	require.Equal(t, 3, len(active))
+	require.Equal(t, stopOrder.ID, active[1].ID)
+	require.Equal(t, gtdOrder.ID, active[2].ID)
+	require.True(t, active[2].QueuedAt.Valid)
+
 	// --- Fail create an order ---
 	badOrder := &models.Order{
 		UserID:    userId,
 		Symbol:    "AAPL",
-		Type:      "buys",
-		Action:    "market",
+		Type:      "markets",
+		Action:    "buy",
 		Quantity:  10,
-		UnitPrice: 150.00,
+		UnitPrice: models.NewMoney(150),
 		Timing:    "day",
 		Status:    "open",
 	}
 
-	id, err = repo.CreateOrder(badOrder)
+	id, err = repo.CreateOrder(context.Background(), badOrder)
 
 	require.NotNil(t, err)
 	require.Equal(t, 0, id)
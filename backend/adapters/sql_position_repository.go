@@ -3,15 +3,28 @@ package adapters
 import (
 	"brokerx/models"
 	"brokerx/ports"
+	"context"
 	"database/sql"
+	"time"
 )
 
 type SQLPositionRepository struct {
-	DB *sql.DB
+	DB DBTX
 }
 
-func (repo *SQLPositionRepository) FindByUserIdAndSymbol(userId string, symbol string) ([]*models.Position, error) {
-	rows, err := repo.DB.Query("SELECT symbol, quantity, unit_price FROM brokerx.positions WHERE user_id=? and symbol=?", userId, symbol)
+func (repo *SQLPositionRepository) FindByUserIdAndSymbol(ctx context.Context, userId string, symbol string) ([]*models.Position, error) {
+	return repo.queryPositions(ctx, "SELECT "+positionColumns+" FROM brokerx.positions WHERE user_id=? and symbol=?", userId, symbol)
+}
+
+// FindByUserId returns every position of the user, open and closed, ordered by symbol.
+func (repo *SQLPositionRepository) FindByUserId(ctx context.Context, userId string) ([]*models.Position, error) {
+	return repo.queryPositions(ctx, "SELECT "+positionColumns+" FROM brokerx.positions WHERE user_id=? ORDER BY symbol, id", userId)
+}
+
+const positionColumns = "id, user_id, symbol, quantity, reserved_quantity, unsettled_quantity, unit_price, realized_pnl, status, closed_at"
+
+func (repo *SQLPositionRepository) queryPositions(ctx context.Context, query string, args ...any) ([]*models.Position, error) {
+	rows, err := repo.DB.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -21,7 +34,8 @@ func (repo *SQLPositionRepository) FindByUserIdAndSymbol(userId string, symbol s
 
 	for rows.Next() {
 		var pos models.Position
-		if err := rows.Scan(&pos.Symbol, &pos.Quantity, &pos.UnitPrice); err != nil {
+		if err := rows.Scan(&pos.ID, &pos.UserId, &pos.Symbol, &pos.Quantity, &pos.ReservedQuantity, &pos.UnsettledQuantity, &pos.UnitPrice,
+			&pos.RealizedPnL, &pos.Status, &pos.ClosedAt); err != nil {
 			return nil, err
 		}
 		positions = append(positions, &pos)
@@ -34,4 +48,159 @@ func (repo *SQLPositionRepository) FindByUserIdAndSymbol(userId string, symbol s
 	return positions, nil
 }
 
-var _ ports.PositionRepository = (*SQLPositionRepository)(nil) // Ensure interface is implemented at compile time
\ No newline at end of file
+// ReserveShares reserves settled shares across the positions of the symbol, oldest first.
+// The positions are locked while reserving so that concurrent sell orders can never commit
+// the same shares twice.
+func (repo *SQLPositionRepository) ReserveShares(ctx context.Context, userId string, symbol string, quantity int) error {
+	return inTransaction(ctx, repo.DB, func(tx DBTX) error {
+		positions, err := lockPositions(ctx, tx, userId, symbol, "ORDER BY id")
+		if err != nil {
+			return err
+		}
+
+		remaining := quantity
+		for _, pos := range positions {
+			reserved := min(remaining, pos.Quantity-pos.ReservedQuantity-pos.UnsettledQuantity)
+			if reserved <= 0 {
+				continue
+			}
+			if _, err := tx.ExecContext(ctx, "UPDATE brokerx.positions SET reserved_quantity = reserved_quantity + ? WHERE id=?", reserved, pos.ID); err != nil {
+				return err
+			}
+			remaining -= reserved
+		}
+
+		if remaining > 0 {
+			return ports.ErrInsufficientShares
+		}
+		return nil
+	})
+}
+
+// ReleaseShares releases reserved shares, newest positions first.
+func (repo *SQLPositionRepository) ReleaseShares(ctx context.Context, userId string, symbol string, quantity int) error {
+	return inTransaction(ctx, repo.DB, func(tx DBTX) error {
+		positions, err := lockPositions(ctx, tx, userId, symbol, "ORDER BY id DESC")
+		if err != nil {
+			return err
+		}
+
+		remaining := quantity
+		for _, pos := range positions {
+			released := min(remaining, pos.ReservedQuantity)
+			if released <= 0 {
+				continue
+			}
+			if _, err := tx.ExecContext(ctx, "UPDATE brokerx.positions SET reserved_quantity = reserved_quantity - ? WHERE id=?", released, pos.ID); err != nil {
+				return err
+			}
+			remaining -= released
+		}
+		return nil
+	})
+}
+
+// AddShares adds bought shares to the open position of the symbol and recomputes its
+// average cost, rounded half even to four decimal places. The shares are unsettled until
+// SettleShares. A new position is opened when the user holds none.
+func (repo *SQLPositionRepository) AddShares(ctx context.Context, userId string, symbol string, quantity int, unitPrice models.Money) error {
+	return inTransaction(ctx, repo.DB, func(tx DBTX) error {
+		positions, err := lockPositions(ctx, tx, userId, symbol, "ORDER BY id")
+		if err != nil {
+			return err
+		}
+
+		if len(positions) == 0 {
+			_, err = tx.ExecContext(ctx, "INSERT INTO brokerx.positions (user_id, symbol, quantity, unsettled_quantity, unit_price) VALUES (?, ?, ?, ?, ?)",
+				userId, symbol, quantity, quantity, unitPrice)
+			return err
+		}
+
+		pos := positions[0]
+		total := pos.Quantity + quantity
+		averageCost := pos.UnitPrice.Mul(pos.Quantity).Add(unitPrice.Mul(quantity)).Div(total, models.RoundHalfEven)
+		_, err = tx.ExecContext(ctx, "UPDATE brokerx.positions SET quantity=?, unsettled_quantity = unsettled_quantity + ?, unit_price=? WHERE id=?", total, quantity, averageCost, pos.ID)
+		return err
+	})
+}
+
+// ConsumeReservedShares removes sold shares from the positions that reserved them, oldest
+// first, and books the realized P&L of the sale against their average cost, rounded half
+// even to the minor unit of the trading currency. A position left without shares is closed.
+func (repo *SQLPositionRepository) ConsumeReservedShares(ctx context.Context, userId string, symbol string, quantity int, currency string, unitPrice models.Money) error {
+	return inTransaction(ctx, repo.DB, func(tx DBTX) error {
+		positions, err := lockPositions(ctx, tx, userId, symbol, "ORDER BY id")
+		if err != nil {
+			return err
+		}
+
+		remaining := quantity
+		for _, pos := range positions {
+			consumed := min(remaining, pos.ReservedQuantity)
+			if consumed <= 0 {
+				continue
+			}
+			status, closedAt := "open", sql.NullTime{}
+			if pos.Quantity == consumed {
+				status, closedAt = "closed", sql.NullTime{Time: time.Now().UTC(), Valid: true}
+			}
+			realizedPnL := unitPrice.Sub(pos.UnitPrice).Mul(consumed).RoundToCurrency(currency, models.RoundHalfEven)
+			if _, err := tx.ExecContext(ctx, "UPDATE brokerx.positions SET quantity = quantity - ?, reserved_quantity = reserved_quantity - ?, realized_pnl = realized_pnl + ?, status=?, closed_at=? WHERE id=?",
+				consumed, consumed, realizedPnL, status, closedAt, pos.ID); err != nil {
+				return err
+			}
+			remaining -= consumed
+		}
+
+		if remaining > 0 {
+			return ports.ErrInsufficientShares
+		}
+		return nil
+	})
+}
+
+// SettleShares settles bought shares across the positions of the symbol, oldest first.
+func (repo *SQLPositionRepository) SettleShares(ctx context.Context, userId string, symbol string, quantity int) error {
+	return inTransaction(ctx, repo.DB, func(tx DBTX) error {
+		positions, err := lockPositions(ctx, tx, userId, symbol, "ORDER BY id")
+		if err != nil {
+			return err
+		}
+
+		remaining := quantity
+		for _, pos := range positions {
+			settled := min(remaining, pos.UnsettledQuantity)
+			if settled <= 0 {
+				continue
+			}
+			if _, err := tx.ExecContext(ctx, "UPDATE brokerx.positions SET unsettled_quantity = unsettled_quantity - ? WHERE id=?", settled, pos.ID); err != nil {
+				return err
+			}
+			remaining -= settled
+		}
+		return nil
+	})
+}
+
+// lockPositions locks the open positions of the symbol for the rest of the transaction.
+func lockPositions(ctx context.Context, tx DBTX, userId string, symbol string, orderBy string) ([]*models.Position, error) {
+	rows, err := tx.QueryContext(ctx, "SELECT id, quantity, reserved_quantity, unsettled_quantity, unit_price FROM brokerx.positions WHERE user_id=? and symbol=? and status='open' "+orderBy+" FOR UPDATE", userId, symbol)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var positions []*models.Position
+
+	for rows.Next() {
+		var pos models.Position
+		if err := rows.Scan(&pos.ID, &pos.Quantity, &pos.ReservedQuantity, &pos.UnsettledQuantity, &pos.UnitPrice); err != nil {
+			return nil, err
+		}
+		positions = append(positions, &pos)
+	}
+
+	return positions, rows.Err()
+}
+
+var _ ports.PositionRepository = (*SQLPositionRepository)(nil) // Ensure interface is implemented at compile time
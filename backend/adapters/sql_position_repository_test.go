@@ -1,6 +1,9 @@
 package adapters
 
 import (
+	"context"
+	"brokerx/models"
+	"brokerx/ports"
 	"database/sql"
 	"testing"
 
@@ -11,7 +14,7 @@ import (
 )
 
 var quantity int = 1000
-var unitPrice float64 = 150.0
+var unitPrice models.Money = models.NewMoney(150)
 
 func insertPositionTestData(t *testing.T, db *sql.DB) {
 	_, err := db.Query(`INSERT INTO users (id, email, password) 
@@ -31,15 +34,93 @@ func TestSQLPositionRepositoryIntegration(t *testing.T) {
 	repo := &SQLPositionRepository{DB: db}
 
 	// --- FindByUserIdAndSymbol ---
-	positions, err := repo.FindByUserIdAndSymbol(userId, symbol)
+	positions, err := repo.FindByUserIdAndSymbol(context.Background(), userId, symbol)
 	require.NoError(t, err)
 	require.Equal(t, 1, len(positions))
 	require.Equal(t, symbol, positions[0].Symbol)
 	require.Equal(t, quantity, positions[0].Quantity)
 	require.Equal(t, unitPrice, positions[0].UnitPrice)
 
+	// --- ReserveShares ---
+	err = repo.ReserveShares(context.Background(), userId, symbol, 600)
+	require.NoError(t, err)
+	err = repo.ReserveShares(context.Background(), userId, symbol, 401)
+	require.ErrorIs(t, err, ports.ErrInsufficientShares)
+	positions, err = repo.FindByUserIdAndSymbol(context.Background(), userId, symbol)
+	require.NoError(t, err)
+	require.Equal(t, 600, positions[0].ReservedQuantity)
+
+	// --- ReleaseShares ---
+	err = repo.ReleaseShares(context.Background(), userId, symbol, 100)
+	require.NoError(t, err)
+	positions, err = repo.FindByUserIdAndSymbol(context.Background(), userId, symbol)
+	require.NoError(t, err)
+	require.Equal(t, 500, positions[0].ReservedQuantity)
+
+	// --- ConsumeReservedShares ---
+	err = repo.ConsumeReservedShares(context.Background(), userId, symbol, 200, models.BaseCurrency, models.NewMoney(160))
+	require.NoError(t, err)
+	positions, err = repo.FindByUserIdAndSymbol(context.Background(), userId, symbol)
+	require.NoError(t, err)
+	require.Equal(t, quantity-200, positions[0].Quantity)
+	require.Equal(t, 300, positions[0].ReservedQuantity)
+	require.Equal(t, models.NewMoney(2000), positions[0].RealizedPnL)
+	require.Equal(t, "open", positions[0].Status)
+	err = repo.ConsumeReservedShares(context.Background(), userId, symbol, 301, models.BaseCurrency, models.NewMoney(160))
+	require.ErrorIs(t, err, ports.ErrInsufficientShares)
+
+	// --- AddShares recomputes the average cost ---
+	err = repo.AddShares(context.Background(), userId, symbol, 200, models.NewMoney(180))
+	require.NoError(t, err)
+	positions, err = repo.FindByUserIdAndSymbol(context.Background(), userId, symbol)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(positions))
+	require.Equal(t, quantity, positions[0].Quantity)
+	require.Equal(t, 200, positions[0].UnsettledQuantity)
+	require.Equal(t, models.NewMoney(156), positions[0].UnitPrice)
+
+	// --- ReserveShares excludes unsettled shares ---
+	err = repo.ReserveShares(context.Background(), userId, symbol, quantity-300)
+	require.ErrorIs(t, err, ports.ErrInsufficientShares)
+
+	// --- SettleShares ---
+	err = repo.SettleShares(context.Background(), userId, symbol, 200)
+	require.NoError(t, err)
+	positions, err = repo.FindByUserIdAndSymbol(context.Background(), userId, symbol)
+	require.NoError(t, err)
+	require.Equal(t, 0, positions[0].UnsettledQuantity)
+
+	// --- ConsumeReservedShares closes an emptied position ---
+	err = repo.ReserveShares(context.Background(), userId, symbol, quantity-300)
+	require.NoError(t, err)
+	err = repo.ConsumeReservedShares(context.Background(), userId, symbol, quantity, models.BaseCurrency, models.NewMoney(150))
+	require.NoError(t, err)
+	positions, err = repo.FindByUserIdAndSymbol(context.Background(), userId, symbol)
+	require.NoError(t, err)
+	require.Equal(t, 0, positions[0].Quantity)
+	require.Equal(t, "closed", positions[0].Status)
+	require.True(t, positions[0].ClosedAt.Valid)
+	require.Equal(t, models.NewMoney(-4000), positions[0].RealizedPnL)
+
+	// --- AddShares opens a new position once the previous one is closed ---
+	err = repo.AddShares(context.Background(), userId, symbol, 10, models.NewMoney(170))
+	require.NoError(t, err)
+	positions, err = repo.FindByUserIdAndSymbol(context.Background(), userId, symbol)
+	require.NoError(t, err)
+	require.Equal(t, 2, len(positions))
+	require.Equal(t, "open", positions[1].Status)
+	require.Equal(t, 10, positions[1].Quantity)
+	require.Equal(t, 10, positions[1].UnsettledQuantity)
+	require.Equal(t, models.NewMoney(170), positions[1].UnitPrice)
+
+	// --- FindByUserId ---
+	positions, err = repo.FindByUserId(context.Background(), userId)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(positions))
+	require.Equal(t, symbol, positions[0].Symbol)
+
 	// --- FindByUserIdAndSymbol No positions ---
-	positions, err = repo.FindByUserIdAndSymbol(userId, "stockThatUserDoesntOwn")
+	positions, err = repo.FindByUserIdAndSymbol(context.Background(), userId, "stockThatUserDoesntOwn")
 	require.NoError(t, err)
 	require.Equal(t, 0, len(positions))
 
@@ -49,16 +130,54 @@ func TestSQLPositionRepositoryIntegration(t *testing.T) {
 	mock.ExpectQuery(".*").
 		WillReturnError(sql.ErrConnDone)
 
-	positions, err = repo.FindByUserIdAndSymbol(userId, symbol)
+	positions, err = repo.FindByUserIdAndSymbol(context.Background(), userId, symbol)
 	require.Nil(t, positions)
 	require.ErrorIs(t, err, sql.ErrConnDone)
 
 	// --- FindByUserIdAndSymbol scan error ---
-	rows := sqlmock.NewRows([]string{"symbol", "quantity", "unit_price"}).
-		AddRow("AAPL", 10, "bad-data")
+	rows := sqlmock.NewRows([]string{"id", "user_id", "symbol", "quantity", "reserved_quantity", "unsettled_quantity", "unit_price", "realized_pnl", "status", "closed_at"}).
+		AddRow(1, userId, "AAPL", 10, 0, 0, "bad-data", 0, "open", nil)
 	mock.ExpectQuery(".*").WillReturnRows(rows)
 
-	positions, err = repo.FindByUserIdAndSymbol(userId, symbol)
+	positions, err = repo.FindByUserIdAndSymbol(context.Background(), userId, symbol)
 	assert.Nil(t, positions)
 	assert.Error(t, err)
+
+	// --- AddShares connection error ---
+	mock.ExpectQuery(".*").WillReturnError(sql.ErrConnDone)
+
+	err = repo.AddShares(context.Background(), userId, symbol, 10, models.NewMoney(150))
+	require.ErrorIs(t, err, sql.ErrConnDone)
+
+	// --- SettleShares connection error ---
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT id, quantity, reserved_quantity, unsettled_quantity, unit_price").WillReturnError(sql.ErrConnDone)
+	mock.ExpectRollback()
+
+	err = repo.SettleShares(context.Background(), userId, symbol, 10)
+	require.ErrorIs(t, err, sql.ErrConnDone)
+
+	// --- FindByUserId connection error ---
+	mock.ExpectQuery(".*").WillReturnError(sql.ErrConnDone)
+
+	positions, err = repo.FindByUserId(context.Background(), userId)
+	require.Nil(t, positions)
+	require.ErrorIs(t, err, sql.ErrConnDone)
+}
+
+func TestSQLPositionRepositoryRoundsRealizedPnLToTheTradingCurrency(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	repo := &SQLPositionRepository{DB: db}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT id, quantity, reserved_quantity, unsettled_quantity, unit_price").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "quantity", "reserved_quantity", "unsettled_quantity", "unit_price"}).AddRow(1, 3, 3, 0, "2400.25"))
+	mock.ExpectExec("UPDATE brokerx.positions").
+		WithArgs(3, 3, models.NewMoney(299), "closed", sqlmock.AnyArg(), 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err := repo.ConsumeReservedShares(context.Background(), userId, "7203", 3, "JPY", models.NewMoney(2500))
+	require.NoError(t, err)
+	require.NoError(t, mock.ExpectationsWereMet())
 }
@@ -0,0 +1,30 @@
+package adapters
+
+import (
+	"brokerx/models"
+	"brokerx/ports"
+	"context"
+	"database/sql"
+	"errors"
+)
+
+type SQLSymbolRepository struct {
+	DB DBTX
+}
+
+func (repo *SQLSymbolRepository) FindBySymbol(ctx context.Context, symbol string) (*models.Symbol, error) {
+	row := repo.DB.QueryRowContext(ctx, "SELECT symbol, currency FROM brokerx.symbols WHERE symbol=?", symbol)
+
+	var listed models.Symbol
+	err := row.Scan(&listed.Symbol, &listed.Currency)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ports.ErrSymbolNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &listed, nil
+}
+
+var _ ports.SymbolRepository = (*SQLSymbolRepository)(nil) // Ensure interface is implemented at compile time
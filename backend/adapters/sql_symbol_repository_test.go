@@ -0,0 +1,53 @@
+package adapters
+
+import (
+	"brokerx/ports"
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSQLSymbolRepositoryIntegration(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := &SQLSymbolRepository{DB: db}
+
+	// --- FindBySymbol from the seed data ---
+	listed, err := repo.FindBySymbol(context.Background(), "SHOP")
+	require.NoError(t, err)
+	require.Equal(t, "CAD", listed.Currency)
+
+	// --- FindBySymbol not listed ---
+	_, err = repo.FindBySymbol(context.Background(), "UNLISTED")
+	require.ErrorIs(t, err, ports.ErrSymbolNotFound)
+}
+
+func TestSQLSymbolRepositoryErrors(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	repo := &SQLSymbolRepository{DB: db}
+
+	// --- FindBySymbol ---
+	mock.ExpectQuery("SELECT symbol, currency FROM brokerx.symbols").WithArgs("RY").
+		WillReturnRows(sqlmock.NewRows([]string{"symbol", "currency"}).AddRow("RY", "CAD"))
+	listed, err := repo.FindBySymbol(context.Background(), "RY")
+	require.NoError(t, err)
+	require.Equal(t, "CAD", listed.Currency)
+
+	// --- FindBySymbol not listed ---
+	mock.ExpectQuery(".*").WillReturnRows(sqlmock.NewRows([]string{"symbol", "currency"}))
+	_, err = repo.FindBySymbol(context.Background(), "UNLISTED")
+	require.ErrorIs(t, err, ports.ErrSymbolNotFound)
+
+	// --- FindBySymbol connection error ---
+	mock.ExpectQuery(".*").WillReturnError(sql.ErrConnDone)
+	listed, err = repo.FindBySymbol(context.Background(), "RY")
+	require.Nil(t, listed)
+	require.ErrorIs(t, err, sql.ErrConnDone)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
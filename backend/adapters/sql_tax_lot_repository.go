@@ -0,0 +1,162 @@
+package adapters
+
+import (
+	"brokerx/models"
+	"brokerx/ports"
+	"context"
+	"database/sql"
+	"errors"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type SQLTaxLotRepository struct {
+	DB DBTX
+}
+
+func (repo *SQLTaxLotRepository) CreateLot(ctx context.Context, lot *models.TaxLot) (int, error) {
+	result, err := repo.DB.ExecContext(ctx, "INSERT INTO brokerx.tax_lots (user_id, symbol, quantity, remaining_quantity, unit_price, acquired_at) VALUES (?, ?, ?, ?, ?, ?)",
+		lot.UserID, lot.Symbol, lot.Quantity, lot.RemainingQuantity, lot.UnitPrice, lot.AcquiredAt)
+	if err != nil {
+		log.Errorf("Error creating tax lot: %v", err)
+		return 0, err
+	}
+	id, _ := result.LastInsertId()
+	return int(id), nil
+}
+
+func (repo *SQLTaxLotRepository) FindById(ctx context.Context, id int) (*models.TaxLot, error) {
+	row := repo.DB.QueryRowContext(ctx, "SELECT "+taxLotColumns+" FROM brokerx.tax_lots WHERE id=?", id)
+
+	lot, err := scanTaxLot(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ports.ErrLotNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return lot, nil
+}
+
+// FindByUserId returns every lot of the user, relieved or not, ordered by symbol and
+// acquisition time.
+func (repo *SQLTaxLotRepository) FindByUserId(ctx context.Context, userId string) ([]*models.TaxLot, error) {
+	return repo.queryTaxLots(ctx, "SELECT "+taxLotColumns+" FROM brokerx.tax_lots WHERE user_id=? ORDER BY symbol, acquired_at, id", userId)
+}
+
+// FindOpenLots returns the lots of the symbol that still have shares, oldest first. The
+// lots are locked for the rest of the transaction so that concurrent fills can never
+// relieve the same shares twice.
+func (repo *SQLTaxLotRepository) FindOpenLots(ctx context.Context, userId string, symbol string) ([]*models.TaxLot, error) {
+	return repo.queryTaxLots(ctx, "SELECT "+taxLotColumns+" FROM brokerx.tax_lots WHERE user_id=? AND symbol=? AND remaining_quantity > 0 ORDER BY acquired_at, id FOR UPDATE",
+		userId, symbol)
+}
+
+// RelieveLot removes the relieved shares from the lot and records the gain realized on them.
+func (repo *SQLTaxLotRepository) RelieveLot(ctx context.Context, relief *models.LotRelief) error {
+	return inTransaction(ctx, repo.DB, func(tx DBTX) error {
+		result, err := tx.ExecContext(ctx, "UPDATE brokerx.tax_lots SET remaining_quantity = remaining_quantity - ? WHERE id=? AND remaining_quantity >= ?",
+			relief.Quantity, relief.LotID, relief.Quantity)
+		if err != nil {
+			return err
+		}
+		if rows, _ := result.RowsAffected(); rows == 0 {
+			return ports.ErrInsufficientShares
+		}
+
+		result, err = tx.ExecContext(ctx, "INSERT INTO brokerx.lot_reliefs (lot_id, execution_id, quantity, cost_basis, proceeds, realized_gain, term, relieved_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+			relief.LotID, relief.ExecutionID, relief.Quantity, relief.CostBasis, relief.Proceeds, relief.RealizedGain, relief.Term, relief.RelievedAt)
+		if err != nil {
+			log.Errorf("Error relieving tax lot %d: %v", relief.LotID, err)
+			return err
+		}
+		id, _ := result.LastInsertId()
+		relief.ID = int(id)
+		return nil
+	})
+}
+
+// ReserveLot reserves shares of the lot for a sell order that designates it. Shares already
+// reserved by other orders cannot be reserved again.
+func (repo *SQLTaxLotRepository) ReserveLot(ctx context.Context, id int, quantity int) error {
+	result, err := repo.DB.ExecContext(ctx, "UPDATE brokerx.tax_lots SET reserved_quantity = reserved_quantity + ? WHERE id=? AND remaining_quantity - reserved_quantity >= ?",
+		quantity, id, quantity)
+	if err != nil {
+		return err
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return ports.ErrInsufficientShares
+	}
+	return nil
+}
+
+// ReleaseLot releases reserved shares of the lot.
+func (repo *SQLTaxLotRepository) ReleaseLot(ctx context.Context, id int, quantity int) error {
+	_, err := repo.DB.ExecContext(ctx, "UPDATE brokerx.tax_lots SET reserved_quantity = GREATEST(reserved_quantity - ?, 0) WHERE id=?", quantity, id)
+	return err
+}
+
+// FindReliefsByUserId returns the realized gains of the user, most recent first.
+func (repo *SQLTaxLotRepository) FindReliefsByUserId(ctx context.Context, userId string) ([]*models.LotRelief, error) {
+	rows, err := repo.DB.QueryContext(ctx, `SELECT r.id, r.lot_id, r.execution_id, l.symbol, r.quantity, r.cost_basis, r.proceeds, r.realized_gain, r.term, r.relieved_at
+		FROM brokerx.lot_reliefs r JOIN brokerx.tax_lots l ON l.id = r.lot_id WHERE l.user_id=? ORDER BY r.relieved_at DESC, r.id DESC`, userId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reliefs []*models.LotRelief
+
+	for rows.Next() {
+		var relief models.LotRelief
+		if err := rows.Scan(&relief.ID, &relief.LotID, &relief.ExecutionID, &relief.Symbol, &relief.Quantity, &relief.CostBasis,
+			&relief.Proceeds, &relief.RealizedGain, &relief.Term, &relief.RelievedAt); err != nil {
+			return nil, err
+		}
+		reliefs = append(reliefs, &relief)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return reliefs, nil
+}
+
+func (repo *SQLTaxLotRepository) queryTaxLots(ctx context.Context, query string, args ...any) ([]*models.TaxLot, error) {
+	rows, err := repo.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var lots []*models.TaxLot
+
+	for rows.Next() {
+		lot, err := scanTaxLot(rows)
+		if err != nil {
+			return nil, err
+		}
+		lots = append(lots, lot)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return lots, nil
+}
+
+const taxLotColumns = "id, user_id, symbol, quantity, remaining_quantity, reserved_quantity, unit_price, acquired_at"
+
+func scanTaxLot(row interface{ Scan(dest ...any) error }) (*models.TaxLot, error) {
+	var lot models.TaxLot
+	err := row.Scan(&lot.ID, &lot.UserID, &lot.Symbol, &lot.Quantity, &lot.RemainingQuantity, &lot.ReservedQuantity, &lot.UnitPrice, &lot.AcquiredAt)
+	if err != nil {
+		return nil, err
+	}
+	return &lot, nil
+}
+
+var _ ports.TaxLotRepository = (*SQLTaxLotRepository)(nil) // Ensure interface is implemented at compile time
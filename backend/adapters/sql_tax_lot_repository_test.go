@@ -0,0 +1,101 @@
+package adapters
+
+import (
+	"brokerx/models"
+	"brokerx/ports"
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSQLTaxLotRepositoryIntegration(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	buyOrderId, sellOrderId := insertExecutionTestData(t, db)
+	defer cleanup()
+
+	executionId, err := (&SQLExecutionRepository{DB: db}).CreateExecution(context.Background(), &models.Execution{BuyOrderID: buyOrderId, SellOrderID: sellOrderId,
+		Symbol: symbol, Quantity: 10, Price: models.NewMoney(150), LiquidityFlag: "seller_maker", ExecutedAt: time.Now().UTC(), SettlementDate: time.Now().UTC().Truncate(24 * time.Hour)})
+	require.NoError(t, err)
+
+	repo := &SQLTaxLotRepository{DB: db}
+	acquiredAt := time.Now().UTC().AddDate(0, -1, 0)
+
+	// --- CreateLot ---
+	lot := &models.TaxLot{UserID: userId, Symbol: symbol, Quantity: 10, RemainingQuantity: 10, UnitPrice: models.NewMoney(120), AcquiredAt: acquiredAt}
+	lotId, err := repo.CreateLot(context.Background(), lot)
+	require.NoError(t, err)
+	require.Greater(t, lotId, 0)
+
+	// --- FindById ---
+	found, err := repo.FindById(context.Background(), lotId)
+	require.NoError(t, err)
+	require.Equal(t, userId, found.UserID)
+	require.Equal(t, 10, found.RemainingQuantity)
+	require.Equal(t, models.NewMoney(120), found.UnitPrice)
+	require.WithinDuration(t, acquiredAt, found.AcquiredAt, time.Second)
+
+	// --- ReserveLot ---
+	err = repo.ReserveLot(context.Background(), lotId, 8)
+	require.NoError(t, err)
+
+	// --- ReserveLot more than the lot has unreserved ---
+	err = repo.ReserveLot(context.Background(), lotId, 3)
+	require.ErrorIs(t, err, ports.ErrInsufficientShares)
+
+	// --- ReleaseLot ---
+	err = repo.ReleaseLot(context.Background(), lotId, 8)
+	require.NoError(t, err)
+	found, err = repo.FindById(context.Background(), lotId)
+	require.NoError(t, err)
+	require.Equal(t, 0, found.ReservedQuantity)
+
+	// --- RelieveLot ---
+	relief := &models.LotRelief{LotID: lotId, ExecutionID: executionId, Quantity: 4, CostBasis: models.NewMoney(480), Proceeds: models.NewMoney(600),
+		RealizedGain: models.NewMoney(120), Term: "short", RelievedAt: time.Now().UTC()}
+	err = repo.RelieveLot(context.Background(), relief)
+	require.NoError(t, err)
+	require.Greater(t, relief.ID, 0)
+
+	// --- RelieveLot more than the lot holds ---
+	relief.Quantity = 7
+	err = repo.RelieveLot(context.Background(), relief)
+	require.ErrorIs(t, err, ports.ErrInsufficientShares)
+
+	// --- FindOpenLots ---
+	lots, err := repo.FindOpenLots(context.Background(), userId, symbol)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(lots))
+	require.Equal(t, 6, lots[0].RemainingQuantity)
+
+	// --- FindByUserId ---
+	lots, err = repo.FindByUserId(context.Background(), userId)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(lots))
+
+	// --- FindReliefsByUserId ---
+	reliefs, err := repo.FindReliefsByUserId(context.Background(), userId)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(reliefs))
+	require.Equal(t, symbol, reliefs[0].Symbol)
+	require.Equal(t, 4, reliefs[0].Quantity)
+	require.Equal(t, models.NewMoney(120), reliefs[0].RealizedGain)
+	require.Equal(t, "short", reliefs[0].Term)
+
+	// --- FindById not found ---
+	_, err = repo.FindById(context.Background(), -1)
+	require.ErrorIs(t, err, ports.ErrLotNotFound)
+
+	// --- FindOpenLots connection error ---
+	mockDb, mock, _ := sqlmock.New()
+	repo = &SQLTaxLotRepository{DB: mockDb}
+	mock.ExpectQuery(".*").WillReturnError(sql.ErrConnDone)
+
+	lots, err = repo.FindOpenLots(context.Background(), userId, symbol)
+	require.Nil(t, lots)
+	require.ErrorIs(t, err, sql.ErrConnDone)
+}
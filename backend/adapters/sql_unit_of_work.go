@@ -0,0 +1,94 @@
+package adapters
+
+import (
+	"brokerx/ports"
+	"context"
+	"database/sql"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// DBTX is implemented by both *sql.DB and *sql.Tx so that repositories work the same way
+// inside and outside of a transaction.
+type DBTX interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// SQLUnitOfWork runs each unit of work in a transaction. A positive Timeout bounds the
+// whole transaction, statements included.
+type SQLUnitOfWork struct {
+	DB      *sql.DB
+	Timeout time.Duration
+}
+
+func (uow *SQLUnitOfWork) Execute(ctx context.Context, fn func(repos ports.Repositories) error) error {
+	if uow.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, uow.Timeout)
+		defer cancel()
+	}
+
+	return inTransaction(ctx, uow.DB, func(tx DBTX) error {
+		tx = boundTx{tx: tx, ctx: ctx}
+		return fn(ports.Repositories{
+			Orders:        &SQLOrderRepository{DB: tx},
+			Executions:    &SQLExecutionRepository{DB: tx},
+			Wallets:       &SQLWalletRepository{DB: tx},
+			Ledger:        &SQLLedgerRepository{DB: tx},
+			Positions:     &SQLPositionRepository{DB: tx},
+			TaxLots:       &SQLTaxLotRepository{DB: tx},
+			Users:         &SQLUserRepository{DB: tx},
+			Deposits:      &SQLDepositRepository{DB: tx},
+			Withdrawals:   &SQLWithdrawalRepository{DB: tx},
+			FXConversions: &SQLFXConversionRepository{DB: tx},
+			Confirmations: &SQLConfirmationRepository{DB: tx},
+		})
+	})
+}
+
+// boundTx runs the statements of a unit of work under its context rather than the one of
+// the caller, so that they are canceled along with the transaction when it times out.
+type boundTx struct {
+	tx  DBTX
+	ctx context.Context
+}
+
+func (bound boundTx) ExecContext(_ context.Context, query string, args ...any) (sql.Result, error) {
+	return bound.tx.ExecContext(bound.ctx, query, args...)
+}
+
+func (bound boundTx) QueryContext(_ context.Context, query string, args ...any) (*sql.Rows, error) {
+	return bound.tx.QueryContext(bound.ctx, query, args...)
+}
+
+func (bound boundTx) QueryRowContext(_ context.Context, query string, args ...any) *sql.Row {
+	return bound.tx.QueryRowContext(bound.ctx, query, args...)
+}
+
+// inTransaction runs fn inside a transaction that is committed when fn succeeds and
+// rolled back otherwise. When db is already a transaction, fn simply joins it.
+func inTransaction(ctx context.Context, db DBTX, fn func(tx DBTX) error) error {
+	conn, ok := db.(*sql.DB)
+	if !ok {
+		return fn(db)
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err = fn(tx); err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			log.Errorf("Error rolling back transaction: %v", rollbackErr)
+		}
+		return err
+	}
+
+	return tx.Commit()
+}
+
+var _ ports.UnitOfWork = (*SQLUnitOfWork)(nil) // Ensure interface is implemented at compile time
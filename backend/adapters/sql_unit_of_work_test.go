@@ -0,0 +1,129 @@
+package adapters
+
+import (
+	"context"
+	"brokerx/models"
+	"brokerx/ports"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func insertUnitOfWorkTestData(t *testing.T, db *sql.DB) {
+	_, err := db.Exec(`INSERT INTO users (id, email, password) 
+                      VALUES (?, 'email', 'hashedpw')`, userId)
+	require.NoError(t, err)
+
+	_, err = db.Exec(`INSERT INTO wallets (id, user_id, available_funds, funds_on_hold) VALUES(?, ?, ?, ?)`,
+		uuid.New().String(), userId, 1000.0, 0.0)
+	require.NoError(t, err)
+}
+
+// testHoldEntry moves the amount of the test user from the available funds to the funds on hold.
+func testHoldEntry(amount models.Money) *models.JournalEntry {
+	return &models.JournalEntry{Type: "hold", Reference: "order:1", Postings: []models.LedgerPosting{
+		{Account: "available", UserID: userId, Currency: models.BaseCurrency, Amount: amount.Neg()},
+		{Account: "on_hold", UserID: userId, Currency: models.BaseCurrency, Amount: amount},
+	}}
+}
+
+func TestSQLUnitOfWorkIntegration(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	insertUnitOfWorkTestData(t, db)
+	defer cleanup()
+
+	uow := &SQLUnitOfWork{DB: db}
+	walletRepo := &SQLWalletRepository{DB: db}
+
+	// --- Rollback when the unit of work fails ---
+	err := uow.Execute(context.Background(), func(repos ports.Repositories) error {
+		require.NoError(t, repos.Ledger.Post(context.Background(), testHoldEntry(models.NewMoney(400))))
+		return assert.AnError
+	})
+	require.ErrorIs(t, err, assert.AnError)
+	wallet, err := walletRepo.FindByUserIdAndCurrency(context.Background(), userId, models.BaseCurrency)
+	require.NoError(t, err)
+	require.Equal(t, models.NewMoney(1000), wallet.AvailableFunds)
+
+	// --- Commit when the unit of work succeeds ---
+	err = uow.Execute(context.Background(), func(repos ports.Repositories) error {
+		return repos.Ledger.Post(context.Background(), testHoldEntry(models.NewMoney(400)))
+	})
+	require.NoError(t, err)
+	wallet, err = walletRepo.FindByUserIdAndCurrency(context.Background(), userId, models.BaseCurrency)
+	require.NoError(t, err)
+	require.Equal(t, models.NewMoney(600), wallet.AvailableFunds)
+	require.Equal(t, models.NewMoney(400), wallet.OnHoldFunds)
+}
+
+func TestSQLUnitOfWork(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	uow := &SQLUnitOfWork{DB: db}
+
+	// --- Begin error ---
+	mock.ExpectBegin().WillReturnError(sql.ErrConnDone)
+	err := uow.Execute(context.Background(), func(repos ports.Repositories) error {
+		return nil
+	})
+	require.ErrorIs(t, err, sql.ErrConnDone)
+
+	// --- Commit ---
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO brokerx.journal_entries").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO brokerx.ledger_postings").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("UPDATE brokerx.wallets").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO brokerx.ledger_postings").WillReturnResult(sqlmock.NewResult(2, 1))
+	mock.ExpectExec("INSERT INTO brokerx.wallets .* ON DUPLICATE KEY UPDATE funds_on_hold").WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+	err = uow.Execute(context.Background(), func(repos ports.Repositories) error {
+		return repos.Ledger.Post(context.Background(), testHoldEntry(models.NewMoney(10)))
+	})
+	require.NoError(t, err)
+
+	// --- Rollback ---
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO brokerx.journal_entries").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO brokerx.ledger_postings").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("UPDATE brokerx.wallets").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+	err = uow.Execute(context.Background(), func(repos ports.Repositories) error {
+		return repos.Ledger.Post(context.Background(), testHoldEntry(models.NewMoney(10)))
+	})
+	require.ErrorIs(t, err, ports.ErrInsufficientFunds)
+
+	// --- Repositories join the transaction instead of starting their own ---
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT id, quantity, reserved_quantity, unsettled_quantity, unit_price").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "quantity", "reserved_quantity", "unsettled_quantity", "unit_price"}).AddRow(1, 10, 0, 0, 150.0))
+	mock.ExpectExec("UPDATE brokerx.positions").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	err = uow.Execute(context.Background(), func(repos ports.Repositories) error {
+		return repos.Positions.ReserveShares(context.Background(), userId, symbol, 5)
+	})
+	require.NoError(t, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSQLUnitOfWorkTimeout(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	uow := &SQLUnitOfWork{DB: db, Timeout: 50 * time.Millisecond}
+
+	// --- Statements are canceled with the transaction even without a deadline of their own ---
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT id, quantity, reserved_quantity, unsettled_quantity, unit_price").
+		WillDelayFor(time.Second).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "quantity", "reserved_quantity", "unsettled_quantity", "unit_price"}).AddRow(1, 10, 0, 0, 150.0))
+	mock.ExpectRollback()
+	start := time.Now()
+	err := uow.Execute(context.Background(), func(repos ports.Repositories) error {
+		return repos.Positions.ReserveShares(context.Background(), userId, symbol, 5)
+	})
+	require.Error(t, err)
+	require.Less(t, time.Since(start), time.Second)
+}
@@ -3,18 +3,39 @@ package adapters
 import (
 	"brokerx/models"
 	"brokerx/ports"
+	"context"
 	"database/sql"
 )
 
 type SQLUserRepository struct {
-	DB *sql.DB
+	DB DBTX
 }
 
-func (repo * SQLUserRepository) FindByEmail(email string) (*models.User, error) {
-	row := repo.DB.QueryRow("SELECT id, email, password, failed_attempts, locked_until FROM brokerx.users WHERE email=?", email)
+func (repo * SQLUserRepository) FindByEmail(ctx context.Context, email string) (*models.User, error) {
+	row := repo.DB.QueryRowContext(ctx, "SELECT "+userColumns+" FROM brokerx.users WHERE email=?", email)
+	return scanUser(row)
+}
+
+func (repo * SQLUserRepository) FindById(ctx context.Context, id string) (*models.User, error) {
+	row := repo.DB.QueryRowContext(ctx, "SELECT "+userColumns+" FROM brokerx.users WHERE id=?", id)
+	return scanUser(row)
+}
 
+func (repo * SQLUserRepository) Update(ctx context.Context, user *models.User) error {
+	_, e := repo.DB.ExecContext(ctx, "UPDATE brokerx.users SET failed_attempts=?, locked_until=? WHERE email=?", user.FailedAttempts, user.LockedUntil, user.Email)
+	return e
+}
+
+func (repo * SQLUserRepository) UpdateLotReliefMethod(ctx context.Context, id string, method string) error {
+	_, e := repo.DB.ExecContext(ctx, "UPDATE brokerx.users SET lot_relief_method=? WHERE id=?", method, id)
+	return e
+}
+
+const userColumns = "id, email, password, failed_attempts, locked_until, lot_relief_method, role"
+
+func scanUser(row *sql.Row) (*models.User, error) {
 	var user models.User
-	e := row.Scan(&user.ID, &user.Email, &user.Password, &user.FailedAttempts, &user.LockedUntil)
+	e := row.Scan(&user.ID, &user.Email, &user.Password, &user.FailedAttempts, &user.LockedUntil, &user.LotReliefMethod, &user.Role)
 	if e != nil {
 		return nil, e
 	}
@@ -22,9 +43,4 @@ func (repo * SQLUserRepository) FindByEmail(email string) (*models.User, error)
 	return &user, nil
 }
 
-func (repo * SQLUserRepository) Update(user *models.User) error {
-	_, e := repo.DB.Exec("UPDATE brokerx.users SET failed_attempts=?, locked_until=? WHERE email=?", user.FailedAttempts, user.LockedUntil, user.Email)
-	return e
-}
-
 var _ ports.UserRepository = (*SQLUserRepository)(nil) // Ensure interface is implemented at compile time
\ No newline at end of file
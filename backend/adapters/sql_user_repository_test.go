@@ -1,6 +1,7 @@
 package adapters
 
 import (
+	"context"
 	"database/sql"
 	"log"
 	"os"
@@ -28,6 +29,26 @@ func setupTestDB(t *testing.T) (*sql.DB, func()) {
 	err = db.Ping()
 	require.NoError(t, err)
 
+	_, err = db.Exec("DELETE FROM ledger_postings")
+	require.NoError(t, err)
+	_, err = db.Exec("DELETE FROM journal_entries")
+	require.NoError(t, err)
+	_, err = db.Exec("DELETE FROM fx_conversions")
+	require.NoError(t, err)
+	_, err = db.Exec("DELETE FROM trade_confirmations")
+	require.NoError(t, err)
+	_, err = db.Exec("DELETE FROM withdrawals")
+	require.NoError(t, err)
+	_, err = db.Exec("DELETE FROM deposits")
+	require.NoError(t, err)
+	_, err = db.Exec("DELETE FROM lot_reliefs")
+	require.NoError(t, err)
+	_, err = db.Exec("DELETE FROM tax_lots")
+	require.NoError(t, err)
+	_, err = db.Exec("DELETE FROM executions")
+	require.NoError(t, err)
+	_, err = db.Exec("DELETE FROM order_versions")
+	require.NoError(t, err)
 	_, err = db.Exec("DELETE FROM orders")
 	require.NoError(t, err)
 	_, err = db.Exec("DELETE FROM positions")
@@ -62,7 +83,7 @@ func TestSQLUserRepositoryIntegration(t *testing.T) {
 		Time:  time.Date(1, time.January, 1, 0, 0, 0, 0, time.UTC),
 		Valid: false,
 	}
-	user, err := repo.FindByEmail(email)
+	user, err := repo.FindByEmail(context.Background(), email)
 	require.NoError(t, err)
 	require.Equal(t, email, user.Email)
 	require.Equal(t, expectedFailedAttempts, user.FailedAttempts)
@@ -75,14 +96,27 @@ func TestSQLUserRepositoryIntegration(t *testing.T) {
 	}
 	user.FailedAttempts = 2
 	user.LockedUntil = expectedLockedUntil
-	err = repo.Update(user)
+	err = repo.Update(context.Background(), user)
 	require.NoError(t, err)
-	result, err := repo.FindByEmail(email)
+	result, err := repo.FindByEmail(context.Background(), email)
 	require.NoError(t, err)
 	require.Equal(t, 2, result.FailedAttempts)
 	require.WithinDuration(t, expectedLockedUntil.Time, result.LockedUntil.Time, time.Second)
 
+	// --- FindById ---
+	result, err = repo.FindById(context.Background(), user.ID)
+	require.NoError(t, err)
+	require.Equal(t, email, result.Email)
+	require.Equal(t, "fifo", result.LotReliefMethod)
+
+	// --- UpdateLotReliefMethod ---
+	err = repo.UpdateLotReliefMethod(context.Background(), user.ID, "highest_cost")
+	require.NoError(t, err)
+	result, err = repo.FindById(context.Background(), user.ID)
+	require.NoError(t, err)
+	require.Equal(t, "highest_cost", result.LotReliefMethod)
+
 	// --- FindByEmail non-existing user ---
-	_, err = repo.FindByEmail("fakeemail")
+	_, err = repo.FindByEmail(context.Background(), "fakeemail")
 	require.Error(t, err)
 }
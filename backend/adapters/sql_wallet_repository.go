@@ -3,18 +3,30 @@ package adapters
 import (
 	"brokerx/models"
 	"brokerx/ports"
+	"context"
 	"database/sql"
+	"errors"
 )
 
 type SQLWalletRepository struct {
-	DB *sql.DB
+	DB DBTX
 }
 
-func (repo *SQLWalletRepository) FindByUserId(userId string) (*models.Wallet, error) {
-	row := repo.DB.QueryRow("SELECT available_funds, funds_on_hold FROM brokerx.wallets WHERE user_id=?", userId)
+// FindByUserId returns the wallets of the user ordered by currency.
+func (repo *SQLWalletRepository) FindByUserId(ctx context.Context, userId string) ([]*models.Wallet, error) {
+	return repo.queryWallets(ctx, "SELECT id, user_id, currency, available_funds, funds_on_hold, unsettled_funds FROM brokerx.wallets WHERE user_id=? ORDER BY currency", userId)
+}
+
+// FindByUserIdAndCurrency returns an empty wallet when the user never held the currency,
+// the wallet is only created by the first posting credited to it.
+func (repo *SQLWalletRepository) FindByUserIdAndCurrency(ctx context.Context, userId string, currency string) (*models.Wallet, error) {
+	row := repo.DB.QueryRowContext(ctx, "SELECT id, available_funds, funds_on_hold, unsettled_funds FROM brokerx.wallets WHERE user_id=? AND currency=?", userId, currency)
 
-	var wallet models.Wallet
-	e := row.Scan(&wallet.AvailableFunds, &wallet.OnHoldFunds)
+	wallet := models.Wallet{UserId: userId, Currency: currency}
+	e := row.Scan(&wallet.ID, &wallet.AvailableFunds, &wallet.OnHoldFunds, &wallet.UnsettledFunds)
+	if errors.Is(e, sql.ErrNoRows) {
+		return &wallet, nil
+	}
 	if e != nil {
 		return nil, e
 	}
@@ -22,4 +34,32 @@ func (repo *SQLWalletRepository) FindByUserId(userId string) (*models.Wallet, er
 	return &wallet, nil
 }
 
-var _ ports.WalletRepository = (*SQLWalletRepository)(nil) // Ensure interface is implemented at compile time
\ No newline at end of file
+func (repo *SQLWalletRepository) FindAll(ctx context.Context) ([]*models.Wallet, error) {
+	return repo.queryWallets(ctx, "SELECT id, user_id, currency, available_funds, funds_on_hold, unsettled_funds FROM brokerx.wallets ORDER BY user_id, currency")
+}
+
+func (repo *SQLWalletRepository) queryWallets(ctx context.Context, query string, args ...any) ([]*models.Wallet, error) {
+	rows, err := repo.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var wallets []*models.Wallet
+
+	for rows.Next() {
+		var wallet models.Wallet
+		if err := rows.Scan(&wallet.ID, &wallet.UserId, &wallet.Currency, &wallet.AvailableFunds, &wallet.OnHoldFunds, &wallet.UnsettledFunds); err != nil {
+			return nil, err
+		}
+		wallets = append(wallets, &wallet)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return wallets, nil
+}
+
+var _ ports.WalletRepository = (*SQLWalletRepository)(nil) // Ensure interface is implemented at compile time
@@ -1,16 +1,19 @@
 package adapters
 
 import (
+	"brokerx/models"
+	"context"
 	"database/sql"
 	"testing"
 
+	"github.com/DATA-DOG/go-sqlmock"
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
 )
 
-var availableFunds float64 = 1000.0
-var fundsOnHold float64 = 150.0
+var availableFunds = models.NewMoney(1000)
+var fundsOnHold = models.NewMoney(150)
 
 func insertWalletTestData(t *testing.T, db *sql.DB) {
 	_, err := db.Query(`INSERT INTO users (id, email, password) 
@@ -29,14 +32,52 @@ func TestSQLWalletRepositoryIntegration(t *testing.T) {
 
 	repo := &SQLWalletRepository{DB: db}
 
-	// --- FindByUserId ---
-	wallet, err := repo.FindByUserId(userId)
+	// --- FindByUserIdAndCurrency ---
+	wallet, err := repo.FindByUserIdAndCurrency(context.Background(), userId, "USD")
 	require.NoError(t, err)
 	require.Equal(t, availableFunds, wallet.AvailableFunds)
 	require.Equal(t, fundsOnHold, wallet.OnHoldFunds)
 
+	// --- FindByUserIdAndCurrency of a currency never held ---
+	wallet, err = repo.FindByUserIdAndCurrency(context.Background(), userId, "CAD")
+	require.NoError(t, err)
+	require.Equal(t, &models.Wallet{UserId: userId, Currency: "CAD"}, wallet)
+
+	// --- FindByUserId ---
+	wallets, err := repo.FindByUserId(context.Background(), userId)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(wallets))
+	require.Equal(t, "USD", wallets[0].Currency)
+	require.Equal(t, availableFunds, wallets[0].AvailableFunds)
+
+	// --- FindAll ---
+	wallets, err = repo.FindAll(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, len(wallets))
+	require.Equal(t, userId, wallets[0].UserId)
+	require.Equal(t, fundsOnHold, wallets[0].OnHoldFunds)
+
 	// --- FindByUserId not found ---
-	wallet, err = repo.FindByUserId("non existent user id")
-	require.ErrorIs(t, err, sql.ErrNoRows)
+	wallets, err = repo.FindByUserId(context.Background(), "non existent user id")
+	require.NoError(t, err)
+	require.Empty(t, wallets)
+}
+
+func TestSQLWalletRepositoryErrors(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	repo := &SQLWalletRepository{DB: db}
+
+	// --- FindByUserIdAndCurrency connection error ---
+	mock.ExpectQuery(".*").WillReturnError(sql.ErrConnDone)
+	wallet, err := repo.FindByUserIdAndCurrency(context.Background(), userId, "USD")
 	require.Nil(t, wallet)
+	require.ErrorIs(t, err, sql.ErrConnDone)
+
+	// --- FindByUserId connection error ---
+	mock.ExpectQuery(".*").WillReturnError(sql.ErrConnDone)
+	wallets, err := repo.FindByUserId(context.Background(), userId)
+	require.Nil(t, wallets)
+	require.ErrorIs(t, err, sql.ErrConnDone)
+
+	require.NoError(t, mock.ExpectationsWereMet())
 }
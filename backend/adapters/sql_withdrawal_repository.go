@@ -0,0 +1,115 @@
+package adapters
+
+import (
+	"brokerx/models"
+	"brokerx/ports"
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type SQLWithdrawalRepository struct {
+	DB DBTX
+}
+
+func (repo *SQLWithdrawalRepository) CreateWithdrawal(ctx context.Context, withdrawal *models.Withdrawal) (int, error) {
+	result, err := repo.DB.ExecContext(ctx, "INSERT INTO brokerx.withdrawals (user_id, amount, status) VALUES (?, ?, ?)",
+		withdrawal.UserID, withdrawal.Amount, withdrawal.Status)
+	if err != nil {
+		log.Errorf("Error creating withdrawal: %v", err)
+		return 0, err
+	}
+	id, _ := result.LastInsertId()
+	return int(id), nil
+}
+
+// UpdateWithdrawal only updates the withdrawal while it is still in the previous status so
+// that a withdrawal can never be paid out or reversed twice.
+func (repo *SQLWithdrawalRepository) UpdateWithdrawal(ctx context.Context, withdrawal *models.Withdrawal, previousStatus string) error {
+	result, err := repo.DB.ExecContext(ctx, "UPDATE brokerx.withdrawals SET status=?, provider_reference=?, failure_reason=?, reviewed_by=? WHERE id=? AND status=?",
+		withdrawal.Status, withdrawal.ProviderReference, withdrawal.FailureReason, withdrawal.ReviewedBy, withdrawal.ID, previousStatus)
+	if err != nil {
+		log.Errorf("Error updating withdrawal %d: %v", withdrawal.ID, err)
+		return err
+	}
+
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return ports.ErrWithdrawalNotPending
+	}
+	return nil
+}
+
+func (repo *SQLWithdrawalRepository) FindById(ctx context.Context, id int) (*models.Withdrawal, error) {
+	row := repo.DB.QueryRowContext(ctx, "SELECT "+withdrawalColumns+" FROM brokerx.withdrawals WHERE id=?", id)
+
+	withdrawal, err := scanWithdrawal(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ports.ErrWithdrawalNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return withdrawal, nil
+}
+
+// FindByUserId returns the withdrawals of the user, most recent first.
+func (repo *SQLWithdrawalRepository) FindByUserId(ctx context.Context, userId string) ([]*models.Withdrawal, error) {
+	return repo.queryWithdrawals(ctx, "SELECT "+withdrawalColumns+" FROM brokerx.withdrawals WHERE user_id=? ORDER BY id DESC", userId)
+}
+
+// FindByStatus returns the withdrawals in the given status, oldest first.
+func (repo *SQLWithdrawalRepository) FindByStatus(ctx context.Context, status string) ([]*models.Withdrawal, error) {
+	return repo.queryWithdrawals(ctx, "SELECT "+withdrawalColumns+" FROM brokerx.withdrawals WHERE status=? ORDER BY id", status)
+}
+
+func (repo *SQLWithdrawalRepository) SumWithdrawnSince(ctx context.Context, userId string, since time.Time) (models.Money, error) {
+	var total models.Money
+	err := repo.DB.QueryRowContext(ctx, "SELECT COALESCE(SUM(amount), 0) FROM brokerx.withdrawals WHERE user_id=? AND created_at >= ? AND status NOT IN ('failed', 'rejected')",
+		userId, since).Scan(&total)
+	if err != nil {
+		return models.Money{}, err
+	}
+	return total, nil
+}
+
+func (repo *SQLWithdrawalRepository) queryWithdrawals(ctx context.Context, query string, args ...any) ([]*models.Withdrawal, error) {
+	rows, err := repo.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var withdrawals []*models.Withdrawal
+
+	for rows.Next() {
+		withdrawal, err := scanWithdrawal(rows)
+		if err != nil {
+			return nil, err
+		}
+		withdrawals = append(withdrawals, withdrawal)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return withdrawals, nil
+}
+
+const withdrawalColumns = "id, user_id, amount, status, provider_reference, failure_reason, reviewed_by, created_at, updated_at"
+
+func scanWithdrawal(row interface{ Scan(dest ...any) error }) (*models.Withdrawal, error) {
+	var withdrawal models.Withdrawal
+	err := row.Scan(&withdrawal.ID, &withdrawal.UserID, &withdrawal.Amount, &withdrawal.Status, &withdrawal.ProviderReference,
+		&withdrawal.FailureReason, &withdrawal.ReviewedBy, &withdrawal.CreatedAt, &withdrawal.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &withdrawal, nil
+}
+
+var _ ports.WithdrawalRepository = (*SQLWithdrawalRepository)(nil) // Ensure interface is implemented at compile time
@@ -0,0 +1,102 @@
+package adapters
+
+import (
+	"brokerx/models"
+	"brokerx/ports"
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSQLWithdrawalRepositoryIntegration(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	insertOrderTestData(t, db)
+	defer cleanup()
+
+	repo := &SQLWithdrawalRepository{DB: db}
+	since := time.Now().UTC().Add(-time.Hour)
+
+	// --- CreateWithdrawal ---
+	withdrawal := &models.Withdrawal{UserID: userId, Amount: models.NewMoney(1500), Status: "pending_approval"}
+	withdrawalId, err := repo.CreateWithdrawal(context.Background(), withdrawal)
+	require.NoError(t, err)
+	require.Greater(t, withdrawalId, 0)
+
+	rejectedId, err := repo.CreateWithdrawal(context.Background(), &models.Withdrawal{UserID: userId, Amount: models.NewMoney(300), Status: "rejected"})
+	require.NoError(t, err)
+	require.Greater(t, rejectedId, withdrawalId)
+
+	// --- SumWithdrawnSince ---
+	total, err := repo.SumWithdrawnSince(context.Background(), userId, since)
+	require.NoError(t, err)
+	require.Equal(t, models.NewMoney(1500), total)
+
+	// --- FindByStatus ---
+	pending, err := repo.FindByStatus(context.Background(), "pending_approval")
+	require.NoError(t, err)
+	require.Equal(t, 1, len(pending))
+	require.Equal(t, withdrawalId, pending[0].ID)
+
+	// --- UpdateWithdrawal ---
+	withdrawal.ID = withdrawalId
+	withdrawal.Status = "processing"
+	withdrawal.ReviewedBy = userId
+	err = repo.UpdateWithdrawal(context.Background(), withdrawal, "pending_approval")
+	require.NoError(t, err)
+
+	// --- UpdateWithdrawal from a stale status ---
+	err = repo.UpdateWithdrawal(context.Background(), withdrawal, "pending_approval")
+	require.ErrorIs(t, err, ports.ErrWithdrawalNotPending)
+
+	// --- FindById ---
+	found, err := repo.FindById(context.Background(), withdrawalId)
+	require.NoError(t, err)
+	require.Equal(t, userId, found.UserID)
+	require.Equal(t, models.NewMoney(1500), found.Amount)
+	require.Equal(t, "processing", found.Status)
+	require.Equal(t, userId, found.ReviewedBy)
+
+	// --- FindByUserId ---
+	withdrawals, err := repo.FindByUserId(context.Background(), userId)
+	require.NoError(t, err)
+	require.Equal(t, 2, len(withdrawals))
+	require.Equal(t, rejectedId, withdrawals[0].ID)
+
+	// --- FindById not found ---
+	_, err = repo.FindById(context.Background(), -1)
+	require.ErrorIs(t, err, ports.ErrWithdrawalNotFound)
+}
+
+func TestSQLWithdrawalRepositoryErrors(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	repo := &SQLWithdrawalRepository{DB: db}
+
+	// --- CreateWithdrawal connection error ---
+	mock.ExpectExec(".*").WillReturnError(sql.ErrConnDone)
+	_, err := repo.CreateWithdrawal(context.Background(), &models.Withdrawal{UserID: "user", Amount: models.NewMoney(10), Status: "processing"})
+	require.ErrorIs(t, err, sql.ErrConnDone)
+
+	// --- UpdateWithdrawal moved on ---
+	mock.ExpectExec("UPDATE brokerx.withdrawals").WithArgs("completed", "", "", "", 1, "processing").WillReturnResult(sqlmock.NewResult(0, 0))
+	err = repo.UpdateWithdrawal(context.Background(), &models.Withdrawal{ID: 1, Status: "completed"}, "processing")
+	require.ErrorIs(t, err, ports.ErrWithdrawalNotPending)
+
+	// --- SumWithdrawnSince connection error ---
+	mock.ExpectQuery(".*").WillReturnError(sql.ErrConnDone)
+	total, err := repo.SumWithdrawnSince(context.Background(), "user", time.Now())
+	require.Zero(t, total)
+	require.ErrorIs(t, err, sql.ErrConnDone)
+
+	// --- FindByStatus connection error ---
+	mock.ExpectQuery(".*").WillReturnError(sql.ErrConnDone)
+	withdrawals, err := repo.FindByStatus(context.Background(), "processing")
+	require.Nil(t, withdrawals)
+	require.ErrorIs(t, err, sql.ErrConnDone)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
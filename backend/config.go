@@ -1,6 +1,10 @@
 package main
 
 import (
+	"brokerx/models"
+	"math/big"
+	"reflect"
+
 	"github.com/caarlos0/env"
 )
 
@@ -11,11 +15,63 @@ type Config struct {
 	PasswordLockDurationMinutes int `env:"PASSWORD_LOCK_DURATION_MINUTES" envDefault:"30"`
 	FrontendPath string `env:"FRONTEND_PATH" envDefault:"../frontend"`
 	IsProduction bool `env:"IS_PRODUCTION" envDefault:"false"`
+	DBTimeoutSeconds int `env:"DB_TIMEOUT_SECONDS" envDefault:"5"`
+	SessionCloseTime string `env:"SESSION_CLOSE_TIME" envDefault:"16:00"`
+	GTDExpiryIntervalSeconds int `env:"GTD_EXPIRY_INTERVAL_SECONDS" envDefault:"60"`
+	PaymentDelaySeconds int `env:"PAYMENT_DELAY_SECONDS" envDefault:"10"`
+	PaymentPollIntervalSeconds int `env:"PAYMENT_POLL_INTERVAL_SECONDS" envDefault:"5"`
+	WithdrawalDailyLimit models.Money `env:"WITHDRAWAL_DAILY_LIMIT" envDefault:"5000"`
+	WithdrawalMonthlyLimit models.Money `env:"WITHDRAWAL_MONTHLY_LIMIT" envDefault:"20000"`
+	WithdrawalApprovalThreshold models.Money `env:"WITHDRAWAL_APPROVAL_THRESHOLD" envDefault:"1000"`
+	ReconciliationIntervalSeconds int `env:"RECONCILIATION_INTERVAL_SECONDS" envDefault:"3600"`
+	FXRates models.ExchangeRates `env:"FX_RATES" envDefault:"USD/CAD=1.37,EUR/USD=1.08,GBP/USD=1.27,USD/CHF=0.88,USD/JPY=150"`
+	CommissionPerShare models.Money `env:"COMMISSION_PER_SHARE" envDefault:"0.005"`
+	CommissionPerOrder models.Money `env:"COMMISSION_PER_ORDER" envDefault:"0"`
+	CommissionRate *big.Rat `env:"COMMISSION_RATE" envDefault:"0"`
+	CommissionMinimum models.Money `env:"COMMISSION_MINIMUM" envDefault:"1"`
+	CommissionMaximum models.Money `env:"COMMISSION_MAXIMUM" envDefault:"0"` // 0 leaves the commission uncapped
+	RegulatoryFeeRate *big.Rat `env:"REGULATORY_FEE_RATE" envDefault:"0.0000278"`
+	SettlementDays int `env:"SETTLEMENT_DAYS" envDefault:"1"`
+	MarketHolidays models.Holidays `env:"MARKET_HOLIDAYS"` // e.g. 2026-12-25,2027-01-01
 }
 
 func (config *Config) LoadConfig() error {
-	if err := env.Parse(config); err != nil {
+	parsers := env.CustomParsers{
+		reflect.TypeOf(models.Money{}):         parseMoney,
+		reflect.TypeOf(models.ExchangeRates{}): parseExchangeRates,
+		reflect.TypeOf(&big.Rat{}):             parseRate,
+		reflect.TypeOf(models.Holidays{}):      parseHolidays,
+	}
+	if err := env.ParseWithFuncs(config, parsers); err != nil {
 		return err
 	}
 	return nil
-}
\ No newline at end of file
+}
+
+func parseMoney(value string) (interface{}, error) {
+	return models.ParseMoney(value)
+}
+
+func parseExchangeRates(value string) (interface{}, error) {
+	return models.ParseExchangeRates(value)
+}
+
+func parseRate(value string) (interface{}, error) {
+	return models.ParseRate(value)
+}
+
+func parseHolidays(value string) (interface{}, error) {
+	return models.ParseHolidays(value)
+}
+
+// FeeSchedule returns the commission and regulatory fee settings as a fee schedule.
+func (config *Config) FeeSchedule() models.FeeSchedule {
+	return models.FeeSchedule{
+		PerShare:       config.CommissionPerShare,
+		PerOrder:       config.CommissionPerOrder,
+		Rate:           config.CommissionRate,
+		Minimum:        config.CommissionMinimum,
+		Maximum:        config.CommissionMaximum,
+		RegulatoryRate: config.RegulatoryFeeRate,
+	}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"brokerx/models"
 	"os"
 	"testing"
 
@@ -19,11 +20,36 @@ func TestLoadConfigDefaults(t *testing.T) {
 	assert.Equal(t, 3, cfg.PasswordAllowedRetries)
 	assert.Equal(t, 30, cfg.PasswordLockDurationMinutes)
 	assert.False(t, cfg.IsProduction)
+	assert.Equal(t, 5, cfg.DBTimeoutSeconds)
+	assert.Equal(t, "16:00", cfg.SessionCloseTime)
+	assert.Equal(t, 60, cfg.GTDExpiryIntervalSeconds)
+	assert.Equal(t, 10, cfg.PaymentDelaySeconds)
+	assert.Equal(t, 5, cfg.PaymentPollIntervalSeconds)
+	assert.Equal(t, models.NewMoney(5000), cfg.WithdrawalDailyLimit)
+	assert.Equal(t, models.NewMoney(20000), cfg.WithdrawalMonthlyLimit)
+	assert.Equal(t, models.NewMoney(1000), cfg.WithdrawalApprovalThreshold)
+	assert.Equal(t, 3600, cfg.ReconciliationIntervalSeconds)
+	rate, ok := cfg.FXRates.Rate("USD", "CAD")
+	assert.True(t, ok)
+	assert.Equal(t, "1.37", rate.FloatString(2))
+	fees := cfg.FeeSchedule()
+	assert.Equal(t, models.MustParseMoney("0.005"), fees.PerShare)
+	assert.Equal(t, models.NewMoney(1), fees.Minimum)
+	assert.True(t, fees.Maximum.IsZero())
+	assert.Equal(t, 0, fees.Rate.Sign())
+	assert.Equal(t, "0.0000278", fees.RegulatoryRate.FloatString(7))
+	assert.Equal(t, 1, cfg.SettlementDays)
+	assert.Empty(t, cfg.MarketHolidays)
 }
 
 func TestLoadConfigCustomValues(t *testing.T) {
 	os.Setenv("APP_PORT", "9999")
 	os.Setenv("PASSWORD_ALLOWED_RETRIES", "10")
+	os.Setenv("FX_RATES", "EUR/CAD=1.5")
+	os.Setenv("COMMISSION_RATE", "0.001")
+	os.Setenv("COMMISSION_MAXIMUM", "20")
+	os.Setenv("SETTLEMENT_DAYS", "2")
+	os.Setenv("MARKET_HOLIDAYS", "2026-12-25,2027-01-01")
 	defer os.Clearenv()
 
 	cfg := Config{}
@@ -32,6 +58,13 @@ func TestLoadConfigCustomValues(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, "9999", cfg.Port)
 	assert.Equal(t, 10, cfg.PasswordAllowedRetries)
+	assert.Len(t, cfg.FXRates, 1)
+	_, ok := cfg.FXRates.Rate("USD", "CAD")
+	assert.False(t, ok)
+	assert.Equal(t, "0.001", cfg.CommissionRate.FloatString(3))
+	assert.Equal(t, models.NewMoney(20), cfg.CommissionMaximum)
+	assert.Equal(t, 2, cfg.SettlementDays)
+	assert.Equal(t, models.Holidays{"2026-12-25": true, "2027-01-01": true}, cfg.MarketHolidays)
 }
 
 func TestLoadConfigError(t *testing.T) {
@@ -43,3 +76,33 @@ func TestLoadConfigError(t *testing.T) {
 
 	assert.NotNil(t, err)
 }
+
+func TestLoadConfigInvalidExchangeRate(t *testing.T) {
+	os.Setenv("FX_RATES", "USD/XYZ=1.2")
+	defer os.Clearenv()
+
+	cfg := Config{}
+	err := cfg.LoadConfig()
+
+	assert.NotNil(t, err)
+}
+
+func TestLoadConfigInvalidMarketHoliday(t *testing.T) {
+	os.Setenv("MARKET_HOLIDAYS", "2026-12-32")
+	defer os.Clearenv()
+
+	cfg := Config{}
+	err := cfg.LoadConfig()
+
+	assert.NotNil(t, err)
+}
+
+func TestLoadConfigInvalidCommissionRate(t *testing.T) {
+	os.Setenv("COMMISSION_RATE", "-0.01")
+	defer os.Clearenv()
+
+	cfg := Config{}
+	err := cfg.LoadConfig()
+
+	assert.NotNil(t, err)
+}
@@ -3,6 +3,7 @@ package core
 import (
 	"brokerx/models"
 	"brokerx/ports"
+	"context"
 	"database/sql"
 	"errors"
 	"time"
@@ -18,8 +19,8 @@ type AuthService struct {
 	PasswordLockDurationMinutes int
 }
 
-func (authService *AuthService) Authenticate(email, password string) (*models.User, error) {
-	user, e := authService.Repo.FindByEmail(email)
+func (authService *AuthService) Authenticate(ctx context.Context, email, password string) (*models.User, error) {
+	user, e := authService.Repo.FindByEmail(ctx, email)
 	if e != nil {
 		return nil, errors.New("user not found")
 	}
@@ -29,15 +30,15 @@ func (authService *AuthService) Authenticate(email, password string) (*models.Us
 	}
 
 	if bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)) != nil {
-		authService.lockUser(user)
+		authService.lockUser(ctx, user)
 		return nil, errors.New("invalid credentials")
 	}
 
-	authService.resetLockout(user)
+	authService.resetLockout(ctx, user)
 	return user, nil
 }
 
-func (authService *AuthService) lockUser(user *models.User) {
+func (authService *AuthService) lockUser(ctx context.Context, user *models.User) {
 	user.FailedAttempts++
 	if user.FailedAttempts >= authService.PasswordAllowedRetries {
 		user.LockedUntil = sql.NullTime{
@@ -46,20 +47,20 @@ func (authService *AuthService) lockUser(user *models.User) {
 		}
 	}
 
-	err := authService.Repo.Update(user)
+	err := authService.Repo.Update(ctx, user)
 	if err != nil {
 		log.Errorf("Failed to update user lock status: %v", err)
 	}
 }
 
-func (authService *AuthService) resetLockout(user *models.User) {
+func (authService *AuthService) resetLockout(ctx context.Context, user *models.User) {
 	if user.FailedAttempts == 0 {
 		return
 	}
 	user.FailedAttempts = 0
 	user.LockedUntil = sql.NullTime{Valid: false}
 	
-	err := authService.Repo.Update(user)
+	err := authService.Repo.Update(ctx, user)
 	if err != nil {
 		log.Errorf("Failed to update user lock status: %v", err)
 	}
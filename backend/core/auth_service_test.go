@@ -1,6 +1,7 @@
 package core
 
 import (
+	"context"
 	"brokerx/models"
 	"bytes"
 	"database/sql"
@@ -17,16 +18,29 @@ type MockUserRepo struct {
 	mock.Mock
 }
 
-func (m *MockUserRepo) FindByEmail(email string) (*models.User, error) {
-	args := m.Called(email)
+func (m *MockUserRepo) FindByEmail(ctx context.Context, email string) (*models.User, error) {
+	args := m.Called(ctx, email)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*models.User), args.Error(1)
 }
 
-func (m *MockUserRepo) Update(user *models.User) error {
-	args := m.Called(user)
+func (m *MockUserRepo) FindById(ctx context.Context, id string) (*models.User, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+func (m *MockUserRepo) Update(ctx context.Context, user *models.User) error {
+	args := m.Called(ctx, user)
+	return args.Error(0)
+}
+
+func (m *MockUserRepo) UpdateLotReliefMethod(ctx context.Context, id string, method string) error {
+	args := m.Called(ctx, id, method)
 	return args.Error(0)
 }
 
@@ -73,19 +87,19 @@ func (s *AuthServiceTestSuite) SetupTest() {
 
 func (s *AuthServiceTestSuite) TestAuthenticateSuccess() {
 	user := makeUser(s.email, s.pass, 0, sql.NullTime{Valid: false})
-	s.repo.On("FindByEmail", s.email).Return(user, nil)
-	s.repo.On("Update", mock.Anything).Return(nil)
+	s.repo.On("FindByEmail", mock.Anything, s.email).Return(user, nil)
+	s.repo.On("Update", mock.Anything, mock.Anything).Return(nil)
 
-	result, err := s.service.Authenticate(s.email, s.pass)
+	result, err := s.service.Authenticate(context.Background(), s.email, s.pass)
 
 	s.Require().NoError(err)
 	s.Equal(user, result)
 }
 
 func (s *AuthServiceTestSuite) TestAuthenticateUserNotFound() {
-	s.repo.On("FindByEmail", s.email).Return(nil, sql.ErrNoRows)
+	s.repo.On("FindByEmail", mock.Anything, s.email).Return(nil, sql.ErrNoRows)
 
-	result, err := s.service.Authenticate(s.email, s.pass)
+	result, err := s.service.Authenticate(context.Background(), s.email, s.pass)
 
 	s.Nil(result)
 	s.Error(err)
@@ -94,11 +108,11 @@ func (s *AuthServiceTestSuite) TestAuthenticateUserNotFound() {
 
 func (s *AuthServiceTestSuite) TestAuthenticateInvalidPasswordTriggersLockout() {
 	user := makeUser(s.email, s.pass, 0, sql.NullTime{Valid: false})
-	s.repo.On("FindByEmail", s.email).Return(user, nil)
-	s.repo.On("Update", mock.Anything).Return(nil)
+	s.repo.On("FindByEmail", mock.Anything, s.email).Return(user, nil)
+	s.repo.On("Update", mock.Anything, mock.Anything).Return(nil)
 	s.service.PasswordAllowedRetries = 1
 
-	result, err := s.service.Authenticate(s.email, "wrongpassword")
+	result, err := s.service.Authenticate(context.Background(), s.email, "wrongpassword")
 
 	s.Nil(result)
 	s.Error(err)
@@ -111,9 +125,9 @@ func (s *AuthServiceTestSuite) TestAuthenticateAccountLocked() {
 		Time:  time.Now().Add(10 * time.Minute),
 		Valid: true,
 	})
-	s.repo.On("FindByEmail", s.email).Return(user, nil)
+	s.repo.On("FindByEmail", mock.Anything, s.email).Return(user, nil)
 
-	result, err := s.service.Authenticate(s.email, s.pass)
+	result, err := s.service.Authenticate(context.Background(), s.email, s.pass)
 
 	s.Nil(result)
 	s.Error(err)
@@ -123,16 +137,16 @@ func (s *AuthServiceTestSuite) TestAuthenticateAccountLocked() {
 func (s *AuthServiceTestSuite) TestAuthenticateUserLockUserUpdateFailure() {
 	expectedLog := "Failed to update user lock status: sql: connection is already closed"
 	user := makeUser(s.email, s.pass, 0, sql.NullTime{Valid: false})
-	s.repo.On("FindByEmail", s.email).Return(user, nil)
-	s.repo.On("Update", mock.Anything).Return(sql.ErrConnDone)
+	s.repo.On("FindByEmail", mock.Anything, s.email).Return(user, nil)
+	s.repo.On("Update", mock.Anything, mock.Anything).Return(sql.ErrConnDone)
 	s.service.PasswordAllowedRetries = 1
 
 	var buf bytes.Buffer
-	originalOutput := log.StandardLogger().Writer()
+	originalOutput := log.StandardLogger().Out
 	log.SetOutput(&buf)
 	defer log.SetOutput(originalOutput)
 
-	result, err := s.service.Authenticate(s.email, "wrongpassword")
+	result, err := s.service.Authenticate(context.Background(), s.email, "wrongpassword")
 	logOutput := buf.String()
 
 	s.Contains(logOutput, expectedLog)
@@ -142,12 +156,12 @@ func (s *AuthServiceTestSuite) TestAuthenticateUserLockUserUpdateFailure() {
 
 func (s *AuthServiceTestSuite) TestAuthenticateResetLockout() {
 	user := makeUser(s.email, s.pass, 3, sql.NullTime{Valid: false})
-	s.repo.On("FindByEmail", s.email).Return(user, nil)
-	s.repo.On("Update", mock.Anything).Return(nil)
+	s.repo.On("FindByEmail", mock.Anything, s.email).Return(user, nil)
+	s.repo.On("Update", mock.Anything, mock.Anything).Return(nil)
 	s.service.PasswordAllowedRetries = 5
 	s.service.PasswordLockDurationMinutes = 5
 
-	result, err := s.service.Authenticate(s.email, s.pass)
+	result, err := s.service.Authenticate(context.Background(), s.email, s.pass)
 
 	s.Equal(0, result.FailedAttempts)
 	s.NoError(err)
@@ -156,17 +170,17 @@ func (s *AuthServiceTestSuite) TestAuthenticateResetLockout() {
 func (s *AuthServiceTestSuite) TestAuthenticateResetLockoutUpdateFailure() {
 	expectedLog := "Failed to update user lock status: sql: connection is already closed"
 	user := makeUser(s.email, s.pass, 3, sql.NullTime{Valid: false})
-	s.repo.On("FindByEmail", s.email).Return(user, nil)
-	s.repo.On("Update", mock.Anything).Return(sql.ErrConnDone)
+	s.repo.On("FindByEmail", mock.Anything, s.email).Return(user, nil)
+	s.repo.On("Update", mock.Anything, mock.Anything).Return(sql.ErrConnDone)
 	s.service.PasswordAllowedRetries = 5
 	s.service.PasswordLockDurationMinutes = 5
 
 	var buf bytes.Buffer
-	originalOutput := log.StandardLogger().Writer()
+	originalOutput := log.StandardLogger().Out
 	log.SetOutput(&buf)
 	defer log.SetOutput(originalOutput)
 
-	result, err := s.service.Authenticate(s.email, s.pass)
+	result, err := s.service.Authenticate(context.Background(), s.email, s.pass)
 	logOutput := buf.String()
 
 	s.Contains(logOutput, expectedLog)
@@ -3,24 +3,40 @@ package core
 import (
 	"brokerx/models"
 	"brokerx/ports"
-	"errors"
+	"context"
 )
 
 type ComplianceService struct {
 	WalletRepo ports.WalletRepository
 	PositionRepo ports.PositionRepository
+	TaxLotRepo ports.TaxLotRepository
+	Fees models.FeeSchedule
 }
 
-func (service *ComplianceService) VerifyOrderCompliance(order *models.Order) error {
+// VerifyOrderCompliance checks that the user can afford a buy order, its estimated
+// commission included, and owns the shares a sell order gives up. A buy order is checked
+// at its unit price, the price its funds are held at: the limit price of a limit or stop
+// limit order and the protection price of a market or stop order. A buy stop or stop
+// limit order is checked at its stop price instead when that is higher.
+func (service *ComplianceService) VerifyOrderCompliance(ctx context.Context, order *models.Order) error {
 
 	if order.Action == "buy" {
-		if err := service.verifyBuyOrderCompliance(order); err != nil {
+		price := order.UnitPrice
+		if isStop(order) && order.StopPrice.GreaterThan(price) {
+			price = order.StopPrice
+		}
+		notional := price.Mul(order.Quantity)
+		requiredFunds := notional.Add(service.Fees.Commission(order.Quantity, notional, order.Currency))
+		if err := service.verifyBuyOrderCompliance(ctx, order.UserID, order.Currency, requiredFunds); err != nil {
 			return err
 		}
 	}
 
 	if order.Action == "sell" {
-		if err := service.verifySellOrderCompliance(order); err != nil {
+		if err := service.verifySellOrderCompliance(ctx, order.UserID, order.Symbol, order.Quantity); err != nil {
+			return err
+		}
+		if err := service.verifyDesignatedLot(ctx, order, order.Quantity); err != nil {
 			return err
 		}
 	}
@@ -28,34 +44,84 @@ func (service *ComplianceService) VerifyOrderCompliance(order *models.Order) err
 	return nil
 }
 
-func (service *ComplianceService) verifyBuyOrderCompliance(order *models.Order) error {
-	wallet, err := service.WalletRepo.FindByUserId(order.UserID)
+// VerifyOrderModificationCompliance only checks the funds or shares that the modified
+// order requires on top of what the current order already required. The funds of a buy
+// order include the commission it holds.
+func (service *ComplianceService) VerifyOrderModificationCompliance(ctx context.Context, order *models.Order, modified *models.Order) error {
+
+	if order.Action == "buy" {
+		delta := reservedFunds(modified).Sub(reservedFunds(order))
+		if delta.IsPositive() {
+			return service.verifyBuyOrderCompliance(ctx, order.UserID, order.Currency, delta)
+		}
+	}
+
+	if order.Action == "sell" {
+		delta := remainingQuantity(modified) - remainingQuantity(order)
+		if delta > 0 {
+			if err := service.verifySellOrderCompliance(ctx, order.UserID, order.Symbol, delta); err != nil {
+				return err
+			}
+			return service.verifyDesignatedLot(ctx, order, delta)
+		}
+	}
+
+	return nil
+}
+
+// verifyBuyOrderCompliance checks the available funds of the wallet of the user in the
+// trading currency of the order.
+func (service *ComplianceService) verifyBuyOrderCompliance(ctx context.Context, userId string, currency string, requiredFunds models.Money) error {
+	wallet, err := service.WalletRepo.FindByUserIdAndCurrency(ctx, userId, currency)
 	if err != nil {
 		return err
 	}
 
-	if wallet.AvailableFunds < (order.UnitPrice * float64(order.Quantity)) {
-		return errors.New("not enough available funds")
+	if wallet.AvailableFunds.LessThan(requiredFunds) {
+		return ports.ErrInsufficientFunds
 	}
 
 	return nil
 }
 
-func (service *ComplianceService) verifySellOrderCompliance(order *models.Order) error {
-	positions, err := service.PositionRepo.FindByUserIdAndSymbol(order.UserID, order.Symbol)
+func (service *ComplianceService) verifySellOrderCompliance(ctx context.Context, userId string, symbol string, requiredQuantity int) error {
+	positions, err := service.PositionRepo.FindByUserIdAndSymbol(ctx, userId, symbol)
 	if err != nil {
 		return err
 	}
 
-	totalOwnedStock := 0
+	// Shares bought by unsettled trades cannot be sold before they settle
+	unreservedStock := 0
 	for _, p := range positions {
-		totalOwnedStock += p.Quantity
+		unreservedStock += p.Quantity - p.ReservedQuantity - p.UnsettledQuantity
+	}
+	if unreservedStock < requiredQuantity {
+		return ports.ErrInsufficientShares
+	}
+
+	return nil
+}
+
+// verifyDesignatedLot checks that the tax lot designated by a sell order belongs to the
+// user, is in the symbol of the order and still holds the quantity to sell besides the
+// shares other sell orders designating it have reserved.
+func (service *ComplianceService) verifyDesignatedLot(ctx context.Context, order *models.Order, requiredQuantity int) error {
+	if order.LotID == 0 {
+		return nil
+	}
+
+	lot, err := service.TaxLotRepo.FindById(ctx, order.LotID)
+	if err != nil {
+		return err
+	}
+	if lot.UserID != order.UserID || lot.Symbol != order.Symbol {
+		return ports.ErrLotNotFound
 	}
-	if totalOwnedStock < order.Quantity {
-		return errors.New("not enough owned stocks")
+	if lot.RemainingQuantity-lot.ReservedQuantity < requiredQuantity {
+		return ports.ErrInsufficientShares
 	}
 
 	return nil
 }
 
-var _ ports.ComplianceService = (*ComplianceService)(nil) // Ensure interface is implemented at compile time
\ No newline at end of file
+var _ ports.ComplianceService = (*ComplianceService)(nil) // Ensure interface is implemented at compile time
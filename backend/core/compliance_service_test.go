@@ -1,7 +1,9 @@
 package core
 
 import (
+	"context"
 	"brokerx/models"
+	"brokerx/ports"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -13,31 +15,81 @@ type MockWalletRepo struct {
 	mock.Mock
 }
 
-func (m *MockWalletRepo) FindByUserId(userId string) (*models.Wallet, error) {
-	args := m.Called(userId)
+func (m *MockWalletRepo) FindByUserId(ctx context.Context, userId string) ([]*models.Wallet, error) {
+	args := m.Called(ctx, userId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Wallet), args.Error(1)
+}
+
+func (m *MockWalletRepo) FindByUserIdAndCurrency(ctx context.Context, userId string, currency string) (*models.Wallet, error) {
+	args := m.Called(ctx, userId, currency)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*models.Wallet), args.Error(1)
 }
 
+func (m *MockWalletRepo) FindAll(ctx context.Context) ([]*models.Wallet, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Wallet), args.Error(1)
+}
+
 type MockPositionsRepo struct {
 	mock.Mock
 }
 
-func (m *MockPositionsRepo) FindByUserIdAndSymbol(userId string, symbol string) ([]*models.Position, error) {
-	args := m.Called(userId, symbol)
+func (m *MockPositionsRepo) FindByUserIdAndSymbol(ctx context.Context, userId string, symbol string) ([]*models.Position, error) {
+	args := m.Called(ctx, userId, symbol)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Position), args.Error(1)
+}
+
+func (m *MockPositionsRepo) FindByUserId(ctx context.Context, userId string) ([]*models.Position, error) {
+	args := m.Called(ctx, userId)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).([]*models.Position), args.Error(1)
 }
 
+func (m *MockPositionsRepo) ReserveShares(ctx context.Context, userId string, symbol string, quantity int) error {
+	args := m.Called(ctx, userId, symbol, quantity)
+	return args.Error(0)
+}
+
+func (m *MockPositionsRepo) ReleaseShares(ctx context.Context, userId string, symbol string, quantity int) error {
+	args := m.Called(ctx, userId, symbol, quantity)
+	return args.Error(0)
+}
+
+func (m *MockPositionsRepo) AddShares(ctx context.Context, userId string, symbol string, quantity int, unitPrice models.Money) error {
+	args := m.Called(ctx, userId, symbol, quantity, unitPrice)
+	return args.Error(0)
+}
+
+func (m *MockPositionsRepo) ConsumeReservedShares(ctx context.Context, userId string, symbol string, quantity int, currency string, unitPrice models.Money) error {
+	args := m.Called(ctx, userId, symbol, quantity, currency, unitPrice)
+	return args.Error(0)
+}
+
+func (m *MockPositionsRepo) SettleShares(ctx context.Context, userId string, symbol string, quantity int) error {
+	args := m.Called(ctx, userId, symbol, quantity)
+	return args.Error(0)
+}
+
 func makeWallet(order *models.Order) *models.Wallet {
 	return &models.Wallet{
 		UserId: order.UserID,
-		AvailableFunds: (order.UnitPrice * float64(order.Quantity)) + 100,
-		OnHoldFunds: 100.0,
+		Currency: order.Currency,
+		AvailableFunds: order.UnitPrice.Mul(order.Quantity).Add(models.NewMoney(100)),
+		OnHoldFunds: models.NewMoney(100),
 	}
 }
 
@@ -55,13 +107,15 @@ type ComplianceServiceTestSuite struct {
 	suite.Suite
 	walletRepo    *MockWalletRepo
 	positionRepo *MockPositionsRepo
+	taxLotRepo *MockTaxLotRepo
 	service *ComplianceService
 }
 
 func (s *ComplianceServiceTestSuite) SetupTest() {
 	s.walletRepo = new(MockWalletRepo)
 	s.positionRepo = new(MockPositionsRepo)
-	s.service = &ComplianceService{WalletRepo: s.walletRepo, PositionRepo: s.positionRepo}
+	s.taxLotRepo = new(MockTaxLotRepo)
+	s.service = &ComplianceService{WalletRepo: s.walletRepo, PositionRepo: s.positionRepo, TaxLotRepo: s.taxLotRepo}
 }
 
 // ---------------------------
@@ -71,9 +125,77 @@ func (s *ComplianceServiceTestSuite) SetupTest() {
 func (s *ComplianceServiceTestSuite) TestVerifyBuyOrderSuccess() {
 	order := makeOrder()
 	wallet := makeWallet(order)
-	s.walletRepo.On("FindByUserId", order.UserID).Return(wallet, nil)
+	s.walletRepo.On("FindByUserIdAndCurrency", mock.Anything, order.UserID, order.Currency).Return(wallet, nil)
 
-	err := s.service.VerifyOrderCompliance(order)
+	err := s.service.VerifyOrderCompliance(context.Background(), order)
+
+	s.Require().NoError(err)
+}
+
+func (s *ComplianceServiceTestSuite) TestVerifyBuyStopLimitOrderUsesTheLimitPrice() {
+	order := makeOrder()
+	order.Type = "stop_limit"
+	order.StopPrice = models.NewMoney(145)
+	wallet := makeWallet(order)
+	wallet.AvailableFunds = models.NewMoney(1500)
+	s.walletRepo.On("FindByUserIdAndCurrency", mock.Anything, order.UserID, order.Currency).Return(wallet, nil)
+
+	err := s.service.VerifyOrderCompliance(context.Background(), order)
+
+	s.Require().NoError(err)
+}
+
+func (s *ComplianceServiceTestSuite) TestVerifyBuyStopLimitOrderUsesTheStopPriceAboveTheLimit() {
+	order := makeOrder()
+	order.Type = "stop_limit"
+	order.StopPrice = models.NewMoney(160)
+	wallet := makeWallet(order)
+	wallet.AvailableFunds = models.NewMoney(1599)
+	s.walletRepo.On("FindByUserIdAndCurrency", mock.Anything, order.UserID, order.Currency).Return(wallet, nil)
+
+	err := s.service.VerifyOrderCompliance(context.Background(), order)
+
+	s.ErrorIs(err, ports.ErrInsufficientFunds)
+}
+
+func (s *ComplianceServiceTestSuite) TestVerifyBuyStopOrderUsesTheStopPriceWithTheCommission() {
+	s.service.Fees = models.FeeSchedule{PerOrder: models.NewMoney(1)}
+	order := makeOrder()
+	order.Type = "stop"
+	order.StopPrice = models.NewMoney(160)
+	wallet := makeWallet(order)
+	wallet.AvailableFunds = models.NewMoney(1601)
+	s.walletRepo.On("FindByUserIdAndCurrency", mock.Anything, order.UserID, order.Currency).Return(wallet, nil)
+
+	s.Require().NoError(s.service.VerifyOrderCompliance(context.Background(), order))
+
+	wallet.AvailableFunds = models.NewMoney(1600)
+	s.ErrorIs(s.service.VerifyOrderCompliance(context.Background(), order), ports.ErrInsufficientFunds)
+}
+
+func (s *ComplianceServiceTestSuite) TestVerifyBuyStopOrderUsesTheProtectionPrice() {
+	order := makeOrder()
+	order.Type = "stop"
+	order.StopPrice = models.NewMoney(140)
+	wallet := makeWallet(order)
+	wallet.AvailableFunds = models.NewMoney(1450)
+	s.walletRepo.On("FindByUserIdAndCurrency", mock.Anything, order.UserID, order.Currency).Return(wallet, nil)
+
+	err := s.service.VerifyOrderCompliance(context.Background(), order)
+
+	s.ErrorIs(err, ports.ErrInsufficientFunds)
+}
+
+func (s *ComplianceServiceTestSuite) TestVerifyBuyOrderSpendingExactlyTheAvailableFunds() {
+	// 0.10 * 3 is 0.30000000000000004 in float64, more than the 0.30 available
+	order := makeOrder()
+	order.UnitPrice = models.MustParseMoney("0.10")
+	order.Quantity = 3
+	wallet := makeWallet(order)
+	wallet.AvailableFunds = models.MustParseMoney("0.30")
+	s.walletRepo.On("FindByUserIdAndCurrency", mock.Anything, order.UserID, order.Currency).Return(wallet, nil)
+
+	err := s.service.VerifyOrderCompliance(context.Background(), order)
 
 	s.Require().NoError(err)
 }
@@ -81,19 +203,50 @@ func (s *ComplianceServiceTestSuite) TestVerifyBuyOrderSuccess() {
 func (s *ComplianceServiceTestSuite) TestVerifyBuyOrderNonCompliance() {
 	order := makeOrder()
 	wallet := makeWallet(order)
-	wallet.AvailableFunds = (order.UnitPrice * float64(order.Quantity)) - 5
-	s.walletRepo.On("FindByUserId", order.UserID).Return(wallet, nil)
+	wallet.AvailableFunds = order.UnitPrice.Mul(order.Quantity).Sub(models.NewMoney(5))
+	s.walletRepo.On("FindByUserIdAndCurrency", mock.Anything, order.UserID, order.Currency).Return(wallet, nil)
 
-	err := s.service.VerifyOrderCompliance(order)
+	err := s.service.VerifyOrderCompliance(context.Background(), order)
 
 	s.EqualError(err, "not enough available funds")
 }
 
+func (s *ComplianceServiceTestSuite) TestVerifyBuyOrderCoversTheEstimatedCommission() {
+	s.service.Fees = models.FeeSchedule{PerOrder: models.NewMoney(1), PerShare: models.MustParseMoney("0.05")}
+	order := makeOrder()
+	wallet := makeWallet(order)
+	wallet.AvailableFunds = models.MustParseMoney("1501.49")
+	s.walletRepo.On("FindByUserIdAndCurrency", mock.Anything, order.UserID, order.Currency).Return(wallet, nil)
+
+	err := s.service.VerifyOrderCompliance(context.Background(), order)
+
+	s.ErrorIs(err, ports.ErrInsufficientFunds)
+
+	wallet.AvailableFunds = models.MustParseMoney("1501.50")
+	err = s.service.VerifyOrderCompliance(context.Background(), order)
+
+	s.Require().NoError(err)
+}
+
+func (s *ComplianceServiceTestSuite) TestVerifyBuyOrderChecksTheWalletOfTheTradingCurrency() {
+	order := makeOrder()
+	order.Symbol = "SHOP"
+	order.Currency = "CAD"
+	wallet := makeWallet(order)
+	wallet.AvailableFunds = models.NewMoney(10)
+	s.walletRepo.On("FindByUserIdAndCurrency", mock.Anything, order.UserID, "CAD").Return(wallet, nil)
+
+	err := s.service.VerifyOrderCompliance(context.Background(), order)
+
+	s.ErrorIs(err, ports.ErrInsufficientFunds)
+	s.walletRepo.AssertNotCalled(s.T(), "FindByUserIdAndCurrency", mock.Anything, order.UserID, "USD")
+}
+
 func (s *ComplianceServiceTestSuite) TestVerifyBuyOrderFailure() {
 	order := makeOrder()
-	s.walletRepo.On("FindByUserId", order.UserID).Return(nil, assert.AnError)
+	s.walletRepo.On("FindByUserIdAndCurrency", mock.Anything, order.UserID, order.Currency).Return(nil, assert.AnError)
 
-	err := s.service.VerifyOrderCompliance(order)
+	err := s.service.VerifyOrderCompliance(context.Background(), order)
 
 	s.Error(err)
 }
@@ -102,9 +255,9 @@ func (s *ComplianceServiceTestSuite) TestVerifySellOrderSuccess() {
 	order := makeOrder()
 	order.Action = "sell"
 	positions := makePositions(order)
-	s.positionRepo.On("FindByUserIdAndSymbol", order.UserID, order.Symbol).Return(positions, nil)
+	s.positionRepo.On("FindByUserIdAndSymbol", mock.Anything, order.UserID, order.Symbol).Return(positions, nil)
 
-	err := s.service.VerifyOrderCompliance(order)
+	err := s.service.VerifyOrderCompliance(context.Background(), order)
 
 	s.Require().NoError(err)
 }
@@ -112,23 +265,147 @@ func (s *ComplianceServiceTestSuite) TestVerifySellOrderSuccess() {
 func (s *ComplianceServiceTestSuite) TestVerifySellOrderNonCompliance() {
 	order := makeOrder()
 	order.Action = "sell"
-	s.positionRepo.On("FindByUserIdAndSymbol", order.UserID, order.Symbol).Return(make([]*models.Position, 0), nil)
+	s.positionRepo.On("FindByUserIdAndSymbol", mock.Anything, order.UserID, order.Symbol).Return(make([]*models.Position, 0), nil)
 
-	err := s.service.VerifyOrderCompliance(order)
+	err := s.service.VerifyOrderCompliance(context.Background(), order)
 
 	s.EqualError(err, "not enough owned stocks")
 }
 
+func (s *ComplianceServiceTestSuite) TestVerifySellOrderDesignatedLot() {
+	order := makeOrder()
+	order.Action = "sell"
+	s.positionRepo.On("FindByUserIdAndSymbol", mock.Anything, order.UserID, order.Symbol).Return(makePositions(order), nil)
+	s.taxLotRepo.On("FindById", mock.Anything, 1).Return(&models.TaxLot{ID: 1, UserID: order.UserID, Symbol: order.Symbol, RemainingQuantity: order.Quantity}, nil)
+	s.taxLotRepo.On("FindById", mock.Anything, 2).Return(&models.TaxLot{ID: 2, UserID: order.UserID, Symbol: order.Symbol, RemainingQuantity: order.Quantity - 1}, nil)
+	s.taxLotRepo.On("FindById", mock.Anything, 3).Return(&models.TaxLot{ID: 3, UserID: "other", Symbol: order.Symbol, RemainingQuantity: order.Quantity}, nil)
+	s.taxLotRepo.On("FindById", mock.Anything, 4).Return(nil, ports.ErrLotNotFound)
+	s.taxLotRepo.On("FindById", mock.Anything, 5).Return(&models.TaxLot{ID: 5, UserID: order.UserID, Symbol: order.Symbol, RemainingQuantity: order.Quantity + 5, ReservedQuantity: 6}, nil)
+	expected := map[int]error{1: nil, 2: ports.ErrInsufficientShares, 3: ports.ErrLotNotFound, 4: ports.ErrLotNotFound, 5: ports.ErrInsufficientShares}
+
+	for lotID, expectedErr := range expected {
+		order.LotID = lotID
+
+		err := s.service.VerifyOrderCompliance(context.Background(), order)
+
+		if expectedErr == nil {
+			s.NoError(err, lotID)
+		} else {
+			s.ErrorIs(err, expectedErr, lotID)
+		}
+	}
+}
+
+func (s *ComplianceServiceTestSuite) TestVerifySellOrderReservedSharesExcluded() {
+	order := makeOrder()
+	order.Action = "sell"
+	positions := makePositions(order)
+	positions[0].ReservedQuantity = 2
+	s.positionRepo.On("FindByUserIdAndSymbol", mock.Anything, order.UserID, order.Symbol).Return(positions, nil)
+
+	err := s.service.VerifyOrderCompliance(context.Background(), order)
+
+	s.ErrorIs(err, ports.ErrInsufficientShares)
+}
+
+func (s *ComplianceServiceTestSuite) TestVerifySellOrderUnsettledSharesExcluded() {
+	order := makeOrder()
+	order.Action = "sell"
+	positions := makePositions(order)
+	positions[0].UnsettledQuantity = 2
+	s.positionRepo.On("FindByUserIdAndSymbol", mock.Anything, order.UserID, order.Symbol).Return(positions, nil)
+
+	err := s.service.VerifyOrderCompliance(context.Background(), order)
+
+	s.ErrorIs(err, ports.ErrInsufficientShares)
+}
+
 func (s *ComplianceServiceTestSuite) TestVerifySellOrderFailure() {
 	order := makeOrder()
 	order.Action = "sell"
-	s.positionRepo.On("FindByUserIdAndSymbol", order.UserID, order.Symbol).Return(nil, assert.AnError)
+	s.positionRepo.On("FindByUserIdAndSymbol", mock.Anything, order.UserID, order.Symbol).Return(nil, assert.AnError)
 
-	err := s.service.VerifyOrderCompliance(order)
+	err := s.service.VerifyOrderCompliance(context.Background(), order)
 
 	s.Error(err)
 }
 
+func (s *ComplianceServiceTestSuite) TestVerifyBuyOrderModificationChecksDelta() {
+	order := makeOrder()
+	modified := *order
+	modified.Quantity = 12
+	wallet := makeWallet(order)
+	wallet.AvailableFunds = models.NewMoney(300)
+	s.walletRepo.On("FindByUserIdAndCurrency", mock.Anything, order.UserID, order.Currency).Return(wallet, nil)
+
+	err := s.service.VerifyOrderModificationCompliance(context.Background(), order, &modified)
+
+	s.Require().NoError(err)
+
+	modified.Quantity = 13
+	err = s.service.VerifyOrderModificationCompliance(context.Background(), order, &modified)
+
+	s.EqualError(err, "not enough available funds")
+}
+
+func (s *ComplianceServiceTestSuite) TestVerifyBuyOrderModificationChecksTheHeldCommission() {
+	order := makeOrder()
+	order.FeesOnHold = models.NewMoney(1)
+	modified := *order
+	modified.FeesOnHold = models.NewMoney(3)
+	wallet := makeWallet(order)
+	wallet.AvailableFunds = models.MustParseMoney("1.99")
+	s.walletRepo.On("FindByUserIdAndCurrency", mock.Anything, order.UserID, order.Currency).Return(wallet, nil)
+
+	err := s.service.VerifyOrderModificationCompliance(context.Background(), order, &modified)
+
+	s.ErrorIs(err, ports.ErrInsufficientFunds)
+}
+
+func (s *ComplianceServiceTestSuite) TestVerifyBuyOrderModificationReducingExposure() {
+	order := makeOrder()
+	modified := *order
+	modified.UnitPrice = models.NewMoney(100)
+
+	err := s.service.VerifyOrderModificationCompliance(context.Background(), order, &modified)
+
+	s.Require().NoError(err)
+	s.walletRepo.AssertNotCalled(s.T(), "FindByUserIdAndCurrency", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *ComplianceServiceTestSuite) TestVerifySellOrderModificationChecksDelta() {
+	order := makeOrder()
+	order.Action = "sell"
+	modified := *order
+	modified.Quantity = order.Quantity + 2
+	s.positionRepo.On("FindByUserIdAndSymbol", mock.Anything, order.UserID, order.Symbol).Return(
+		[]*models.Position{{UserId: order.UserID, Symbol: order.Symbol, Quantity: 1}}, nil)
+
+	err := s.service.VerifyOrderModificationCompliance(context.Background(), order, &modified)
+
+	s.EqualError(err, "not enough owned stocks")
+}
+
+func (s *ComplianceServiceTestSuite) TestVerifySellOrderModificationChecksTheUnreservedSharesOfTheLot() {
+	order := makeOrder()
+	order.Action = "sell"
+	order.LotID = 1
+	modified := *order
+	modified.Quantity = order.Quantity + 2
+	s.positionRepo.On("FindByUserIdAndSymbol", mock.Anything, order.UserID, order.Symbol).Return(makePositions(order), nil)
+	lot := &models.TaxLot{ID: 1, UserID: order.UserID, Symbol: order.Symbol, RemainingQuantity: order.Quantity + 2, ReservedQuantity: order.Quantity}
+	s.taxLotRepo.On("FindById", mock.Anything, 1).Return(lot, nil)
+
+	err := s.service.VerifyOrderModificationCompliance(context.Background(), order, &modified)
+
+	s.Require().NoError(err)
+
+	lot.ReservedQuantity = order.Quantity + 1
+	err = s.service.VerifyOrderModificationCompliance(context.Background(), order, &modified)
+
+	s.ErrorIs(err, ports.ErrInsufficientShares)
+}
+
 // ---------------------------
 // Run the suite
 // ---------------------------
@@ -0,0 +1,91 @@
+package core
+
+import (
+	"brokerx/models"
+	"brokerx/ports"
+	"context"
+	"errors"
+)
+
+// ConfirmationService gives the users access to the confirmations of their trades.
+type ConfirmationService struct {
+	Repo ports.ConfirmationRepository
+}
+
+func (service *ConfirmationService) GetConfirmation(ctx context.Context, userID string, number string) (*models.Confirmation, error) {
+	confirmation, err := service.Repo.FindByNumber(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+
+	if confirmation.UserID != userID {
+		return nil, ports.ErrConfirmationNotOwned
+	}
+
+	return confirmation, nil
+}
+
+// ListConfirmations returns the confirmations of the user, most recent first.
+func (service *ConfirmationService) ListConfirmations(ctx context.Context, userID string) ([]*models.Confirmation, error) {
+	return service.Repo.FindByUserId(ctx, userID)
+}
+
+// confirmFill issues a confirmation to each side of the execution. The confirmations are
+// numbered after the execution and the side, so a fill that is replayed finds its
+// confirmations already issued. They are left as they are when they confirm the same
+// trade, and the replay fails with ErrConfirmationConflict when they do not.
+func confirmFill(ctx context.Context, repos ports.Repositories, buyOrder *models.Order, sellOrder *models.Order, execution *models.Execution) error {
+	for _, order := range []*models.Order{buyOrder, sellOrder} {
+		confirmation := &models.Confirmation{
+			Number:         models.ConfirmationNumber(execution, order.Action),
+			ExecutionID:    execution.ID,
+			OrderID:        order.ID,
+			UserID:         order.UserID,
+			Symbol:         execution.Symbol,
+			Side:           order.Action,
+			Quantity:       execution.Quantity,
+			Price:          execution.Price,
+			Currency:       order.Currency,
+			Commission:     execution.BuyCommission,
+			ExecutedAt:     execution.ExecutedAt,
+			SettlementDate: execution.SettlementDate,
+		}
+		if order.Action == "sell" {
+			confirmation.Commission = execution.SellCommission
+			confirmation.RegulatoryFee = execution.RegulatoryFee
+		}
+
+		issued, err := repos.Confirmations.FindByNumber(ctx, confirmation.Number)
+		if err == nil {
+			if !sameTrade(issued, confirmation) {
+				return ports.ErrConfirmationConflict
+			}
+			continue
+		}
+		if !errors.Is(err, ports.ErrConfirmationNotFound) {
+			return err
+		}
+
+		if _, err := repos.Confirmations.CreateConfirmation(ctx, confirmation); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sameTrade tells whether an issued confirmation confirms the trade of the other: the same
+// side of the same execution, at the same quantity, price and fees.
+func sameTrade(issued *models.Confirmation, confirmation *models.Confirmation) bool {
+	return issued.ExecutionID == confirmation.ExecutionID &&
+		issued.OrderID == confirmation.OrderID &&
+		issued.UserID == confirmation.UserID &&
+		issued.Symbol == confirmation.Symbol &&
+		issued.Side == confirmation.Side &&
+		issued.Quantity == confirmation.Quantity &&
+		issued.Price == confirmation.Price &&
+		issued.Currency == confirmation.Currency &&
+		issued.Commission == confirmation.Commission &&
+		issued.RegulatoryFee == confirmation.RegulatoryFee
+}
+
+var _ ports.ConfirmationService = (*ConfirmationService)(nil) // Ensure interface is implemented at compile time
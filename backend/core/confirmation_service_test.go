@@ -0,0 +1,184 @@
+package core
+
+import (
+	"brokerx/models"
+	"brokerx/ports"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type MockConfirmationRepo struct {
+	mock.Mock
+}
+
+func (m *MockConfirmationRepo) CreateConfirmation(ctx context.Context, confirmation *models.Confirmation) (int, error) {
+	args := m.Called(ctx, confirmation)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockConfirmationRepo) FindByNumber(ctx context.Context, number string) (*models.Confirmation, error) {
+	args := m.Called(ctx, number)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Confirmation), args.Error(1)
+}
+
+func (m *MockConfirmationRepo) FindByUserId(ctx context.Context, userId string) ([]*models.Confirmation, error) {
+	args := m.Called(ctx, userId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Confirmation), args.Error(1)
+}
+
+// ---------------------------
+// Test Suite
+// ---------------------------
+
+type ConfirmationServiceTestSuite struct {
+	suite.Suite
+	repo    *MockConfirmationRepo
+	service *ConfirmationService
+}
+
+func (s *ConfirmationServiceTestSuite) SetupTest() {
+	s.repo = new(MockConfirmationRepo)
+	s.service = &ConfirmationService{Repo: s.repo}
+}
+
+// expectConfirmationsIssued confirms the fill and lets the repository find the issued
+// confirmations by their number afterwards.
+func (s *ConfirmationServiceTestSuite) expectConfirmationsIssued(repos ports.Repositories, buyOrder *models.Order, sellOrder *models.Order, execution *models.Execution) []*models.Confirmation {
+	var issued []*models.Confirmation
+	s.repo.On("FindByNumber", mock.Anything, mock.Anything).Return(nil, ports.ErrConfirmationNotFound).Twice()
+	s.repo.On("CreateConfirmation", mock.Anything, mock.Anything).Return(1, nil).Run(func(args mock.Arguments) {
+		issued = append(issued, args.Get(1).(*models.Confirmation))
+	})
+
+	s.Require().NoError(confirmFill(context.Background(), repos, buyOrder, sellOrder, execution))
+
+	s.Require().Len(issued, 2)
+	for _, confirmation := range issued {
+		s.repo.On("FindByNumber", mock.Anything, confirmation.Number).Return(confirmation, nil)
+	}
+	return issued
+}
+
+// ---------------------------
+// Tests
+// ---------------------------
+
+func (s *ConfirmationServiceTestSuite) TestGetConfirmation() {
+	confirmation := &models.Confirmation{ID: 1, Number: "TC-20260918-00000007-B", UserID: "user"}
+	s.repo.On("FindByNumber", mock.Anything, confirmation.Number).Return(confirmation, nil)
+
+	found, err := s.service.GetConfirmation(context.Background(), "user", confirmation.Number)
+
+	s.Require().NoError(err)
+	s.Equal(confirmation, found)
+}
+
+func (s *ConfirmationServiceTestSuite) TestGetConfirmationOfAnotherUser() {
+	confirmation := &models.Confirmation{ID: 1, Number: "TC-20260918-00000007-B", UserID: "other"}
+	s.repo.On("FindByNumber", mock.Anything, confirmation.Number).Return(confirmation, nil)
+
+	found, err := s.service.GetConfirmation(context.Background(), "user", confirmation.Number)
+
+	s.Nil(found)
+	s.ErrorIs(err, ports.ErrConfirmationNotOwned)
+}
+
+func (s *ConfirmationServiceTestSuite) TestGetConfirmationNotFound() {
+	s.repo.On("FindByNumber", mock.Anything, "unknown").Return(nil, ports.ErrConfirmationNotFound)
+
+	found, err := s.service.GetConfirmation(context.Background(), "user", "unknown")
+
+	s.Nil(found)
+	s.ErrorIs(err, ports.ErrConfirmationNotFound)
+}
+
+func (s *ConfirmationServiceTestSuite) TestListConfirmations() {
+	confirmations := []*models.Confirmation{{ID: 2}, {ID: 1}}
+	s.repo.On("FindByUserId", mock.Anything, "user").Return(confirmations, nil)
+
+	found, err := s.service.ListConfirmations(context.Background(), "user")
+
+	s.Require().NoError(err)
+	s.Equal(confirmations, found)
+}
+
+func (s *ConfirmationServiceTestSuite) TestConfirmFillIsIdempotent() {
+	buyOrder := &models.Order{ID: 2, UserID: "buyer", Action: "buy", Currency: "USD"}
+	sellOrder := &models.Order{ID: 9, UserID: "seller", Action: "sell", Currency: "USD"}
+	execution := &models.Execution{ID: 7, BuyOrderID: 2, SellOrderID: 9, Symbol: "AAPL", Quantity: 10, Price: models.NewMoney(148),
+		BuyCommission: models.MustParseMoney("2.48"), SellCommission: models.MustParseMoney("2.48"), RegulatoryFee: models.MustParseMoney("0.05"),
+		ExecutedAt: time.Date(2026, 9, 18, 14, 30, 0, 0, time.UTC), SettlementDate: time.Date(2026, 9, 21, 0, 0, 0, 0, time.UTC)}
+	repos := ports.Repositories{Confirmations: s.repo}
+	issued := s.expectConfirmationsIssued(repos, buyOrder, sellOrder, execution)
+
+	err := confirmFill(context.Background(), repos, buyOrder, sellOrder, execution)
+
+	// The replayed fill finds its confirmations already issued under the same numbers
+	s.Require().NoError(err)
+	s.Equal([]string{"TC-20260918-00000007-B", "TC-20260918-00000007-S"}, []string{issued[0].Number, issued[1].Number})
+	s.repo.AssertNumberOfCalls(s.T(), "CreateConfirmation", 2)
+	s.repo.AssertCalled(s.T(), "CreateConfirmation", mock.Anything, &models.Confirmation{Number: "TC-20260918-00000007-S", ExecutionID: 7, OrderID: 9, UserID: "seller",
+		Symbol: "AAPL", Side: "sell", Quantity: 10, Price: models.NewMoney(148), Currency: "USD", Commission: models.MustParseMoney("2.48"),
+		RegulatoryFee: models.MustParseMoney("0.05"), ExecutedAt: execution.ExecutedAt, SettlementDate: execution.SettlementDate})
+}
+
+func (s *ConfirmationServiceTestSuite) TestConfirmFillReplayedWithOtherFeesConflicts() {
+	buyOrder := &models.Order{ID: 2, UserID: "buyer", Action: "buy", Currency: "USD"}
+	sellOrder := &models.Order{ID: 9, UserID: "seller", Action: "sell", Currency: "USD"}
+	execution := &models.Execution{ID: 7, Symbol: "AAPL", Quantity: 10, Price: models.NewMoney(148), BuyCommission: models.MustParseMoney("2.48"),
+		SellCommission: models.MustParseMoney("2.48"), ExecutedAt: time.Date(2026, 9, 18, 14, 30, 0, 0, time.UTC)}
+	repos := ports.Repositories{Confirmations: s.repo}
+	s.expectConfirmationsIssued(repos, buyOrder, sellOrder, execution)
+
+	execution.BuyCommission = models.NewMoney(99)
+	err := confirmFill(context.Background(), repos, buyOrder, sellOrder, execution)
+
+	s.ErrorIs(err, ports.ErrConfirmationConflict)
+	s.repo.AssertNumberOfCalls(s.T(), "CreateConfirmation", 2)
+}
+
+func (s *ConfirmationServiceTestSuite) TestConfirmFillFailure() {
+	s.repo.On("FindByNumber", mock.Anything, mock.Anything).Return(nil, ports.ErrConfirmationNotFound)
+	s.repo.On("CreateConfirmation", mock.Anything, mock.Anything).Return(0, assert.AnError)
+	buyOrder := &models.Order{ID: 2, UserID: "buyer", Action: "buy"}
+	sellOrder := &models.Order{ID: 9, UserID: "seller", Action: "sell"}
+
+	err := confirmFill(context.Background(), ports.Repositories{Confirmations: s.repo}, buyOrder, sellOrder, &models.Execution{ID: 7})
+
+	s.ErrorIs(err, assert.AnError)
+	s.repo.AssertNumberOfCalls(s.T(), "CreateConfirmation", 1)
+}
+
+func (s *ConfirmationServiceTestSuite) TestConfirmationNumber() {
+	execution := &models.Execution{ID: 42, ExecutedAt: time.Date(2026, 9, 18, 23, 30, 0, 0, time.UTC)}
+
+	s.Equal("TC-20260918-00000042-S", models.ConfirmationNumber(execution, "sell"))
+	s.Equal("TC-20260918-00000042-", models.ConfirmationNumber(execution, ""))
+}
+
+func (s *ConfirmationServiceTestSuite) TestConfirmationNetAmount() {
+	purchase := &models.Confirmation{Side: "buy", Quantity: 10, Price: models.NewMoney(148), Commission: models.MustParseMoney("2.48")}
+	sale := &models.Confirmation{Side: "sell", Quantity: 10, Price: models.NewMoney(148), Commission: models.MustParseMoney("2.48"), RegulatoryFee: models.MustParseMoney("0.05")}
+
+	s.Equal(models.MustParseMoney("-1482.48"), purchase.NetAmount())
+	s.Equal(models.MustParseMoney("1477.47"), sale.NetAmount())
+}
+
+// ---------------------------
+// Run Test Suite
+// ---------------------------
+
+func TestConfirmationServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(ConfirmationServiceTestSuite))
+}
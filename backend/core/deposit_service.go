@@ -0,0 +1,133 @@
+package core
+
+import (
+	"brokerx/models"
+	"brokerx/ports"
+	"context"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type DepositService struct {
+	Repo       ports.DepositRepository
+	UnitOfWork ports.UnitOfWork
+	Provider   ports.PaymentProvider
+}
+
+// InitiateDeposit records a pending deposit and charges it with the payment provider. The
+// deposit is settled or failed right away when the provider answers immediately, and is
+// left pending for RefreshPendingDeposits otherwise. A charge that gets no answer may
+// still have gone through, so it leaves the deposit pending as well.
+func (service *DepositService) InitiateDeposit(ctx context.Context, userID string, amount models.Money) (*models.Deposit, error) {
+	if !isCurrencyAmount(amount, models.BaseCurrency) {
+		return nil, ports.ErrInvalidAmount
+	}
+
+	deposit := &models.Deposit{UserID: userID, Amount: amount, Status: "pending"}
+	id, err := service.Repo.CreateDeposit(ctx, deposit)
+	if err != nil {
+		return nil, err
+	}
+	deposit.ID = id
+
+	result, err := service.Provider.Charge(ctx, deposit)
+	if err != nil {
+		log.Errorf("Failed to charge deposit %d, leaving it pending: %v", deposit.ID, err)
+		return deposit, nil
+	}
+
+	// The provider already answered, its answer must be recorded even if the client leaves
+	if err := service.apply(context.WithoutCancel(ctx), deposit, result); err != nil {
+		return nil, err
+	}
+	return deposit, nil
+}
+
+func (service *DepositService) GetDeposit(ctx context.Context, userID string, depositID int) (*models.Deposit, error) {
+	deposit, err := service.Repo.FindById(ctx, depositID)
+	if err != nil {
+		return nil, err
+	}
+
+	if deposit.UserID != userID {
+		return nil, ports.ErrDepositNotOwned
+	}
+
+	return deposit, nil
+}
+
+// ListDeposits returns the deposits of the user, most recent first.
+func (service *DepositService) ListDeposits(ctx context.Context, userID string) ([]*models.Deposit, error) {
+	return service.Repo.FindByUserId(ctx, userID)
+}
+
+// RefreshPendingDeposits asks the payment provider about the pending deposits and settles
+// or fails those it resolved. A deposit whose charge was never answered has no reference
+// yet and is charged again, which returns the charge already made if there is one. A
+// deposit that fails to refresh is logged and left pending so that the next run can retry
+// it. It returns the number of resolved deposits.
+func (service *DepositService) RefreshPendingDeposits(ctx context.Context) (int, error) {
+	deposits, err := service.Repo.FindPending(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	resolved := 0
+	for _, deposit := range deposits {
+		result, err := service.chargeStatus(ctx, deposit)
+		if err != nil {
+			log.Errorf("Failed to get the payment status of deposit %d: %v", deposit.ID, err)
+			continue
+		}
+		if result.Status == "pending" {
+			if result.Reference != "" && result.Reference != deposit.ProviderReference {
+				if err := service.apply(ctx, deposit, result); err != nil {
+					log.Errorf("Failed to record the charge of deposit %d: %v", deposit.ID, err)
+				}
+			}
+			continue
+		}
+
+		if err := service.apply(ctx, deposit, result); err != nil {
+			log.Errorf("Failed to resolve deposit %d: %v", deposit.ID, err)
+			continue
+		}
+		resolved++
+	}
+
+	return resolved, nil
+}
+
+// chargeStatus asks the payment provider about the charge of the deposit.
+func (service *DepositService) chargeStatus(ctx context.Context, deposit *models.Deposit) (*models.PaymentResult, error) {
+	if deposit.ProviderReference == "" {
+		return service.Provider.Charge(ctx, deposit)
+	}
+	return service.Provider.Status(ctx, deposit.ProviderReference)
+}
+
+// apply records the answer of the payment provider. An approved payment settles the
+// deposit and posts it to the ledger in the same transaction.
+func (service *DepositService) apply(ctx context.Context, deposit *models.Deposit, result *models.PaymentResult) error {
+	if result.Reference != "" {
+		deposit.ProviderReference = result.Reference
+	}
+
+	switch result.Status {
+	case "approved":
+		return service.UnitOfWork.Execute(ctx, func(repos ports.Repositories) error {
+			deposit.Status = "settled"
+			if err := repos.Deposits.UpdateDeposit(ctx, deposit); err != nil {
+				return err
+			}
+			return repos.Ledger.Post(ctx, depositEntry(deposit))
+		})
+	case "declined":
+		deposit.Status = "failed"
+		deposit.FailureReason = result.Reason
+	}
+
+	return service.Repo.UpdateDeposit(ctx, deposit)
+}
+
+var _ ports.DepositService = (*DepositService)(nil) // Ensure interface is implemented at compile time
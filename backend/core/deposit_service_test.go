@@ -0,0 +1,248 @@
+package core
+
+import (
+	"brokerx/models"
+	"brokerx/ports"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type MockDepositRepo struct {
+	mock.Mock
+}
+
+func (m *MockDepositRepo) CreateDeposit(ctx context.Context, deposit *models.Deposit) (int, error) {
+	args := m.Called(ctx, deposit)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockDepositRepo) UpdateDeposit(ctx context.Context, deposit *models.Deposit) error {
+	args := m.Called(ctx, deposit)
+	return args.Error(0)
+}
+
+func (m *MockDepositRepo) FindById(ctx context.Context, id int) (*models.Deposit, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Deposit), args.Error(1)
+}
+
+func (m *MockDepositRepo) FindByUserId(ctx context.Context, userId string) ([]*models.Deposit, error) {
+	args := m.Called(ctx, userId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Deposit), args.Error(1)
+}
+
+func (m *MockDepositRepo) FindPending(ctx context.Context) ([]*models.Deposit, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Deposit), args.Error(1)
+}
+
+type MockPaymentProvider struct {
+	mock.Mock
+}
+
+func (m *MockPaymentProvider) Charge(ctx context.Context, deposit *models.Deposit) (*models.PaymentResult, error) {
+	args := m.Called(ctx, deposit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.PaymentResult), args.Error(1)
+}
+
+func (m *MockPaymentProvider) Payout(ctx context.Context, withdrawal *models.Withdrawal) (*models.PaymentResult, error) {
+	args := m.Called(ctx, withdrawal)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.PaymentResult), args.Error(1)
+}
+
+func (m *MockPaymentProvider) Status(ctx context.Context, reference string) (*models.PaymentResult, error) {
+	args := m.Called(ctx, reference)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.PaymentResult), args.Error(1)
+}
+
+// ---------------------------
+// Test Suite
+// ---------------------------
+
+type DepositServiceTestSuite struct {
+	suite.Suite
+	repo       *MockDepositRepo
+	ledgerRepo *MockLedgerRepo
+	provider   *MockPaymentProvider
+	service    *DepositService
+	UserID     string
+}
+
+func (s *DepositServiceTestSuite) SetupTest() {
+	s.repo = new(MockDepositRepo)
+	s.ledgerRepo = new(MockLedgerRepo)
+	s.provider = new(MockPaymentProvider)
+	s.service = &DepositService{
+		Repo:       s.repo,
+		UnitOfWork: &MockUnitOfWork{repos: ports.Repositories{Deposits: s.repo, Ledger: s.ledgerRepo}},
+		Provider:   s.provider,
+	}
+	s.UserID = "user"
+}
+
+// ---------------------------
+// Tests
+// ---------------------------
+
+func (s *DepositServiceTestSuite) TestInitiateDepositApprovedCreditsWallet() {
+	s.repo.On("CreateDeposit", mock.Anything, mock.Anything).Return(4, nil)
+	s.provider.On("Charge", mock.Anything, mock.Anything).Return(&models.PaymentResult{Reference: "ref-4", Status: "approved"}, nil)
+	s.repo.On("UpdateDeposit", mock.Anything, mock.Anything).Return(nil)
+	s.ledgerRepo.On("Post", mock.Anything, entryMoving("deposit", s.UserID, models.NewMoney(250))).Return(nil)
+
+	deposit, err := s.service.InitiateDeposit(context.Background(), s.UserID, models.NewMoney(250))
+
+	s.Require().NoError(err)
+	s.Equal(4, deposit.ID)
+	s.Equal("settled", deposit.Status)
+	s.Equal("ref-4", deposit.ProviderReference)
+	s.repo.AssertNumberOfCalls(s.T(), "UpdateDeposit", 1)
+	s.ledgerRepo.AssertExpectations(s.T())
+}
+
+func (s *DepositServiceTestSuite) TestInitiateDepositDeclined() {
+	s.repo.On("CreateDeposit", mock.Anything, mock.Anything).Return(4, nil)
+	s.provider.On("Charge", mock.Anything, mock.Anything).Return(&models.PaymentResult{Reference: "ref-4", Status: "declined", Reason: "card declined"}, nil)
+	s.repo.On("UpdateDeposit", mock.Anything, mock.Anything).Return(nil)
+
+	deposit, err := s.service.InitiateDeposit(context.Background(), s.UserID, models.NewMoney(250))
+
+	s.Require().NoError(err)
+	s.Equal("failed", deposit.Status)
+	s.Equal("card declined", deposit.FailureReason)
+	s.ledgerRepo.AssertNotCalled(s.T(), "Post", mock.Anything, mock.Anything)
+}
+
+func (s *DepositServiceTestSuite) TestInitiateDepositPending() {
+	s.repo.On("CreateDeposit", mock.Anything, mock.Anything).Return(4, nil)
+	s.provider.On("Charge", mock.Anything, mock.Anything).Return(&models.PaymentResult{Reference: "ref-4", Status: "pending"}, nil)
+	s.repo.On("UpdateDeposit", mock.Anything, mock.Anything).Return(nil)
+
+	deposit, err := s.service.InitiateDeposit(context.Background(), s.UserID, models.NewMoney(250))
+
+	s.Require().NoError(err)
+	s.Equal("pending", deposit.Status)
+	s.Equal("ref-4", deposit.ProviderReference)
+	s.ledgerRepo.AssertNotCalled(s.T(), "Post", mock.Anything, mock.Anything)
+}
+
+func (s *DepositServiceTestSuite) TestInitiateDepositProviderUnavailableStaysPending() {
+	s.repo.On("CreateDeposit", mock.Anything, mock.Anything).Return(4, nil)
+	s.provider.On("Charge", mock.Anything, mock.Anything).Return(nil, assert.AnError)
+
+	deposit, err := s.service.InitiateDeposit(context.Background(), s.UserID, models.NewMoney(250))
+
+	// The charge may have gone through, the next refresh finds out
+	s.Require().NoError(err)
+	s.Equal("pending", deposit.Status)
+	s.Empty(deposit.FailureReason)
+	s.repo.AssertNotCalled(s.T(), "UpdateDeposit", mock.Anything, mock.Anything)
+	s.ledgerRepo.AssertNotCalled(s.T(), "Post", mock.Anything, mock.Anything)
+}
+
+func (s *DepositServiceTestSuite) TestInitiateDepositInvalidAmount() {
+	for _, amount := range []models.Money{models.NewMoney(0), models.NewMoney(-10)} {
+		deposit, err := s.service.InitiateDeposit(context.Background(), s.UserID, amount)
+
+		s.Nil(deposit)
+		s.ErrorIs(err, ports.ErrInvalidAmount)
+	}
+	s.repo.AssertNotCalled(s.T(), "CreateDeposit", mock.Anything, mock.Anything)
+}
+
+func (s *DepositServiceTestSuite) TestGetDepositNotOwned() {
+	s.repo.On("FindById", mock.Anything, 4).Return(&models.Deposit{ID: 4, UserID: "other"}, nil)
+
+	deposit, err := s.service.GetDeposit(context.Background(), s.UserID, 4)
+
+	s.Nil(deposit)
+	s.ErrorIs(err, ports.ErrDepositNotOwned)
+}
+
+func (s *DepositServiceTestSuite) TestRefreshPendingDeposits() {
+	approved := &models.Deposit{ID: 1, UserID: s.UserID, Amount: models.NewMoney(100), Status: "pending", ProviderReference: "ref-1"}
+	declined := &models.Deposit{ID: 2, UserID: s.UserID, Amount: models.NewMoney(200), Status: "pending", ProviderReference: "ref-2"}
+	stillPending := &models.Deposit{ID: 3, UserID: s.UserID, Amount: models.NewMoney(300), Status: "pending", ProviderReference: "ref-3"}
+	failing := &models.Deposit{ID: 4, UserID: s.UserID, Amount: models.NewMoney(400), Status: "pending", ProviderReference: "ref-4"}
+	unanswered := &models.Deposit{ID: 5, UserID: s.UserID, Amount: models.NewMoney(500), Status: "pending"}
+	unreachable := &models.Deposit{ID: 6, UserID: s.UserID, Amount: models.NewMoney(600), Status: "pending"}
+	nowPending := &models.Deposit{ID: 7, UserID: s.UserID, Amount: models.NewMoney(700), Status: "pending"}
+	s.repo.On("FindPending", mock.Anything).Return([]*models.Deposit{approved, declined, stillPending, failing, unanswered, unreachable, nowPending}, nil)
+	s.provider.On("Status", mock.Anything, "ref-1").Return(&models.PaymentResult{Reference: "ref-1", Status: "approved"}, nil)
+	s.provider.On("Status", mock.Anything, "ref-2").Return(&models.PaymentResult{Reference: "ref-2", Status: "declined", Reason: "card declined"}, nil)
+	s.provider.On("Status", mock.Anything, "ref-3").Return(&models.PaymentResult{Reference: "ref-3", Status: "pending"}, nil)
+	s.provider.On("Status", mock.Anything, "ref-4").Return(nil, assert.AnError)
+	s.provider.On("Charge", mock.Anything, unanswered).Return(&models.PaymentResult{Reference: "ref-5", Status: "approved"}, nil)
+	s.provider.On("Charge", mock.Anything, unreachable).Return(nil, assert.AnError)
+	s.provider.On("Charge", mock.Anything, nowPending).Return(&models.PaymentResult{Reference: "ref-7", Status: "pending"}, nil)
+	s.repo.On("UpdateDeposit", mock.Anything, mock.Anything).Return(nil)
+	s.ledgerRepo.On("Post", mock.Anything, entryMoving("deposit", s.UserID, models.NewMoney(100))).Return(nil)
+	s.ledgerRepo.On("Post", mock.Anything, entryMoving("deposit", s.UserID, models.NewMoney(500))).Return(nil)
+
+	resolved, err := s.service.RefreshPendingDeposits(context.Background())
+
+	s.Require().NoError(err)
+	s.Equal(3, resolved)
+	s.Equal("settled", approved.Status)
+	s.Equal("failed", declined.Status)
+	s.Equal("pending", stillPending.Status)
+	s.Equal("pending", failing.Status)
+	s.Equal("settled", unanswered.Status)
+	s.Equal("pending", unreachable.Status)
+	s.Equal("pending", nowPending.Status)
+	s.Equal("ref-7", nowPending.ProviderReference)
+	s.repo.AssertNumberOfCalls(s.T(), "UpdateDeposit", 4)
+	s.ledgerRepo.AssertNumberOfCalls(s.T(), "Post", 2)
+	s.provider.AssertNotCalled(s.T(), "Status", mock.Anything, "")
+}
+
+func (s *DepositServiceTestSuite) TestRefreshPendingDepositsAlreadySettled() {
+	deposit := &models.Deposit{ID: 1, UserID: s.UserID, Amount: models.NewMoney(100), Status: "pending", ProviderReference: "ref-1"}
+	s.repo.On("FindPending", mock.Anything).Return([]*models.Deposit{deposit}, nil)
+	s.provider.On("Status", mock.Anything, "ref-1").Return(&models.PaymentResult{Reference: "ref-1", Status: "approved"}, nil)
+	s.repo.On("UpdateDeposit", mock.Anything, mock.Anything).Return(ports.ErrDepositNotPending)
+
+	resolved, err := s.service.RefreshPendingDeposits(context.Background())
+
+	s.Require().NoError(err)
+	s.Equal(0, resolved)
+	s.ledgerRepo.AssertNotCalled(s.T(), "Post", mock.Anything, mock.Anything)
+}
+
+func (s *DepositServiceTestSuite) TestRefreshPendingDepositsFailure() {
+	s.repo.On("FindPending", mock.Anything).Return(nil, assert.AnError)
+
+	resolved, err := s.service.RefreshPendingDeposits(context.Background())
+
+	s.Equal(0, resolved)
+	s.ErrorIs(err, assert.AnError)
+}
+
+// ---------------------------
+// Run the suite
+// ---------------------------
+func TestDepositServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(DepositServiceTestSuite))
+}
@@ -0,0 +1,96 @@
+package core
+
+import (
+	"brokerx/ports"
+	"context"
+	"errors"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// ExpiryScheduler expires DAY orders at the close of every trading session and GTD orders
+// once their expiry has passed.
+type ExpiryScheduler struct {
+	Service ports.OrderService
+	SessionClose string // HH:MM in the server's local time
+	GoodTillDateInterval time.Duration
+}
+
+// Start checks the session close time and expires orders in the background until ctx is
+// done. Orders left over from a session that closed while the server was down are
+// expired right away.
+func (scheduler *ExpiryScheduler) Start(ctx context.Context) error {
+	sessionClose, err := time.Parse("15:04", scheduler.SessionClose)
+	if err != nil {
+		return err
+	}
+	if scheduler.GoodTillDateInterval <= 0 {
+		return errors.New("good till date interval must be positive")
+	}
+
+	go scheduler.expireGoodTillDateOrders(ctx)
+	go func() {
+		scheduler.expire(ctx, previousSessionClose(time.Now(), sessionClose))
+
+		for {
+			next := nextSessionClose(time.Now(), sessionClose)
+			timer := time.NewTimer(time.Until(next))
+
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+				scheduler.expire(ctx, next)
+			}
+		}
+	}()
+
+	return nil
+}
+
+func (scheduler *ExpiryScheduler) expireGoodTillDateOrders(ctx context.Context) {
+	ticker := time.NewTicker(scheduler.GoodTillDateInterval)
+	defer ticker.Stop()
+
+	for {
+		expired, err := scheduler.Service.ExpireGoodTillDateOrders(ctx, time.Now())
+		if err != nil {
+			log.Errorf("Failed to expire GTD orders: %v", err)
+		} else if expired > 0 {
+			log.Infof("Expired %d GTD orders", expired)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (scheduler *ExpiryScheduler) expire(ctx context.Context, sessionClose time.Time) {
+	expired, err := scheduler.Service.ExpireDayOrders(ctx, sessionClose)
+	if err != nil {
+		log.Errorf("Failed to expire DAY orders of session closing at %s: %v", sessionClose, err)
+		return
+	}
+	if expired > 0 {
+		log.Infof("Expired %d DAY orders of session closing at %s", expired, sessionClose)
+	}
+}
+
+// nextSessionClose is the first session close strictly after now.
+func nextSessionClose(now time.Time, sessionClose time.Time) time.Time {
+	next := time.Date(now.Year(), now.Month(), now.Day(), sessionClose.Hour(), sessionClose.Minute(), 0, 0, now.Location())
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
+
+// previousSessionClose is the last session close at or before now.
+func previousSessionClose(now time.Time, sessionClose time.Time) time.Time {
+	return nextSessionClose(now, sessionClose).AddDate(0, 0, -1)
+}
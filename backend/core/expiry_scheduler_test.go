@@ -0,0 +1,48 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNextSessionClose(t *testing.T) {
+	sessionClose, _ := time.Parse("15:04", "16:00")
+
+	before := time.Date(2025, 10, 1, 9, 30, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2025, 10, 1, 16, 0, 0, 0, time.UTC), nextSessionClose(before, sessionClose))
+
+	atClose := time.Date(2025, 10, 1, 16, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2025, 10, 2, 16, 0, 0, 0, time.UTC), nextSessionClose(atClose, sessionClose))
+
+	after := time.Date(2025, 10, 1, 18, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2025, 10, 2, 16, 0, 0, 0, time.UTC), nextSessionClose(after, sessionClose))
+}
+
+func TestPreviousSessionClose(t *testing.T) {
+	sessionClose, _ := time.Parse("15:04", "16:00")
+
+	before := time.Date(2025, 10, 1, 9, 30, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2025, 9, 30, 16, 0, 0, 0, time.UTC), previousSessionClose(before, sessionClose))
+
+	atClose := time.Date(2025, 10, 1, 16, 0, 0, 0, time.UTC)
+	assert.Equal(t, atClose, previousSessionClose(atClose, sessionClose))
+}
+
+func TestExpirySchedulerInvalidSessionClose(t *testing.T) {
+	scheduler := &ExpiryScheduler{SessionClose: "4pm", GoodTillDateInterval: time.Minute}
+
+	err := scheduler.Start(context.Background())
+
+	assert.Error(t, err)
+}
+
+func TestExpirySchedulerInvalidGoodTillDateInterval(t *testing.T) {
+	scheduler := &ExpiryScheduler{SessionClose: "16:00"}
+
+	err := scheduler.Start(context.Background())
+
+	assert.Error(t, err)
+}
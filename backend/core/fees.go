@@ -0,0 +1,100 @@
+package core
+
+import (
+	"brokerx/models"
+)
+
+// The commission of an order is worked out on everything it has filled so far, so that
+// its per-order fee and its minimum and maximum apply once to the whole order. Each fill
+// is charged what it adds to the commission of the order. A buy order also holds the
+// commission that its unfilled quantity can still cost at its limit price.
+
+// fillState is the quantity and the notional an order has filled.
+type fillState struct {
+	quantity int
+	notional models.Money
+}
+
+func currentFillState(order *models.Order) *fillState {
+	return &fillState{quantity: order.FilledQuantity, notional: order.AverageFillPrice.Mul(order.FilledQuantity)}
+}
+
+// fillStatesBeforeMatch returns what the orders of a match had filled before it. The
+// engine has already applied the fills of the match to the orders, so they are taken
+// back off their totals.
+func fillStatesBeforeMatch(orders map[int]*models.Order, executions []*models.Execution) map[int]*fillState {
+	states := make(map[int]*fillState, len(orders))
+	for id, order := range orders {
+		states[id] = currentFillState(order)
+	}
+	for _, execution := range executions {
+		for _, id := range []int{execution.BuyOrderID, execution.SellOrderID} {
+			states[id].quantity -= execution.Quantity
+			states[id].notional = states[id].notional.Sub(execution.Price.Mul(execution.Quantity))
+		}
+	}
+	return states
+}
+
+// chargeFees charges the commission of the fill to both orders and the regulatory fee to
+// the seller, and itemizes them on the execution. The fees of the seller are taken from
+// the proceeds of the fill and never exceed them. It returns what the fill consumes of
+// the funds held by the buy order: the fill at the limit price and the commission that
+// is no longer held.
+func chargeFees(fees models.FeeSchedule, buyOrder *models.Order, sellOrder *models.Order, execution *models.Execution, states map[int]*fillState) models.Money {
+	heldFees := buyOrder.FeesOnHold
+
+	execution.BuyCommission = chargeCommission(fees, buyOrder, states[buyOrder.ID], execution)
+	execution.SellCommission = chargeCommission(fees, sellOrder, states[sellOrder.ID], execution)
+	execution.RegulatoryFee = fees.RegulatoryFee(execution.Price.Mul(execution.Quantity), sellOrder.Currency)
+	capSellerFees(sellOrder, execution)
+
+	buyOrder.FeesOnHold = estimatedFees(fees, buyOrder, states[buyOrder.ID])
+	return buyOrder.UnitPrice.Mul(execution.Quantity).Add(heldFees).Sub(buyOrder.FeesOnHold)
+}
+
+// chargeCommission adds the fill to the state of the order and charges the order what the
+// fill adds to its commission.
+func chargeCommission(fees models.FeeSchedule, order *models.Order, state *fillState, execution *models.Execution) models.Money {
+	state.quantity += execution.Quantity
+	state.notional = state.notional.Add(execution.Price.Mul(execution.Quantity))
+
+	commission := fees.Commission(state.quantity, state.notional, order.Currency).Sub(order.Commission)
+	if commission.IsNegative() {
+		commission = models.Money{}
+	}
+	order.Commission = order.Commission.Add(commission)
+	return commission
+}
+
+// capSellerFees keeps the fees of the seller within the proceeds of the fill. The
+// regulatory fee is charged first; the commission left uncharged stays owed by the order
+// and is charged on its later fills.
+func capSellerFees(sellOrder *models.Order, execution *models.Execution) {
+	proceeds := execution.Price.Mul(execution.Quantity)
+	if execution.RegulatoryFee.GreaterThan(proceeds) {
+		execution.RegulatoryFee = proceeds
+	}
+
+	excess := execution.SellCommission.Add(execution.RegulatoryFee).Sub(proceeds)
+	if excess.IsPositive() {
+		execution.SellCommission = execution.SellCommission.Sub(excess)
+		sellOrder.Commission = sellOrder.Commission.Sub(excess)
+	}
+}
+
+// estimatedFees is the commission that the unfilled quantity of a buy order can still
+// cost when it fills at the limit price.
+func estimatedFees(fees models.FeeSchedule, order *models.Order, state *fillState) models.Money {
+	remaining := order.Quantity - state.quantity
+	if order.Action != "buy" || remaining <= 0 {
+		return models.Money{}
+	}
+
+	notional := state.notional.Add(order.UnitPrice.Mul(remaining))
+	estimate := fees.Commission(order.Quantity, notional, order.Currency).Sub(order.Commission)
+	if estimate.IsNegative() {
+		return models.Money{}
+	}
+	return estimate
+}
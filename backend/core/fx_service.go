@@ -0,0 +1,72 @@
+package core
+
+import (
+	"brokerx/models"
+	"brokerx/ports"
+	"context"
+	"math/big"
+)
+
+// rateDecimals is the number of decimal places a rate is applied and recorded with.
+const rateDecimals = 8
+
+type FXService struct {
+	Repo       ports.FXConversionRepository
+	UnitOfWork ports.UnitOfWork
+	Rates      models.ExchangeRates
+}
+
+// Convert exchanges an amount of the available funds of the user at the rate of the rate
+// table. The converted amount is rounded down to the minor unit of the target currency, so
+// the broker never pays out more than the rate allows. The conversion and its journal
+// entry are recorded together, the entry is what shows the conversion in the cash history
+// of the user.
+func (service *FXService) Convert(ctx context.Context, userID string, fromCurrency string, toCurrency string, amount models.Money) (*models.FXConversion, error) {
+	if !models.IsSupportedCurrency(fromCurrency) || !models.IsSupportedCurrency(toCurrency) {
+		return nil, ports.ErrUnsupportedCurrency
+	}
+	if !isCurrencyAmount(amount, fromCurrency) {
+		return nil, ports.ErrInvalidAmount
+	}
+
+	rate, ok := service.Rates.Rate(fromCurrency, toCurrency)
+	if !ok {
+		return nil, ports.ErrRateNotFound
+	}
+	rate, _ = new(big.Rat).SetString(rate.FloatString(rateDecimals))
+
+	converted := amount.MulRate(rate, models.RoundDown).RoundToCurrency(toCurrency, models.RoundDown)
+	if !converted.IsPositive() {
+		return nil, ports.ErrInvalidAmount
+	}
+
+	conversion := &models.FXConversion{
+		UserID:          userID,
+		FromCurrency:    fromCurrency,
+		ToCurrency:      toCurrency,
+		Amount:          amount,
+		ConvertedAmount: converted,
+		Rate:            rate.FloatString(rateDecimals),
+	}
+	err := service.UnitOfWork.Execute(ctx, func(repos ports.Repositories) error {
+		id, err := repos.FXConversions.CreateConversion(ctx, conversion)
+		if err != nil {
+			return err
+		}
+		conversion.ID = id
+
+		return repos.Ledger.Post(ctx, fxConversionEntry(conversion))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return conversion, nil
+}
+
+// ListConversions returns the conversions of the user, most recent first.
+func (service *FXService) ListConversions(ctx context.Context, userID string) ([]*models.FXConversion, error) {
+	return service.Repo.FindByUserId(ctx, userID)
+}
+
+var _ ports.FXService = (*FXService)(nil) // Ensure interface is implemented at compile time
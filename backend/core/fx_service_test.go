@@ -0,0 +1,150 @@
+package core
+
+import (
+	"brokerx/models"
+	"brokerx/ports"
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type MockFXConversionRepo struct {
+	mock.Mock
+}
+
+func (m *MockFXConversionRepo) CreateConversion(ctx context.Context, conversion *models.FXConversion) (int, error) {
+	args := m.Called(ctx, conversion)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockFXConversionRepo) FindByUserId(ctx context.Context, userId string) ([]*models.FXConversion, error) {
+	args := m.Called(ctx, userId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.FXConversion), args.Error(1)
+}
+
+// ---------------------------
+// Test Suite
+// ---------------------------
+
+type FXServiceTestSuite struct {
+	suite.Suite
+	repo       *MockFXConversionRepo
+	ledgerRepo *MockLedgerRepo
+	service    *FXService
+	UserID     string
+}
+
+func (s *FXServiceTestSuite) SetupTest() {
+	s.repo = new(MockFXConversionRepo)
+	s.ledgerRepo = new(MockLedgerRepo)
+	s.service = &FXService{
+		Repo:       s.repo,
+		UnitOfWork: &MockUnitOfWork{repos: ports.Repositories{FXConversions: s.repo, Ledger: s.ledgerRepo}},
+		Rates:      models.ExchangeRates{"USD/CAD": big.NewRat(137, 100), "USD/JPY": big.NewRat(150, 1)},
+	}
+	s.UserID = "user"
+}
+
+// ---------------------------
+// Tests
+// ---------------------------
+
+func (s *FXServiceTestSuite) TestConvertPostsTheConversion() {
+	s.repo.On("CreateConversion", mock.Anything, mock.Anything).Return(5, nil)
+	s.ledgerRepo.On("Post", mock.Anything, mock.MatchedBy(func(entry *models.JournalEntry) bool {
+		return entry.Type == "fx_conversion" && entry.Reference == "fx_conversion:5" && len(entry.Postings) == 4
+	})).Return(nil)
+
+	conversion, err := s.service.Convert(context.Background(), s.UserID, "USD", "CAD", models.NewMoney(100))
+
+	s.Require().NoError(err)
+	s.Equal(5, conversion.ID)
+	s.Equal(models.NewMoney(137), conversion.ConvertedAmount)
+	s.Equal("1.37000000", conversion.Rate)
+	s.ledgerRepo.AssertExpectations(s.T())
+}
+
+func (s *FXServiceTestSuite) TestConvertRoundsDownToTheTargetCurrency() {
+	s.repo.On("CreateConversion", mock.Anything, mock.Anything).Return(6, nil)
+	s.ledgerRepo.On("Post", mock.Anything, mock.Anything).Return(nil)
+
+	// The inverse of 1.37 is 0.72992701 to eight places, 100 CAD buy 72.992701 USD
+	conversion, err := s.service.Convert(context.Background(), s.UserID, "CAD", "USD", models.NewMoney(100))
+
+	s.Require().NoError(err)
+	s.Equal("0.72992701", conversion.Rate)
+	s.Equal(models.MustParseMoney("72.99"), conversion.ConvertedAmount)
+
+	conversion, err = s.service.Convert(context.Background(), s.UserID, "USD", "JPY", models.MustParseMoney("10.01"))
+
+	s.Require().NoError(err)
+	s.Equal(models.NewMoney(1501), conversion.ConvertedAmount)
+}
+
+func (s *FXServiceTestSuite) TestConvertInsufficientFunds() {
+	s.repo.On("CreateConversion", mock.Anything, mock.Anything).Return(7, nil)
+	s.ledgerRepo.On("Post", mock.Anything, mock.Anything).Return(ports.ErrInsufficientFunds)
+
+	conversion, err := s.service.Convert(context.Background(), s.UserID, "USD", "CAD", models.NewMoney(100))
+
+	s.Nil(conversion)
+	s.ErrorIs(err, ports.ErrInsufficientFunds)
+}
+
+func (s *FXServiceTestSuite) TestConvertRejectsInvalidRequests() {
+	cases := []struct {
+		from     string
+		to       string
+		amount   models.Money
+		expected error
+	}{
+		{"USD", "XYZ", models.NewMoney(100), ports.ErrUnsupportedCurrency},
+		{"USD", "USD", models.NewMoney(100), ports.ErrRateNotFound},
+		{"USD", "EUR", models.NewMoney(100), ports.ErrRateNotFound},
+		{"USD", "CAD", models.Money{}, ports.ErrInvalidAmount},
+		{"USD", "CAD", models.MustParseMoney("10.001"), ports.ErrInvalidAmount},
+		{"JPY", "USD", models.MustParseMoney("1.5"), ports.ErrInvalidAmount},
+		// One yen is worth less than a cent
+		{"JPY", "USD", models.NewMoney(1), ports.ErrInvalidAmount},
+	}
+	for _, c := range cases {
+		conversion, err := s.service.Convert(context.Background(), s.UserID, c.from, c.to, c.amount)
+
+		s.Nil(conversion)
+		s.ErrorIs(err, c.expected, "%s to %s", c.from, c.to)
+	}
+	s.repo.AssertNotCalled(s.T(), "CreateConversion", mock.Anything, mock.Anything)
+}
+
+func (s *FXServiceTestSuite) TestListConversions() {
+	conversions := []*models.FXConversion{{ID: 2, UserID: s.UserID}}
+	s.repo.On("FindByUserId", mock.Anything, s.UserID).Return(conversions, nil)
+
+	result, err := s.service.ListConversions(context.Background(), s.UserID)
+
+	s.Require().NoError(err)
+	s.Equal(conversions, result)
+}
+
+func (s *FXServiceTestSuite) TestListConversionsFailure() {
+	s.repo.On("FindByUserId", mock.Anything, s.UserID).Return(nil, assert.AnError)
+
+	result, err := s.service.ListConversions(context.Background(), s.UserID)
+
+	s.Nil(result)
+	s.ErrorIs(err, assert.AnError)
+}
+
+// ---------------------------
+// Run the suite
+// ---------------------------
+func TestFXServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(FXServiceTestSuite))
+}
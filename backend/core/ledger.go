@@ -0,0 +1,132 @@
+package core
+
+import (
+	"brokerx/models"
+	"fmt"
+)
+
+// The builders below describe every movement of cash as a balanced journal entry. A
+// deposit is credited to the available funds of the user and debited from the cash the
+// broker holds at the payment provider; a withdrawal does the opposite from the funds on
+// hold. Deposits and withdrawals are in the base currency, orders and their fees are in
+// the trading currency of their symbol.
+
+func depositEntry(deposit *models.Deposit) *models.JournalEntry {
+	return &models.JournalEntry{Type: "deposit", Reference: fmt.Sprintf("deposit:%d", deposit.ID), Postings: []models.LedgerPosting{
+		{Account: "available", UserID: deposit.UserID, Currency: models.BaseCurrency, Amount: deposit.Amount},
+		{Account: "payment_provider", Currency: models.BaseCurrency, Amount: deposit.Amount.Neg()},
+	}}
+}
+
+func withdrawalEntry(withdrawal *models.Withdrawal) *models.JournalEntry {
+	return &models.JournalEntry{Type: "withdrawal", Reference: withdrawalReference(withdrawal), Postings: []models.LedgerPosting{
+		{Account: "on_hold", UserID: withdrawal.UserID, Currency: models.BaseCurrency, Amount: withdrawal.Amount.Neg()},
+		{Account: "payment_provider", Currency: models.BaseCurrency, Amount: withdrawal.Amount},
+	}}
+}
+
+// holdEntry moves funds of the user from available to on hold.
+func holdEntry(userID string, currency string, amount models.Money, reference string) *models.JournalEntry {
+	return &models.JournalEntry{Type: "hold", Reference: reference, Postings: []models.LedgerPosting{
+		{Account: "available", UserID: userID, Currency: currency, Amount: amount.Neg()},
+		{Account: "on_hold", UserID: userID, Currency: currency, Amount: amount},
+	}}
+}
+
+// releaseEntry moves funds of the user from on hold back to available.
+func releaseEntry(userID string, currency string, amount models.Money, reference string) *models.JournalEntry {
+	return &models.JournalEntry{Type: "release", Reference: reference, Postings: []models.LedgerPosting{
+		{Account: "on_hold", UserID: userID, Currency: currency, Amount: amount.Neg()},
+		{Account: "available", UserID: userID, Currency: currency, Amount: amount},
+	}}
+}
+
+// tradeEntry consumes the funds the buy order held for the fill, returns to the buyer
+// what was held above the cost of the fill and credits the cost of the fill to the
+// unsettled funds of the seller.
+func tradeEntry(buyOrder *models.Order, sellOrder *models.Order, execution *models.Execution, heldAmount models.Money) *models.JournalEntry {
+	cost := execution.Price.Mul(execution.Quantity)
+	currency := buyOrder.Currency
+
+	postings := []models.LedgerPosting{{Account: "on_hold", UserID: buyOrder.UserID, Currency: currency, Amount: heldAmount.Neg()}}
+	if heldAmount != cost {
+		postings = append(postings, models.LedgerPosting{Account: "available", UserID: buyOrder.UserID, Currency: currency, Amount: heldAmount.Sub(cost)})
+	}
+	postings = append(postings, models.LedgerPosting{Account: "unsettled", UserID: sellOrder.UserID, Currency: currency, Amount: cost})
+
+	return &models.JournalEntry{Type: "trade", Reference: executionReference(execution), Postings: postings}
+}
+
+// feeEntry charges the fees itemized on the execution to the available funds of the
+// buyer and to the unsettled proceeds of the seller. The commissions are revenue of the
+// broker, the regulatory fee is owed to the regulator. It returns nil when the fill is
+// free.
+func feeEntry(buyOrder *models.Order, sellOrder *models.Order, execution *models.Execution) *models.JournalEntry {
+	currency := buyOrder.Currency
+	commissions := execution.BuyCommission.Add(execution.SellCommission)
+	sellerFees := execution.SellCommission.Add(execution.RegulatoryFee)
+
+	var postings []models.LedgerPosting
+	if execution.BuyCommission.IsPositive() {
+		postings = append(postings, models.LedgerPosting{Account: "available", UserID: buyOrder.UserID, Currency: currency, Amount: execution.BuyCommission.Neg()})
+	}
+	if sellerFees.IsPositive() {
+		postings = append(postings, models.LedgerPosting{Account: "unsettled", UserID: sellOrder.UserID, Currency: currency, Amount: sellerFees.Neg()})
+	}
+	if commissions.IsPositive() {
+		postings = append(postings, models.LedgerPosting{Account: "fee_revenue", Currency: currency, Amount: commissions})
+	}
+	if execution.RegulatoryFee.IsPositive() {
+		postings = append(postings, models.LedgerPosting{Account: "regulatory_fees", Currency: currency, Amount: execution.RegulatoryFee})
+	}
+	if len(postings) == 0 {
+		return nil
+	}
+
+	return &models.JournalEntry{Type: "fee", Reference: executionReference(execution), Postings: postings}
+}
+
+// settlementEntry moves the proceeds of the fill, net of the fees of the seller, from
+// the unsettled funds of the seller to the available funds. It returns nil when the fees
+// took all of the proceeds.
+func settlementEntry(sellOrder *models.Order, execution *models.Execution) *models.JournalEntry {
+	proceeds := execution.Price.Mul(execution.Quantity).Sub(execution.SellCommission).Sub(execution.RegulatoryFee)
+	if !proceeds.IsPositive() {
+		return nil
+	}
+
+	return &models.JournalEntry{Type: "settlement", Reference: executionReference(execution), Postings: []models.LedgerPosting{
+		{Account: "unsettled", UserID: sellOrder.UserID, Currency: sellOrder.Currency, Amount: proceeds.Neg()},
+		{Account: "available", UserID: sellOrder.UserID, Currency: sellOrder.Currency, Amount: proceeds},
+	}}
+}
+
+// fxConversionEntry exchanges the available funds of the user through the fx account of
+// the broker, which takes the amount in one currency and pays the converted amount in
+// the other.
+func fxConversionEntry(conversion *models.FXConversion) *models.JournalEntry {
+	return &models.JournalEntry{Type: "fx_conversion", Reference: fmt.Sprintf("fx_conversion:%d", conversion.ID), Postings: []models.LedgerPosting{
+		{Account: "available", UserID: conversion.UserID, Currency: conversion.FromCurrency, Amount: conversion.Amount.Neg()},
+		{Account: "fx", Currency: conversion.FromCurrency, Amount: conversion.Amount},
+		{Account: "fx", Currency: conversion.ToCurrency, Amount: conversion.ConvertedAmount.Neg()},
+		{Account: "available", UserID: conversion.UserID, Currency: conversion.ToCurrency, Amount: conversion.ConvertedAmount},
+	}}
+}
+
+func orderReference(order *models.Order) string {
+	return fmt.Sprintf("order:%d", order.ID)
+}
+
+func executionReference(execution *models.Execution) string {
+	return fmt.Sprintf("execution:%d", execution.ID)
+}
+
+func withdrawalReference(withdrawal *models.Withdrawal) string {
+	return fmt.Sprintf("withdrawal:%d", withdrawal.ID)
+}
+
+// isCurrencyAmount tells whether the amount is positive and in whole minor units of the
+// currency. Wallets and limit prices never hold a fraction of a cent.
+func isCurrencyAmount(amount models.Money, currency string) bool {
+	return amount.IsPositive() && amount.FitsCurrency(currency)
+}
@@ -0,0 +1,65 @@
+package core
+
+import (
+	"brokerx/models"
+	"brokerx/ports"
+	"context"
+)
+
+type LedgerService struct {
+	LedgerRepo ports.LedgerRepository
+	WalletRepo ports.WalletRepository
+}
+
+// ListEntries returns the journal entries that moved the cash of the user, most recent first.
+func (service *LedgerService) ListEntries(ctx context.Context, userID string) ([]*models.JournalEntry, error) {
+	return service.LedgerRepo.FindEntriesByUserId(ctx, userID)
+}
+
+// ListWallets returns the balances of the user, one wallet per currency.
+func (service *LedgerService) ListWallets(ctx context.Context, userID string) ([]*models.Wallet, error) {
+	return service.WalletRepo.FindByUserId(ctx, userID)
+}
+
+// Reconcile compares the balances stored on the wallets with the balances derived from
+// the ledger, currency by currency. A wallet without any posting is expected to be empty.
+func (service *LedgerService) Reconcile(ctx context.Context) ([]*models.WalletDiscrepancy, error) {
+	wallets, err := service.WalletRepo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	balances, err := service.LedgerRepo.FindBalances(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ledger := make(map[string]*models.LedgerBalance, len(balances))
+	for _, balance := range balances {
+		ledger[balance.UserID+"/"+balance.Currency] = balance
+	}
+
+	discrepancies := []*models.WalletDiscrepancy{}
+	for _, wallet := range wallets {
+		balance, ok := ledger[wallet.UserId+"/"+wallet.Currency]
+		if !ok {
+			balance = &models.LedgerBalance{UserID: wallet.UserId, Currency: wallet.Currency}
+		}
+
+		if wallet.AvailableFunds != balance.AvailableFunds || wallet.OnHoldFunds != balance.OnHoldFunds || wallet.UnsettledFunds != balance.UnsettledFunds {
+			discrepancies = append(discrepancies, &models.WalletDiscrepancy{
+				UserID:               wallet.UserId,
+				Currency:             wallet.Currency,
+				StoredAvailableFunds: wallet.AvailableFunds,
+				LedgerAvailableFunds: balance.AvailableFunds,
+				StoredOnHoldFunds:    wallet.OnHoldFunds,
+				LedgerOnHoldFunds:    balance.OnHoldFunds,
+				StoredUnsettledFunds: wallet.UnsettledFunds,
+				LedgerUnsettledFunds: balance.UnsettledFunds,
+			})
+		}
+	}
+
+	return discrepancies, nil
+}
+
+var _ ports.LedgerService = (*LedgerService)(nil) // Ensure interface is implemented at compile time
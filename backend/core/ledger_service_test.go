@@ -0,0 +1,213 @@
+package core
+
+import (
+	"brokerx/models"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type MockLedgerRepo struct {
+	mock.Mock
+}
+
+func (m *MockLedgerRepo) Post(ctx context.Context, entry *models.JournalEntry) error {
+	args := m.Called(ctx, entry)
+	return args.Error(0)
+}
+
+func (m *MockLedgerRepo) FindEntriesByUserId(ctx context.Context, userId string) ([]*models.JournalEntry, error) {
+	args := m.Called(ctx, userId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.JournalEntry), args.Error(1)
+}
+
+func (m *MockLedgerRepo) FindBalances(ctx context.Context) ([]*models.LedgerBalance, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.LedgerBalance), args.Error(1)
+}
+
+// ---------------------------
+// Test Suite
+// ---------------------------
+
+type LedgerServiceTestSuite struct {
+	suite.Suite
+	ledgerRepo *MockLedgerRepo
+	walletRepo *MockWalletRepo
+	service    *LedgerService
+}
+
+func (s *LedgerServiceTestSuite) SetupTest() {
+	s.ledgerRepo = new(MockLedgerRepo)
+	s.walletRepo = new(MockWalletRepo)
+	s.service = &LedgerService{LedgerRepo: s.ledgerRepo, WalletRepo: s.walletRepo}
+}
+
+// ---------------------------
+// Tests
+// ---------------------------
+
+func (s *LedgerServiceTestSuite) TestListWallets() {
+	wallets := []*models.Wallet{{UserId: "user", Currency: "CAD", AvailableFunds: models.NewMoney(70)}, {UserId: "user", Currency: "USD"}}
+	s.walletRepo.On("FindByUserId", mock.Anything, "user").Return(wallets, nil)
+
+	result, err := s.service.ListWallets(context.Background(), "user")
+
+	s.Require().NoError(err)
+	s.Equal(wallets, result)
+}
+
+func (s *LedgerServiceTestSuite) TestReconcileFlagsDisagreeingWallets() {
+	s.walletRepo.On("FindAll", mock.Anything).Return([]*models.Wallet{
+		{UserId: "balanced", Currency: "CAD", AvailableFunds: models.NewMoney(75)},
+		{UserId: "balanced", Currency: "USD", AvailableFunds: models.NewMoney(600), OnHoldFunds: models.NewMoney(400)},
+		{UserId: "drifted", Currency: "USD", AvailableFunds: models.NewMoney(1000), OnHoldFunds: models.NewMoney(0)},
+		{UserId: "empty", Currency: "USD"},
+		{UserId: "unposted", Currency: "CAD", AvailableFunds: models.NewMoney(50)},
+		{UserId: "unsettled", Currency: "USD", UnsettledFunds: models.NewMoney(200)},
+	}, nil)
+	s.ledgerRepo.On("FindBalances", mock.Anything).Return([]*models.LedgerBalance{
+		{UserID: "balanced", Currency: "CAD", AvailableFunds: models.NewMoney(75)},
+		{UserID: "balanced", Currency: "USD", AvailableFunds: models.NewMoney(600), OnHoldFunds: models.NewMoney(400)},
+		{UserID: "drifted", Currency: "USD", AvailableFunds: models.NewMoney(900), OnHoldFunds: models.NewMoney(100)},
+		{UserID: "unposted", Currency: "USD", AvailableFunds: models.NewMoney(50)},
+		{UserID: "unsettled", Currency: "USD", AvailableFunds: models.NewMoney(200)},
+	}, nil)
+
+	discrepancies, err := s.service.Reconcile(context.Background())
+
+	s.Require().NoError(err)
+	s.Require().Len(discrepancies, 3)
+	s.Equal(&models.WalletDiscrepancy{UserID: "drifted", Currency: "USD", StoredAvailableFunds: models.NewMoney(1000), LedgerAvailableFunds: models.NewMoney(900), StoredOnHoldFunds: models.NewMoney(0), LedgerOnHoldFunds: models.NewMoney(100)}, discrepancies[0])
+	s.Equal("unposted", discrepancies[1].UserID)
+	s.Equal("CAD", discrepancies[1].Currency)
+	s.Equal(models.NewMoney(0), discrepancies[1].LedgerAvailableFunds)
+	s.Equal(&models.WalletDiscrepancy{UserID: "unsettled", Currency: "USD", LedgerAvailableFunds: models.NewMoney(200), StoredUnsettledFunds: models.NewMoney(200)}, discrepancies[2])
+}
+
+func (s *LedgerServiceTestSuite) TestReconcileFailure() {
+	s.walletRepo.On("FindAll", mock.Anything).Return([]*models.Wallet{}, nil)
+	s.ledgerRepo.On("FindBalances", mock.Anything).Return(nil, assert.AnError)
+
+	discrepancies, err := s.service.Reconcile(context.Background())
+
+	s.Nil(discrepancies)
+	s.ErrorIs(err, assert.AnError)
+}
+
+func (s *LedgerServiceTestSuite) TestTradeEntryBalances() {
+	buyOrder := &models.Order{ID: 1, UserID: "buyer", Currency: "CAD", UnitPrice: models.NewMoney(155)}
+	sellOrder := &models.Order{ID: 2, UserID: "seller", Currency: "CAD"}
+	execution := &models.Execution{ID: 7, Quantity: 10, Price: models.NewMoney(150)}
+
+	entry := tradeEntry(buyOrder, sellOrder, execution, models.NewMoney(1550))
+
+	s.Equal("trade", entry.Type)
+	s.Equal("execution:7", entry.Reference)
+	s.Equal([]models.LedgerPosting{
+		{Account: "on_hold", UserID: "buyer", Currency: "CAD", Amount: models.NewMoney(-1550)},
+		{Account: "available", UserID: "buyer", Currency: "CAD", Amount: models.NewMoney(50)},
+		{Account: "unsettled", UserID: "seller", Currency: "CAD", Amount: models.NewMoney(1500)},
+	}, entry.Postings)
+}
+
+func (s *LedgerServiceTestSuite) TestFeeEntryBalances() {
+	buyOrder := &models.Order{ID: 1, UserID: "buyer", Currency: "USD"}
+	sellOrder := &models.Order{ID: 2, UserID: "seller", Currency: "USD"}
+	execution := &models.Execution{ID: 7, BuyCommission: models.NewMoney(1), SellCommission: models.NewMoney(1), RegulatoryFee: models.MustParseMoney("0.05")}
+
+	entry := feeEntry(buyOrder, sellOrder, execution)
+
+	s.Equal("fee", entry.Type)
+	s.Equal("execution:7", entry.Reference)
+	s.Equal([]models.LedgerPosting{
+		{Account: "available", UserID: "buyer", Currency: "USD", Amount: models.NewMoney(-1)},
+		{Account: "unsettled", UserID: "seller", Currency: "USD", Amount: models.MustParseMoney("-1.05")},
+		{Account: "fee_revenue", Currency: "USD", Amount: models.NewMoney(2)},
+		{Account: "regulatory_fees", Currency: "USD", Amount: models.MustParseMoney("0.05")},
+	}, entry.Postings)
+
+	// A free fill posts nothing
+	s.Nil(feeEntry(buyOrder, sellOrder, &models.Execution{ID: 8}))
+}
+
+func (s *LedgerServiceTestSuite) TestSettlementEntryReleasesTheNetProceeds() {
+	sellOrder := &models.Order{ID: 2, UserID: "seller", Currency: "USD"}
+	execution := &models.Execution{ID: 7, Quantity: 10, Price: models.NewMoney(150), SellCommission: models.NewMoney(1), RegulatoryFee: models.MustParseMoney("0.05")}
+
+	entry := settlementEntry(sellOrder, execution)
+
+	s.Equal("settlement", entry.Type)
+	s.Equal("execution:7", entry.Reference)
+	s.Equal([]models.LedgerPosting{
+		{Account: "unsettled", UserID: "seller", Currency: "USD", Amount: models.MustParseMoney("-1498.95")},
+		{Account: "available", UserID: "seller", Currency: "USD", Amount: models.MustParseMoney("1498.95")},
+	}, entry.Postings)
+
+	// Fees that took all of the proceeds leave nothing to settle
+	s.Nil(settlementEntry(sellOrder, &models.Execution{ID: 8, Quantity: 1, Price: models.NewMoney(1), SellCommission: models.NewMoney(1)}))
+}
+
+func (s *LedgerServiceTestSuite) TestFXConversionEntryBalancesInEachCurrency() {
+	conversion := &models.FXConversion{ID: 3, UserID: "user", FromCurrency: "USD", ToCurrency: "CAD", Amount: models.NewMoney(100), ConvertedAmount: models.NewMoney(137)}
+
+	entry := fxConversionEntry(conversion)
+
+	s.Equal("fx_conversion", entry.Type)
+	s.Equal("fx_conversion:3", entry.Reference)
+	totals := map[string]models.Money{}
+	for _, posting := range entry.Postings {
+		totals[posting.Currency] = totals[posting.Currency].Add(posting.Amount)
+	}
+	s.Equal(map[string]models.Money{"USD": {}, "CAD": {}}, totals)
+	s.Contains(entry.Postings, models.LedgerPosting{Account: "available", UserID: "user", Currency: "USD", Amount: models.NewMoney(-100)})
+	s.Contains(entry.Postings, models.LedgerPosting{Account: "available", UserID: "user", Currency: "CAD", Amount: models.NewMoney(137)})
+}
+
+func TestReconciliationJobInvalidInterval(t *testing.T) {
+	job := &ReconciliationJob{Interval: 0}
+
+	err := job.Start(context.Background())
+
+	assert.Error(t, err)
+}
+
+func TestReconciliationJobReconcilesUntilDone(t *testing.T) {
+	reconciled := make(chan struct{}, 1)
+	walletRepo := new(MockWalletRepo)
+	walletRepo.On("FindAll", mock.Anything).Return(nil, assert.AnError).Run(func(args mock.Arguments) {
+		select {
+		case reconciled <- struct{}{}:
+		default:
+		}
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	job := &ReconciliationJob{Service: &LedgerService{WalletRepo: walletRepo}, Interval: time.Millisecond}
+
+	err := job.Start(ctx)
+
+	assert.NoError(t, err)
+	select {
+	case <-reconciled:
+	case <-time.After(time.Second):
+		t.Fatal("the wallets were never reconciled")
+	}
+}
+
+// ---------------------------
+// Run the suite
+// ---------------------------
+func TestLedgerServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(LedgerServiceTestSuite))
+}
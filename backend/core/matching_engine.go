@@ -0,0 +1,217 @@
+package core
+
+import (
+	"brokerx/models"
+	"brokerx/ports"
+	"sync"
+)
+
+type MatchingEngine struct {
+	mutex sync.Mutex
+	books map[string]*OrderBook
+	triggers map[string]*TriggerBook
+	lastPrices map[string]models.Money
+}
+
+// Submit matches the order against the book of its symbol with price-time priority.
+// It returns the resulting executions along with the other orders they touched.
+// Unfilled limit orders rest on the book, unfilled market, IOC and FOK orders are
+// canceled. A FOK order that the book cannot fill in full is canceled without matching.
+// Stop orders stay dormant on the trigger book until the last traded price crosses their
+// stop price, the stop orders triggered by the trades of a match are matched in turn.
+func (engine *MatchingEngine) Submit(order *models.Order) ([]*models.Execution, []*models.Order) {
+	engine.mutex.Lock()
+	defer engine.mutex.Unlock()
+
+	return engine.submit(order)
+}
+
+// Replace applies a modified quantity and price to a resting order. A quantity decrease
+// at the same price keeps the order's time priority, any other change removes the order
+// and submits it again as a new arrival.
+func (engine *MatchingEngine) Replace(order *models.Order) ([]*models.Execution, []*models.Order) {
+	engine.mutex.Lock()
+	defer engine.mutex.Unlock()
+
+	if engine.trigger(order.Symbol).Remove(order) {
+		return engine.submit(order)
+	}
+
+	book := engine.book(order.Symbol)
+	resting := book.Find(order)
+	if resting != nil && keepsPriority(resting, order) {
+		book.Swap(order)
+		return nil, nil
+	}
+
+	book.Remove(order)
+	return engine.submit(order)
+}
+
+// Cancel removes the order from the book or the trigger book of its symbol. It returns
+// false when the order was neither resting nor dormant.
+func (engine *MatchingEngine) Cancel(order *models.Order) bool {
+	engine.mutex.Lock()
+	defer engine.mutex.Unlock()
+
+	return engine.book(order.Symbol).Remove(order) || engine.trigger(order.Symbol).Remove(order)
+}
+
+// Load puts an active order back on the book of its symbol without matching it, or on its
+// trigger book for a stop order that is still dormant, as when the books are restored
+// after a restart. Orders must be loaded in their time priority. It returns false when
+// the order can neither rest nor wait for its trigger.
+func (engine *MatchingEngine) Load(order *models.Order) bool {
+	engine.mutex.Lock()
+	defer engine.mutex.Unlock()
+
+	if !canRest(order) || remainingQuantity(order) <= 0 {
+		return false
+	}
+	if isStop(order) {
+		engine.trigger(order.Symbol).Add(order)
+		return true
+	}
+	if order.Type != "limit" {
+		return false
+	}
+	engine.book(order.Symbol).Rest(order)
+	return true
+}
+
+// Checkpoint records the book, the trigger book and the last traded price of the symbol.
+// The returned function puts them back as they were, along with the state of the orders
+// they held, to undo the matches whose results could not be persisted.
+func (engine *MatchingEngine) Checkpoint(symbol string) func() {
+	engine.mutex.Lock()
+	defer engine.mutex.Unlock()
+
+	book, trigger := engine.book(symbol), engine.trigger(symbol)
+	bids, asks, dormant := snapshotOrders(book.Bids), snapshotOrders(book.Asks), snapshotOrders(trigger.Orders)
+	lastPrice, traded := engine.lastPrices[symbol]
+
+	return func() {
+		engine.mutex.Lock()
+		defer engine.mutex.Unlock()
+
+		book.Bids, book.Asks, trigger.Orders = bids.restore(), asks.restore(), dormant.restore()
+		if traded {
+			engine.lastPrices[symbol] = lastPrice
+		} else {
+			delete(engine.lastPrices, symbol)
+		}
+	}
+}
+
+func (engine *MatchingEngine) submit(order *models.Order) ([]*models.Execution, []*models.Order) {
+	if isStop(order) {
+		lastPrice, traded := engine.lastPrices[order.Symbol]
+		if !traded || !isTriggeredBy(order, lastPrice) {
+			engine.trigger(order.Symbol).Add(order)
+			return nil, nil
+		}
+		activate(order)
+	}
+
+	book := engine.book(order.Symbol)
+	executions, counterparties := engine.match(book, order)
+	if engine.lastPrices == nil {
+		engine.lastPrices = make(map[string]models.Money)
+	}
+
+	for prints := executions; len(prints) > 0; {
+		lastPrice := prints[len(prints)-1].Price
+		engine.lastPrices[order.Symbol] = lastPrice
+
+		prints = nil
+		for _, triggered := range engine.trigger(order.Symbol).Trigger(lastPrice) {
+			activate(triggered)
+			triggeredExecutions, triggeredCounterparties := engine.match(book, triggered)
+			executions = append(executions, triggeredExecutions...)
+			counterparties = append(append(counterparties, triggered), triggeredCounterparties...)
+			prints = append(prints, triggeredExecutions...)
+		}
+	}
+
+	return executions, counterparties
+}
+
+func (engine *MatchingEngine) match(book *OrderBook, order *models.Order) ([]*models.Execution, []*models.Order) {
+	if order.Timing == "fok" && book.Liquidity(order) < remainingQuantity(order) {
+		order.Status = "canceled"
+		return nil, nil
+	}
+
+	executions, counterparties := book.Match(order)
+
+	if remainingQuantity(order) > 0 {
+		if order.Type == "limit" && canRest(order) {
+			book.Rest(order)
+		} else {
+			order.Status = "canceled"
+		}
+	}
+
+	return executions, counterparties
+}
+
+func (engine *MatchingEngine) book(symbol string) *OrderBook {
+	if engine.books == nil {
+		engine.books = make(map[string]*OrderBook)
+	}
+
+	book, ok := engine.books[symbol]
+	if !ok {
+		book = &OrderBook{Symbol: symbol}
+		engine.books[symbol] = book
+	}
+	return book
+}
+
+func (engine *MatchingEngine) trigger(symbol string) *TriggerBook {
+	if engine.triggers == nil {
+		engine.triggers = make(map[string]*TriggerBook)
+	}
+
+	trigger, ok := engine.triggers[symbol]
+	if !ok {
+		trigger = &TriggerBook{Symbol: symbol}
+		engine.triggers[symbol] = trigger
+	}
+	return trigger
+}
+
+// orderSnapshot holds a list of orders along with a copy of each of them.
+type orderSnapshot struct {
+	orders []*models.Order
+	states []models.Order
+}
+
+func snapshotOrders(orders []*models.Order) orderSnapshot {
+	snapshot := orderSnapshot{orders: append([]*models.Order(nil), orders...), states: make([]models.Order, len(orders))}
+	for i, order := range orders {
+		snapshot.states[i] = *order
+	}
+	return snapshot
+}
+
+// restore resets the orders to their recorded state and returns them.
+func (snapshot orderSnapshot) restore() []*models.Order {
+	for i, order := range snapshot.orders {
+		*order = snapshot.states[i]
+	}
+	return snapshot.orders
+}
+
+// keepsPriority reports whether the modified order keeps the time priority of the resting
+// order it replaces: only a quantity decrease at the same price does.
+func keepsPriority(resting *models.Order, modified *models.Order) bool {
+	return modified.UnitPrice == resting.UnitPrice && modified.Quantity <= resting.Quantity
+}
+
+// canRest reports whether the time in force of the order lets it wait on the book.
+func canRest(order *models.Order) bool {
+	return order.Timing != "ioc" && order.Timing != "fok"
+}
+
+var _ ports.MatchingEngine = (*MatchingEngine)(nil) // Ensure interface is implemented at compile time
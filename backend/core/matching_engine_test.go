@@ -0,0 +1,404 @@
+package core
+
+import (
+	"brokerx/models"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+func makeBookOrder(id int, action string, orderType string, quantity int, price int64) *models.Order {
+	return &models.Order{
+		ID:        id,
+		UserID:    "user",
+		Symbol:    "AAPL",
+		Type:      orderType,
+		Action:    action,
+		Quantity:  quantity,
+		UnitPrice: models.NewMoney(price),
+		Timing:    "day",
+		Status:    "open",
+	}
+}
+
+// ---------------------------
+// Test Suite
+// ---------------------------
+
+type MatchingEngineTestSuite struct {
+	suite.Suite
+	engine *MatchingEngine
+}
+
+func (s *MatchingEngineTestSuite) SetupTest() {
+	s.engine = &MatchingEngine{}
+}
+
+// ---------------------------
+// Tests
+// ---------------------------
+
+func (s *MatchingEngineTestSuite) TestSubmitNoMatchRestsLimitOrder() {
+	order := makeBookOrder(1, "buy", "limit", 10, 100)
+
+	executions, counterparties := s.engine.Submit(order)
+
+	s.Empty(executions)
+	s.Empty(counterparties)
+	s.Equal("open", order.Status)
+	s.Equal([]*models.Order{order}, s.engine.book("AAPL").Bids)
+}
+
+func (s *MatchingEngineTestSuite) TestSubmitFullMatch() {
+	resting := makeBookOrder(1, "sell", "limit", 10, 100)
+	s.engine.Submit(resting)
+	order := makeBookOrder(2, "buy", "limit", 10, 101)
+
+	executions, counterparties := s.engine.Submit(order)
+
+	s.Require().Len(executions, 1)
+	s.Equal(2, executions[0].BuyOrderID)
+	s.Equal(1, executions[0].SellOrderID)
+	s.Equal(10, executions[0].Quantity)
+	s.Equal(models.NewMoney(100), executions[0].Price)
+	s.Equal("seller_maker", executions[0].LiquidityFlag)
+	s.Equal([]*models.Order{resting}, counterparties)
+	s.Equal("filled", order.Status)
+	s.Equal("filled", resting.Status)
+	s.Empty(s.engine.book("AAPL").Asks)
+	s.Empty(s.engine.book("AAPL").Bids)
+}
+
+func (s *MatchingEngineTestSuite) TestSubmitPartialFillRestsRemainder() {
+	s.engine.Submit(makeBookOrder(1, "buy", "limit", 4, 100))
+	order := makeBookOrder(2, "sell", "limit", 10, 99)
+
+	executions, _ := s.engine.Submit(order)
+
+	s.Require().Len(executions, 1)
+	s.Equal("buyer_maker", executions[0].LiquidityFlag)
+	s.Equal(4, order.FilledQuantity)
+	s.Equal("partially filled", order.Status)
+	s.Equal([]*models.Order{order}, s.engine.book("AAPL").Asks)
+}
+
+func (s *MatchingEngineTestSuite) TestSubmitPriceTimePriority() {
+	first := makeBookOrder(1, "sell", "limit", 5, 101)
+	second := makeBookOrder(2, "sell", "limit", 5, 100)
+	third := makeBookOrder(3, "sell", "limit", 5, 100)
+	s.engine.Submit(first)
+	s.engine.Submit(second)
+	s.engine.Submit(third)
+	order := makeBookOrder(4, "buy", "limit", 12, 101)
+
+	executions, counterparties := s.engine.Submit(order)
+
+	s.Require().Len(executions, 3)
+	s.Equal([]*models.Order{second, third, first}, counterparties)
+	s.Equal(models.NewMoney(101), executions[2].Price)
+	s.Equal(2, executions[2].Quantity)
+	s.Equal("partially filled", first.Status)
+	s.Equal("filled", order.Status)
+}
+
+func (s *MatchingEngineTestSuite) TestSubmitLimitDoesNotCross() {
+	s.engine.Submit(makeBookOrder(1, "sell", "limit", 5, 105))
+	order := makeBookOrder(2, "buy", "limit", 5, 100)
+
+	executions, _ := s.engine.Submit(order)
+
+	s.Empty(executions)
+	s.Equal("open", order.Status)
+}
+
+func (s *MatchingEngineTestSuite) TestSubmitMarketOrderRemainderIsCanceled() {
+	s.engine.Submit(makeBookOrder(1, "buy", "limit", 3, 250))
+	order := makeBookOrder(2, "sell", "market", 5, 300)
+
+	executions, _ := s.engine.Submit(order)
+
+	s.Require().Len(executions, 1)
+	s.Equal(models.NewMoney(250), executions[0].Price)
+	s.Equal(3, order.FilledQuantity)
+	s.Equal("canceled", order.Status)
+	s.Empty(s.engine.book("AAPL").Asks)
+}
+
+func (s *MatchingEngineTestSuite) TestSubmitMarketBuyWalksTheBookUpToItsProtectionPrice() {
+	s.engine.Submit(makeBookOrder(1, "sell", "limit", 5, 148))
+	s.engine.Submit(makeBookOrder(2, "sell", "limit", 5, 150))
+	above := makeBookOrder(3, "sell", "limit", 5, 152)
+	s.engine.Submit(above)
+	order := makeBookOrder(4, "buy", "market", 12, 150)
+
+	executions, _ := s.engine.Submit(order)
+
+	s.Require().Len(executions, 2)
+	s.Equal(models.NewMoney(148), executions[0].Price)
+	s.Equal(models.NewMoney(150), executions[1].Price)
+	s.Equal(10, order.FilledQuantity)
+	s.Equal("canceled", order.Status)
+	s.Equal([]*models.Order{above}, s.engine.book("AAPL").Asks)
+	s.Equal(0, above.FilledQuantity)
+	s.Empty(s.engine.book("AAPL").Bids)
+}
+
+func (s *MatchingEngineTestSuite) TestSubmitIOCRemainderIsCanceled() {
+	s.engine.Submit(makeBookOrder(1, "sell", "limit", 3, 100))
+	order := makeBookOrder(2, "buy", "limit", 5, 100)
+	order.Timing = "ioc"
+
+	executions, _ := s.engine.Submit(order)
+
+	s.Require().Len(executions, 1)
+	s.Equal(3, order.FilledQuantity)
+	s.Equal("canceled", order.Status)
+	s.Empty(s.engine.book("AAPL").Bids)
+}
+
+func (s *MatchingEngineTestSuite) TestSubmitFOKFilledInFull() {
+	s.engine.Submit(makeBookOrder(1, "sell", "limit", 3, 100))
+	s.engine.Submit(makeBookOrder(2, "sell", "limit", 2, 101))
+	order := makeBookOrder(3, "buy", "limit", 5, 101)
+	order.Timing = "fok"
+
+	executions, counterparties := s.engine.Submit(order)
+
+	s.Len(executions, 2)
+	s.Len(counterparties, 2)
+	s.Equal("filled", order.Status)
+	s.Empty(s.engine.book("AAPL").Asks)
+}
+
+func (s *MatchingEngineTestSuite) TestSubmitFOKWithoutEnoughLiquidityIsKilled() {
+	s.engine.Submit(makeBookOrder(1, "sell", "limit", 3, 100))
+	s.engine.Submit(makeBookOrder(2, "sell", "limit", 2, 102))
+	order := makeBookOrder(3, "buy", "limit", 5, 101)
+	order.Timing = "fok"
+
+	executions, _ := s.engine.Submit(order)
+
+	s.Empty(executions)
+	s.Equal(0, order.FilledQuantity)
+	s.Equal("canceled", order.Status)
+	s.Len(s.engine.book("AAPL").Asks, 2)
+	s.Empty(s.engine.book("AAPL").Bids)
+}
+
+func (s *MatchingEngineTestSuite) TestSubmitTracksAverageFillPrice() {
+	first := makeBookOrder(1, "sell", "limit", 2, 100)
+	s.engine.Submit(first)
+	s.engine.Submit(makeBookOrder(2, "sell", "limit", 3, 101))
+	order := makeBookOrder(3, "buy", "limit", 10, 101)
+
+	s.engine.Submit(order)
+
+	s.Equal(5, order.FilledQuantity)
+	s.Equal(models.MustParseMoney("100.6"), order.AverageFillPrice)
+	s.Equal(models.NewMoney(100), first.AverageFillPrice)
+}
+
+func (s *MatchingEngineTestSuite) TestSubmitRoundsAverageFillPriceHalfEven() {
+	s.engine.Submit(makeBookOrder(1, "sell", "limit", 1, 100))
+	s.engine.Submit(makeBookOrder(2, "sell", "limit", 2, 101))
+	order := makeBookOrder(3, "buy", "limit", 3, 101)
+
+	s.engine.Submit(order)
+
+	s.Equal(models.MustParseMoney("100.6667"), order.AverageFillPrice)
+}
+
+func (s *MatchingEngineTestSuite) TestSubmitBooksAreSeparatedBySymbol() {
+	s.engine.Submit(makeBookOrder(1, "sell", "limit", 5, 100))
+	order := makeBookOrder(2, "buy", "limit", 5, 100)
+	order.Symbol = "IBM"
+
+	executions, _ := s.engine.Submit(order)
+
+	s.Empty(executions)
+}
+
+func (s *MatchingEngineTestSuite) TestSubmitStopOrderStaysDormant() {
+	s.engine.Submit(makeBookOrder(1, "sell", "limit", 5, 100))
+	stop := makeBookOrder(2, "buy", "stop", 5, 105)
+	stop.StopPrice = models.NewMoney(105)
+
+	executions, counterparties := s.engine.Submit(stop)
+
+	s.Empty(executions)
+	s.Empty(counterparties)
+	s.Equal("stop", stop.Type)
+	s.Equal([]*models.Order{stop}, s.engine.trigger("AAPL").Orders)
+	s.Len(s.engine.book("AAPL").Asks, 1)
+}
+
+func (s *MatchingEngineTestSuite) TestSubmitTradeTriggersStopOrders() {
+	s.engine.Submit(makeBookOrder(1, "sell", "limit", 2, 100))
+	s.engine.Submit(makeBookOrder(2, "sell", "limit", 3, 101))
+	stop := makeBookOrder(3, "buy", "stop", 3, 101)
+	stop.StopPrice = models.NewMoney(100)
+	s.engine.Submit(stop)
+	stopLimit := makeBookOrder(4, "buy", "stop_limit", 4, 99)
+	stopLimit.StopPrice = models.NewMoney(101)
+	s.engine.Submit(stopLimit)
+	order := makeBookOrder(5, "buy", "limit", 2, 100)
+
+	executions, counterparties := s.engine.Submit(order)
+
+	s.Require().Len(executions, 2)
+	s.Equal(5, executions[0].BuyOrderID)
+	s.Equal(3, executions[1].BuyOrderID)
+	s.Equal(models.NewMoney(101), executions[1].Price)
+	s.Equal("market", stop.Type)
+	s.Equal("filled", stop.Status)
+	s.Equal("limit", stopLimit.Type)
+	s.Equal("open", stopLimit.Status)
+	s.Contains(counterparties, stop)
+	s.Contains(counterparties, stopLimit)
+	s.Empty(s.engine.trigger("AAPL").Orders)
+	s.Equal([]*models.Order{stopLimit}, s.engine.book("AAPL").Bids)
+}
+
+func (s *MatchingEngineTestSuite) TestSubmitStopOrderAlreadyCrossedIsActivated() {
+	s.engine.Submit(makeBookOrder(1, "buy", "limit", 2, 100))
+	s.engine.Submit(makeBookOrder(2, "sell", "limit", 1, 100))
+	stop := makeBookOrder(3, "sell", "stop", 1, 100)
+	stop.StopPrice = models.NewMoney(101)
+
+	executions, _ := s.engine.Submit(stop)
+
+	s.Require().Len(executions, 1)
+	s.Equal("market", stop.Type)
+	s.Equal("filled", stop.Status)
+	s.Empty(s.engine.trigger("AAPL").Orders)
+}
+
+func (s *MatchingEngineTestSuite) TestCancelRemovesDormantStopOrder() {
+	stop := makeBookOrder(1, "sell", "stop_limit", 5, 95)
+	stop.StopPrice = models.NewMoney(96)
+	s.engine.Submit(stop)
+
+	s.True(s.engine.Cancel(&models.Order{ID: 1, Symbol: "AAPL", Action: "sell"}))
+	s.Empty(s.engine.trigger("AAPL").Orders)
+}
+
+func (s *MatchingEngineTestSuite) TestCancelRemovesRestingOrder() {
+	order := makeBookOrder(1, "buy", "limit", 5, 100)
+	s.engine.Submit(order)
+
+	s.True(s.engine.Cancel(&models.Order{ID: 1, Symbol: "AAPL", Action: "buy"}))
+	s.Empty(s.engine.book("AAPL").Bids)
+	s.False(s.engine.Cancel(order))
+}
+
+func (s *MatchingEngineTestSuite) TestReplaceQuantityDecreaseKeepsPriority() {
+	first := makeBookOrder(1, "buy", "limit", 10, 100)
+	second := makeBookOrder(2, "buy", "limit", 10, 100)
+	s.engine.Submit(first)
+	s.engine.Submit(second)
+	modified := *first
+	modified.Quantity = 5
+
+	executions, _ := s.engine.Replace(&modified)
+
+	s.Empty(executions)
+	s.Equal([]*models.Order{&modified, second}, s.engine.book("AAPL").Bids)
+}
+
+func (s *MatchingEngineTestSuite) TestReplaceQuantityIncreaseLosesPriority() {
+	first := makeBookOrder(1, "buy", "limit", 10, 100)
+	second := makeBookOrder(2, "buy", "limit", 10, 100)
+	s.engine.Submit(first)
+	s.engine.Submit(second)
+	modified := *first
+	modified.Quantity = 15
+
+	s.engine.Replace(&modified)
+
+	s.Equal([]*models.Order{second, &modified}, s.engine.book("AAPL").Bids)
+}
+
+func (s *MatchingEngineTestSuite) TestReplacePriceChangeCanMatch() {
+	s.engine.Submit(makeBookOrder(1, "sell", "limit", 10, 105))
+	order := makeBookOrder(2, "buy", "limit", 10, 100)
+	s.engine.Submit(order)
+	modified := *order
+	modified.UnitPrice = models.NewMoney(105)
+
+	executions, counterparties := s.engine.Replace(&modified)
+
+	s.Require().Len(executions, 1)
+	s.Len(counterparties, 1)
+	s.Equal("filled", modified.Status)
+	s.Empty(s.engine.book("AAPL").Bids)
+}
+
+func (s *MatchingEngineTestSuite) TestLoadRestsOrdersWithoutMatching() {
+	first := makeBookOrder(1, "buy", "limit", 10, 100)
+	better := makeBookOrder(2, "buy", "limit", 10, 101)
+	second := makeBookOrder(3, "buy", "limit", 10, 100)
+	second.FilledQuantity = 4
+	second.Status = "partially filled"
+	ask := makeBookOrder(4, "sell", "limit", 10, 102)
+
+	s.True(s.engine.Load(first))
+	s.True(s.engine.Load(better))
+	s.True(s.engine.Load(second))
+	s.True(s.engine.Load(ask))
+
+	s.Equal([]*models.Order{better, first, second}, s.engine.book("AAPL").Bids)
+	s.Equal([]*models.Order{ask}, s.engine.book("AAPL").Asks)
+	s.Equal("open", first.Status)
+}
+
+func (s *MatchingEngineTestSuite) TestLoadPutsDormantStopOrdersOnTheTriggerBook() {
+	stop := makeBookOrder(1, "sell", "stop", 10, 90)
+	stop.StopPrice = models.NewMoney(95)
+	stop.Timing = "gtc"
+
+	s.True(s.engine.Load(stop))
+
+	s.Equal([]*models.Order{stop}, s.engine.trigger("AAPL").Orders)
+	s.Empty(s.engine.book("AAPL").Asks)
+	s.engine.Submit(makeBookOrder(2, "buy", "limit", 10, 95))
+	s.engine.Submit(makeBookOrder(3, "sell", "limit", 10, 95))
+	s.Empty(s.engine.trigger("AAPL").Orders)
+	s.Equal("market", stop.Type)
+}
+
+func (s *MatchingEngineTestSuite) TestLoadSkipsOrdersThatCannotRest() {
+	s.False(s.engine.Load(makeBookOrder(1, "buy", "market", 10, 100)))
+	ioc := makeBookOrder(2, "buy", "limit", 10, 100)
+	ioc.Timing = "ioc"
+	s.False(s.engine.Load(ioc))
+
+	s.Empty(s.engine.book("AAPL").Bids)
+}
+
+func (s *MatchingEngineTestSuite) TestCheckpointRestoresTheBooks() {
+	ask := makeBookOrder(1, "sell", "limit", 10, 100)
+	s.engine.Submit(ask)
+	stop := makeBookOrder(2, "sell", "stop", 5, 90)
+	stop.StopPrice = models.NewMoney(100)
+	s.engine.Submit(stop)
+	restore := s.engine.Checkpoint("AAPL")
+	order := makeBookOrder(3, "buy", "limit", 15, 100)
+
+	s.engine.Submit(order)
+	restore()
+
+	s.Equal([]*models.Order{ask}, s.engine.book("AAPL").Asks)
+	s.Equal(0, ask.FilledQuantity)
+	s.Equal("open", ask.Status)
+	s.Empty(s.engine.book("AAPL").Bids)
+	s.Equal([]*models.Order{stop}, s.engine.trigger("AAPL").Orders)
+	s.Equal("stop", stop.Type)
+	s.NotContains(s.engine.lastPrices, "AAPL")
+}
+
+// ---------------------------
+// Run the suite
+// ---------------------------
+func TestMatchingEngineTestSuite(t *testing.T) {
+	suite.Run(t, new(MatchingEngineTestSuite))
+}
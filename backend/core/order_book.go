@@ -0,0 +1,177 @@
+package core
+
+import (
+	"brokerx/models"
+	"time"
+)
+
+// OrderBook holds the resting limit orders of a single symbol. Each side is kept
+// sorted by price priority, and orders at the same price keep their arrival order.
+type OrderBook struct {
+	Symbol string
+	Bids   []*models.Order
+	Asks   []*models.Order
+}
+
+func (book *OrderBook) Match(order *models.Order) ([]*models.Execution, []*models.Order) {
+	var executions []*models.Execution
+	var counterparties []*models.Order
+
+	for remainingQuantity(order) > 0 {
+		resting := book.bestOpposite(order)
+		if resting == nil || !crosses(order, resting) {
+			break
+		}
+
+		quantity := min(remainingQuantity(order), remainingQuantity(resting))
+		applyFill(order, quantity, resting.UnitPrice)
+		applyFill(resting, quantity, resting.UnitPrice)
+		executions = append(executions, newExecution(order, resting, quantity))
+		counterparties = append(counterparties, resting)
+
+		if remainingQuantity(resting) == 0 {
+			book.Remove(resting)
+		}
+	}
+
+	return executions, counterparties
+}
+
+func (book *OrderBook) Rest(order *models.Order) {
+	side := book.side(order)
+	position := len(*side)
+	for i, resting := range *side {
+		if hasPricePriority(order, resting) {
+			position = i
+			break
+		}
+	}
+
+	*side = append(*side, nil)
+	copy((*side)[position+1:], (*side)[position:])
+	(*side)[position] = order
+}
+
+func (book *OrderBook) Remove(order *models.Order) bool {
+	side := book.side(order)
+	for i, resting := range *side {
+		if resting.ID == order.ID {
+			*side = append((*side)[:i], (*side)[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func (book *OrderBook) Find(order *models.Order) *models.Order {
+	for _, resting := range *book.side(order) {
+		if resting.ID == order.ID {
+			return resting
+		}
+	}
+	return nil
+}
+
+// Swap puts order in place of the resting order with the same ID, keeping its priority.
+func (book *OrderBook) Swap(order *models.Order) bool {
+	side := *book.side(order)
+	for i, resting := range side {
+		if resting.ID == order.ID {
+			side[i] = order
+			return true
+		}
+	}
+	return false
+}
+
+func (book *OrderBook) side(order *models.Order) *[]*models.Order {
+	if order.Action == "buy" {
+		return &book.Bids
+	}
+	return &book.Asks
+}
+
+// Liquidity is the quantity resting on the opposite side at prices the order crosses.
+func (book *OrderBook) Liquidity(order *models.Order) int {
+	liquidity := 0
+	for _, resting := range book.opposite(order) {
+		if !crosses(order, resting) {
+			break
+		}
+		liquidity += remainingQuantity(resting)
+	}
+	return liquidity
+}
+
+func (book *OrderBook) opposite(order *models.Order) []*models.Order {
+	if order.Action == "sell" {
+		return book.Bids
+	}
+	return book.Asks
+}
+
+func (book *OrderBook) bestOpposite(order *models.Order) *models.Order {
+	opposite := book.opposite(order)
+	if len(opposite) == 0 {
+		return nil
+	}
+	return opposite[0]
+}
+
+// hasPricePriority reports whether order must be placed ahead of resting. Equal
+// prices return false so that earlier orders keep their time priority.
+func hasPricePriority(order *models.Order, resting *models.Order) bool {
+	if order.Action == "buy" {
+		return order.UnitPrice.GreaterThan(resting.UnitPrice)
+	}
+	return order.UnitPrice.LessThan(resting.UnitPrice)
+}
+
+// crosses reports whether order can trade against resting. A market buy only crosses asks
+// up to its unit price, the protection price its funds are held at, whereas a market sell
+// crosses any bid.
+func crosses(order *models.Order, resting *models.Order) bool {
+	if order.Action == "buy" {
+		return order.UnitPrice.Cmp(resting.UnitPrice) >= 0
+	}
+	if order.Type == "market" {
+		return true
+	}
+	return order.UnitPrice.Cmp(resting.UnitPrice) <= 0
+}
+
+func remainingQuantity(order *models.Order) int {
+	return order.Quantity - order.FilledQuantity
+}
+
+// applyFill adds a fill to the order and folds its price into the average fill price,
+// rounded half even to four decimal places.
+func applyFill(order *models.Order, quantity int, price models.Money) {
+	filledValue := order.AverageFillPrice.Mul(order.FilledQuantity).Add(price.Mul(quantity))
+	order.FilledQuantity += quantity
+	order.AverageFillPrice = filledValue.Div(order.FilledQuantity, models.RoundHalfEven)
+	if remainingQuantity(order) == 0 {
+		order.Status = "filled"
+	} else {
+		order.Status = "partially filled"
+	}
+}
+
+func newExecution(order *models.Order, resting *models.Order, quantity int) *models.Execution {
+	execution := &models.Execution{
+		Symbol:     order.Symbol,
+		Quantity:   quantity,
+		Price:      resting.UnitPrice,
+		ExecutedAt: time.Now(),
+	}
+
+	if order.Action == "buy" {
+		execution.BuyOrderID, execution.SellOrderID = order.ID, resting.ID
+		execution.LiquidityFlag = "seller_maker"
+	} else {
+		execution.BuyOrderID, execution.SellOrderID = resting.ID, order.ID
+		execution.LiquidityFlag = "buyer_maker"
+	}
+
+	return execution
+}
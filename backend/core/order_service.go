@@ -3,24 +3,485 @@ package core
 import (
 	"brokerx/models"
 	"brokerx/ports"
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	defaultOrderPageSize = 20
+	maxOrderPageSize = 100
 )
 
 type OrderService struct {
 	Repo ports.OrderRepository
+	UnitOfWork ports.UnitOfWork
 	ComplianceService ports.ComplianceService
+	Engine ports.MatchingEngine
+	SymbolRepo ports.SymbolRepository
+	Fees models.FeeSchedule
+	SettlementDays int
+	Holidays models.Holidays
+	mutex sync.Mutex
 }
 
-func (service * OrderService) PlaceOrder(order *models.Order) error {
-	err := service.ComplianceService.VerifyOrderCompliance(order)
+// PlaceOrder accepts the order in the trading currency of its symbol. Its prices must be
+// in whole minor units of that currency. A buy order holds its estimated commission
+// along with its cost.
+func (service * OrderService) PlaceOrder(ctx context.Context, order *models.Order) error {
+	currency, err := tradingCurrency(ctx, service.SymbolRepo, order.Symbol)
 	if err != nil {
 		return err
 	}
+	order.Currency = currency
+	if !order.UnitPrice.FitsCurrency(currency) || !order.StopPrice.FitsCurrency(currency) {
+		return ports.ErrFractionalCents
+	}
 
-	_, err = service.Repo.CreateOrder(order)
+	err = service.ComplianceService.VerifyOrderCompliance(ctx, order)
 	if err != nil {
 		return err
 	}
+
+	order.Status = "open"
+	order.FilledQuantity = 0
+	order.Version = 1
+	order.QueuedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	order.FeesOnHold = estimatedFees(service.Fees, order, currentFillState(order))
+
+	// Matching and persistence are serialized so that fills are written in the order they
+	// happen. The order is recorded and matched in a single transaction, whose failure
+	// undoes the match on the book.
+	service.mutex.Lock()
+	defer service.mutex.Unlock()
+
+	// The request going away must not roll back a match
+	ctx = context.WithoutCancel(ctx)
+	restore := service.Engine.Checkpoint(order.Symbol)
+	err = service.UnitOfWork.Execute(ctx, func(repos ports.Repositories) error {
+		id, err := repos.Orders.CreateOrder(ctx, order)
+		if err != nil {
+			return err
+		}
+		order.ID = id
+
+		if err := reserve(ctx, repos, order); err != nil {
+			return err
+		}
+		if err := repos.Orders.SaveOrderVersion(ctx, order); err != nil {
+			return err
+		}
+		return service.match(ctx, repos, order)
+	})
+	if err != nil {
+		restore()
+	}
+	return err
+}
+
+func (service *OrderService) CancelOrder(ctx context.Context, userID string, orderID int) error {
+	service.mutex.Lock()
+	defer service.mutex.Unlock()
+
+	var order *models.Order
+	err := service.UnitOfWork.Execute(ctx, func(repos ports.Repositories) error {
+		var err error
+		order, err = findActiveOrder(ctx, repos.Orders, userID, orderID, ports.ErrOrderNotCancelable)
+		if err != nil {
+			return err
+		}
+
+		order.Status = "canceled"
+		if err = repos.Orders.UpdateOrder(ctx, order); err != nil {
+			return err
+		}
+		return release(ctx, repos, order)
+	})
+	if err != nil {
+		return err
+	}
+
+	service.Engine.Cancel(order)
 	return nil
 }
 
-var _ ports.OrderService = (*OrderService)(nil) // Ensure interface is implemented at compile time
\ No newline at end of file
+// ModifyOrder replaces the quantity and limit price of an active order. The change only
+// needs to pass compliance for the additional funds or shares it requires, and every
+// accepted change is recorded as a new version of the order.
+func (service *OrderService) ModifyOrder(ctx context.Context, userID string, orderID int, quantity int, unitPrice models.Money) error {
+	service.mutex.Lock()
+	defer service.mutex.Unlock()
+
+	order, err := findActiveOrder(ctx, service.Repo, userID, orderID, ports.ErrOrderNotModifiable)
+	if err != nil {
+		return err
+	}
+
+	if quantity <= order.FilledQuantity || !isCurrencyAmount(unitPrice, order.Currency) {
+		return ports.ErrInvalidModification
+	}
+
+	modified := *order
+	modified.Quantity = quantity
+	modified.UnitPrice = unitPrice
+	modified.Version++
+	modified.FeesOnHold = estimatedFees(service.Fees, &modified, currentFillState(&modified))
+	if isStop(order) || !keepsPriority(order, &modified) {
+		modified.QueuedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	}
+
+	if err = service.ComplianceService.VerifyOrderModificationCompliance(ctx, order, &modified); err != nil {
+		return err
+	}
+
+	// The engine is updated inside the transaction, whose failure undoes the replacement
+	// on the book. It must not be aborted by the request going away once the replacement
+	// has been matched.
+	ctx = context.WithoutCancel(ctx)
+	restore := service.Engine.Checkpoint(order.Symbol)
+	err = service.UnitOfWork.Execute(ctx, func(repos ports.Repositories) error {
+		if err := adjustReservation(ctx, repos, order, &modified); err != nil {
+			return err
+		}
+
+		executions, counterparties := service.Engine.Replace(&modified)
+
+		if err := repos.Orders.UpdateOrder(ctx, &modified); err != nil {
+			return err
+		}
+		if err := repos.Orders.SaveOrderVersion(ctx, &modified); err != nil {
+			return err
+		}
+		return service.persistMatch(ctx, repos, &modified, executions, counterparties)
+	})
+	if err != nil {
+		restore()
+	}
+	return err
+}
+
+// LoadOrders restores the order books and the trigger books from the active orders, in
+// their time priority, so that the orders waiting before a restart can still be matched.
+// The GTD orders whose expiry passed while the server was down are expired first. It
+// returns the number of orders put back on the books.
+func (service *OrderService) LoadOrders(ctx context.Context, now time.Time) (int, error) {
+	service.mutex.Lock()
+	defer service.mutex.Unlock()
+
+	expired, err := service.Repo.FindExpiredGoodTillDateOrders(ctx, now)
+	if err != nil {
+		return 0, err
+	}
+	service.expire(ctx, expired)
+
+	orders, err := service.Repo.FindActiveOrders(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	loaded := 0
+	for _, order := range orders {
+		if service.Engine.Load(order) {
+			loaded++
+		}
+	}
+	return loaded, nil
+}
+
+// GetOrder returns an order of the user.
+func (service *OrderService) GetOrder(ctx context.Context, userID string, orderID int) (*models.Order, error) {
+	return findOwnedOrder(ctx, service.Repo, userID, orderID)
+}
+
+// ListOrders returns a page of the orders of the user that match the filter, most recent
+// first. The page size defaults to defaultOrderPageSize and is capped at maxOrderPageSize.
+func (service *OrderService) ListOrders(ctx context.Context, userID string, filter models.OrderFilter) (*models.OrderPage, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = defaultOrderPageSize
+	}
+	filter.Limit = min(filter.Limit, maxOrderPageSize)
+
+	// One extra order tells whether there is a next page
+	pageSize := filter.Limit
+	filter.Limit++
+	orders, err := service.Repo.FindByUserId(ctx, userID, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &models.OrderPage{Orders: orders}
+	if len(orders) > pageSize {
+		page.Orders = orders[:pageSize]
+		page.NextCursor = page.Orders[pageSize-1].ID
+	}
+	return page, nil
+}
+
+// ExpireDayOrders expires the active DAY orders placed before the given session close and
+// releases what they still had reserved. It returns the number of expired orders.
+func (service *OrderService) ExpireDayOrders(ctx context.Context, sessionClose time.Time) (int, error) {
+	service.mutex.Lock()
+	defer service.mutex.Unlock()
+
+	orders, err := service.Repo.FindExpirableOrders(ctx, "day", sessionClose)
+	if err != nil {
+		return 0, err
+	}
+	return service.expire(ctx, orders), nil
+}
+
+// ExpireGoodTillDateOrders expires the active GTD orders whose expiry has passed and
+// releases what they still had reserved. It returns the number of expired orders.
+func (service *OrderService) ExpireGoodTillDateOrders(ctx context.Context, now time.Time) (int, error) {
+	service.mutex.Lock()
+	defer service.mutex.Unlock()
+
+	orders, err := service.Repo.FindExpiredGoodTillDateOrders(ctx, now)
+	if err != nil {
+		return 0, err
+	}
+	return service.expire(ctx, orders), nil
+}
+
+// expire marks the orders as expired and takes them off the book. An order that fails to
+// expire is logged and left active so that the next run can retry it.
+func (service *OrderService) expire(ctx context.Context, orders []*models.Order) int {
+	expired := 0
+	for _, order := range orders {
+		err := service.UnitOfWork.Execute(ctx, func(repos ports.Repositories) error {
+			order.Status = "expired"
+			if err := repos.Orders.UpdateOrder(ctx, order); err != nil {
+				return err
+			}
+			return release(ctx, repos, order)
+		})
+		if err != nil {
+			log.Errorf("Failed to expire order %d: %v", order.ID, err)
+			continue
+		}
+
+		service.Engine.Cancel(order)
+		expired++
+	}
+
+	return expired
+}
+
+// match runs the order through the matching engine and persists the resulting executions
+// along with every order they touched.
+func (service *OrderService) match(ctx context.Context, repos ports.Repositories, order *models.Order) error {
+	orderType := order.Type
+	executions, counterparties := service.Engine.Submit(order)
+
+	if err := service.persistMatch(ctx, repos, order, executions, counterparties); err != nil {
+		return err
+	}
+
+	if order.Status == "open" && order.Type == orderType {
+		return nil
+	}
+	if err := repos.Orders.UpdateOrder(ctx, order); err != nil {
+		return err
+	}
+	if order.Status == "canceled" {
+		return release(ctx, repos, order)
+	}
+	return nil
+}
+
+// tradingCurrency returns the currency the symbol trades in. Symbols that are not listed
+// trade in the base currency.
+func tradingCurrency(ctx context.Context, symbols ports.SymbolRepository, symbol string) (string, error) {
+	listed, err := symbols.FindBySymbol(ctx, symbol)
+	if errors.Is(err, ports.ErrSymbolNotFound) {
+		return models.BaseCurrency, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return listed.Currency, nil
+}
+
+func findOwnedOrder(ctx context.Context, repo ports.OrderRepository, userID string, orderID int) (*models.Order, error) {
+	order, err := repo.FindById(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	if order.UserID != userID {
+		return nil, ports.ErrOrderNotOwned
+	}
+
+	return order, nil
+}
+
+func findActiveOrder(ctx context.Context, repo ports.OrderRepository, userID string, orderID int, inactiveErr error) (*models.Order, error) {
+	order, err := findOwnedOrder(ctx, repo, userID, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	if order.Status != "open" && order.Status != "partially filled" {
+		return nil, inactiveErr
+	}
+
+	return order, nil
+}
+
+// persistMatch saves the executions of a match with their fees, to settle SettlementDays
+// business days after the trade. It settles the funds of both sides of every fill and
+// issues their trade confirmations.
+//
+// It then updates the other orders touched by the match: the resting orders that were
+// filled and the stop orders that were triggered. A triggered order whose remainder was
+// canceled releases what it still had reserved.
+func (service *OrderService) persistMatch(ctx context.Context, repos ports.Repositories, order *models.Order, executions []*models.Execution, counterparties []*models.Order) error {
+	orders := map[int]*models.Order{order.ID: order}
+	var touched []*models.Order
+	for _, counterparty := range counterparties {
+		if _, ok := orders[counterparty.ID]; !ok {
+			orders[counterparty.ID] = counterparty
+			touched = append(touched, counterparty)
+		}
+	}
+
+	states := fillStatesBeforeMatch(orders, executions)
+	for _, execution := range executions {
+		buyOrder, sellOrder := orders[execution.BuyOrderID], orders[execution.SellOrderID]
+		heldAmount := chargeFees(service.Fees, buyOrder, sellOrder, execution, states)
+		execution.SettlementDate = service.Holidays.AddBusinessDays(execution.ExecutedAt.Local(), service.SettlementDays)
+
+		id, err := repos.Executions.CreateExecution(ctx, execution)
+		if err != nil {
+			return err
+		}
+		execution.ID = id
+
+		if err = settleFill(ctx, repos, buyOrder, sellOrder, execution, heldAmount); err != nil {
+			return err
+		}
+		if err = confirmFill(ctx, repos, buyOrder, sellOrder, execution); err != nil {
+			return err
+		}
+	}
+
+	for _, counterparty := range touched {
+		if err := repos.Orders.UpdateOrder(ctx, counterparty); err != nil {
+			return err
+		}
+		if counterparty.Status == "canceled" {
+			if err := release(ctx, repos, counterparty); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// settleFill consumes the held amount of the buy order for the fill, credits the proceeds
+// of the fill to the unsettled funds of the seller, charges the fees of the fill to both
+// sides, adds the bought shares to the position and the tax lots of the buyer and removes
+// the sold shares from those of the seller.
+func settleFill(ctx context.Context, repos ports.Repositories, buyOrder *models.Order, sellOrder *models.Order, execution *models.Execution, heldAmount models.Money) error {
+	if err := repos.Ledger.Post(ctx, tradeEntry(buyOrder, sellOrder, execution, heldAmount)); err != nil {
+		return err
+	}
+	if entry := feeEntry(buyOrder, sellOrder, execution); entry != nil {
+		if err := repos.Ledger.Post(ctx, entry); err != nil {
+			return err
+		}
+	}
+	if err := repos.Positions.AddShares(ctx, buyOrder.UserID, execution.Symbol, execution.Quantity, execution.Price); err != nil {
+		return err
+	}
+	if err := openLot(ctx, repos, buyOrder, execution); err != nil {
+		return err
+	}
+	if err := repos.Positions.ConsumeReservedShares(ctx, sellOrder.UserID, execution.Symbol, execution.Quantity, sellOrder.Currency, execution.Price); err != nil {
+		return err
+	}
+	return relieveLots(ctx, repos, sellOrder, execution)
+}
+
+// reserve holds what the unfilled quantity of the order requires: funds for a buy order
+// and shares for a sell order, taken from the designated tax lot when there is one.
+func reserve(ctx context.Context, repos ports.Repositories, order *models.Order) error {
+	switch order.Action {
+	case "buy":
+		return repos.Ledger.Post(ctx, holdEntry(order.UserID, order.Currency, reservedFunds(order), orderReference(order)))
+	case "sell":
+		return reserveShares(ctx, repos, order, remainingQuantity(order))
+	}
+	return nil
+}
+
+func release(ctx context.Context, repos ports.Repositories, order *models.Order) error {
+	switch order.Action {
+	case "buy":
+		return repos.Ledger.Post(ctx, releaseEntry(order.UserID, order.Currency, reservedFunds(order), orderReference(order)))
+	case "sell":
+		return releaseShares(ctx, repos, order, remainingQuantity(order))
+	}
+	return nil
+}
+
+// adjustReservation reserves or releases the difference between what the modified order
+// and the current order require.
+func adjustReservation(ctx context.Context, repos ports.Repositories, order *models.Order, modified *models.Order) error {
+	switch order.Action {
+	case "buy":
+		delta := reservedFunds(modified).Sub(reservedFunds(order))
+		if delta.IsPositive() {
+			return repos.Ledger.Post(ctx, holdEntry(order.UserID, order.Currency, delta, orderReference(order)))
+		}
+		if delta.IsNegative() {
+			return repos.Ledger.Post(ctx, releaseEntry(order.UserID, order.Currency, delta.Neg(), orderReference(order)))
+		}
+	case "sell":
+		delta := remainingQuantity(modified) - remainingQuantity(order)
+		if delta > 0 {
+			return reserveShares(ctx, repos, order, delta)
+		}
+		if delta < 0 {
+			return releaseShares(ctx, repos, order, -delta)
+		}
+	}
+	return nil
+}
+
+// reserveShares reserves shares of the position for a sell order and, when the order
+// designates a tax lot, of that lot so that no other sell can designate them.
+func reserveShares(ctx context.Context, repos ports.Repositories, order *models.Order, quantity int) error {
+	if err := repos.Positions.ReserveShares(ctx, order.UserID, order.Symbol, quantity); err != nil {
+		return err
+	}
+	if order.LotID == 0 {
+		return nil
+	}
+	return repos.TaxLots.ReserveLot(ctx, order.LotID, quantity)
+}
+
+func releaseShares(ctx context.Context, repos ports.Repositories, order *models.Order, quantity int) error {
+	if err := repos.Positions.ReleaseShares(ctx, order.UserID, order.Symbol, quantity); err != nil {
+		return err
+	}
+	if order.LotID == 0 {
+		return nil
+	}
+	return repos.TaxLots.ReleaseLot(ctx, order.LotID, quantity)
+}
+
+// reservedFunds is the amount held for the unfilled quantity of a buy order and the
+// commission it can still cost.
+func reservedFunds(order *models.Order) models.Money {
+	if order.Action != "buy" {
+		return models.Money{}
+	}
+	return order.UnitPrice.Mul(remainingQuantity(order)).Add(order.FeesOnHold)
+}
+
+var _ ports.OrderService = (*OrderService)(nil) // Ensure interface is implemented at compile time
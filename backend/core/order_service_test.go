@@ -1,8 +1,13 @@
 package core
 
 import (
+	"context"
 	"brokerx/models"
+	"brokerx/ports"
+	"database/sql"
+	"math/big"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
@@ -14,31 +19,193 @@ type MockOrderRepo struct {
 	mock.Mock
 }
 
-func (m *MockOrderRepo) CreateOrder(order *models.Order) (int, error) {
-	args := m.Called(order)
+func (m *MockOrderRepo) CreateOrder(ctx context.Context, order *models.Order) (int, error) {
+	args := m.Called(ctx, order)
 	if args.Get(0) == nil {
 		return 0, args.Error(1)
 	}
 	return args.Get(0).(int), args.Error(1)
 }
 
-type MockComplianceService struct {
+func (m *MockOrderRepo) UpdateOrder(ctx context.Context, order *models.Order) error {
+	args := m.Called(ctx, order)
+	return args.Error(0)
+}
+
+func (m *MockOrderRepo) FindById(ctx context.Context, id int) (*models.Order, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Order), args.Error(1)
+}
+
+func (m *MockOrderRepo) FindByUserId(ctx context.Context, userId string, filter models.OrderFilter) ([]*models.Order, error) {
+	args := m.Called(ctx, userId, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Order), args.Error(1)
+}
+
+func (m *MockOrderRepo) FindExpirableOrders(ctx context.Context, timing string, createdBefore time.Time) ([]*models.Order, error) {
+	args := m.Called(ctx, timing, createdBefore)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Order), args.Error(1)
+}
+
+func (m *MockOrderRepo) FindExpiredGoodTillDateOrders(ctx context.Context, now time.Time) ([]*models.Order, error) {
+	args := m.Called(ctx, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Order), args.Error(1)
+}
+
+func (m *MockOrderRepo) FindActiveOrders(ctx context.Context) ([]*models.Order, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Order), args.Error(1)
+}
+
+func (m *MockOrderRepo) SaveOrderVersion(ctx context.Context, order *models.Order) error {
+	args := m.Called(ctx, order)
+	return args.Error(0)
+}
+
+func (m *MockOrderRepo) FindOrderVersions(ctx context.Context, orderId int) ([]*models.OrderVersion, error) {
+	args := m.Called(ctx, orderId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.OrderVersion), args.Error(1)
+}
+
+type MockExecutionRepo struct {
 	mock.Mock
 }
 
-func (m *MockComplianceService) VerifyOrderCompliance(order *models.Order) error {
+func (m *MockExecutionRepo) CreateExecution(ctx context.Context, execution *models.Execution) (int, error) {
+	args := m.Called(ctx, execution)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockExecutionRepo) FindByOrderId(ctx context.Context, orderId int) ([]*models.Execution, error) {
+	args := m.Called(ctx, orderId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Execution), args.Error(1)
+}
+
+func (m *MockExecutionRepo) FindLatestPrice(ctx context.Context, symbol string) (models.Money, error) {
+	args := m.Called(ctx, symbol)
+	return args.Get(0).(models.Money), args.Error(1)
+}
+
+func (m *MockExecutionRepo) FindLatestPriceBefore(ctx context.Context, symbol string, before time.Time) (models.Money, error) {
+	args := m.Called(ctx, symbol, before)
+	return args.Get(0).(models.Money), args.Error(1)
+}
+
+func (m *MockExecutionRepo) FindByUserIdAndPeriod(ctx context.Context, userId string, from time.Time, to time.Time) ([]*models.Execution, error) {
+	args := m.Called(ctx, userId, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Execution), args.Error(1)
+}
+
+func (m *MockExecutionRepo) FindUnsettled(ctx context.Context, settlementDate time.Time) ([]*models.Execution, error) {
+	args := m.Called(ctx, settlementDate)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Execution), args.Error(1)
+}
+
+func (m *MockExecutionRepo) MarkSettled(ctx context.Context, executionId int, settledAt time.Time) error {
+	args := m.Called(ctx, executionId, settledAt)
+	return args.Error(0)
+}
+
+type MockMatchingEngine struct {
+	mock.Mock
+}
+
+func (m *MockMatchingEngine) Submit(order *models.Order) ([]*models.Execution, []*models.Order) {
 	args := m.Called(order)
+	return args.Get(0).([]*models.Execution), args.Get(1).([]*models.Order)
+}
+
+func (m *MockMatchingEngine) Cancel(order *models.Order) bool {
+	args := m.Called(order)
+	return args.Bool(0)
+}
+
+func (m *MockMatchingEngine) Replace(order *models.Order) ([]*models.Execution, []*models.Order) {
+	args := m.Called(order)
+	return args.Get(0).([]*models.Execution), args.Get(1).([]*models.Order)
+}
+
+func (m *MockMatchingEngine) Load(order *models.Order) bool {
+	args := m.Called(order)
+	return args.Bool(0)
+}
+
+func (m *MockMatchingEngine) Checkpoint(symbol string) func() {
+	args := m.Called(symbol)
+	return args.Get(0).(func())
+}
+
+// MockUnitOfWork runs the unit of work directly against the mocked repositories.
+type MockUnitOfWork struct {
+	repos ports.Repositories
+}
+
+func (m *MockUnitOfWork) Execute(ctx context.Context, fn func(repos ports.Repositories) error) error {
+	return fn(m.repos)
+}
+
+type MockComplianceService struct {
+	mock.Mock
+}
+
+func (m *MockComplianceService) VerifyOrderCompliance(ctx context.Context, order *models.Order) error {
+	args := m.Called(ctx, order)
+	return args.Error(0)
+}
+
+func (m *MockComplianceService) VerifyOrderModificationCompliance(ctx context.Context, order *models.Order, modified *models.Order) error {
+	args := m.Called(ctx, order, modified)
 	return args.Error(0)
 }
 
+type MockSymbolRepo struct {
+	mock.Mock
+}
+
+func (m *MockSymbolRepo) FindBySymbol(ctx context.Context, symbol string) (*models.Symbol, error) {
+	args := m.Called(ctx, symbol)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Symbol), args.Error(1)
+}
+
 func makeOrder() *models.Order {
 	return &models.Order{
 		UserID: uuid.New().String(),
 		Symbol: "AAPL",
+		Currency: "USD",
 		Type:   "market",
 		Action: "buy",
 		Quantity:  10,
-		UnitPrice: 150.00,
+		UnitPrice: models.NewMoney(150),
 		Timing:    "day",
 		Status:    "open",
 	}
@@ -51,14 +218,91 @@ func makeOrder() *models.Order {
 type OrderServiceTestSuite struct {
 	suite.Suite
 	repo    *MockOrderRepo
+	executionRepo *MockExecutionRepo
+	walletRepo *MockWalletRepo
+	ledgerRepo *MockLedgerRepo
+	positionRepo *MockPositionsRepo
+	taxLotRepo *MockTaxLotRepo
+	userRepo *MockUserRepo
 	complianceService *MockComplianceService
+	engine *MockMatchingEngine
+	symbolRepo *MockSymbolRepo
+	confirmationRepo *MockConfirmationRepo
 	service *OrderService
 }
 
 func (s *OrderServiceTestSuite) SetupTest() {
 	s.repo = new(MockOrderRepo)
+	s.executionRepo = new(MockExecutionRepo)
+	s.walletRepo = new(MockWalletRepo)
+	s.ledgerRepo = new(MockLedgerRepo)
+	s.positionRepo = new(MockPositionsRepo)
+	s.taxLotRepo = new(MockTaxLotRepo)
+	s.userRepo = new(MockUserRepo)
 	s.complianceService = new(MockComplianceService)
-	s.service = &OrderService{Repo: s.repo, ComplianceService: s.complianceService}
+	s.engine = new(MockMatchingEngine)
+	s.symbolRepo = new(MockSymbolRepo)
+	s.confirmationRepo = new(MockConfirmationRepo)
+	s.service = &OrderService{
+		Repo: s.repo,
+		UnitOfWork: &MockUnitOfWork{repos: ports.Repositories{
+			Orders:     s.repo,
+			Executions: s.executionRepo,
+			Wallets:    s.walletRepo,
+			Ledger:     s.ledgerRepo,
+			Positions:  s.positionRepo,
+			TaxLots:    s.taxLotRepo,
+			Users:      s.userRepo,
+			Confirmations: s.confirmationRepo,
+		}},
+		ComplianceService: s.complianceService,
+		Engine:            s.engine,
+		SymbolRepo:        s.symbolRepo,
+	}
+	s.repo.On("SaveOrderVersion", mock.Anything, mock.Anything).Return(nil).Maybe()
+	s.symbolRepo.On("FindBySymbol", mock.Anything, "AAPL").Return(&models.Symbol{Symbol: "AAPL", Currency: "USD"}, nil).Maybe()
+	s.confirmationRepo.On("FindByNumber", mock.Anything, mock.Anything).Return(nil, ports.ErrConfirmationNotFound).Maybe()
+	s.confirmationRepo.On("CreateConfirmation", mock.Anything, mock.Anything).Return(1, nil).Maybe()
+	s.engine.On("Checkpoint", mock.Anything).Return(func() {}).Maybe()
+}
+
+// expectLotRelief expects the fill of the execution to open a lot for the buyer and to
+// relieve a single FIFO lot of the seller.
+func (s *OrderServiceTestSuite) expectLotRelief(seller string, execution *models.Execution) {
+	lot := &models.TaxLot{ID: 3, UserID: seller, Symbol: execution.Symbol, Quantity: 20, RemainingQuantity: 20, UnitPrice: models.NewMoney(140)}
+	s.taxLotRepo.On("CreateLot", mock.Anything, mock.Anything).Return(4, nil)
+	s.userRepo.On("FindById", mock.Anything, seller).Return(&models.User{ID: seller, LotReliefMethod: "fifo"}, nil)
+	s.taxLotRepo.On("FindOpenLots", mock.Anything, seller, execution.Symbol).Return([]*models.TaxLot{lot}, nil)
+	s.taxLotRepo.On("RelieveLot", mock.Anything, mock.MatchedBy(func(relief *models.LotRelief) bool {
+		return relief.LotID == lot.ID && relief.Quantity == execution.Quantity
+	})).Return(nil)
+}
+
+// replaced returns the modified order that the engine was asked to replace.
+func (s *OrderServiceTestSuite) replaced() *models.Order {
+	for _, call := range s.engine.Calls {
+		if call.Method == "Replace" {
+			return call.Arguments.Get(0).(*models.Order)
+		}
+	}
+	s.FailNow("Replace was not called")
+	return nil
+}
+
+// entryMoving matches the journal entry of the given type that moves the amount in or
+// out of an account of the user.
+func entryMoving(entryType string, userID string, amount models.Money) any {
+	return mock.MatchedBy(func(entry *models.JournalEntry) bool {
+		if entry.Type != entryType {
+			return false
+		}
+		for _, posting := range entry.Postings {
+			if posting.UserID == userID && posting.Amount.Abs() == amount {
+				return true
+			}
+		}
+		return false
+	})
 }
 
 // ---------------------------
@@ -67,36 +311,896 @@ func (s *OrderServiceTestSuite) SetupTest() {
 
 func (s *OrderServiceTestSuite) TestPlaceOrderSuccess() {
 	order := makeOrder()
-	s.complianceService.On("VerifyOrderCompliance", order).Return(nil)
-	s.repo.On("CreateOrder", order).Return(1, nil)
+	s.complianceService.On("VerifyOrderCompliance", mock.Anything, order).Return(nil)
+	s.ledgerRepo.On("Post", mock.Anything, entryMoving("hold", order.UserID, models.NewMoney(1500))).Return(nil)
+	s.repo.On("CreateOrder", mock.Anything, order).Return(1, nil)
+	s.engine.On("Submit", order).Return([]*models.Execution{}, []*models.Order{})
+
+	err := s.service.PlaceOrder(context.Background(), order)
+
+	s.Require().NoError(err)
+	s.Equal(1, order.ID)
+	s.Equal(1, order.Version)
+	s.Equal("open", order.Status)
+	s.repo.AssertCalled(s.T(), "SaveOrderVersion", mock.Anything, order)
+	s.repo.AssertNotCalled(s.T(), "UpdateOrder", mock.Anything, mock.Anything)
+	s.ledgerRepo.AssertExpectations(s.T())
+}
+
+func (s *OrderServiceTestSuite) TestPlaceOrderInTheTradingCurrencyOfTheSymbol() {
+	order := makeOrder()
+	order.Symbol = "SHOP"
+	order.Currency = ""
+	s.symbolRepo.On("FindBySymbol", mock.Anything, "SHOP").Return(&models.Symbol{Symbol: "SHOP", Currency: "CAD"}, nil)
+	s.complianceService.On("VerifyOrderCompliance", mock.Anything, order).Return(nil)
+	s.ledgerRepo.On("Post", mock.Anything, mock.MatchedBy(func(entry *models.JournalEntry) bool {
+		return entry.Type == "hold" && entry.Postings[0].Currency == "CAD" && entry.Postings[1].Currency == "CAD"
+	})).Return(nil)
+	s.repo.On("CreateOrder", mock.Anything, order).Return(1, nil)
+	s.engine.On("Submit", order).Return([]*models.Execution{}, []*models.Order{})
+
+	err := s.service.PlaceOrder(context.Background(), order)
+
+	s.Require().NoError(err)
+	s.Equal("CAD", order.Currency)
+	s.ledgerRepo.AssertExpectations(s.T())
+}
+
+func (s *OrderServiceTestSuite) TestPlaceOrderUnlistedSymbolTradesInBaseCurrency() {
+	order := makeOrder()
+	order.Symbol = "XYZ"
+	order.Currency = ""
+	s.symbolRepo.On("FindBySymbol", mock.Anything, "XYZ").Return(nil, ports.ErrSymbolNotFound)
+	s.complianceService.On("VerifyOrderCompliance", mock.Anything, order).Return(assert.AnError)
+
+	err := s.service.PlaceOrder(context.Background(), order)
+
+	s.Error(err)
+	s.Equal(models.BaseCurrency, order.Currency)
+}
+
+func (s *OrderServiceTestSuite) TestPlaceOrderPriceFinerThanTheTradingCurrency() {
+	order := makeOrder()
+	order.Symbol = "SONY"
+	order.UnitPrice = models.MustParseMoney("2500.50")
+	s.symbolRepo.On("FindBySymbol", mock.Anything, "SONY").Return(&models.Symbol{Symbol: "SONY", Currency: "JPY"}, nil)
+
+	err := s.service.PlaceOrder(context.Background(), order)
+
+	s.ErrorIs(err, ports.ErrFractionalCents)
+	s.complianceService.AssertNotCalled(s.T(), "VerifyOrderCompliance", mock.Anything, mock.Anything)
+}
+
+func (s *OrderServiceTestSuite) TestPlaceOrderSymbolFailure() {
+	order := makeOrder()
+	order.Symbol = "MSFT"
+	s.symbolRepo.On("FindBySymbol", mock.Anything, "MSFT").Return(nil, assert.AnError)
+
+	err := s.service.PlaceOrder(context.Background(), order)
+
+	s.ErrorIs(err, assert.AnError)
+	s.repo.AssertNotCalled(s.T(), "CreateOrder", mock.Anything, mock.Anything)
+}
+
+func (s *OrderServiceTestSuite) TestPlaceOrderInsufficientFunds() {
+	order := makeOrder()
+	s.complianceService.On("VerifyOrderCompliance", mock.Anything, order).Return(nil)
+	s.repo.On("CreateOrder", mock.Anything, order).Return(1, nil)
+	s.ledgerRepo.On("Post", mock.Anything, entryMoving("hold", order.UserID, models.NewMoney(1500))).Return(ports.ErrInsufficientFunds)
+
+	err := s.service.PlaceOrder(context.Background(), order)
+
+	s.ErrorIs(err, ports.ErrInsufficientFunds)
+	s.engine.AssertNotCalled(s.T(), "Submit", mock.Anything)
+}
+
+func (s *OrderServiceTestSuite) TestPlaceSellOrderReservesShares() {
+	order := makeOrder()
+	order.Action = "sell"
+	s.complianceService.On("VerifyOrderCompliance", mock.Anything, order).Return(nil)
+	s.positionRepo.On("ReserveShares", mock.Anything, order.UserID, "AAPL", 10).Return(nil)
+	s.repo.On("CreateOrder", mock.Anything, order).Return(1, nil)
+	s.engine.On("Submit", order).Return([]*models.Execution{}, []*models.Order{})
+
+	err := s.service.PlaceOrder(context.Background(), order)
+
+	s.Require().NoError(err)
+	s.positionRepo.AssertExpectations(s.T())
+	s.ledgerRepo.AssertNotCalled(s.T(), "Post", mock.Anything, mock.Anything)
+}
+
+func (s *OrderServiceTestSuite) TestPlaceSellOrderReservesTheDesignatedLot() {
+	order := makeOrder()
+	order.Action = "sell"
+	order.LotID = 3
+	s.complianceService.On("VerifyOrderCompliance", mock.Anything, order).Return(nil)
+	s.positionRepo.On("ReserveShares", mock.Anything, order.UserID, "AAPL", 10).Return(nil)
+	s.taxLotRepo.On("ReserveLot", mock.Anything, 3, 10).Return(nil)
+	s.repo.On("CreateOrder", mock.Anything, order).Return(1, nil)
+	s.engine.On("Submit", order).Return([]*models.Execution{}, []*models.Order{})
+
+	err := s.service.PlaceOrder(context.Background(), order)
+
+	s.Require().NoError(err)
+	s.taxLotRepo.AssertExpectations(s.T())
+}
+
+func (s *OrderServiceTestSuite) TestPlaceSellOrderDesignatedLotAlreadyReserved() {
+	order := makeOrder()
+	order.Action = "sell"
+	order.LotID = 3
+	s.complianceService.On("VerifyOrderCompliance", mock.Anything, order).Return(nil)
+	s.positionRepo.On("ReserveShares", mock.Anything, order.UserID, "AAPL", 10).Return(nil)
+	s.taxLotRepo.On("ReserveLot", mock.Anything, 3, 10).Return(ports.ErrInsufficientShares)
+	s.repo.On("CreateOrder", mock.Anything, order).Return(1, nil)
+
+	err := s.service.PlaceOrder(context.Background(), order)
+
+	s.ErrorIs(err, ports.ErrInsufficientShares)
+	s.engine.AssertNotCalled(s.T(), "Submit", mock.Anything)
+}
+
+func (s *OrderServiceTestSuite) TestPlaceSellOrderInsufficientShares() {
+	order := makeOrder()
+	order.Action = "sell"
+	s.complianceService.On("VerifyOrderCompliance", mock.Anything, order).Return(nil)
+	s.repo.On("CreateOrder", mock.Anything, order).Return(1, nil)
+	s.positionRepo.On("ReserveShares", mock.Anything, order.UserID, "AAPL", 10).Return(ports.ErrInsufficientShares)
+
+	err := s.service.PlaceOrder(context.Background(), order)
+
+	s.ErrorIs(err, ports.ErrInsufficientShares)
+	s.engine.AssertNotCalled(s.T(), "Submit", mock.Anything)
+}
+
+func (s *OrderServiceTestSuite) TestPlaceOrderMatchedPersistsFills() {
+	order := makeOrder()
+	resting := makeOrder()
+	resting.ID = 9
+	resting.Action = "sell"
+	execution := &models.Execution{BuyOrderID: 2, SellOrderID: 9, Symbol: "AAPL", Quantity: 10, Price: models.NewMoney(148)}
+	s.complianceService.On("VerifyOrderCompliance", mock.Anything, order).Return(nil)
+	s.ledgerRepo.On("Post", mock.Anything, entryMoving("hold", order.UserID, models.NewMoney(1500))).Return(nil)
+	s.ledgerRepo.On("Post", mock.Anything, entryMoving("trade", resting.UserID, models.NewMoney(1480))).Return(nil)
+	s.positionRepo.On("AddShares", mock.Anything, order.UserID, "AAPL", 10, models.NewMoney(148)).Return(nil)
+	s.positionRepo.On("ConsumeReservedShares", mock.Anything, resting.UserID, "AAPL", 10, "USD", models.NewMoney(148)).Return(nil)
+	s.expectLotRelief(resting.UserID, execution)
+	s.repo.On("CreateOrder", mock.Anything, order).Return(2, nil)
+	s.executionRepo.On("CreateExecution", mock.Anything, execution).Return(7, nil)
+	s.engine.On("Submit", order).Return([]*models.Execution{execution}, []*models.Order{resting}).Run(func(args mock.Arguments) {
+		order.FilledQuantity, order.Status = 10, "filled"
+		resting.FilledQuantity, resting.Status = 10, "filled"
+	})
+	s.repo.On("UpdateOrder", mock.Anything, resting).Return(nil)
+	s.repo.On("UpdateOrder", mock.Anything, order).Return(nil)
+
+	err := s.service.PlaceOrder(context.Background(), order)
+
+	s.Require().NoError(err)
+	s.Equal(7, execution.ID)
+	s.repo.AssertNumberOfCalls(s.T(), "UpdateOrder", 2)
+	s.ledgerRepo.AssertExpectations(s.T())
+	s.positionRepo.AssertExpectations(s.T())
+}
+
+func (s *OrderServiceTestSuite) TestPlaceOrderMatchedSchedulesTheSettlement() {
+	s.service.SettlementDays = 1
+	s.service.Holidays = models.Holidays{"2026-12-25": true}
+	order := makeOrder()
+	resting := makeOrder()
+	resting.ID = 9
+	resting.Action = "sell"
+	// Thursday before Christmas settles on the following Monday
+	execution := &models.Execution{BuyOrderID: 2, SellOrderID: 9, Symbol: "AAPL", Quantity: 10, Price: models.NewMoney(148), ExecutedAt: time.Date(2026, 12, 24, 12, 0, 0, 0, time.UTC)}
+	s.complianceService.On("VerifyOrderCompliance", mock.Anything, order).Return(nil)
+	s.ledgerRepo.On("Post", mock.Anything, mock.Anything).Return(nil)
+	s.positionRepo.On("AddShares", mock.Anything, order.UserID, "AAPL", 10, models.NewMoney(148)).Return(nil)
+	s.positionRepo.On("ConsumeReservedShares", mock.Anything, resting.UserID, "AAPL", 10, "USD", models.NewMoney(148)).Return(nil)
+	s.expectLotRelief(resting.UserID, execution)
+	s.repo.On("CreateOrder", mock.Anything, order).Return(2, nil)
+	s.executionRepo.On("CreateExecution", mock.Anything, mock.MatchedBy(func(created *models.Execution) bool {
+		return created.SettlementDate.Equal(time.Date(2026, 12, 28, 0, 0, 0, 0, time.UTC))
+	})).Return(7, nil)
+	s.engine.On("Submit", order).Return([]*models.Execution{execution}, []*models.Order{resting}).Run(func(args mock.Arguments) {
+		order.FilledQuantity, order.Status = 10, "filled"
+		resting.FilledQuantity, resting.Status = 10, "filled"
+	})
+	s.repo.On("UpdateOrder", mock.Anything, mock.Anything).Return(nil)
+
+	err := s.service.PlaceOrder(context.Background(), order)
+
+	s.Require().NoError(err)
+	s.executionRepo.AssertExpectations(s.T())
+}
+
+func (s *OrderServiceTestSuite) TestPlaceOrderHoldsTheEstimatedCommission() {
+	s.service.Fees = models.FeeSchedule{PerShare: models.MustParseMoney("0.01"), Minimum: models.NewMoney(1)}
+	order := makeOrder()
+	s.complianceService.On("VerifyOrderCompliance", mock.Anything, order).Return(nil)
+	s.ledgerRepo.On("Post", mock.Anything, entryMoving("hold", order.UserID, models.NewMoney(1501))).Return(nil)
+	s.repo.On("CreateOrder", mock.Anything, order).Return(1, nil)
+	s.engine.On("Submit", order).Return([]*models.Execution{}, []*models.Order{})
+
+	err := s.service.PlaceOrder(context.Background(), order)
+
+	s.Require().NoError(err)
+	s.Equal(models.NewMoney(1), order.FeesOnHold)
+	s.ledgerRepo.AssertExpectations(s.T())
+}
+
+func (s *OrderServiceTestSuite) TestPlaceOrderMatchedChargesFees() {
+	s.service.Fees = models.FeeSchedule{PerOrder: models.NewMoney(1), Rate: big.NewRat(1, 1000), RegulatoryRate: big.NewRat(278, 10000000)}
+	order := makeOrder()
+	resting := makeOrder()
+	resting.ID = 9
+	resting.Action = "sell"
+	execution := &models.Execution{BuyOrderID: 2, SellOrderID: 9, Symbol: "AAPL", Quantity: 10, Price: models.NewMoney(148)}
+	s.complianceService.On("VerifyOrderCompliance", mock.Anything, order).Return(nil)
+	// 1 + 0.1% of 1500 is held for the commission
+	s.ledgerRepo.On("Post", mock.Anything, entryMoving("hold", order.UserID, models.MustParseMoney("1502.50"))).Return(nil)
+	s.ledgerRepo.On("Post", mock.Anything, mock.MatchedBy(func(entry *models.JournalEntry) bool {
+		return entry.Type == "trade" && s.Equal([]models.LedgerPosting{
+			{Account: "on_hold", UserID: order.UserID, Currency: "USD", Amount: models.MustParseMoney("-1502.50")},
+			{Account: "available", UserID: order.UserID, Currency: "USD", Amount: models.MustParseMoney("22.50")},
+			{Account: "unsettled", UserID: resting.UserID, Currency: "USD", Amount: models.NewMoney(1480)},
+		}, entry.Postings)
+	})).Return(nil)
+	s.ledgerRepo.On("Post", mock.Anything, mock.MatchedBy(func(entry *models.JournalEntry) bool {
+		return entry.Type == "fee" && entry.Reference == "execution:7" && s.Equal([]models.LedgerPosting{
+			{Account: "available", UserID: order.UserID, Currency: "USD", Amount: models.MustParseMoney("-2.48")},
+			{Account: "unsettled", UserID: resting.UserID, Currency: "USD", Amount: models.MustParseMoney("-2.53")},
+			{Account: "fee_revenue", Currency: "USD", Amount: models.MustParseMoney("4.96")},
+			{Account: "regulatory_fees", Currency: "USD", Amount: models.MustParseMoney("0.05")},
+		}, entry.Postings)
+	})).Return(nil)
+	s.positionRepo.On("AddShares", mock.Anything, order.UserID, "AAPL", 10, models.NewMoney(148)).Return(nil)
+	s.positionRepo.On("ConsumeReservedShares", mock.Anything, resting.UserID, "AAPL", 10, "USD", models.NewMoney(148)).Return(nil)
+	s.expectLotRelief(resting.UserID, execution)
+	s.repo.On("CreateOrder", mock.Anything, order).Return(2, nil)
+	s.executionRepo.On("CreateExecution", mock.Anything, execution).Return(7, nil)
+	s.engine.On("Submit", order).Return([]*models.Execution{execution}, []*models.Order{resting}).Run(func(args mock.Arguments) {
+		order.FilledQuantity, order.AverageFillPrice, order.Status = 10, models.NewMoney(148), "filled"
+		resting.FilledQuantity, resting.AverageFillPrice, resting.Status = 10, models.NewMoney(148), "filled"
+	})
+	s.repo.On("UpdateOrder", mock.Anything, mock.Anything).Return(nil)
+
+	err := s.service.PlaceOrder(context.Background(), order)
+
+	s.Require().NoError(err)
+	s.Equal(models.MustParseMoney("2.48"), execution.BuyCommission)
+	s.Equal(models.MustParseMoney("2.48"), execution.SellCommission)
+	s.Equal(models.MustParseMoney("0.05"), execution.RegulatoryFee)
+	s.Equal(models.MustParseMoney("2.48"), order.Commission)
+	s.Equal(models.MustParseMoney("2.48"), resting.Commission)
+	s.True(order.FeesOnHold.IsZero())
+	s.ledgerRepo.AssertExpectations(s.T())
+	s.confirmationRepo.AssertCalled(s.T(), "CreateConfirmation", mock.Anything, mock.MatchedBy(func(confirmation *models.Confirmation) bool {
+		return confirmation.OrderID == order.ID && confirmation.Side == "buy" && confirmation.Commission == models.MustParseMoney("2.48") && confirmation.RegulatoryFee.IsZero()
+	}))
+	s.confirmationRepo.AssertCalled(s.T(), "CreateConfirmation", mock.Anything, mock.MatchedBy(func(confirmation *models.Confirmation) bool {
+		return confirmation.OrderID == resting.ID && confirmation.Side == "sell" && confirmation.RegulatoryFee == models.MustParseMoney("0.05")
+	}))
+}
+
+func (s *OrderServiceTestSuite) TestPartialFillsChargeTheMinimumCommissionOnce() {
+	s.service.Fees = models.FeeSchedule{PerShare: models.MustParseMoney("0.10"), Minimum: models.NewMoney(5)}
+	order := makeOrder()
+	order.ID = 2
+	order.Status = "filled"
+	order.FilledQuantity, order.AverageFillPrice = 10, models.NewMoney(150)
+	order.Commission = models.NewMoney(5)
+	first := makeOrder()
+	first.ID, first.Action, first.Quantity = 9, "sell", 3
+	second := makeOrder()
+	second.ID, second.Action, second.Quantity = 10, "sell", 3
+	executions := []*models.Execution{
+		{BuyOrderID: 2, SellOrderID: 9, Symbol: "AAPL", Quantity: 3, Price: models.NewMoney(150)},
+		{BuyOrderID: 2, SellOrderID: 10, Symbol: "AAPL", Quantity: 3, Price: models.NewMoney(150)},
+	}
+	s.ledgerRepo.On("Post", mock.Anything, mock.Anything).Return(nil)
+	s.positionRepo.On("AddShares", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	s.positionRepo.On("ConsumeReservedShares", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	s.expectLotRelief(first.UserID, executions[0])
+	s.expectLotRelief(second.UserID, executions[1])
+	s.executionRepo.On("CreateExecution", mock.Anything, mock.Anything).Return(7, nil)
+	s.repo.On("UpdateOrder", mock.Anything, mock.Anything).Return(nil)
+	first.FilledQuantity, first.AverageFillPrice = 3, models.NewMoney(150)
+	second.FilledQuantity, second.AverageFillPrice = 3, models.NewMoney(150)
+
+	err := s.service.persistMatch(context.Background(), s.service.UnitOfWork.(*MockUnitOfWork).repos, order, executions, []*models.Order{first, second})
+
+	// The buy order reached the minimum on its earlier fill, the sells pay it on their first
+	s.Require().NoError(err)
+	s.True(executions[0].BuyCommission.IsZero())
+	s.True(executions[1].BuyCommission.IsZero())
+	s.Equal(models.NewMoney(5), executions[0].SellCommission)
+	s.Equal(models.NewMoney(5), executions[1].SellCommission)
+	s.Equal(models.NewMoney(5), order.Commission)
+}
+
+func (s *OrderServiceTestSuite) TestSellerFeesAreCappedAtTheProceeds() {
+	s.service.Fees = models.FeeSchedule{Minimum: models.NewMoney(1), RegulatoryRate: big.NewRat(278, 10000000)}
+	order := makeOrder()
+	order.ID, order.Quantity, order.UnitPrice, order.Status = 2, 1, models.MustParseMoney("0.50"), "filled"
+	order.FilledQuantity, order.AverageFillPrice = 1, models.MustParseMoney("0.50")
+	resting := makeOrder()
+	resting.ID, resting.Action, resting.Quantity, resting.UnitPrice, resting.Status = 9, "sell", 1, models.MustParseMoney("0.50"), "filled"
+	resting.FilledQuantity, resting.AverageFillPrice = 1, models.MustParseMoney("0.50")
+	execution := &models.Execution{BuyOrderID: 2, SellOrderID: 9, Symbol: "AAPL", Quantity: 1, Price: models.MustParseMoney("0.50")}
+	s.ledgerRepo.On("Post", mock.Anything, mock.MatchedBy(func(entry *models.JournalEntry) bool {
+		return entry.Type == "fee" && s.Equal([]models.LedgerPosting{
+			{Account: "available", UserID: order.UserID, Currency: "USD", Amount: models.NewMoney(-1)},
+			{Account: "unsettled", UserID: resting.UserID, Currency: "USD", Amount: models.MustParseMoney("-0.50")},
+			{Account: "fee_revenue", Currency: "USD", Amount: models.MustParseMoney("1.49")},
+			{Account: "regulatory_fees", Currency: "USD", Amount: models.MustParseMoney("0.01")},
+		}, entry.Postings)
+	})).Return(nil)
+	s.ledgerRepo.On("Post", mock.Anything, mock.Anything).Return(nil)
+	s.positionRepo.On("AddShares", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	s.positionRepo.On("ConsumeReservedShares", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	s.expectLotRelief(resting.UserID, execution)
+	s.executionRepo.On("CreateExecution", mock.Anything, execution).Return(7, nil)
+	s.repo.On("UpdateOrder", mock.Anything, mock.Anything).Return(nil)
+
+	err := s.service.persistMatch(context.Background(), s.service.UnitOfWork.(*MockUnitOfWork).repos, order, []*models.Execution{execution}, []*models.Order{resting})
+
+	// The minimum commission of the seller is cut to what the regulatory fee leaves of the proceeds
+	s.Require().NoError(err)
+	s.Equal(models.NewMoney(1), execution.BuyCommission)
+	s.Equal(models.MustParseMoney("0.49"), execution.SellCommission)
+	s.Equal(models.MustParseMoney("0.01"), execution.RegulatoryFee)
+	s.Equal(models.MustParseMoney("0.49"), resting.Commission)
+	s.ledgerRepo.AssertExpectations(s.T())
+}
+
+func (s *OrderServiceTestSuite) TestPlaceMarketOrderReleasesCanceledRemainder() {
+	order := makeOrder()
+	s.complianceService.On("VerifyOrderCompliance", mock.Anything, order).Return(nil)
+	s.ledgerRepo.On("Post", mock.Anything, entryMoving("hold", order.UserID, models.NewMoney(1500))).Return(nil)
+	s.repo.On("CreateOrder", mock.Anything, order).Return(3, nil)
+	s.engine.On("Submit", order).Return([]*models.Execution{}, []*models.Order{}).Run(func(args mock.Arguments) {
+		order.Status = "canceled"
+	})
+	s.repo.On("UpdateOrder", mock.Anything, order).Return(nil)
+	s.ledgerRepo.On("Post", mock.Anything, entryMoving("release", order.UserID, models.NewMoney(1500))).Return(nil)
 
-	err := s.service.PlaceOrder(order)
+	err := s.service.PlaceOrder(context.Background(), order)
 
 	s.Require().NoError(err)
+	s.ledgerRepo.AssertExpectations(s.T())
+}
+
+func (s *OrderServiceTestSuite) TestPlaceOrderTriggeredStopPersistsAndReleasesRemainder() {
+	order := makeOrder()
+	order.Type = "limit"
+	resting := makeOrder()
+	resting.ID = 9
+	resting.Type = "limit"
+	resting.Action = "sell"
+	stop := makeOrder()
+	stop.ID = 11
+	stop.Type = "stop"
+	stop.StopPrice = models.NewMoney(140)
+	execution := &models.Execution{BuyOrderID: 2, SellOrderID: 9, Symbol: "AAPL", Quantity: 10, Price: models.NewMoney(150)}
+	s.complianceService.On("VerifyOrderCompliance", mock.Anything, order).Return(nil)
+	s.ledgerRepo.On("Post", mock.Anything, entryMoving("hold", order.UserID, models.NewMoney(1500))).Return(nil)
+	s.ledgerRepo.On("Post", mock.Anything, entryMoving("trade", resting.UserID, models.NewMoney(1500))).Return(nil)
+	s.positionRepo.On("AddShares", mock.Anything, order.UserID, "AAPL", 10, models.NewMoney(150)).Return(nil)
+	s.positionRepo.On("ConsumeReservedShares", mock.Anything, resting.UserID, "AAPL", 10, "USD", models.NewMoney(150)).Return(nil)
+	s.expectLotRelief(resting.UserID, execution)
+	s.repo.On("CreateOrder", mock.Anything, order).Return(2, nil)
+	s.executionRepo.On("CreateExecution", mock.Anything, execution).Return(7, nil)
+	s.engine.On("Submit", order).Return([]*models.Execution{execution}, []*models.Order{resting, stop, resting}).Run(func(args mock.Arguments) {
+		order.FilledQuantity, order.Status = 10, "filled"
+		resting.FilledQuantity, resting.Status = 10, "filled"
+		stop.Type, stop.Status = "market", "canceled"
+	})
+	s.repo.On("UpdateOrder", mock.Anything, mock.Anything).Return(nil)
+	s.ledgerRepo.On("Post", mock.Anything, entryMoving("release", stop.UserID, models.NewMoney(1500))).Return(nil)
+
+	err := s.service.PlaceOrder(context.Background(), order)
+
+	s.Require().NoError(err)
+	s.repo.AssertNumberOfCalls(s.T(), "UpdateOrder", 3)
+	s.repo.AssertCalled(s.T(), "UpdateOrder", mock.Anything, stop)
+	s.ledgerRepo.AssertNumberOfCalls(s.T(), "Post", 3)
+}
+
+func (s *OrderServiceTestSuite) TestPlaceStopOrderActivatedWithoutFillIsUpdated() {
+	order := makeOrder()
+	order.Type = "stop_limit"
+	order.StopPrice = models.NewMoney(140)
+	s.complianceService.On("VerifyOrderCompliance", mock.Anything, order).Return(nil)
+	s.ledgerRepo.On("Post", mock.Anything, entryMoving("hold", order.UserID, models.NewMoney(1500))).Return(nil)
+	s.repo.On("CreateOrder", mock.Anything, order).Return(2, nil)
+	s.engine.On("Submit", order).Return([]*models.Execution{}, []*models.Order{}).Run(func(args mock.Arguments) {
+		order.Type = "limit"
+	})
+	s.repo.On("UpdateOrder", mock.Anything, order).Return(nil)
+
+	err := s.service.PlaceOrder(context.Background(), order)
+
+	s.Require().NoError(err)
+	s.repo.AssertCalled(s.T(), "UpdateOrder", mock.Anything, order)
+}
+
+func (s *OrderServiceTestSuite) TestPlaceOrderExecutionFailure() {
+	order := makeOrder()
+	execution := &models.Execution{Quantity: 10}
+	s.complianceService.On("VerifyOrderCompliance", mock.Anything, order).Return(nil)
+	s.ledgerRepo.On("Post", mock.Anything, entryMoving("hold", order.UserID, models.NewMoney(1500))).Return(nil)
+	s.repo.On("CreateOrder", mock.Anything, order).Return(4, nil)
+	s.engine.On("Submit", order).Return([]*models.Execution{execution}, []*models.Order{makeOrder()})
+	s.executionRepo.On("CreateExecution", mock.Anything, execution).Return(0, assert.AnError)
+
+	err := s.service.PlaceOrder(context.Background(), order)
+
+	s.Error(err)
+	s.repo.AssertNotCalled(s.T(), "UpdateOrder", mock.Anything, mock.Anything)
+}
+
+func (s *OrderServiceTestSuite) TestPlaceOrderFailedMatchIsUndoneOnTheBook() {
+	engine := &MatchingEngine{}
+	s.service.Engine = engine
+	ask := makeOrder()
+	ask.ID = 9
+	ask.Type = "limit"
+	ask.Action = "sell"
+	engine.Load(ask)
+	order := makeOrder()
+	order.Type = "limit"
+	s.complianceService.On("VerifyOrderCompliance", mock.Anything, order).Return(nil)
+	s.ledgerRepo.On("Post", mock.Anything, entryMoving("hold", order.UserID, models.NewMoney(1500))).Return(nil)
+	s.repo.On("CreateOrder", mock.Anything, order).Return(4, nil)
+	s.executionRepo.On("CreateExecution", mock.Anything, mock.Anything).Return(0, assert.AnError)
+
+	err := s.service.PlaceOrder(context.Background(), order)
+
+	s.ErrorIs(err, assert.AnError)
+	s.Equal([]*models.Order{ask}, engine.book("AAPL").Asks)
+	s.Equal(0, ask.FilledQuantity)
+	s.Equal("open", ask.Status)
+	s.Empty(engine.book("AAPL").Bids)
+}
+
+func (s *OrderServiceTestSuite) TestPlaceOrderUpdateFailure() {
+	order := makeOrder()
+	s.complianceService.On("VerifyOrderCompliance", mock.Anything, order).Return(nil)
+	s.ledgerRepo.On("Post", mock.Anything, entryMoving("hold", order.UserID, models.NewMoney(1500))).Return(nil)
+	s.repo.On("CreateOrder", mock.Anything, order).Return(3, nil)
+	s.engine.On("Submit", order).Return([]*models.Execution{}, []*models.Order{}).Run(func(args mock.Arguments) {
+		order.Status = "canceled"
+	})
+	s.repo.On("UpdateOrder", mock.Anything, order).Return(assert.AnError)
+
+	err := s.service.PlaceOrder(context.Background(), order)
+
+	s.Error(err)
 }
 
 func (s *OrderServiceTestSuite) TestPlaceOrderNonCompliance() {
 	order := makeOrder()
-	s.complianceService.On("VerifyOrderCompliance", order).Return(assert.AnError)
+	s.complianceService.On("VerifyOrderCompliance", mock.Anything, order).Return(assert.AnError)
 
-	err := s.service.PlaceOrder(order)
+	err := s.service.PlaceOrder(context.Background(), order)
 
 	s.Error(err)
 }
 
 func (s *OrderServiceTestSuite) TestPlaceOrderFailure() {
 	order := makeOrder()
-	s.complianceService.On("VerifyOrderCompliance", order).Return(nil)
-	s.repo.On("CreateOrder", order).Return(0, assert.AnError)
+	s.complianceService.On("VerifyOrderCompliance", mock.Anything, order).Return(nil)
+	s.repo.On("CreateOrder", mock.Anything, order).Return(0, assert.AnError)
 
-	err := s.service.PlaceOrder(order)
+	err := s.service.PlaceOrder(context.Background(), order)
 
 	s.Error(err)
+	s.engine.AssertNotCalled(s.T(), "Submit", mock.Anything)
+	s.ledgerRepo.AssertNotCalled(s.T(), "Post", mock.Anything, mock.Anything)
+}
+
+func (s *OrderServiceTestSuite) TestCancelOrderSuccess() {
+	order := makeOrder()
+	order.ID = 5
+	order.Status = "partially filled"
+	order.FilledQuantity = 4
+	s.repo.On("FindById", mock.Anything, 5).Return(order, nil)
+	s.engine.On("Cancel", order).Return(true)
+	s.repo.On("UpdateOrder", mock.Anything, order).Return(nil)
+	s.ledgerRepo.On("Post", mock.Anything, entryMoving("release", order.UserID, models.NewMoney(900))).Return(nil)
+
+	err := s.service.CancelOrder(context.Background(), order.UserID, 5)
+
+	s.Require().NoError(err)
+	s.Equal("canceled", order.Status)
+	s.engine.AssertCalled(s.T(), "Cancel", order)
+	s.ledgerRepo.AssertExpectations(s.T())
+}
+
+func (s *OrderServiceTestSuite) TestCancelSellOrderReleasesShares() {
+	order := makeOrder()
+	order.Action = "sell"
+	order.FilledQuantity = 3
+	s.repo.On("FindById", mock.Anything, 5).Return(order, nil)
+	s.engine.On("Cancel", order).Return(true)
+	s.repo.On("UpdateOrder", mock.Anything, order).Return(nil)
+	s.positionRepo.On("ReleaseShares", mock.Anything, order.UserID, "AAPL", 7).Return(nil)
+
+	err := s.service.CancelOrder(context.Background(), order.UserID, 5)
+
+	s.Require().NoError(err)
+	s.positionRepo.AssertExpectations(s.T())
+}
+
+func (s *OrderServiceTestSuite) TestCancelOrderUpdateFailureKeepsOrderOnBook() {
+	order := makeOrder()
+	s.repo.On("FindById", mock.Anything, 5).Return(order, nil)
+	s.repo.On("UpdateOrder", mock.Anything, order).Return(assert.AnError)
+
+	err := s.service.CancelOrder(context.Background(), order.UserID, 5)
+
+	s.Error(err)
+	s.engine.AssertNotCalled(s.T(), "Cancel", mock.Anything)
+}
+
+func (s *OrderServiceTestSuite) TestCancelOrderNotFound() {
+	s.repo.On("FindById", mock.Anything, 5).Return(nil, ports.ErrOrderNotFound)
+
+	err := s.service.CancelOrder(context.Background(), "user", 5)
+
+	s.ErrorIs(err, ports.ErrOrderNotFound)
+}
+
+func (s *OrderServiceTestSuite) TestCancelOrderNotOwned() {
+	order := makeOrder()
+	s.repo.On("FindById", mock.Anything, 5).Return(order, nil)
+
+	err := s.service.CancelOrder(context.Background(), "someone else", 5)
+
+	s.ErrorIs(err, ports.ErrOrderNotOwned)
+	s.engine.AssertNotCalled(s.T(), "Cancel", mock.Anything)
+}
+
+func (s *OrderServiceTestSuite) TestCancelOrderAlreadyFilled() {
+	order := makeOrder()
+	order.Status = "filled"
+	s.repo.On("FindById", mock.Anything, 5).Return(order, nil)
+
+	err := s.service.CancelOrder(context.Background(), order.UserID, 5)
+
+	s.ErrorIs(err, ports.ErrOrderNotCancelable)
+	s.repo.AssertNotCalled(s.T(), "UpdateOrder", mock.Anything, mock.Anything)
+}
+
+func (s *OrderServiceTestSuite) TestModifyOrderKeepsPriority() {
+	order := makeOrder()
+	order.ID = 6
+	order.Version = 1
+	order.QueuedAt = sql.NullTime{Time: time.Date(2025, 10, 1, 14, 0, 0, 0, time.UTC), Valid: true}
+	s.repo.On("FindById", mock.Anything, 6).Return(order, nil)
+	s.complianceService.On("VerifyOrderModificationCompliance", mock.Anything, order, mock.Anything).Return(nil)
+	s.ledgerRepo.On("Post", mock.Anything, entryMoving("release", order.UserID, models.NewMoney(750))).Return(nil)
+	s.engine.On("Replace", mock.Anything).Return([]*models.Execution(nil), []*models.Order(nil))
+	s.repo.On("UpdateOrder", mock.Anything, mock.Anything).Return(nil)
+
+	err := s.service.ModifyOrder(context.Background(), order.UserID, 6, 5, models.NewMoney(150))
+
+	s.Require().NoError(err)
+	modified := s.replaced()
+	s.Equal(5, modified.Quantity)
+	s.Equal(2, modified.Version)
+	s.Equal(10, order.Quantity)
+	s.Equal(order.QueuedAt, modified.QueuedAt)
+	s.repo.AssertCalled(s.T(), "UpdateOrder", mock.Anything, modified)
+	s.repo.AssertCalled(s.T(), "SaveOrderVersion", mock.Anything, modified)
+	s.ledgerRepo.AssertExpectations(s.T())
+}
+
+func (s *OrderServiceTestSuite) TestModifyOrderPersistsExecutions() {
+	order := makeOrder()
+	order.ID = 6
+	resting := makeOrder()
+	resting.ID = 9
+	resting.Action = "sell"
+	execution := &models.Execution{BuyOrderID: 6, SellOrderID: 9, Symbol: "AAPL", Quantity: 10, Price: models.NewMoney(150)}
+	s.repo.On("FindById", mock.Anything, 6).Return(order, nil)
+	s.complianceService.On("VerifyOrderModificationCompliance", mock.Anything, order, mock.Anything).Return(nil)
+	s.ledgerRepo.On("Post", mock.Anything, entryMoving("hold", order.UserID, models.NewMoney(50))).Return(nil)
+	s.ledgerRepo.On("Post", mock.Anything, entryMoving("trade", resting.UserID, models.NewMoney(1500))).Return(nil)
+	s.positionRepo.On("AddShares", mock.Anything, order.UserID, "AAPL", 10, models.NewMoney(150)).Return(nil)
+	s.positionRepo.On("ConsumeReservedShares", mock.Anything, resting.UserID, "AAPL", 10, "USD", models.NewMoney(150)).Return(nil)
+	s.expectLotRelief(resting.UserID, execution)
+	s.engine.On("Replace", mock.Anything).Return([]*models.Execution{execution}, []*models.Order{resting})
+	s.executionRepo.On("CreateExecution", mock.Anything, execution).Return(8, nil)
+	s.repo.On("UpdateOrder", mock.Anything, mock.Anything).Return(nil)
+
+	err := s.service.ModifyOrder(context.Background(), order.UserID, 6, 10, models.NewMoney(155))
+
+	s.Require().NoError(err)
+	s.Equal(8, execution.ID)
+	modified := s.replaced()
+	s.True(modified.QueuedAt.Valid)
+	s.repo.AssertCalled(s.T(), "UpdateOrder", mock.Anything, resting)
+	s.ledgerRepo.AssertExpectations(s.T())
+}
+
+func (s *OrderServiceTestSuite) TestModifyOrderFailedMatchIsUndoneOnTheBook() {
+	engine := &MatchingEngine{}
+	s.service.Engine = engine
+	ask := makeOrder()
+	ask.ID = 9
+	ask.Type = "limit"
+	ask.Action = "sell"
+	engine.Load(ask)
+	bid := makeOrder()
+	bid.ID = 6
+	bid.Type = "limit"
+	bid.UnitPrice = models.NewMoney(140)
+	engine.Load(bid)
+	stored := *bid
+	s.repo.On("FindById", mock.Anything, 6).Return(&stored, nil)
+	s.complianceService.On("VerifyOrderModificationCompliance", mock.Anything, &stored, mock.Anything).Return(nil)
+	s.ledgerRepo.On("Post", mock.Anything, entryMoving("hold", bid.UserID, models.NewMoney(100))).Return(nil)
+	s.repo.On("UpdateOrder", mock.Anything, mock.Anything).Return(nil)
+	s.executionRepo.On("CreateExecution", mock.Anything, mock.Anything).Return(0, assert.AnError)
+
+	err := s.service.ModifyOrder(context.Background(), bid.UserID, 6, 10, models.NewMoney(150))
+
+	s.ErrorIs(err, assert.AnError)
+	s.Equal([]*models.Order{bid}, engine.book("AAPL").Bids)
+	s.Equal(models.NewMoney(140), bid.UnitPrice)
+	s.Equal([]*models.Order{ask}, engine.book("AAPL").Asks)
+	s.Equal(0, ask.FilledQuantity)
+	s.Equal("open", ask.Status)
+}
+
+func (s *OrderServiceTestSuite) TestModifyOrderInsufficientFunds() {
+	order := makeOrder()
+	s.repo.On("FindById", mock.Anything, 6).Return(order, nil)
+	s.complianceService.On("VerifyOrderModificationCompliance", mock.Anything, order, mock.Anything).Return(nil)
+	s.ledgerRepo.On("Post", mock.Anything, entryMoving("hold", order.UserID, models.NewMoney(1500))).Return(ports.ErrInsufficientFunds)
+
+	err := s.service.ModifyOrder(context.Background(), order.UserID, 6, 20, models.NewMoney(150))
+
+	s.ErrorIs(err, ports.ErrInsufficientFunds)
+	s.engine.AssertNotCalled(s.T(), "Replace", mock.Anything)
+}
+
+func (s *OrderServiceTestSuite) TestModifySellOrderReservesAdditionalShares() {
+	order := makeOrder()
+	order.Action = "sell"
+	s.repo.On("FindById", mock.Anything, 6).Return(order, nil)
+	s.complianceService.On("VerifyOrderModificationCompliance", mock.Anything, order, mock.Anything).Return(nil)
+	s.positionRepo.On("ReserveShares", mock.Anything, order.UserID, "AAPL", 5).Return(nil)
+	s.engine.On("Replace", mock.Anything).Return([]*models.Execution(nil), []*models.Order(nil))
+	s.repo.On("UpdateOrder", mock.Anything, mock.Anything).Return(nil)
+
+	err := s.service.ModifyOrder(context.Background(), order.UserID, 6, 15, models.NewMoney(150))
+
+	s.Require().NoError(err)
+	s.positionRepo.AssertExpectations(s.T())
+}
+
+func (s *OrderServiceTestSuite) TestModifyOrderInvalid() {
+	order := makeOrder()
+	order.FilledQuantity = 4
+	order.Status = "partially filled"
+	s.repo.On("FindById", mock.Anything, 6).Return(order, nil)
+
+	err := s.service.ModifyOrder(context.Background(), order.UserID, 6, 4, models.NewMoney(150))
+
+	s.ErrorIs(err, ports.ErrInvalidModification)
+	s.engine.AssertNotCalled(s.T(), "Replace", mock.Anything)
+}
+
+func (s *OrderServiceTestSuite) TestModifyOrderNotModifiable() {
+	order := makeOrder()
+	order.Status = "canceled"
+	s.repo.On("FindById", mock.Anything, 6).Return(order, nil)
+
+	err := s.service.ModifyOrder(context.Background(), order.UserID, 6, 20, models.NewMoney(150))
+
+	s.ErrorIs(err, ports.ErrOrderNotModifiable)
+}
+
+func (s *OrderServiceTestSuite) TestModifyOrderNonCompliance() {
+	order := makeOrder()
+	s.repo.On("FindById", mock.Anything, 6).Return(order, nil)
+	s.complianceService.On("VerifyOrderModificationCompliance", mock.Anything, order, mock.Anything).Return(assert.AnError)
+
+	err := s.service.ModifyOrder(context.Background(), order.UserID, 6, 20, models.NewMoney(150))
+
+	s.Error(err)
+	s.engine.AssertNotCalled(s.T(), "Replace", mock.Anything)
+	s.repo.AssertNotCalled(s.T(), "UpdateOrder", mock.Anything, mock.Anything)
+}
+
+func (s *OrderServiceTestSuite) TestGetOrder() {
+	order := makeOrder()
+	s.repo.On("FindById", mock.Anything, 5).Return(order, nil)
+
+	found, err := s.service.GetOrder(context.Background(), order.UserID, 5)
+
+	s.Require().NoError(err)
+	s.Equal(order, found)
+}
+
+func (s *OrderServiceTestSuite) TestGetOrderNotOwned() {
+	order := makeOrder()
+	s.repo.On("FindById", mock.Anything, 5).Return(order, nil)
+
+	found, err := s.service.GetOrder(context.Background(), "someone else", 5)
+
+	s.ErrorIs(err, ports.ErrOrderNotOwned)
+	s.Nil(found)
+}
+
+func (s *OrderServiceTestSuite) TestListOrdersLastPage() {
+	orders := []*models.Order{{ID: 3}, {ID: 2}}
+	filter := models.OrderFilter{Status: "open", Limit: 2}
+	s.repo.On("FindByUserId", mock.Anything, "user", models.OrderFilter{Status: "open", Limit: 3}).Return(orders, nil)
+
+	page, err := s.service.ListOrders(context.Background(), "user", filter)
+
+	s.Require().NoError(err)
+	s.Equal(orders, page.Orders)
+	s.Equal(0, page.NextCursor)
+}
+
+func (s *OrderServiceTestSuite) TestListOrdersHasNextPage() {
+	orders := []*models.Order{{ID: 9}, {ID: 7}, {ID: 4}}
+	s.repo.On("FindByUserId", mock.Anything, "user", models.OrderFilter{Cursor: 10, Limit: 3}).Return(orders, nil)
+
+	page, err := s.service.ListOrders(context.Background(), "user", models.OrderFilter{Cursor: 10, Limit: 2})
+
+	s.Require().NoError(err)
+	s.Equal(orders[:2], page.Orders)
+	s.Equal(7, page.NextCursor)
+}
+
+func (s *OrderServiceTestSuite) TestListOrdersPageSize() {
+	s.repo.On("FindByUserId", mock.Anything, "user", models.OrderFilter{Limit: defaultOrderPageSize + 1}).Return([]*models.Order{}, nil)
+	s.repo.On("FindByUserId", mock.Anything, "user", models.OrderFilter{Limit: maxOrderPageSize + 1}).Return([]*models.Order{}, nil)
+
+	_, err := s.service.ListOrders(context.Background(), "user", models.OrderFilter{})
+	s.Require().NoError(err)
+	_, err = s.service.ListOrders(context.Background(), "user", models.OrderFilter{Limit: 1000})
+	s.Require().NoError(err)
+
+	s.repo.AssertExpectations(s.T())
+}
+
+func (s *OrderServiceTestSuite) TestExpireDayOrdersReleasesReservations() {
+	sessionClose := time.Date(2025, 10, 1, 16, 0, 0, 0, time.UTC)
+	buy := makeOrder()
+	buy.ID = 5
+	buy.Type = "limit"
+	buy.FilledQuantity = 4
+	sell := makeOrder()
+	sell.ID = 6
+	sell.Type = "limit"
+	sell.Action = "sell"
+	s.repo.On("FindExpirableOrders", mock.Anything, "day", sessionClose).Return([]*models.Order{buy, sell}, nil)
+	s.repo.On("UpdateOrder", mock.Anything, mock.Anything).Return(nil)
+	s.ledgerRepo.On("Post", mock.Anything, entryMoving("release", buy.UserID, models.NewMoney(900))).Return(nil)
+	s.positionRepo.On("ReleaseShares", mock.Anything, sell.UserID, "AAPL", 10).Return(nil)
+	s.engine.On("Cancel", mock.Anything).Return(true)
+
+	expired, err := s.service.ExpireDayOrders(context.Background(), sessionClose)
+
+	s.Require().NoError(err)
+	s.Equal(2, expired)
+	s.Equal("expired", buy.Status)
+	s.Equal("expired", sell.Status)
+	s.engine.AssertCalled(s.T(), "Cancel", buy)
+	s.engine.AssertCalled(s.T(), "Cancel", sell)
+	s.ledgerRepo.AssertExpectations(s.T())
+	s.positionRepo.AssertExpectations(s.T())
+}
+
+func (s *OrderServiceTestSuite) TestExpireDayOrdersSkipsOrderThatFailsToExpire() {
+	sessionClose := time.Date(2025, 10, 1, 16, 0, 0, 0, time.UTC)
+	failing := makeOrder()
+	failing.ID = 5
+	order := makeOrder()
+	order.ID = 6
+	s.repo.On("FindExpirableOrders", mock.Anything, "day", sessionClose).Return([]*models.Order{failing, order}, nil)
+	s.repo.On("UpdateOrder", mock.Anything, failing).Return(assert.AnError)
+	s.repo.On("UpdateOrder", mock.Anything, order).Return(nil)
+	s.ledgerRepo.On("Post", mock.Anything, entryMoving("release", order.UserID, models.NewMoney(1500))).Return(nil)
+	s.engine.On("Cancel", order).Return(true)
+
+	expired, err := s.service.ExpireDayOrders(context.Background(), sessionClose)
+
+	s.Require().NoError(err)
+	s.Equal(1, expired)
+	s.engine.AssertNotCalled(s.T(), "Cancel", failing)
+}
+
+func (s *OrderServiceTestSuite) TestExpireDayOrdersFindError() {
+	s.repo.On("FindExpirableOrders", mock.Anything, "day", mock.Anything).Return(nil, assert.AnError)
+
+	expired, err := s.service.ExpireDayOrders(context.Background(), time.Now())
+
+	s.Error(err)
+	s.Equal(0, expired)
+}
+
+func (s *OrderServiceTestSuite) TestExpireGoodTillDateOrders() {
+	now := time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)
+	order := makeOrder()
+	order.ID = 5
+	order.Type = "limit"
+	order.Timing = "gtd"
+	order.ExpiresAt = sql.NullTime{Time: now.Add(-time.Minute), Valid: true}
+	s.repo.On("FindExpiredGoodTillDateOrders", mock.Anything, now).Return([]*models.Order{order}, nil)
+	s.repo.On("UpdateOrder", mock.Anything, order).Return(nil)
+	s.ledgerRepo.On("Post", mock.Anything, entryMoving("release", order.UserID, models.NewMoney(1500))).Return(nil)
+	s.engine.On("Cancel", order).Return(true)
+
+	expired, err := s.service.ExpireGoodTillDateOrders(context.Background(), now)
+
+	s.Require().NoError(err)
+	s.Equal(1, expired)
+	s.Equal("expired", order.Status)
+	s.engine.AssertCalled(s.T(), "Cancel", order)
 }
 
 // ---------------------------
 // Run the suite
 // ---------------------------
+func (s *OrderServiceTestSuite) TestLoadOrdersRestoresTheBooks() {
+	now := time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)
+	resting := makeOrder()
+	resting.ID = 5
+	resting.Type = "limit"
+	resting.Timing = "gtc"
+	market := makeOrder()
+	market.ID = 6
+	s.repo.On("FindExpiredGoodTillDateOrders", mock.Anything, now).Return([]*models.Order{}, nil)
+	s.repo.On("FindActiveOrders", mock.Anything).Return([]*models.Order{resting, market}, nil)
+	s.engine.On("Load", resting).Return(true)
+	s.engine.On("Load", market).Return(false)
+
+	loaded, err := s.service.LoadOrders(context.Background(), now)
+
+	s.Require().NoError(err)
+	s.Equal(1, loaded)
+	s.engine.AssertExpectations(s.T())
+}
+
+func (s *OrderServiceTestSuite) TestLoadOrdersExpiresLapsedGoodTillDateOrdersFirst() {
+	now := time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)
+	lapsed := makeOrder()
+	lapsed.ID = 5
+	lapsed.Type = "limit"
+	lapsed.Timing = "gtd"
+	lapsed.ExpiresAt = sql.NullTime{Time: now.Add(-time.Hour), Valid: true}
+	s.repo.On("FindExpiredGoodTillDateOrders", mock.Anything, now).Return([]*models.Order{lapsed}, nil)
+	s.repo.On("UpdateOrder", mock.Anything, lapsed).Return(nil)
+	s.ledgerRepo.On("Post", mock.Anything, entryMoving("release", lapsed.UserID, models.NewMoney(1500))).Return(nil)
+	s.engine.On("Cancel", lapsed).Return(false)
+	s.repo.On("FindActiveOrders", mock.Anything).Return([]*models.Order{}, nil)
+
+	loaded, err := s.service.LoadOrders(context.Background(), now)
+
+	s.Require().NoError(err)
+	s.Equal(0, loaded)
+	s.Equal("expired", lapsed.Status)
+	s.engine.AssertNotCalled(s.T(), "Load", mock.Anything)
+	s.ledgerRepo.AssertExpectations(s.T())
+}
+
+func (s *OrderServiceTestSuite) TestLoadOrdersFindError() {
+	s.repo.On("FindExpiredGoodTillDateOrders", mock.Anything, mock.Anything).Return([]*models.Order{}, nil)
+	s.repo.On("FindActiveOrders", mock.Anything).Return(nil, assert.AnError)
+
+	_, err := s.service.LoadOrders(context.Background(), time.Now())
+
+	s.ErrorIs(err, assert.AnError)
+	s.engine.AssertNotCalled(s.T(), "Load", mock.Anything)
+}
+
 func TestOrderServiceTestSuite(t *testing.T) {
 	suite.Run(t, new(OrderServiceTestSuite))
 }
@@ -0,0 +1,58 @@
+package core
+
+import (
+	"brokerx/ports"
+	"context"
+	"errors"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// PaymentPoller resolves the pending deposits and the processing withdrawals with the
+// payment provider at a fixed interval.
+type PaymentPoller struct {
+	Deposits    ports.DepositService
+	Withdrawals ports.WalletService
+	Interval    time.Duration
+}
+
+// Start refreshes the pending payments in the background until ctx is done.
+func (poller *PaymentPoller) Start(ctx context.Context) error {
+	if poller.Interval <= 0 {
+		return errors.New("payment poll interval must be positive")
+	}
+
+	go func() {
+		ticker := time.NewTicker(poller.Interval)
+		defer ticker.Stop()
+
+		for {
+			poller.refresh(ctx)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	return nil
+}
+
+func (poller *PaymentPoller) refresh(ctx context.Context) {
+	resolved, err := poller.Deposits.RefreshPendingDeposits(ctx)
+	if err != nil {
+		log.Errorf("Failed to refresh pending deposits: %v", err)
+	} else if resolved > 0 {
+		log.Infof("Resolved %d pending deposits", resolved)
+	}
+
+	resolved, err = poller.Withdrawals.RefreshProcessingWithdrawals(ctx)
+	if err != nil {
+		log.Errorf("Failed to refresh processing withdrawals: %v", err)
+	} else if resolved > 0 {
+		log.Infof("Resolved %d processing withdrawals", resolved)
+	}
+}
@@ -0,0 +1,48 @@
+package core
+
+import (
+	"brokerx/models"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestPaymentPollerInvalidInterval(t *testing.T) {
+	poller := &PaymentPoller{Interval: 0}
+
+	err := poller.Start(context.Background())
+
+	assert.Error(t, err)
+}
+
+func TestPaymentPollerRefreshesUntilDone(t *testing.T) {
+	refreshed := make(chan struct{}, 1)
+	depositRepo := new(MockDepositRepo)
+	depositRepo.On("FindPending", mock.Anything).Return([]*models.Deposit{}, nil)
+	withdrawalRepo := new(MockWithdrawalRepo)
+	withdrawalRepo.On("FindByStatus", mock.Anything, "processing").Return([]*models.Withdrawal{}, nil).Run(func(args mock.Arguments) {
+		select {
+		case refreshed <- struct{}{}:
+		default:
+		}
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	poller := &PaymentPoller{
+		Deposits:    &DepositService{Repo: depositRepo},
+		Withdrawals: &WalletService{Repo: withdrawalRepo},
+		Interval:    time.Millisecond,
+	}
+
+	err := poller.Start(ctx)
+
+	assert.NoError(t, err)
+	select {
+	case <-refreshed:
+	case <-time.After(time.Second):
+		t.Fatal("pending payments were never refreshed")
+	}
+}
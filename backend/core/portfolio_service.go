@@ -0,0 +1,94 @@
+package core
+
+import (
+	"brokerx/models"
+	"brokerx/ports"
+	"context"
+	"errors"
+	"sort"
+)
+
+type PortfolioService struct {
+	PositionRepo ports.PositionRepository
+	ExecutionRepo ports.ExecutionRepository
+	SymbolRepo ports.SymbolRepository
+}
+
+// GetPortfolio aggregates the positions of the user per symbol. The average cost of a
+// holding is weighted by the quantity of each position, and the holding is valued at the
+// price of the latest execution of its symbol. The totals of the portfolio are kept per
+// trading currency, their realized P&L sums the closed and open positions of the user.
+func (service *PortfolioService) GetPortfolio(ctx context.Context, userID string) (*models.Portfolio, error) {
+	positions, err := service.PositionRepo.FindByUserId(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	portfolio := &models.Portfolio{}
+	holdings := make(map[string]*models.Holding)
+	totals := make(map[string]*models.PortfolioTotal)
+	for _, position := range positions {
+		total, err := service.total(ctx, portfolio, totals, position.Symbol)
+		if err != nil {
+			return nil, err
+		}
+		total.RealizedPnL = total.RealizedPnL.Add(position.RealizedPnL)
+		if position.Quantity == 0 {
+			continue
+		}
+
+		holding, ok := holdings[position.Symbol]
+		if !ok {
+			holding = &models.Holding{Symbol: position.Symbol, Currency: total.Currency}
+			holdings[position.Symbol] = holding
+			portfolio.Holdings = append(portfolio.Holdings, holding)
+		}
+		holding.Quantity += position.Quantity
+		holding.UnsettledQuantity += position.UnsettledQuantity
+		holding.CostBasis = holding.CostBasis.Add(position.UnitPrice.Mul(position.Quantity))
+	}
+
+	for _, holding := range portfolio.Holdings {
+		holding.AverageCost = holding.CostBasis.Div(holding.Quantity, models.RoundHalfEven)
+
+		price, err := service.ExecutionRepo.FindLatestPrice(ctx, holding.Symbol)
+		if errors.Is(err, ports.ErrPriceNotFound) {
+			price = holding.AverageCost
+		} else if err != nil {
+			return nil, err
+		}
+
+		holding.LastPrice = price
+		holding.MarketValue = price.Mul(holding.Quantity)
+		holding.UnrealizedPnL = holding.MarketValue.Sub(holding.CostBasis)
+
+		total := totals[holding.Currency]
+		total.CostBasis = total.CostBasis.Add(holding.CostBasis)
+		total.MarketValue = total.MarketValue.Add(holding.MarketValue)
+		total.UnrealizedPnL = total.UnrealizedPnL.Add(holding.UnrealizedPnL)
+	}
+
+	sort.Slice(portfolio.Totals, func(i, j int) bool {
+		return portfolio.Totals[i].Currency < portfolio.Totals[j].Currency
+	})
+	return portfolio, nil
+}
+
+// total returns the total of the portfolio in the trading currency of the symbol, adding
+// it to the portfolio on first use.
+func (service *PortfolioService) total(ctx context.Context, portfolio *models.Portfolio, totals map[string]*models.PortfolioTotal, symbol string) (*models.PortfolioTotal, error) {
+	currency, err := tradingCurrency(ctx, service.SymbolRepo, symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	total, ok := totals[currency]
+	if !ok {
+		total = &models.PortfolioTotal{Currency: currency}
+		totals[currency] = total
+		portfolio.Totals = append(portfolio.Totals, total)
+	}
+	return total, nil
+}
+
+var _ ports.PortfolioService = (*PortfolioService)(nil) // Ensure interface is implemented at compile time
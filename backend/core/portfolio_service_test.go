@@ -0,0 +1,146 @@
+package core
+
+import (
+	"brokerx/models"
+	"brokerx/ports"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+// ---------------------------
+// Test Suite
+// ---------------------------
+
+type PortfolioServiceTestSuite struct {
+	suite.Suite
+	positionRepo  *MockPositionsRepo
+	executionRepo *MockExecutionRepo
+	symbolRepo    *MockSymbolRepo
+	service       *PortfolioService
+	UserID        string
+}
+
+func (s *PortfolioServiceTestSuite) SetupTest() {
+	s.positionRepo = new(MockPositionsRepo)
+	s.executionRepo = new(MockExecutionRepo)
+	s.symbolRepo = new(MockSymbolRepo)
+	s.service = &PortfolioService{PositionRepo: s.positionRepo, ExecutionRepo: s.executionRepo, SymbolRepo: s.symbolRepo}
+	s.UserID = "user"
+	s.symbolRepo.On("FindBySymbol", mock.Anything, "7203").Return(&models.Symbol{Symbol: "7203", Currency: "JPY"}, nil).Maybe()
+	s.symbolRepo.On("FindBySymbol", mock.Anything, mock.Anything).Return(nil, ports.ErrSymbolNotFound).Maybe()
+}
+
+// ---------------------------
+// Tests
+// ---------------------------
+
+func (s *PortfolioServiceTestSuite) TestGetPortfolioAggregatesPositionsPerSymbol() {
+	s.positionRepo.On("FindByUserId", mock.Anything, s.UserID).Return([]*models.Position{
+		{UserId: s.UserID, Symbol: "AAPL", Quantity: 10, UnitPrice: models.NewMoney(100)},
+		{UserId: s.UserID, Symbol: "AAPL", Quantity: 30, UnsettledQuantity: 5, UnitPrice: models.NewMoney(120)},
+		{UserId: s.UserID, Symbol: "MSFT", Quantity: 5, UnitPrice: models.NewMoney(300)},
+		{UserId: s.UserID, Symbol: "TSLA", Quantity: 0, UnitPrice: models.NewMoney(200), RealizedPnL: models.NewMoney(75), Status: "closed"},
+	}, nil)
+	s.executionRepo.On("FindLatestPrice", mock.Anything, "AAPL").Return(models.NewMoney(125), nil)
+	s.executionRepo.On("FindLatestPrice", mock.Anything, "MSFT").Return(models.NewMoney(290), nil)
+
+	portfolio, err := s.service.GetPortfolio(context.Background(), s.UserID)
+
+	s.Require().NoError(err)
+	s.Require().Len(portfolio.Holdings, 2)
+	aapl := portfolio.Holdings[0]
+	s.Equal("AAPL", aapl.Symbol)
+	s.Equal(40, aapl.Quantity)
+	s.Equal(5, aapl.UnsettledQuantity)
+	s.Equal(models.NewMoney(4600), aapl.CostBasis)
+	s.Equal(models.NewMoney(115), aapl.AverageCost)
+	s.Equal(models.NewMoney(5000), aapl.MarketValue)
+	s.Equal(models.NewMoney(400), aapl.UnrealizedPnL)
+	msft := portfolio.Holdings[1]
+	s.Equal("MSFT", msft.Symbol)
+	s.Equal(models.NewMoney(-50), msft.UnrealizedPnL)
+	s.Equal([]*models.PortfolioTotal{{Currency: "USD", CostBasis: models.NewMoney(6100), MarketValue: models.NewMoney(6450),
+		UnrealizedPnL: models.NewMoney(350), RealizedPnL: models.NewMoney(75)}}, portfolio.Totals)
+	s.executionRepo.AssertNotCalled(s.T(), "FindLatestPrice", mock.Anything, "TSLA")
+}
+
+func (s *PortfolioServiceTestSuite) TestGetPortfolioWithoutTradesValuesAtCost() {
+	s.positionRepo.On("FindByUserId", mock.Anything, s.UserID).Return([]*models.Position{
+		{UserId: s.UserID, Symbol: "AAPL", Quantity: 10, UnitPrice: models.NewMoney(100)},
+	}, nil)
+	s.executionRepo.On("FindLatestPrice", mock.Anything, "AAPL").Return(models.NewMoney(0), ports.ErrPriceNotFound)
+
+	portfolio, err := s.service.GetPortfolio(context.Background(), s.UserID)
+
+	s.Require().NoError(err)
+	s.Require().Len(portfolio.Holdings, 1)
+	s.Equal(models.NewMoney(100), portfolio.Holdings[0].LastPrice)
+	s.Require().Len(portfolio.Totals, 1)
+	s.Equal(models.NewMoney(1000), portfolio.Totals[0].MarketValue)
+	s.Equal(models.NewMoney(0), portfolio.Totals[0].UnrealizedPnL)
+}
+
+func (s *PortfolioServiceTestSuite) TestGetPortfolioKeepsTotalsPerCurrency() {
+	s.positionRepo.On("FindByUserId", mock.Anything, s.UserID).Return([]*models.Position{
+		{UserId: s.UserID, Symbol: "AAPL", Quantity: 10, UnitPrice: models.NewMoney(100)},
+		{UserId: s.UserID, Symbol: "7203", Quantity: 100, UnitPrice: models.NewMoney(2500), RealizedPnL: models.NewMoney(3000)},
+	}, nil)
+	s.executionRepo.On("FindLatestPrice", mock.Anything, "AAPL").Return(models.NewMoney(110), nil)
+	s.executionRepo.On("FindLatestPrice", mock.Anything, "7203").Return(models.NewMoney(2400), nil)
+
+	portfolio, err := s.service.GetPortfolio(context.Background(), s.UserID)
+
+	s.Require().NoError(err)
+	s.Require().Len(portfolio.Holdings, 2)
+	s.Equal("USD", portfolio.Holdings[0].Currency)
+	s.Equal("JPY", portfolio.Holdings[1].Currency)
+	s.Equal([]*models.PortfolioTotal{
+		{Currency: "JPY", CostBasis: models.NewMoney(250000), MarketValue: models.NewMoney(240000), UnrealizedPnL: models.NewMoney(-10000), RealizedPnL: models.NewMoney(3000)},
+		{Currency: "USD", CostBasis: models.NewMoney(1000), MarketValue: models.NewMoney(1100), UnrealizedPnL: models.NewMoney(100)},
+	}, portfolio.Totals)
+}
+
+func (s *PortfolioServiceTestSuite) TestGetPortfolioEmpty() {
+	s.positionRepo.On("FindByUserId", mock.Anything, s.UserID).Return([]*models.Position{}, nil)
+
+	portfolio, err := s.service.GetPortfolio(context.Background(), s.UserID)
+
+	s.Require().NoError(err)
+	s.Empty(portfolio.Holdings)
+	s.Empty(portfolio.Totals)
+}
+
+func (s *PortfolioServiceTestSuite) TestGetPortfolioErrors() {
+	s.positionRepo.On("FindByUserId", mock.Anything, s.UserID).Return(nil, assert.AnError).Once()
+
+	portfolio, err := s.service.GetPortfolio(context.Background(), s.UserID)
+	s.Nil(portfolio)
+	s.ErrorIs(err, assert.AnError)
+
+	s.positionRepo.On("FindByUserId", mock.Anything, s.UserID).Return([]*models.Position{{Symbol: "AAPL", Quantity: 1, UnitPrice: models.NewMoney(100)}}, nil)
+	s.executionRepo.On("FindLatestPrice", mock.Anything, "AAPL").Return(models.NewMoney(0), assert.AnError)
+
+	portfolio, err = s.service.GetPortfolio(context.Background(), s.UserID)
+	s.Nil(portfolio)
+	s.ErrorIs(err, assert.AnError)
+
+	s.positionRepo.On("FindByUserId", mock.Anything, "other").Return([]*models.Position{{Symbol: "MSFT", Quantity: 1, UnitPrice: models.NewMoney(100)}}, nil)
+	symbolRepo := new(MockSymbolRepo)
+	symbolRepo.On("FindBySymbol", mock.Anything, "MSFT").Return(nil, assert.AnError)
+	s.service.SymbolRepo = symbolRepo
+
+	portfolio, err = s.service.GetPortfolio(context.Background(), "other")
+	s.Nil(portfolio)
+	s.ErrorIs(err, assert.AnError)
+}
+
+// ---------------------------
+// Run the suite
+// ---------------------------
+func TestPortfolioServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(PortfolioServiceTestSuite))
+}
@@ -0,0 +1,55 @@
+package core
+
+import (
+	"brokerx/ports"
+	"context"
+	"errors"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// ReconciliationJob reconciles the wallets against the ledger at a fixed interval and
+// flags every wallet that disagrees with it.
+type ReconciliationJob struct {
+	Service  ports.LedgerService
+	Interval time.Duration
+}
+
+// Start reconciles the wallets in the background until ctx is done.
+func (job *ReconciliationJob) Start(ctx context.Context) error {
+	if job.Interval <= 0 {
+		return errors.New("reconciliation interval must be positive")
+	}
+
+	go func() {
+		ticker := time.NewTicker(job.Interval)
+		defer ticker.Stop()
+
+		for {
+			job.reconcile(ctx)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	return nil
+}
+
+func (job *ReconciliationJob) reconcile(ctx context.Context) {
+	discrepancies, err := job.Service.Reconcile(ctx)
+	if err != nil {
+		log.Errorf("Failed to reconcile the wallets: %v", err)
+		return
+	}
+
+	for _, discrepancy := range discrepancies {
+		log.Warnf("Wallet of user %s disagrees with the ledger: available %s stored, %s in the ledger; on hold %s stored, %s in the ledger; unsettled %s stored, %s in the ledger",
+			discrepancy.UserID, discrepancy.StoredAvailableFunds, discrepancy.LedgerAvailableFunds, discrepancy.StoredOnHoldFunds, discrepancy.LedgerOnHoldFunds,
+			discrepancy.StoredUnsettledFunds, discrepancy.LedgerUnsettledFunds)
+	}
+}
@@ -0,0 +1,58 @@
+package core
+
+import (
+	"brokerx/ports"
+	"context"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// SettlementJob settles the trades due on the current date at the close of every trading
+// session.
+type SettlementJob struct {
+	Service      ports.SettlementService
+	SessionClose string // HH:MM in the server's local time
+}
+
+// Start checks the session close time and settles trades in the background until ctx is
+// done. Trades that came due while the server was down are settled right away.
+func (job *SettlementJob) Start(ctx context.Context) error {
+	sessionClose, err := time.Parse("15:04", job.SessionClose)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		job.settle(ctx, previousSessionClose(time.Now(), sessionClose))
+
+		for {
+			next := nextSessionClose(time.Now(), sessionClose)
+			timer := time.NewTimer(time.Until(next))
+
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+				job.settle(ctx, next)
+			}
+		}
+	}()
+
+	return nil
+}
+
+// settle settles the trades due on the calendar date of the session close. Settlement
+// dates are stored at midnight UTC.
+func (job *SettlementJob) settle(ctx context.Context, sessionClose time.Time) {
+	date := time.Date(sessionClose.Year(), sessionClose.Month(), sessionClose.Day(), 0, 0, 0, 0, time.UTC)
+	settled, err := job.Service.SettleTrades(ctx, date)
+	if err != nil {
+		log.Errorf("Failed to settle the trades due on %s: %v", date.Format("2006-01-02"), err)
+		return
+	}
+	if settled > 0 {
+		log.Infof("Settled %d trades due on %s", settled, date.Format("2006-01-02"))
+	}
+}
@@ -0,0 +1,62 @@
+package core
+
+import (
+	"brokerx/models"
+	"brokerx/ports"
+	"context"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// SettlementService settles the trades whose settlement date has come: the proceeds of
+// the seller become available funds and the shares of the buyer can be sold.
+type SettlementService struct {
+	ExecutionRepo ports.ExecutionRepository
+	UnitOfWork    ports.UnitOfWork
+}
+
+// SettleTrades settles every unsettled execution due on or before the date. Each
+// execution settles in its own transaction, one that fails is logged and left unsettled
+// so that the next run can retry it. It returns the number of settled executions.
+func (service *SettlementService) SettleTrades(ctx context.Context, settlementDate time.Time) (int, error) {
+	executions, err := service.ExecutionRepo.FindUnsettled(ctx, settlementDate)
+	if err != nil {
+		return 0, err
+	}
+
+	settled := 0
+	for _, execution := range executions {
+		if err := service.settle(ctx, execution); err != nil {
+			log.Errorf("Failed to settle execution %d: %v", execution.ID, err)
+			continue
+		}
+		settled++
+	}
+	return settled, nil
+}
+
+func (service *SettlementService) settle(ctx context.Context, execution *models.Execution) error {
+	return service.UnitOfWork.Execute(ctx, func(repos ports.Repositories) error {
+		buyOrder, err := repos.Orders.FindById(ctx, execution.BuyOrderID)
+		if err != nil {
+			return err
+		}
+		sellOrder, err := repos.Orders.FindById(ctx, execution.SellOrderID)
+		if err != nil {
+			return err
+		}
+
+		if err := repos.Executions.MarkSettled(ctx, execution.ID, time.Now().UTC()); err != nil {
+			return err
+		}
+		if entry := settlementEntry(sellOrder, execution); entry != nil {
+			if err := repos.Ledger.Post(ctx, entry); err != nil {
+				return err
+			}
+		}
+		return repos.Positions.SettleShares(ctx, buyOrder.UserID, execution.Symbol, execution.Quantity)
+	})
+}
+
+var _ ports.SettlementService = (*SettlementService)(nil) // Ensure interface is implemented at compile time
@@ -0,0 +1,110 @@
+package core
+
+import (
+	"brokerx/models"
+	"brokerx/ports"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+// ---------------------------
+// Test Suite
+// ---------------------------
+
+type SettlementServiceTestSuite struct {
+	suite.Suite
+	orderRepo     *MockOrderRepo
+	executionRepo *MockExecutionRepo
+	ledgerRepo    *MockLedgerRepo
+	positionRepo  *MockPositionsRepo
+	service       *SettlementService
+	date          time.Time
+	buyOrder      *models.Order
+	sellOrder     *models.Order
+}
+
+func (s *SettlementServiceTestSuite) SetupTest() {
+	s.orderRepo = new(MockOrderRepo)
+	s.executionRepo = new(MockExecutionRepo)
+	s.ledgerRepo = new(MockLedgerRepo)
+	s.positionRepo = new(MockPositionsRepo)
+	s.service = &SettlementService{
+		ExecutionRepo: s.executionRepo,
+		UnitOfWork: &MockUnitOfWork{repos: ports.Repositories{
+			Orders:     s.orderRepo,
+			Executions: s.executionRepo,
+			Ledger:     s.ledgerRepo,
+			Positions:  s.positionRepo,
+		}},
+	}
+	s.date = time.Date(2026, 10, 21, 0, 0, 0, 0, time.UTC)
+	s.buyOrder = &models.Order{ID: 1, UserID: "buyer", Currency: "USD", Action: "buy"}
+	s.sellOrder = &models.Order{ID: 2, UserID: "seller", Currency: "USD", Action: "sell"}
+	s.orderRepo.On("FindById", mock.Anything, 1).Return(s.buyOrder, nil)
+	s.orderRepo.On("FindById", mock.Anything, 2).Return(s.sellOrder, nil)
+}
+
+func (s *SettlementServiceTestSuite) makeExecution(id int) *models.Execution {
+	return &models.Execution{ID: id, BuyOrderID: 1, SellOrderID: 2, Symbol: "AAPL", Quantity: 10, Price: models.NewMoney(150), SellCommission: models.NewMoney(1), SettlementDate: s.date}
+}
+
+// ---------------------------
+// Tests
+// ---------------------------
+
+func (s *SettlementServiceTestSuite) TestSettleTradesReleasesProceedsAndShares() {
+	execution := s.makeExecution(7)
+	s.executionRepo.On("FindUnsettled", mock.Anything, s.date).Return([]*models.Execution{execution}, nil)
+	s.executionRepo.On("MarkSettled", mock.Anything, 7, mock.Anything).Return(nil)
+	s.ledgerRepo.On("Post", mock.Anything, mock.MatchedBy(func(entry *models.JournalEntry) bool {
+		return entry.Type == "settlement" && entry.Reference == "execution:7" && s.Equal([]models.LedgerPosting{
+			{Account: "unsettled", UserID: "seller", Currency: "USD", Amount: models.NewMoney(-1499)},
+			{Account: "available", UserID: "seller", Currency: "USD", Amount: models.NewMoney(1499)},
+		}, entry.Postings)
+	})).Return(nil)
+	s.positionRepo.On("SettleShares", mock.Anything, "buyer", "AAPL", 10).Return(nil)
+
+	settled, err := s.service.SettleTrades(context.Background(), s.date)
+
+	s.Require().NoError(err)
+	s.Equal(1, settled)
+	s.ledgerRepo.AssertExpectations(s.T())
+	s.positionRepo.AssertExpectations(s.T())
+}
+
+func (s *SettlementServiceTestSuite) TestSettleTradesSkipsFailures() {
+	s.executionRepo.On("FindUnsettled", mock.Anything, s.date).Return([]*models.Execution{s.makeExecution(7), s.makeExecution(8)}, nil)
+	s.executionRepo.On("MarkSettled", mock.Anything, 7, mock.Anything).Return(ports.ErrAlreadySettled)
+	s.executionRepo.On("MarkSettled", mock.Anything, 8, mock.Anything).Return(nil)
+	s.ledgerRepo.On("Post", mock.Anything, mock.Anything).Return(nil)
+	s.positionRepo.On("SettleShares", mock.Anything, "buyer", "AAPL", 10).Return(nil)
+
+	settled, err := s.service.SettleTrades(context.Background(), s.date)
+
+	s.Require().NoError(err)
+	s.Equal(1, settled)
+	s.ledgerRepo.AssertNumberOfCalls(s.T(), "Post", 1)
+	s.positionRepo.AssertNumberOfCalls(s.T(), "SettleShares", 1)
+}
+
+func (s *SettlementServiceTestSuite) TestSettleTradesFailure() {
+	s.executionRepo.On("FindUnsettled", mock.Anything, s.date).Return(nil, assert.AnError)
+
+	settled, err := s.service.SettleTrades(context.Background(), s.date)
+
+	s.ErrorIs(err, assert.AnError)
+	s.Zero(settled)
+}
+
+// ---------------------------
+// Run Test Suite
+// ---------------------------
+
+func TestSettlementServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(SettlementServiceTestSuite))
+}
@@ -0,0 +1,308 @@
+package core
+
+import (
+	"brokerx/models"
+	"brokerx/ports"
+	"context"
+	"errors"
+	"sort"
+	"time"
+)
+
+// StatementService builds the account statements of the users from their ledger entries,
+// deposits, withdrawals, orders, executions and tax lots.
+type StatementService struct {
+	OrderRepo      ports.OrderRepository
+	ExecutionRepo  ports.ExecutionRepository
+	LedgerRepo     ports.LedgerRepository
+	DepositRepo    ports.DepositRepository
+	WithdrawalRepo ports.WithdrawalRepository
+	TaxLotRepo     ports.TaxLotRepository
+	SymbolRepo     ports.SymbolRepository
+}
+
+// GenerateStatement lists the cash of every wallet at the start and at the end of the
+// period, the deposits and withdrawals requested and the trades executed during the
+// period, the gains realized by its sales and the holdings of the user at its end. The
+// lists are ordered oldest first.
+func (service *StatementService) GenerateStatement(ctx context.Context, userID string, from time.Time, to time.Time) (*models.Statement, error) {
+	if !to.After(from) {
+		return nil, ports.ErrInvalidPeriod
+	}
+
+	statement := &models.Statement{UserID: userID, From: from, To: to, GeneratedAt: time.Now().UTC()}
+	if err := service.addCash(ctx, statement); err != nil {
+		return nil, err
+	}
+	if err := service.addPayments(ctx, statement); err != nil {
+		return nil, err
+	}
+	orders := map[int]*models.Order{}
+	if err := service.addTrades(ctx, statement, orders); err != nil {
+		return nil, err
+	}
+	reliefs, err := service.TaxLotRepo.FindReliefsByUserId(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := service.addGains(ctx, statement, reliefs); err != nil {
+		return nil, err
+	}
+	if err := service.addHoldings(ctx, statement, reliefs, orders); err != nil {
+		return nil, err
+	}
+
+	return statement, nil
+}
+
+// addCash sums the postings to the wallets of the user made before the start and before
+// the end of the period.
+func (service *StatementService) addCash(ctx context.Context, statement *models.Statement) error {
+	entries, err := service.LedgerRepo.FindEntriesByUserId(ctx, statement.UserID)
+	if err != nil {
+		return err
+	}
+
+	wallets := map[string]*models.StatementCash{}
+	for _, entry := range entries {
+		if !entry.CreatedAt.Valid || !entry.CreatedAt.Time.Before(statement.To) {
+			continue
+		}
+		for _, posting := range entry.Postings {
+			cash, ok := wallets[posting.Currency]
+			if !ok {
+				cash = &models.StatementCash{Currency: posting.Currency}
+				wallets[posting.Currency] = cash
+				statement.Cash = append(statement.Cash, cash)
+			}
+			if entry.CreatedAt.Time.Before(statement.From) {
+				cash.Opening = cash.Opening.Add(posting.Amount)
+			}
+			cash.Closing = cash.Closing.Add(posting.Amount)
+		}
+	}
+
+	sort.Slice(statement.Cash, func(i, j int) bool {
+		return statement.Cash[i].Currency < statement.Cash[j].Currency
+	})
+	return nil
+}
+
+// addPayments adds the deposits and the withdrawals requested during the period, whatever
+// their status.
+func (service *StatementService) addPayments(ctx context.Context, statement *models.Statement) error {
+	deposits, err := service.DepositRepo.FindByUserId(ctx, statement.UserID)
+	if err != nil {
+		return err
+	}
+	for i := len(deposits) - 1; i >= 0; i-- {
+		if deposits[i].CreatedAt.Valid && inPeriod(deposits[i].CreatedAt.Time, statement) {
+			statement.Deposits = append(statement.Deposits, deposits[i])
+		}
+	}
+
+	withdrawals, err := service.WithdrawalRepo.FindByUserId(ctx, statement.UserID)
+	if err != nil {
+		return err
+	}
+	for i := len(withdrawals) - 1; i >= 0; i-- {
+		if withdrawals[i].CreatedAt.Valid && inPeriod(withdrawals[i].CreatedAt.Time, statement) {
+			statement.Withdrawals = append(statement.Withdrawals, withdrawals[i])
+		}
+	}
+	return nil
+}
+
+// addTrades adds a trade for each side of the executions that the user took. A user who
+// traded with themselves gets both sides.
+func (service *StatementService) addTrades(ctx context.Context, statement *models.Statement, orders map[int]*models.Order) error {
+	executions, err := service.ExecutionRepo.FindByUserIdAndPeriod(ctx, statement.UserID, statement.From, statement.To)
+	if err != nil {
+		return err
+	}
+
+	for _, execution := range executions {
+		for _, id := range []int{execution.BuyOrderID, execution.SellOrderID} {
+			order, err := service.findOrder(ctx, orders, id)
+			if err != nil {
+				return err
+			}
+			if order.UserID != statement.UserID {
+				continue
+			}
+
+			trade := &models.StatementTrade{
+				ExecutionID:    execution.ID,
+				OrderID:        order.ID,
+				ExecutedAt:     execution.ExecutedAt,
+				SettlementDate: execution.SettlementDate,
+				Symbol:         execution.Symbol,
+				Side:           order.Action,
+				Quantity:       execution.Quantity,
+				Price:          execution.Price,
+				Currency:       order.Currency,
+				Commission:     execution.BuyCommission,
+			}
+			if order.Action == "sell" {
+				trade.Commission = execution.SellCommission
+				trade.RegulatoryFee = execution.RegulatoryFee
+			}
+			statement.Trades = append(statement.Trades, trade)
+		}
+	}
+	return nil
+}
+
+// addGains adds the gains realized by the lots relieved during the period, and sums them
+// per trading currency. The reliefs are ordered newest first.
+func (service *StatementService) addGains(ctx context.Context, statement *models.Statement, reliefs []*models.LotRelief) error {
+	currencies := map[string]string{}
+	for i := len(reliefs) - 1; i >= 0; i-- {
+		relief := reliefs[i]
+		if !inPeriod(relief.RelievedAt, statement) {
+			continue
+		}
+
+		currency, ok := currencies[relief.Symbol]
+		if !ok {
+			var err error
+			if currency, err = tradingCurrency(ctx, service.SymbolRepo, relief.Symbol); err != nil {
+				return err
+			}
+			currencies[relief.Symbol] = currency
+		}
+
+		statement.Gains = append(statement.Gains, &models.StatementGain{
+			ExecutionID:  relief.ExecutionID,
+			RelievedAt:   relief.RelievedAt,
+			Symbol:       relief.Symbol,
+			Currency:     currency,
+			Quantity:     relief.Quantity,
+			CostBasis:    relief.CostBasis,
+			Proceeds:     relief.Proceeds,
+			RealizedGain: relief.RealizedGain,
+			Term:         relief.Term,
+		})
+		statement.RealizedPnL = addToTotal(statement.RealizedPnL, currency, relief.RealizedGain)
+	}
+
+	sortTotals(statement.RealizedPnL)
+	return nil
+}
+
+// addHoldings rebuilds the holdings of the user at the end of the period from the tax lots
+// acquired before it, less the shares relieved before it. The shares bought by executions
+// that had not settled by then are unsettled. A holding is valued at the price of the last
+// execution of its symbol before the end of the period, and the market values are summed
+// per trading currency.
+func (service *StatementService) addHoldings(ctx context.Context, statement *models.Statement, reliefs []*models.LotRelief, orders map[int]*models.Order) error {
+	lots, err := service.TaxLotRepo.FindByUserId(ctx, statement.UserID)
+	if err != nil {
+		return err
+	}
+
+	relieved := map[int]int{}
+	for _, relief := range reliefs {
+		if relief.RelievedAt.Before(statement.To) {
+			relieved[relief.LotID] += relief.Quantity
+		}
+	}
+
+	holdings := map[string]*models.Holding{}
+	for _, lot := range lots {
+		quantity := lot.Quantity - relieved[lot.ID]
+		if !lot.AcquiredAt.Before(statement.To) || quantity <= 0 {
+			continue
+		}
+		holding, ok := holdings[lot.Symbol]
+		if !ok {
+			holding = &models.Holding{Symbol: lot.Symbol}
+			holdings[lot.Symbol] = holding
+			statement.Holdings = append(statement.Holdings, holding)
+		}
+		holding.Quantity += quantity
+		holding.CostBasis = holding.CostBasis.Add(lot.UnitPrice.Mul(quantity))
+	}
+	if len(holdings) == 0 {
+		return nil
+	}
+
+	executions, err := service.ExecutionRepo.FindByUserIdAndPeriod(ctx, statement.UserID, time.Time{}, statement.To)
+	if err != nil {
+		return err
+	}
+	for _, execution := range executions {
+		holding, ok := holdings[execution.Symbol]
+		if !ok || (execution.SettledAt.Valid && execution.SettledAt.Time.Before(statement.To)) {
+			continue
+		}
+		order, err := service.findOrder(ctx, orders, execution.BuyOrderID)
+		if err != nil {
+			return err
+		}
+		if order.UserID == statement.UserID {
+			holding.UnsettledQuantity = min(holding.UnsettledQuantity+execution.Quantity, holding.Quantity)
+		}
+	}
+
+	for _, holding := range statement.Holdings {
+		currency, err := tradingCurrency(ctx, service.SymbolRepo, holding.Symbol)
+		if err != nil {
+			return err
+		}
+		holding.Currency = currency
+		holding.AverageCost = holding.CostBasis.Div(holding.Quantity, models.RoundHalfEven)
+
+		price, err := service.ExecutionRepo.FindLatestPriceBefore(ctx, holding.Symbol, statement.To)
+		if errors.Is(err, ports.ErrPriceNotFound) {
+			price = holding.AverageCost
+		} else if err != nil {
+			return err
+		}
+		holding.LastPrice = price
+		holding.MarketValue = price.Mul(holding.Quantity)
+		holding.UnrealizedPnL = holding.MarketValue.Sub(holding.CostBasis)
+
+		statement.MarketValues = addToTotal(statement.MarketValues, currency, holding.MarketValue)
+	}
+
+	sortTotals(statement.MarketValues)
+	return nil
+}
+
+// findOrder returns the order with the id, looking it up once per statement.
+func (service *StatementService) findOrder(ctx context.Context, orders map[int]*models.Order, id int) (*models.Order, error) {
+	if order, ok := orders[id]; ok {
+		return order, nil
+	}
+	order, err := service.OrderRepo.FindById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	orders[id] = order
+	return order, nil
+}
+
+// addToTotal adds the amount to the total of its currency, starting the total on first
+// use.
+func addToTotal(totals []*models.StatementTotal, currency string, amount models.Money) []*models.StatementTotal {
+	for _, total := range totals {
+		if total.Currency == currency {
+			total.Amount = total.Amount.Add(amount)
+			return totals
+		}
+	}
+	return append(totals, &models.StatementTotal{Currency: currency, Amount: amount})
+}
+
+func sortTotals(totals []*models.StatementTotal) {
+	sort.Slice(totals, func(i, j int) bool {
+		return totals[i].Currency < totals[j].Currency
+	})
+}
+
+func inPeriod(at time.Time, statement *models.Statement) bool {
+	return !at.Before(statement.From) && at.Before(statement.To)
+}
+
+var _ ports.StatementService = (*StatementService)(nil) // Ensure interface is implemented at compile time
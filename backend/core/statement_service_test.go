@@ -0,0 +1,202 @@
+package core
+
+import (
+	"brokerx/models"
+	"brokerx/ports"
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+// ---------------------------
+// Test Suite
+// ---------------------------
+
+type StatementServiceTestSuite struct {
+	suite.Suite
+	orderRepo      *MockOrderRepo
+	executionRepo  *MockExecutionRepo
+	ledgerRepo     *MockLedgerRepo
+	depositRepo    *MockDepositRepo
+	withdrawalRepo *MockWithdrawalRepo
+	taxLotRepo     *MockTaxLotRepo
+	symbolRepo     *MockSymbolRepo
+	service        *StatementService
+	from           time.Time
+	to             time.Time
+}
+
+func (s *StatementServiceTestSuite) SetupTest() {
+	s.orderRepo = new(MockOrderRepo)
+	s.executionRepo = new(MockExecutionRepo)
+	s.ledgerRepo = new(MockLedgerRepo)
+	s.depositRepo = new(MockDepositRepo)
+	s.withdrawalRepo = new(MockWithdrawalRepo)
+	s.taxLotRepo = new(MockTaxLotRepo)
+	s.symbolRepo = new(MockSymbolRepo)
+	s.service = &StatementService{
+		OrderRepo:      s.orderRepo,
+		ExecutionRepo:  s.executionRepo,
+		LedgerRepo:     s.ledgerRepo,
+		DepositRepo:    s.depositRepo,
+		WithdrawalRepo: s.withdrawalRepo,
+		TaxLotRepo:     s.taxLotRepo,
+		SymbolRepo:     s.symbolRepo,
+	}
+	s.symbolRepo.On("FindBySymbol", mock.Anything, "7203").Return(&models.Symbol{Symbol: "7203", Currency: "JPY"}, nil).Maybe()
+	s.symbolRepo.On("FindBySymbol", mock.Anything, mock.Anything).Return(nil, ports.ErrSymbolNotFound).Maybe()
+	s.from = time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	s.to = time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+}
+
+func septemberAt(day int) sql.NullTime {
+	return sql.NullTime{Time: time.Date(2026, 9, day, 12, 0, 0, 0, time.UTC), Valid: true}
+}
+
+// ---------------------------
+// Tests
+// ---------------------------
+
+func (s *StatementServiceTestSuite) TestGenerateStatement() {
+	s.ledgerRepo.On("FindEntriesByUserId", mock.Anything, "user").Return([]*models.JournalEntry{
+		// After the period
+		{ID: 4, CreatedAt: sql.NullTime{Time: s.to, Valid: true}, Postings: []models.LedgerPosting{{Account: "available", Currency: "USD", Amount: models.NewMoney(50)}}},
+		{ID: 3, CreatedAt: septemberAt(10), Postings: []models.LedgerPosting{
+			{Account: "available", Currency: "USD", Amount: models.NewMoney(-100)},
+			{Account: "on_hold", Currency: "USD", Amount: models.NewMoney(100)},
+		}},
+		{ID: 2, CreatedAt: septemberAt(5), Postings: []models.LedgerPosting{{Account: "available", Currency: "CAD", Amount: models.NewMoney(137)}}},
+		// Before the period
+		{ID: 1, CreatedAt: sql.NullTime{Time: s.from.Add(-time.Hour), Valid: true}, Postings: []models.LedgerPosting{{Account: "available", Currency: "USD", Amount: models.NewMoney(1000)}}},
+	}, nil)
+	s.depositRepo.On("FindByUserId", mock.Anything, "user").Return([]*models.Deposit{
+		{ID: 3, Amount: models.NewMoney(30), CreatedAt: sql.NullTime{Time: s.to, Valid: true}},
+		{ID: 2, Amount: models.NewMoney(20), CreatedAt: septemberAt(2)},
+		{ID: 1, Amount: models.NewMoney(10), CreatedAt: septemberAt(1)},
+	}, nil)
+	s.withdrawalRepo.On("FindByUserId", mock.Anything, "user").Return([]*models.Withdrawal{
+		{ID: 1, Amount: models.NewMoney(5), CreatedAt: sql.NullTime{Time: s.from.Add(-time.Second), Valid: true}},
+	}, nil)
+	s.executionRepo.On("FindByUserIdAndPeriod", mock.Anything, "user", s.from, s.to).Return([]*models.Execution{
+		{ID: 7, BuyOrderID: 1, SellOrderID: 2, Symbol: "AAPL", Quantity: 10, Price: models.NewMoney(150), BuyCommission: models.NewMoney(1), SellCommission: models.NewMoney(2), RegulatoryFee: models.MustParseMoney("0.05"), ExecutedAt: septemberAt(3).Time},
+		{ID: 8, BuyOrderID: 3, SellOrderID: 2, Symbol: "AAPL", Quantity: 5, Price: models.NewMoney(151), SellCommission: models.NewMoney(1), ExecutedAt: septemberAt(4).Time},
+	}, nil)
+	s.orderRepo.On("FindById", mock.Anything, 1).Return(&models.Order{ID: 1, UserID: "other", Action: "buy", Currency: "USD"}, nil)
+	s.orderRepo.On("FindById", mock.Anything, 2).Return(&models.Order{ID: 2, UserID: "user", Action: "sell", Currency: "USD"}, nil).Once()
+	s.orderRepo.On("FindById", mock.Anything, 3).Return(&models.Order{ID: 3, UserID: "other", Action: "buy", Currency: "USD"}, nil)
+	s.taxLotRepo.On("FindReliefsByUserId", mock.Anything, "user").Return([]*models.LotRelief{
+		{ID: 3, LotID: 3, ExecutionID: 10, Symbol: "7203", Quantity: 100, RealizedGain: models.NewMoney(5000), RelievedAt: septemberAt(5).Time},
+		{ID: 2, LotID: 1, ExecutionID: 8, Quantity: 5, RealizedGain: models.NewMoney(5), RelievedAt: septemberAt(4).Time},
+		{ID: 1, LotID: 1, ExecutionID: 7, Quantity: 10, RealizedGain: models.NewMoney(-2), RelievedAt: septemberAt(3).Time},
+	}, nil)
+	s.taxLotRepo.On("FindByUserId", mock.Anything, "user").Return([]*models.TaxLot{
+		{ID: 1, Symbol: "AAPL", Quantity: 15, UnitPrice: models.NewMoney(140), AcquiredAt: s.from.Add(-time.Hour)},
+		{ID: 2, Symbol: "MSFT", Quantity: 2, UnitPrice: models.NewMoney(290), AcquiredAt: septemberAt(20).Time},
+	}, nil)
+	s.executionRepo.On("FindByUserIdAndPeriod", mock.Anything, "user", time.Time{}, s.to).Return([]*models.Execution{
+		{ID: 9, BuyOrderID: 4, SellOrderID: 5, Symbol: "MSFT", Quantity: 2, Price: models.NewMoney(290), ExecutedAt: septemberAt(20).Time},
+	}, nil)
+	s.orderRepo.On("FindById", mock.Anything, 4).Return(&models.Order{ID: 4, UserID: "user", Action: "buy", Currency: "USD"}, nil)
+	s.executionRepo.On("FindLatestPriceBefore", mock.Anything, "MSFT", s.to).Return(models.NewMoney(300), nil)
+
+	statement, err := s.service.GenerateStatement(context.Background(), "user", s.from, s.to)
+
+	s.Require().NoError(err)
+	s.Equal([]*models.StatementCash{
+		{Currency: "CAD", Opening: models.NewMoney(0), Closing: models.NewMoney(137)},
+		{Currency: "USD", Opening: models.NewMoney(1000), Closing: models.NewMoney(1000)},
+	}, statement.Cash)
+	s.Require().Len(statement.Deposits, 2)
+	s.Equal(1, statement.Deposits[0].ID)
+	s.Equal(2, statement.Deposits[1].ID)
+	s.Empty(statement.Withdrawals)
+	s.Require().Len(statement.Trades, 2)
+	s.Equal(&models.StatementTrade{ExecutionID: 7, OrderID: 2, ExecutedAt: septemberAt(3).Time, Symbol: "AAPL", Side: "sell", Quantity: 10, Price: models.NewMoney(150), Currency: "USD",
+		Commission: models.NewMoney(2), RegulatoryFee: models.MustParseMoney("0.05")}, statement.Trades[0])
+	s.Equal(models.MustParseMoney("1497.95"), statement.Trades[0].Amount())
+	s.Equal(8, statement.Trades[1].ExecutionID)
+	s.Require().Len(statement.Gains, 3)
+	s.Equal(7, statement.Gains[0].ExecutionID)
+	s.Equal("USD", statement.Gains[0].Currency)
+	s.Equal(&models.StatementGain{ExecutionID: 10, RelievedAt: septemberAt(5).Time, Symbol: "7203", Currency: "JPY", Quantity: 100, RealizedGain: models.NewMoney(5000)}, statement.Gains[2])
+	s.Equal([]*models.StatementTotal{{Currency: "JPY", Amount: models.NewMoney(5000)}, {Currency: "USD", Amount: models.NewMoney(3)}}, statement.RealizedPnL)
+	s.Equal([]*models.Holding{{Symbol: "MSFT", Currency: "USD", Quantity: 2, UnsettledQuantity: 2, AverageCost: models.NewMoney(290), CostBasis: models.NewMoney(580),
+		LastPrice: models.NewMoney(300), MarketValue: models.NewMoney(600), UnrealizedPnL: models.NewMoney(20)}}, statement.Holdings)
+	s.Equal([]*models.StatementTotal{{Currency: "USD", Amount: models.NewMoney(600)}}, statement.MarketValues)
+	s.orderRepo.AssertNumberOfCalls(s.T(), "FindById", 4)
+}
+
+func (s *StatementServiceTestSuite) TestGenerateStatementHoldsThePositionsAtTheEndOfThePeriod() {
+	s.ledgerRepo.On("FindEntriesByUserId", mock.Anything, "user").Return([]*models.JournalEntry{}, nil)
+	s.depositRepo.On("FindByUserId", mock.Anything, "user").Return([]*models.Deposit{}, nil)
+	s.withdrawalRepo.On("FindByUserId", mock.Anything, "user").Return([]*models.Withdrawal{}, nil)
+	s.executionRepo.On("FindByUserIdAndPeriod", mock.Anything, "user", s.from, s.to).Return([]*models.Execution{}, nil)
+	s.taxLotRepo.On("FindReliefsByUserId", mock.Anything, "user").Return([]*models.LotRelief{
+		// Sold after the period
+		{ID: 1, LotID: 1, Quantity: 100, RelievedAt: s.to.Add(time.Hour)},
+	}, nil)
+	s.taxLotRepo.On("FindByUserId", mock.Anything, "user").Return([]*models.TaxLot{
+		{ID: 1, Symbol: "7203", Quantity: 100, RemainingQuantity: 0, UnitPrice: models.NewMoney(2000), AcquiredAt: s.from.AddDate(0, -1, 0)},
+		{ID: 2, Symbol: "AAPL", Quantity: 10, RemainingQuantity: 10, UnitPrice: models.NewMoney(150), AcquiredAt: septemberAt(2).Time},
+		// Bought after the period
+		{ID: 3, Symbol: "AAPL", Quantity: 5, RemainingQuantity: 5, UnitPrice: models.NewMoney(160), AcquiredAt: s.to},
+		{ID: 4, Symbol: "TSLA", Quantity: 3, RemainingQuantity: 3, UnitPrice: models.NewMoney(200), AcquiredAt: s.to.Add(time.Hour)},
+	}, nil)
+	s.executionRepo.On("FindByUserIdAndPeriod", mock.Anything, "user", time.Time{}, s.to).Return([]*models.Execution{
+		{ID: 1, BuyOrderID: 1, SellOrderID: 2, Symbol: "7203", Quantity: 100, ExecutedAt: s.from.AddDate(0, -1, 0), SettledAt: sql.NullTime{Time: s.from, Valid: true}},
+		{ID: 2, BuyOrderID: 3, SellOrderID: 4, Symbol: "AAPL", Quantity: 10, ExecutedAt: septemberAt(2).Time, SettledAt: sql.NullTime{Time: septemberAt(3).Time, Valid: true}},
+	}, nil)
+	s.executionRepo.On("FindLatestPriceBefore", mock.Anything, "7203", s.to).Return(models.NewMoney(0), ports.ErrPriceNotFound)
+	s.executionRepo.On("FindLatestPriceBefore", mock.Anything, "AAPL", s.to).Return(models.NewMoney(155), nil)
+
+	statement, err := s.service.GenerateStatement(context.Background(), "user", s.from, s.to)
+
+	s.Require().NoError(err)
+	s.Equal([]*models.Holding{
+		{Symbol: "7203", Currency: "JPY", Quantity: 100, AverageCost: models.NewMoney(2000), CostBasis: models.NewMoney(200000),
+			LastPrice: models.NewMoney(2000), MarketValue: models.NewMoney(200000), UnrealizedPnL: models.NewMoney(0)},
+		{Symbol: "AAPL", Currency: "USD", Quantity: 10, AverageCost: models.NewMoney(150), CostBasis: models.NewMoney(1500),
+			LastPrice: models.NewMoney(155), MarketValue: models.NewMoney(1550), UnrealizedPnL: models.NewMoney(50)},
+	}, statement.Holdings)
+	s.Equal([]*models.StatementTotal{{Currency: "JPY", Amount: models.NewMoney(200000)}, {Currency: "USD", Amount: models.NewMoney(1550)}}, statement.MarketValues)
+	s.Empty(statement.Gains)
+	s.orderRepo.AssertNotCalled(s.T(), "FindById", mock.Anything, mock.Anything)
+}
+
+func (s *StatementServiceTestSuite) TestStatementTradeAmountOfAPurchase() {
+	trade := &models.StatementTrade{Side: "buy", Quantity: 10, Price: models.NewMoney(150), Commission: models.NewMoney(1)}
+
+	s.Equal(models.NewMoney(-1501), trade.Amount())
+}
+
+func (s *StatementServiceTestSuite) TestGenerateStatementInvalidPeriod() {
+	statement, err := s.service.GenerateStatement(context.Background(), "user", s.to, s.from)
+
+	s.Nil(statement)
+	s.ErrorIs(err, ports.ErrInvalidPeriod)
+}
+
+func (s *StatementServiceTestSuite) TestGenerateStatementFailure() {
+	s.ledgerRepo.On("FindEntriesByUserId", mock.Anything, "user").Return([]*models.JournalEntry{}, nil)
+	s.depositRepo.On("FindByUserId", mock.Anything, "user").Return([]*models.Deposit{}, nil)
+	s.withdrawalRepo.On("FindByUserId", mock.Anything, "user").Return([]*models.Withdrawal{}, nil)
+	s.executionRepo.On("FindByUserIdAndPeriod", mock.Anything, "user", s.from, s.to).Return(nil, assert.AnError)
+
+	statement, err := s.service.GenerateStatement(context.Background(), "user", s.from, s.to)
+
+	s.Nil(statement)
+	s.ErrorIs(err, assert.AnError)
+}
+
+// ---------------------------
+// Run Test Suite
+// ---------------------------
+
+func TestStatementServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(StatementServiceTestSuite))
+}
@@ -0,0 +1,150 @@
+package core
+
+import (
+	"brokerx/models"
+	"brokerx/ports"
+	"context"
+	"sort"
+	"time"
+)
+
+type TaxLotService struct {
+	TaxLotRepo ports.TaxLotRepository
+	UserRepo   ports.UserRepository
+}
+
+// GetLots returns every tax lot of the user, including the fully relieved ones.
+func (service *TaxLotService) GetLots(ctx context.Context, userID string) ([]*models.TaxLot, error) {
+	return service.TaxLotRepo.FindByUserId(ctx, userID)
+}
+
+// GetRealizedGains returns the gains realized on each lot relieved by a sell, most recent first.
+func (service *TaxLotService) GetRealizedGains(ctx context.Context, userID string) ([]*models.LotRelief, error) {
+	return service.TaxLotRepo.FindReliefsByUserId(ctx, userID)
+}
+
+func (service *TaxLotService) GetReliefMethod(ctx context.Context, userID string) (string, error) {
+	user, err := service.UserRepo.FindById(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	return user.LotReliefMethod, nil
+}
+
+func (service *TaxLotService) SetReliefMethod(ctx context.Context, userID string, method string) error {
+	switch method {
+	case "fifo", "lifo", "highest_cost", "specific_lot":
+		return service.UserRepo.UpdateLotReliefMethod(ctx, userID, method)
+	}
+	return ports.ErrInvalidReliefMethod
+}
+
+// openLot records the shares bought by a fill as a new tax lot of the buyer.
+func openLot(ctx context.Context, repos ports.Repositories, buyOrder *models.Order, execution *models.Execution) error {
+	_, err := repos.TaxLots.CreateLot(ctx, &models.TaxLot{
+		UserID:            buyOrder.UserID,
+		Symbol:            execution.Symbol,
+		Quantity:          execution.Quantity,
+		RemainingQuantity: execution.Quantity,
+		UnitPrice:         execution.Price,
+		AcquiredAt:        execution.ExecutedAt,
+	})
+	return err
+}
+
+// relieveLots relieves the tax lots of the seller for the shares sold by a fill and
+// records the gain realized on each lot. Shares of a lot reserved by sell orders that
+// designate it are only relieved by those orders.
+func relieveLots(ctx context.Context, repos ports.Repositories, sellOrder *models.Order, execution *models.Execution) error {
+	user, err := repos.Users.FindById(ctx, sellOrder.UserID)
+	if err != nil {
+		return err
+	}
+	lots, err := repos.TaxLots.FindOpenLots(ctx, sellOrder.UserID, execution.Symbol)
+	if err != nil {
+		return err
+	}
+	sortLots(lots, user.LotReliefMethod, sellOrder.LotID)
+
+	remaining := execution.Quantity
+	for _, lot := range lots {
+		if remaining == 0 {
+			break
+		}
+
+		available := lot.RemainingQuantity - lot.ReservedQuantity
+		if lot.ID == sellOrder.LotID {
+			available = lot.RemainingQuantity
+		}
+		quantity := min(remaining, available)
+		if quantity <= 0 {
+			continue
+		}
+		if lot.ID == sellOrder.LotID {
+			if err := repos.TaxLots.ReleaseLot(ctx, lot.ID, quantity); err != nil {
+				return err
+			}
+		}
+
+		relief := &models.LotRelief{
+			LotID:       lot.ID,
+			ExecutionID: execution.ID,
+			Symbol:      execution.Symbol,
+			Quantity:    quantity,
+			CostBasis:   lot.UnitPrice.Mul(quantity).RoundToCurrency(sellOrder.Currency, models.RoundHalfEven),
+			Proceeds:    execution.Price.Mul(quantity),
+			Term:        holdingTerm(lot.AcquiredAt, execution.ExecutedAt),
+			RelievedAt:  execution.ExecutedAt,
+		}
+		relief.RealizedGain = relief.Proceeds.Sub(relief.CostBasis)
+		if err := repos.TaxLots.RelieveLot(ctx, relief); err != nil {
+			return err
+		}
+		remaining -= quantity
+	}
+
+	if remaining > 0 {
+		return ports.ErrInsufficientShares
+	}
+	return nil
+}
+
+// sortLots orders the lots in which they are relieved. A lot designated by the order is
+// always relieved first; the rest follow the relief method of the account. Accounts on
+// specific lot relief fall back to FIFO for sells that designate no lot.
+func sortLots(lots []*models.TaxLot, method string, lotID int) {
+	sort.SliceStable(lots, func(i, j int) bool {
+		a, b := lots[i], lots[j]
+		if (a.ID == lotID) != (b.ID == lotID) {
+			return a.ID == lotID
+		}
+
+		switch method {
+		case "lifo":
+			if !a.AcquiredAt.Equal(b.AcquiredAt) {
+				return a.AcquiredAt.After(b.AcquiredAt)
+			}
+			return a.ID > b.ID
+		case "highest_cost":
+			if a.UnitPrice != b.UnitPrice {
+				return a.UnitPrice.GreaterThan(b.UnitPrice)
+			}
+		}
+
+		if !a.AcquiredAt.Equal(b.AcquiredAt) {
+			return a.AcquiredAt.Before(b.AcquiredAt)
+		}
+		return a.ID < b.ID
+	})
+}
+
+// holdingTerm classifies a gain as long-term when the shares were held for more than a
+// year, and short-term otherwise.
+func holdingTerm(acquiredAt time.Time, soldAt time.Time) string {
+	if soldAt.After(acquiredAt.AddDate(1, 0, 0)) {
+		return "long"
+	}
+	return "short"
+}
+
+var _ ports.TaxLotService = (*TaxLotService)(nil) // Ensure interface is implemented at compile time
@@ -0,0 +1,266 @@
+package core
+
+import (
+	"brokerx/models"
+	"brokerx/ports"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type MockTaxLotRepo struct {
+	mock.Mock
+}
+
+func (m *MockTaxLotRepo) CreateLot(ctx context.Context, lot *models.TaxLot) (int, error) {
+	args := m.Called(ctx, lot)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockTaxLotRepo) FindById(ctx context.Context, id int) (*models.TaxLot, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.TaxLot), args.Error(1)
+}
+
+func (m *MockTaxLotRepo) FindByUserId(ctx context.Context, userId string) ([]*models.TaxLot, error) {
+	args := m.Called(ctx, userId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.TaxLot), args.Error(1)
+}
+
+func (m *MockTaxLotRepo) FindOpenLots(ctx context.Context, userId string, symbol string) ([]*models.TaxLot, error) {
+	args := m.Called(ctx, userId, symbol)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.TaxLot), args.Error(1)
+}
+
+func (m *MockTaxLotRepo) ReserveLot(ctx context.Context, id int, quantity int) error {
+	args := m.Called(ctx, id, quantity)
+	return args.Error(0)
+}
+
+func (m *MockTaxLotRepo) ReleaseLot(ctx context.Context, id int, quantity int) error {
+	args := m.Called(ctx, id, quantity)
+	return args.Error(0)
+}
+
+func (m *MockTaxLotRepo) RelieveLot(ctx context.Context, relief *models.LotRelief) error {
+	args := m.Called(ctx, relief)
+	return args.Error(0)
+}
+
+func (m *MockTaxLotRepo) FindReliefsByUserId(ctx context.Context, userId string) ([]*models.LotRelief, error) {
+	args := m.Called(ctx, userId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.LotRelief), args.Error(1)
+}
+
+func makeLots(now time.Time) []*models.TaxLot {
+	return []*models.TaxLot{
+		{ID: 1, Symbol: "AAPL", RemainingQuantity: 10, UnitPrice: models.NewMoney(100), AcquiredAt: now.AddDate(-2, 0, 0)},
+		{ID: 2, Symbol: "AAPL", RemainingQuantity: 10, UnitPrice: models.NewMoney(180), AcquiredAt: now.AddDate(0, -6, 0)},
+		{ID: 3, Symbol: "AAPL", RemainingQuantity: 10, UnitPrice: models.NewMoney(120), AcquiredAt: now.AddDate(0, -1, 0)},
+	}
+}
+
+func lotIDs(lots []*models.TaxLot) []int {
+	ids := make([]int, 0, len(lots))
+	for _, lot := range lots {
+		ids = append(ids, lot.ID)
+	}
+	return ids
+}
+
+// ---------------------------
+// Test Suite
+// ---------------------------
+
+type TaxLotServiceTestSuite struct {
+	suite.Suite
+	taxLotRepo *MockTaxLotRepo
+	userRepo   *MockUserRepo
+	service    *TaxLotService
+	repos      ports.Repositories
+	UserID     string
+	now        time.Time
+}
+
+func (s *TaxLotServiceTestSuite) SetupTest() {
+	s.taxLotRepo = new(MockTaxLotRepo)
+	s.userRepo = new(MockUserRepo)
+	s.service = &TaxLotService{TaxLotRepo: s.taxLotRepo, UserRepo: s.userRepo}
+	s.repos = ports.Repositories{TaxLots: s.taxLotRepo, Users: s.userRepo}
+	s.UserID = "user"
+	s.now = time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)
+}
+
+// ---------------------------
+// Tests
+// ---------------------------
+
+func (s *TaxLotServiceTestSuite) TestSortLots() {
+	cases := []struct {
+		method   string
+		lotID    int
+		expected []int
+	}{
+		{"fifo", 0, []int{1, 2, 3}},
+		{"lifo", 0, []int{3, 2, 1}},
+		{"highest_cost", 0, []int{2, 3, 1}},
+		{"specific_lot", 0, []int{1, 2, 3}},
+		{"specific_lot", 2, []int{2, 1, 3}},
+		{"lifo", 1, []int{1, 3, 2}},
+	}
+
+	for _, c := range cases {
+		lots := makeLots(s.now)
+
+		sortLots(lots, c.method, c.lotID)
+
+		s.Equal(c.expected, lotIDs(lots), c.method)
+	}
+}
+
+func (s *TaxLotServiceTestSuite) TestHoldingTerm() {
+	s.Equal("short", holdingTerm(s.now.AddDate(-1, 0, 0), s.now))
+	s.Equal("long", holdingTerm(s.now.AddDate(-1, 0, -1), s.now))
+}
+
+func (s *TaxLotServiceTestSuite) TestRelieveLotsRecordsGainPerLot() {
+	sellOrder := &models.Order{UserID: s.UserID, Action: "sell"}
+	execution := &models.Execution{ID: 9, Symbol: "AAPL", Quantity: 15, Price: models.NewMoney(150), ExecutedAt: s.now}
+	s.userRepo.On("FindById", mock.Anything, s.UserID).Return(&models.User{ID: s.UserID, LotReliefMethod: "highest_cost"}, nil)
+	s.taxLotRepo.On("FindOpenLots", mock.Anything, s.UserID, "AAPL").Return(makeLots(s.now), nil)
+	var reliefs []*models.LotRelief
+	s.taxLotRepo.On("RelieveLot", mock.Anything, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		reliefs = append(reliefs, args.Get(1).(*models.LotRelief))
+	})
+
+	err := relieveLots(context.Background(), s.repos, sellOrder, execution)
+
+	s.Require().NoError(err)
+	s.Require().Len(reliefs, 2)
+	s.Equal(models.LotRelief{LotID: 2, ExecutionID: 9, Symbol: "AAPL", Quantity: 10, CostBasis: models.NewMoney(1800), Proceeds: models.NewMoney(1500),
+		RealizedGain: models.NewMoney(-300), Term: "short", RelievedAt: s.now}, *reliefs[0])
+	s.Equal(models.LotRelief{LotID: 3, ExecutionID: 9, Symbol: "AAPL", Quantity: 5, CostBasis: models.NewMoney(600), Proceeds: models.NewMoney(750),
+		RealizedGain: models.NewMoney(150), Term: "short", RelievedAt: s.now}, *reliefs[1])
+}
+
+func (s *TaxLotServiceTestSuite) TestRelieveLotsLeavesSharesReservedByOtherSells() {
+	sellOrder := &models.Order{UserID: s.UserID, Action: "sell"}
+	execution := &models.Execution{ID: 9, Symbol: "AAPL", Quantity: 12, Price: models.NewMoney(150), ExecutedAt: s.now}
+	lots := makeLots(s.now)
+	lots[0].ReservedQuantity = 6
+	s.userRepo.On("FindById", mock.Anything, s.UserID).Return(&models.User{ID: s.UserID, LotReliefMethod: "fifo"}, nil)
+	s.taxLotRepo.On("FindOpenLots", mock.Anything, s.UserID, "AAPL").Return(lots, nil)
+	var reliefs []*models.LotRelief
+	s.taxLotRepo.On("RelieveLot", mock.Anything, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		reliefs = append(reliefs, args.Get(1).(*models.LotRelief))
+	})
+
+	err := relieveLots(context.Background(), s.repos, sellOrder, execution)
+
+	s.Require().NoError(err)
+	s.Require().Len(reliefs, 2)
+	s.Equal([]int{1, 2}, []int{reliefs[0].LotID, reliefs[1].LotID})
+	s.Equal([]int{4, 8}, []int{reliefs[0].Quantity, reliefs[1].Quantity})
+	s.taxLotRepo.AssertNotCalled(s.T(), "ReleaseLot", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *TaxLotServiceTestSuite) TestRelieveLotsConsumesTheReservationOfTheDesignatedLot() {
+	sellOrder := &models.Order{UserID: s.UserID, Action: "sell", LotID: 2}
+	execution := &models.Execution{ID: 9, Symbol: "AAPL", Quantity: 6, Price: models.NewMoney(150), ExecutedAt: s.now}
+	lots := makeLots(s.now)
+	lots[1].ReservedQuantity = 10
+	s.userRepo.On("FindById", mock.Anything, s.UserID).Return(&models.User{ID: s.UserID, LotReliefMethod: "specific_lot"}, nil)
+	s.taxLotRepo.On("FindOpenLots", mock.Anything, s.UserID, "AAPL").Return(lots, nil)
+	s.taxLotRepo.On("ReleaseLot", mock.Anything, 2, 6).Return(nil)
+	s.taxLotRepo.On("RelieveLot", mock.Anything, mock.MatchedBy(func(relief *models.LotRelief) bool {
+		return relief.LotID == 2 && relief.Quantity == 6
+	})).Return(nil)
+
+	err := relieveLots(context.Background(), s.repos, sellOrder, execution)
+
+	s.Require().NoError(err)
+	s.taxLotRepo.AssertExpectations(s.T())
+}
+
+func (s *TaxLotServiceTestSuite) TestRelieveLotsLongTerm() {
+	sellOrder := &models.Order{UserID: s.UserID, Action: "sell"}
+	execution := &models.Execution{ID: 9, Symbol: "AAPL", Quantity: 5, Price: models.NewMoney(150), ExecutedAt: s.now}
+	s.userRepo.On("FindById", mock.Anything, s.UserID).Return(&models.User{ID: s.UserID, LotReliefMethod: "fifo"}, nil)
+	s.taxLotRepo.On("FindOpenLots", mock.Anything, s.UserID, "AAPL").Return(makeLots(s.now), nil)
+	s.taxLotRepo.On("RelieveLot", mock.Anything, mock.MatchedBy(func(relief *models.LotRelief) bool {
+		return relief.LotID == 1 && relief.Term == "long" && relief.RealizedGain == models.NewMoney(250)
+	})).Return(nil)
+
+	err := relieveLots(context.Background(), s.repos, sellOrder, execution)
+
+	s.Require().NoError(err)
+	s.taxLotRepo.AssertNumberOfCalls(s.T(), "RelieveLot", 1)
+}
+
+func (s *TaxLotServiceTestSuite) TestRelieveLotsRoundsTheCostBasisToTheTradingCurrency() {
+	sellOrder := &models.Order{UserID: s.UserID, Action: "sell", Currency: "JPY"}
+	execution := &models.Execution{ID: 9, Symbol: "7203", Quantity: 3, Price: models.NewMoney(2500), ExecutedAt: s.now}
+	lot := &models.TaxLot{ID: 1, Symbol: "7203", RemainingQuantity: 3, UnitPrice: models.MustParseMoney("2400.25"), AcquiredAt: s.now.AddDate(0, -1, 0)}
+	s.userRepo.On("FindById", mock.Anything, s.UserID).Return(&models.User{ID: s.UserID, LotReliefMethod: "fifo"}, nil)
+	s.taxLotRepo.On("FindOpenLots", mock.Anything, s.UserID, "7203").Return([]*models.TaxLot{lot}, nil)
+	s.taxLotRepo.On("RelieveLot", mock.Anything, mock.MatchedBy(func(relief *models.LotRelief) bool {
+		return relief.CostBasis == models.NewMoney(7201) && relief.RealizedGain == models.NewMoney(299)
+	})).Return(nil)
+
+	err := relieveLots(context.Background(), s.repos, sellOrder, execution)
+
+	s.Require().NoError(err)
+	s.taxLotRepo.AssertNumberOfCalls(s.T(), "RelieveLot", 1)
+}
+
+func (s *TaxLotServiceTestSuite) TestRelieveLotsNotEnoughShares() {
+	sellOrder := &models.Order{UserID: s.UserID, Action: "sell"}
+	execution := &models.Execution{ID: 9, Symbol: "AAPL", Quantity: 31, Price: models.NewMoney(150), ExecutedAt: s.now}
+	s.userRepo.On("FindById", mock.Anything, s.UserID).Return(&models.User{ID: s.UserID, LotReliefMethod: "fifo"}, nil)
+	s.taxLotRepo.On("FindOpenLots", mock.Anything, s.UserID, "AAPL").Return(makeLots(s.now), nil)
+	s.taxLotRepo.On("RelieveLot", mock.Anything, mock.Anything).Return(nil)
+
+	err := relieveLots(context.Background(), s.repos, sellOrder, execution)
+
+	s.ErrorIs(err, ports.ErrInsufficientShares)
+}
+
+func (s *TaxLotServiceTestSuite) TestSetReliefMethod() {
+	s.userRepo.On("UpdateLotReliefMethod", mock.Anything, s.UserID, "lifo").Return(nil)
+
+	s.NoError(s.service.SetReliefMethod(context.Background(), s.UserID, "lifo"))
+	s.ErrorIs(s.service.SetReliefMethod(context.Background(), s.UserID, "average"), ports.ErrInvalidReliefMethod)
+	s.userRepo.AssertNumberOfCalls(s.T(), "UpdateLotReliefMethod", 1)
+}
+
+func (s *TaxLotServiceTestSuite) TestGetReliefMethodFailure() {
+	s.userRepo.On("FindById", mock.Anything, s.UserID).Return(nil, assert.AnError)
+
+	method, err := s.service.GetReliefMethod(context.Background(), s.UserID)
+
+	s.Empty(method)
+	s.ErrorIs(err, assert.AnError)
+}
+
+// ---------------------------
+// Run the suite
+// ---------------------------
+func TestTaxLotServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(TaxLotServiceTestSuite))
+}
@@ -0,0 +1,65 @@
+package core
+
+import "brokerx/models"
+
+// TriggerBook holds the dormant stop orders of a single symbol in arrival order until a
+// trade print crosses their stop price.
+type TriggerBook struct {
+	Symbol string
+	Orders []*models.Order
+}
+
+func (book *TriggerBook) Add(order *models.Order) {
+	book.Orders = append(book.Orders, order)
+}
+
+func (book *TriggerBook) Remove(order *models.Order) bool {
+	for i, dormant := range book.Orders {
+		if dormant.ID == order.ID {
+			book.Orders = append(book.Orders[:i], book.Orders[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Trigger removes and returns the orders whose stop price is crossed by a trade at the
+// given price, in arrival order.
+func (book *TriggerBook) Trigger(price models.Money) []*models.Order {
+	var triggered []*models.Order
+	var dormant []*models.Order
+
+	for _, order := range book.Orders {
+		if isTriggeredBy(order, price) {
+			triggered = append(triggered, order)
+		} else {
+			dormant = append(dormant, order)
+		}
+	}
+
+	book.Orders = dormant
+	return triggered
+}
+
+func isStop(order *models.Order) bool {
+	return order.Type == "stop" || order.Type == "stop_limit"
+}
+
+// isTriggeredBy reports whether a trade at the given price crosses the stop price of the
+// order: at or above it for a buy stop, at or below it for a sell stop.
+func isTriggeredBy(order *models.Order, price models.Money) bool {
+	if order.Action == "buy" {
+		return price.Cmp(order.StopPrice) >= 0
+	}
+	return price.Cmp(order.StopPrice) <= 0
+}
+
+// activate turns a triggered stop order into the market or limit order it stands for.
+func activate(order *models.Order) {
+	switch order.Type {
+	case "stop":
+		order.Type = "market"
+	case "stop_limit":
+		order.Type = "limit"
+	}
+}
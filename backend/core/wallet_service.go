@@ -0,0 +1,249 @@
+package core
+
+import (
+	"brokerx/models"
+	"brokerx/ports"
+	"context"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type WalletService struct {
+	Repo              ports.WithdrawalRepository
+	UnitOfWork        ports.UnitOfWork
+	Provider          ports.PaymentProvider
+	DailyLimit        models.Money
+	MonthlyLimit      models.Money
+	ApprovalThreshold models.Money
+}
+
+// Withdraw puts the amount on hold and pays it out through the payment provider. The
+// withdrawal must fit in the unreserved available funds, which exclude the unsettled
+// proceeds of sales, and in the daily and monthly limits of the user. A withdrawal above
+// the approval threshold keeps the amount on hold and waits for ApproveWithdrawal or
+// RejectWithdrawal instead of being paid out.
+func (service *WalletService) Withdraw(ctx context.Context, userID string, amount models.Money) (*models.Withdrawal, error) {
+	if !isCurrencyAmount(amount, models.BaseCurrency) {
+		return nil, ports.ErrInvalidAmount
+	}
+
+	withdrawal := &models.Withdrawal{UserID: userID, Amount: amount, Status: "processing"}
+	if amount.GreaterThan(service.ApprovalThreshold) {
+		withdrawal.Status = "pending_approval"
+	}
+
+	err := service.UnitOfWork.Execute(ctx, func(repos ports.Repositories) error {
+		id, err := repos.Withdrawals.CreateWithdrawal(ctx, withdrawal)
+		if err != nil {
+			return err
+		}
+		withdrawal.ID = id
+
+		// Holding the funds locks the wallet, so concurrent withdrawals of the same user
+		// are checked against the limits one at a time
+		if err := repos.Ledger.Post(ctx, holdEntry(userID, models.BaseCurrency, amount, withdrawalReference(withdrawal))); err != nil {
+			return err
+		}
+		return service.verifyLimits(ctx, repos.Withdrawals, userID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if withdrawal.Status == "pending_approval" {
+		return withdrawal, nil
+	}
+	if err := service.payout(ctx, withdrawal); err != nil {
+		return nil, err
+	}
+	return withdrawal, nil
+}
+
+func (service *WalletService) GetWithdrawal(ctx context.Context, userID string, withdrawalID int) (*models.Withdrawal, error) {
+	withdrawal, err := service.Repo.FindById(ctx, withdrawalID)
+	if err != nil {
+		return nil, err
+	}
+
+	if withdrawal.UserID != userID {
+		return nil, ports.ErrWithdrawalNotOwned
+	}
+
+	return withdrawal, nil
+}
+
+// ListWithdrawals returns the withdrawals of the user, most recent first.
+func (service *WalletService) ListWithdrawals(ctx context.Context, userID string) ([]*models.Withdrawal, error) {
+	return service.Repo.FindByUserId(ctx, userID)
+}
+
+// ListPendingApprovals returns the withdrawals waiting for a back-office review, oldest first.
+func (service *WalletService) ListPendingApprovals(ctx context.Context) ([]*models.Withdrawal, error) {
+	return service.Repo.FindByStatus(ctx, "pending_approval")
+}
+
+// ApproveWithdrawal pays out a withdrawal waiting for a back-office review.
+func (service *WalletService) ApproveWithdrawal(ctx context.Context, reviewerID string, withdrawalID int) (*models.Withdrawal, error) {
+	withdrawal, err := service.Repo.FindById(ctx, withdrawalID)
+	if err != nil {
+		return nil, err
+	}
+	if withdrawal.Status != "pending_approval" {
+		return nil, ports.ErrWithdrawalNotPending
+	}
+
+	withdrawal.Status = "processing"
+	withdrawal.ReviewedBy = reviewerID
+	if err := service.Repo.UpdateWithdrawal(ctx, withdrawal, "pending_approval"); err != nil {
+		return nil, err
+	}
+
+	if err := service.payout(ctx, withdrawal); err != nil {
+		return nil, err
+	}
+	return withdrawal, nil
+}
+
+// RejectWithdrawal rejects a withdrawal waiting for a back-office review and returns its
+// amount to the available funds.
+func (service *WalletService) RejectWithdrawal(ctx context.Context, reviewerID string, withdrawalID int, reason string) (*models.Withdrawal, error) {
+	withdrawal, err := service.Repo.FindById(ctx, withdrawalID)
+	if err != nil {
+		return nil, err
+	}
+	if withdrawal.Status != "pending_approval" {
+		return nil, ports.ErrWithdrawalNotPending
+	}
+
+	withdrawal.Status = "rejected"
+	withdrawal.ReviewedBy = reviewerID
+	withdrawal.FailureReason = reason
+	err = service.UnitOfWork.Execute(ctx, func(repos ports.Repositories) error {
+		if err := repos.Withdrawals.UpdateWithdrawal(ctx, withdrawal, "pending_approval"); err != nil {
+			return err
+		}
+		return repos.Ledger.Post(ctx, releaseEntry(withdrawal.UserID, models.BaseCurrency, withdrawal.Amount, withdrawalReference(withdrawal)))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return withdrawal, nil
+}
+
+// RefreshProcessingWithdrawals asks the payment provider about the payouts it reported as
+// pending or never answered, and completes or reverses those it resolved. A withdrawal
+// that fails to refresh is logged and left processing so that the next run can retry it.
+// It returns the number of resolved withdrawals.
+func (service *WalletService) RefreshProcessingWithdrawals(ctx context.Context) (int, error) {
+	withdrawals, err := service.Repo.FindByStatus(ctx, "processing")
+	if err != nil {
+		return 0, err
+	}
+
+	resolved := 0
+	for _, withdrawal := range withdrawals {
+		result, err := service.payoutStatus(ctx, withdrawal)
+		if err != nil {
+			log.Errorf("Failed to get the payment status of withdrawal %d: %v", withdrawal.ID, err)
+			continue
+		}
+		if result.Status == "pending" {
+			if result.Reference != "" && result.Reference != withdrawal.ProviderReference {
+				if err := service.apply(ctx, withdrawal, result); err != nil {
+					log.Errorf("Failed to record the payout of withdrawal %d: %v", withdrawal.ID, err)
+				}
+			}
+			continue
+		}
+
+		if err := service.apply(ctx, withdrawal, result); err != nil {
+			log.Errorf("Failed to resolve withdrawal %d: %v", withdrawal.ID, err)
+			continue
+		}
+		resolved++
+	}
+
+	return resolved, nil
+}
+
+// verifyLimits checks the withdrawals of the user, including the one being requested,
+// against the daily and monthly limits.
+func (service *WalletService) verifyLimits(ctx context.Context, repo ports.WithdrawalRepository, userID string) error {
+	now := time.Now().UTC()
+
+	withdrawnToday, err := repo.SumWithdrawnSince(ctx, userID, time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		return err
+	}
+	if withdrawnToday.GreaterThan(service.DailyLimit) {
+		return ports.ErrDailyLimitExceeded
+	}
+
+	withdrawnThisMonth, err := repo.SumWithdrawnSince(ctx, userID, time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		return err
+	}
+	if withdrawnThisMonth.GreaterThan(service.MonthlyLimit) {
+		return ports.ErrMonthlyLimitExceeded
+	}
+
+	return nil
+}
+
+// payout asks the payment provider to pay the withdrawal out. A payout that gets no
+// answer may still have gone through, so the withdrawal is left processing with its
+// amount on hold until RefreshProcessingWithdrawals finds out.
+func (service *WalletService) payout(ctx context.Context, withdrawal *models.Withdrawal) error {
+	result, err := service.Provider.Payout(ctx, withdrawal)
+	if err != nil {
+		log.Errorf("Failed to pay out withdrawal %d, leaving it processing: %v", withdrawal.ID, err)
+		return nil
+	}
+
+	// The provider already answered, its answer must be recorded even if the client leaves
+	return service.apply(context.WithoutCancel(ctx), withdrawal, result)
+}
+
+// payoutStatus asks the payment provider about the payout of the withdrawal. A payout the
+// provider never answered has no reference yet and is requested again, which returns the
+// payout already made if there is one.
+func (service *WalletService) payoutStatus(ctx context.Context, withdrawal *models.Withdrawal) (*models.PaymentResult, error) {
+	if withdrawal.ProviderReference == "" {
+		return service.Provider.Payout(ctx, withdrawal)
+	}
+	return service.Provider.Status(ctx, withdrawal.ProviderReference)
+}
+
+// apply records the answer of the payment provider about a processing withdrawal. An
+// approved payout debits the funds on hold, a declined one releases them back to the
+// available funds, in the same transaction as the status change.
+func (service *WalletService) apply(ctx context.Context, withdrawal *models.Withdrawal, result *models.PaymentResult) error {
+	if result.Reference != "" {
+		withdrawal.ProviderReference = result.Reference
+	}
+
+	switch result.Status {
+	case "approved":
+		return service.UnitOfWork.Execute(ctx, func(repos ports.Repositories) error {
+			withdrawal.Status = "completed"
+			if err := repos.Withdrawals.UpdateWithdrawal(ctx, withdrawal, "processing"); err != nil {
+				return err
+			}
+			return repos.Ledger.Post(ctx, withdrawalEntry(withdrawal))
+		})
+	case "declined":
+		return service.UnitOfWork.Execute(ctx, func(repos ports.Repositories) error {
+			withdrawal.Status = "failed"
+			withdrawal.FailureReason = result.Reason
+			if err := repos.Withdrawals.UpdateWithdrawal(ctx, withdrawal, "processing"); err != nil {
+				return err
+			}
+			return repos.Ledger.Post(ctx, releaseEntry(withdrawal.UserID, models.BaseCurrency, withdrawal.Amount, withdrawalReference(withdrawal)))
+		})
+	}
+
+	return service.Repo.UpdateWithdrawal(ctx, withdrawal, "processing")
+}
+
+var _ ports.WalletService = (*WalletService)(nil) // Ensure interface is implemented at compile time
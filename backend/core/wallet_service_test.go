@@ -0,0 +1,317 @@
+package core
+
+import (
+	"brokerx/models"
+	"brokerx/ports"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type MockWithdrawalRepo struct {
+	mock.Mock
+}
+
+func (m *MockWithdrawalRepo) CreateWithdrawal(ctx context.Context, withdrawal *models.Withdrawal) (int, error) {
+	args := m.Called(ctx, withdrawal)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockWithdrawalRepo) UpdateWithdrawal(ctx context.Context, withdrawal *models.Withdrawal, previousStatus string) error {
+	args := m.Called(ctx, withdrawal, previousStatus)
+	return args.Error(0)
+}
+
+func (m *MockWithdrawalRepo) FindById(ctx context.Context, id int) (*models.Withdrawal, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Withdrawal), args.Error(1)
+}
+
+func (m *MockWithdrawalRepo) FindByUserId(ctx context.Context, userId string) ([]*models.Withdrawal, error) {
+	args := m.Called(ctx, userId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Withdrawal), args.Error(1)
+}
+
+func (m *MockWithdrawalRepo) FindByStatus(ctx context.Context, status string) ([]*models.Withdrawal, error) {
+	args := m.Called(ctx, status)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Withdrawal), args.Error(1)
+}
+
+func (m *MockWithdrawalRepo) SumWithdrawnSince(ctx context.Context, userId string, since time.Time) (models.Money, error) {
+	args := m.Called(ctx, userId, since)
+	return args.Get(0).(models.Money), args.Error(1)
+}
+
+// ---------------------------
+// Test Suite
+// ---------------------------
+
+type WalletServiceTestSuite struct {
+	suite.Suite
+	repo       *MockWithdrawalRepo
+	ledgerRepo *MockLedgerRepo
+	provider   *MockPaymentProvider
+	service    *WalletService
+	UserID     string
+}
+
+func (s *WalletServiceTestSuite) SetupTest() {
+	s.repo = new(MockWithdrawalRepo)
+	s.ledgerRepo = new(MockLedgerRepo)
+	s.provider = new(MockPaymentProvider)
+	s.service = &WalletService{
+		Repo:              s.repo,
+		UnitOfWork:        &MockUnitOfWork{repos: ports.Repositories{Withdrawals: s.repo, Ledger: s.ledgerRepo}},
+		Provider:          s.provider,
+		DailyLimit:        models.NewMoney(5000),
+		MonthlyLimit:      models.NewMoney(20000),
+		ApprovalThreshold: models.NewMoney(1000),
+	}
+	s.UserID = "user"
+}
+
+// withinLimits lets the withdrawals of the test pass the daily and monthly limits.
+func (s *WalletServiceTestSuite) withinLimits() {
+	s.repo.On("SumWithdrawnSince", mock.Anything, s.UserID, mock.Anything).Return(models.NewMoney(0), nil)
+}
+
+// ---------------------------
+// Tests
+// ---------------------------
+
+func (s *WalletServiceTestSuite) TestWithdrawApproved() {
+	s.ledgerRepo.On("Post", mock.Anything, entryMoving("hold", s.UserID, models.NewMoney(200))).Return(nil)
+	s.withinLimits()
+	s.repo.On("CreateWithdrawal", mock.Anything, mock.Anything).Return(3, nil)
+	s.provider.On("Payout", mock.Anything, mock.Anything).Return(&models.PaymentResult{Reference: "ref-3", Status: "approved"}, nil)
+	s.repo.On("UpdateWithdrawal", mock.Anything, mock.Anything, "processing").Return(nil)
+	s.ledgerRepo.On("Post", mock.Anything, entryMoving("withdrawal", s.UserID, models.NewMoney(200))).Return(nil)
+
+	withdrawal, err := s.service.Withdraw(context.Background(), s.UserID, models.NewMoney(200))
+
+	s.Require().NoError(err)
+	s.Equal(3, withdrawal.ID)
+	s.Equal("completed", withdrawal.Status)
+	s.Equal("ref-3", withdrawal.ProviderReference)
+	s.ledgerRepo.AssertExpectations(s.T())
+	s.ledgerRepo.AssertNumberOfCalls(s.T(), "Post", 2)
+}
+
+func (s *WalletServiceTestSuite) TestWithdrawDeclinedReversesHold() {
+	s.ledgerRepo.On("Post", mock.Anything, entryMoving("hold", s.UserID, models.NewMoney(200))).Return(nil)
+	s.withinLimits()
+	s.repo.On("CreateWithdrawal", mock.Anything, mock.Anything).Return(3, nil)
+	s.provider.On("Payout", mock.Anything, mock.Anything).Return(&models.PaymentResult{Reference: "ref-3", Status: "declined", Reason: "account closed"}, nil)
+	s.repo.On("UpdateWithdrawal", mock.Anything, mock.Anything, "processing").Return(nil)
+	s.ledgerRepo.On("Post", mock.Anything, entryMoving("release", s.UserID, models.NewMoney(200))).Return(nil)
+
+	withdrawal, err := s.service.Withdraw(context.Background(), s.UserID, models.NewMoney(200))
+
+	s.Require().NoError(err)
+	s.Equal("failed", withdrawal.Status)
+	s.Equal("account closed", withdrawal.FailureReason)
+	s.ledgerRepo.AssertExpectations(s.T())
+}
+
+func (s *WalletServiceTestSuite) TestWithdrawProviderUnavailableKeepsHold() {
+	s.ledgerRepo.On("Post", mock.Anything, entryMoving("hold", s.UserID, models.NewMoney(200))).Return(nil)
+	s.withinLimits()
+	s.repo.On("CreateWithdrawal", mock.Anything, mock.Anything).Return(3, nil)
+	s.provider.On("Payout", mock.Anything, mock.Anything).Return(nil, assert.AnError)
+
+	withdrawal, err := s.service.Withdraw(context.Background(), s.UserID, models.NewMoney(200))
+
+	// The payout may have gone through, the funds stay on hold until the provider tells
+	s.Require().NoError(err)
+	s.Equal("processing", withdrawal.Status)
+	s.Empty(withdrawal.FailureReason)
+	s.ledgerRepo.AssertNumberOfCalls(s.T(), "Post", 1)
+	s.repo.AssertNotCalled(s.T(), "UpdateWithdrawal", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *WalletServiceTestSuite) TestWithdrawPendingPayoutKeepsHold() {
+	s.ledgerRepo.On("Post", mock.Anything, entryMoving("hold", s.UserID, models.NewMoney(200))).Return(nil)
+	s.withinLimits()
+	s.repo.On("CreateWithdrawal", mock.Anything, mock.Anything).Return(3, nil)
+	s.provider.On("Payout", mock.Anything, mock.Anything).Return(&models.PaymentResult{Reference: "ref-3", Status: "pending"}, nil)
+	s.repo.On("UpdateWithdrawal", mock.Anything, mock.Anything, "processing").Return(nil)
+
+	withdrawal, err := s.service.Withdraw(context.Background(), s.UserID, models.NewMoney(200))
+
+	s.Require().NoError(err)
+	s.Equal("processing", withdrawal.Status)
+	s.Equal("ref-3", withdrawal.ProviderReference)
+	s.ledgerRepo.AssertNumberOfCalls(s.T(), "Post", 1)
+}
+
+func (s *WalletServiceTestSuite) TestWithdrawAboveThresholdWaitsForApproval() {
+	s.ledgerRepo.On("Post", mock.Anything, entryMoving("hold", s.UserID, models.NewMoney(1500))).Return(nil)
+	s.withinLimits()
+	s.repo.On("CreateWithdrawal", mock.Anything, mock.Anything).Return(3, nil)
+
+	withdrawal, err := s.service.Withdraw(context.Background(), s.UserID, models.NewMoney(1500))
+
+	s.Require().NoError(err)
+	s.Equal("pending_approval", withdrawal.Status)
+	s.provider.AssertNotCalled(s.T(), "Payout", mock.Anything, mock.Anything)
+}
+
+func (s *WalletServiceTestSuite) TestWithdrawInsufficientFunds() {
+	s.repo.On("CreateWithdrawal", mock.Anything, mock.Anything).Return(3, nil)
+	s.ledgerRepo.On("Post", mock.Anything, entryMoving("hold", s.UserID, models.NewMoney(200))).Return(ports.ErrInsufficientFunds)
+
+	withdrawal, err := s.service.Withdraw(context.Background(), s.UserID, models.NewMoney(200))
+
+	s.Nil(withdrawal)
+	s.ErrorIs(err, ports.ErrInsufficientFunds)
+	s.repo.AssertNotCalled(s.T(), "SumWithdrawnSince", mock.Anything, mock.Anything, mock.Anything)
+	s.provider.AssertNotCalled(s.T(), "Payout", mock.Anything, mock.Anything)
+}
+
+func (s *WalletServiceTestSuite) TestWithdrawDailyLimitExceeded() {
+	s.repo.On("CreateWithdrawal", mock.Anything, mock.Anything).Return(3, nil)
+	s.ledgerRepo.On("Post", mock.Anything, entryMoving("hold", s.UserID, models.NewMoney(200))).Return(nil)
+	s.repo.On("SumWithdrawnSince", mock.Anything, s.UserID, mock.Anything).Return(models.NewMoney(5100), nil).Once()
+
+	withdrawal, err := s.service.Withdraw(context.Background(), s.UserID, models.NewMoney(200))
+
+	s.Nil(withdrawal)
+	s.ErrorIs(err, ports.ErrDailyLimitExceeded)
+	s.provider.AssertNotCalled(s.T(), "Payout", mock.Anything, mock.Anything)
+}
+
+func (s *WalletServiceTestSuite) TestWithdrawMonthlyLimitExceeded() {
+	s.repo.On("CreateWithdrawal", mock.Anything, mock.Anything).Return(3, nil)
+	s.ledgerRepo.On("Post", mock.Anything, entryMoving("hold", s.UserID, models.NewMoney(200))).Return(nil)
+	s.repo.On("SumWithdrawnSince", mock.Anything, s.UserID, mock.Anything).Return(models.NewMoney(200), nil).Once()
+	s.repo.On("SumWithdrawnSince", mock.Anything, s.UserID, mock.Anything).Return(models.NewMoney(20100), nil).Once()
+
+	withdrawal, err := s.service.Withdraw(context.Background(), s.UserID, models.NewMoney(200))
+
+	s.Nil(withdrawal)
+	s.ErrorIs(err, ports.ErrMonthlyLimitExceeded)
+	s.provider.AssertNotCalled(s.T(), "Payout", mock.Anything, mock.Anything)
+}
+
+func (s *WalletServiceTestSuite) TestWithdrawInvalidAmount() {
+	for _, amount := range []models.Money{models.NewMoney(0), models.NewMoney(-10)} {
+		withdrawal, err := s.service.Withdraw(context.Background(), s.UserID, amount)
+
+		s.Nil(withdrawal)
+		s.ErrorIs(err, ports.ErrInvalidAmount)
+	}
+	s.ledgerRepo.AssertNotCalled(s.T(), "Post", mock.Anything, mock.Anything)
+}
+
+func (s *WalletServiceTestSuite) TestGetWithdrawalNotOwned() {
+	s.repo.On("FindById", mock.Anything, 3).Return(&models.Withdrawal{ID: 3, UserID: "other"}, nil)
+
+	withdrawal, err := s.service.GetWithdrawal(context.Background(), s.UserID, 3)
+
+	s.Nil(withdrawal)
+	s.ErrorIs(err, ports.ErrWithdrawalNotOwned)
+}
+
+func (s *WalletServiceTestSuite) TestApproveWithdrawal() {
+	s.repo.On("FindById", mock.Anything, 3).Return(&models.Withdrawal{ID: 3, UserID: s.UserID, Amount: models.NewMoney(1500), Status: "pending_approval"}, nil)
+	s.repo.On("UpdateWithdrawal", mock.Anything, mock.Anything, "pending_approval").Return(nil)
+	s.provider.On("Payout", mock.Anything, mock.Anything).Return(&models.PaymentResult{Reference: "ref-3", Status: "approved"}, nil)
+	s.repo.On("UpdateWithdrawal", mock.Anything, mock.Anything, "processing").Return(nil)
+	s.ledgerRepo.On("Post", mock.Anything, entryMoving("withdrawal", s.UserID, models.NewMoney(1500))).Return(nil)
+
+	withdrawal, err := s.service.ApproveWithdrawal(context.Background(), "reviewer", 3)
+
+	s.Require().NoError(err)
+	s.Equal("completed", withdrawal.Status)
+	s.Equal("reviewer", withdrawal.ReviewedBy)
+	s.ledgerRepo.AssertExpectations(s.T())
+}
+
+func (s *WalletServiceTestSuite) TestApproveWithdrawalNotPending() {
+	s.repo.On("FindById", mock.Anything, 3).Return(&models.Withdrawal{ID: 3, UserID: s.UserID, Amount: models.NewMoney(1500), Status: "rejected"}, nil)
+
+	withdrawal, err := s.service.ApproveWithdrawal(context.Background(), "reviewer", 3)
+
+	s.Nil(withdrawal)
+	s.ErrorIs(err, ports.ErrWithdrawalNotPending)
+	s.provider.AssertNotCalled(s.T(), "Payout", mock.Anything, mock.Anything)
+}
+
+func (s *WalletServiceTestSuite) TestApproveWithdrawalReviewedConcurrently() {
+	s.repo.On("FindById", mock.Anything, 3).Return(&models.Withdrawal{ID: 3, UserID: s.UserID, Amount: models.NewMoney(1500), Status: "pending_approval"}, nil)
+	s.repo.On("UpdateWithdrawal", mock.Anything, mock.Anything, "pending_approval").Return(ports.ErrWithdrawalNotPending)
+
+	withdrawal, err := s.service.ApproveWithdrawal(context.Background(), "reviewer", 3)
+
+	s.Nil(withdrawal)
+	s.ErrorIs(err, ports.ErrWithdrawalNotPending)
+	s.provider.AssertNotCalled(s.T(), "Payout", mock.Anything, mock.Anything)
+}
+
+func (s *WalletServiceTestSuite) TestRejectWithdrawalReleasesHold() {
+	s.repo.On("FindById", mock.Anything, 3).Return(&models.Withdrawal{ID: 3, UserID: s.UserID, Amount: models.NewMoney(1500), Status: "pending_approval"}, nil)
+	s.repo.On("UpdateWithdrawal", mock.Anything, mock.Anything, "pending_approval").Return(nil)
+	s.ledgerRepo.On("Post", mock.Anything, entryMoving("release", s.UserID, models.NewMoney(1500))).Return(nil)
+
+	withdrawal, err := s.service.RejectWithdrawal(context.Background(), "reviewer", 3, "suspicious activity")
+
+	s.Require().NoError(err)
+	s.Equal("rejected", withdrawal.Status)
+	s.Equal("suspicious activity", withdrawal.FailureReason)
+	s.ledgerRepo.AssertExpectations(s.T())
+}
+
+func (s *WalletServiceTestSuite) TestRefreshProcessingWithdrawals() {
+	approved := &models.Withdrawal{ID: 1, UserID: s.UserID, Amount: models.NewMoney(100), Status: "processing", ProviderReference: "ref-1"}
+	declined := &models.Withdrawal{ID: 2, UserID: s.UserID, Amount: models.NewMoney(200), Status: "processing", ProviderReference: "ref-2"}
+	stillPending := &models.Withdrawal{ID: 3, UserID: s.UserID, Amount: models.NewMoney(300), Status: "processing", ProviderReference: "ref-3"}
+	unanswered := &models.Withdrawal{ID: 4, UserID: s.UserID, Amount: models.NewMoney(400), Status: "processing"}
+	unreachable := &models.Withdrawal{ID: 5, UserID: s.UserID, Amount: models.NewMoney(500), Status: "processing"}
+	nowPending := &models.Withdrawal{ID: 6, UserID: s.UserID, Amount: models.NewMoney(600), Status: "processing"}
+	s.repo.On("FindByStatus", mock.Anything, "processing").Return([]*models.Withdrawal{approved, declined, stillPending, unanswered, unreachable, nowPending}, nil)
+	s.provider.On("Status", mock.Anything, "ref-1").Return(&models.PaymentResult{Reference: "ref-1", Status: "approved"}, nil)
+	s.provider.On("Status", mock.Anything, "ref-2").Return(&models.PaymentResult{Reference: "ref-2", Status: "declined", Reason: "account closed"}, nil)
+	s.provider.On("Status", mock.Anything, "ref-3").Return(&models.PaymentResult{Reference: "ref-3", Status: "pending"}, nil)
+	s.provider.On("Payout", mock.Anything, unanswered).Return(&models.PaymentResult{Reference: "ref-4", Status: "approved"}, nil)
+	s.provider.On("Payout", mock.Anything, unreachable).Return(nil, assert.AnError)
+	s.provider.On("Payout", mock.Anything, nowPending).Return(&models.PaymentResult{Reference: "ref-6", Status: "pending"}, nil)
+	s.repo.On("UpdateWithdrawal", mock.Anything, mock.Anything, "processing").Return(nil)
+	s.ledgerRepo.On("Post", mock.Anything, entryMoving("withdrawal", s.UserID, models.NewMoney(100))).Return(nil)
+	s.ledgerRepo.On("Post", mock.Anything, entryMoving("withdrawal", s.UserID, models.NewMoney(400))).Return(nil)
+	s.ledgerRepo.On("Post", mock.Anything, entryMoving("release", s.UserID, models.NewMoney(200))).Return(nil)
+
+	resolved, err := s.service.RefreshProcessingWithdrawals(context.Background())
+
+	s.Require().NoError(err)
+	s.Equal(3, resolved)
+	s.Equal("completed", approved.Status)
+	s.Equal("failed", declined.Status)
+	s.Equal("processing", stillPending.Status)
+	s.Equal("completed", unanswered.Status)
+	s.Equal("ref-4", unanswered.ProviderReference)
+	s.Equal("processing", unreachable.Status)
+	s.Equal("processing", nowPending.Status)
+	s.Equal("ref-6", nowPending.ProviderReference)
+	s.ledgerRepo.AssertExpectations(s.T())
+	s.provider.AssertNotCalled(s.T(), "Status", mock.Anything, "")
+}
+
+// ---------------------------
+// Run the suite
+// ---------------------------
+func TestWalletServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(WalletServiceTestSuite))
+}
@@ -3,9 +3,13 @@ package main
 import (
 	"brokerx/adapters"
 	"brokerx/core"
+	"brokerx/ports"
+	"context"
 	"database/sql"
+	"errors"
 	"html/template"
 	"net/http"
+	"time"
 
 	"github.com/gorilla/sessions"
 	log "github.com/sirupsen/logrus"
@@ -29,9 +33,9 @@ func run() http.Handler {
 		log.Fatalf("Config error : %s", err)
 	}
 
-    userRepo, orderRepo := initDbConnection()
+    repos := initDbConnection()
     authService := &core.AuthService{
-        Repo:                        userRepo,
+        Repo:                        repos.users,
         PasswordAllowedRetries:      config.PasswordAllowedRetries,
         PasswordLockDurationMinutes: config.PasswordLockDurationMinutes,
     }
@@ -41,24 +45,151 @@ func run() http.Handler {
         IsProduction: config.IsProduction,
     }
 
-    orderService := &core.OrderService{Repo: orderRepo}
+    complianceService := &core.ComplianceService{WalletRepo: repos.wallets, PositionRepo: repos.positions, TaxLotRepo: repos.taxLots, Fees: config.FeeSchedule()}
+    orderService := &core.OrderService{
+        Repo:              repos.orders,
+        UnitOfWork:        repos.unitOfWork,
+        ComplianceService: complianceService,
+        Engine:            &core.MatchingEngine{},
+        SymbolRepo:        repos.symbols,
+        Fees:              config.FeeSchedule(),
+        SettlementDays:    config.SettlementDays,
+        Holidays:          config.MarketHolidays,
+    }
     orderHandler := &adapters.OrderHandler{Service: orderService}
+    orderAPIHandler := &adapters.OrderAPIHandler{Service: orderService}
+    portfolioHandler := &adapters.PortfolioHandler{
+        Service: &core.PortfolioService{PositionRepo: repos.positions, ExecutionRepo: repos.executions, SymbolRepo: repos.symbols},
+    }
+
+    loaded, err := orderService.LoadOrders(context.Background(), time.Now())
+    if err != nil {
+        log.Errorf("Failed to load the order books: %v", err)
+    } else {
+        log.Infof("Loaded %d orders on the order books", loaded)
+    }
+
+    expiryScheduler := &core.ExpiryScheduler{
+        Service:              orderService,
+        SessionClose:         config.SessionCloseTime,
+        GoodTillDateInterval: time.Duration(config.GTDExpiryIntervalSeconds) * time.Second,
+    }
+    if err := expiryScheduler.Start(context.Background()); err != nil {
+		log.Fatalf("Expiry scheduler error : %s", err)
+	}
+
+    settlementJob := &core.SettlementJob{
+        Service:      &core.SettlementService{ExecutionRepo: repos.executions, UnitOfWork: repos.unitOfWork},
+        SessionClose: config.SessionCloseTime,
+    }
+    if err := settlementJob.Start(context.Background()); err != nil {
+		log.Fatalf("Settlement job error : %s", err)
+	}
+
+    taxLotHandler := &adapters.TaxLotHandler{
+        Service: &core.TaxLotService{TaxLotRepo: repos.taxLots, UserRepo: repos.users},
+    }
+
+    depositService := &core.DepositService{
+        Repo:       repos.deposits,
+        UnitOfWork: repos.unitOfWork,
+        Provider:   &adapters.FakePaymentProvider{Delay: time.Duration(config.PaymentDelaySeconds) * time.Second},
+    }
+    depositHandler := &adapters.DepositHandler{Service: depositService}
+
+    walletService := &core.WalletService{
+        Repo:              repos.withdrawals,
+        UnitOfWork:        repos.unitOfWork,
+        Provider:          depositService.Provider,
+        DailyLimit:        config.WithdrawalDailyLimit,
+        MonthlyLimit:      config.WithdrawalMonthlyLimit,
+        ApprovalThreshold: config.WithdrawalApprovalThreshold,
+    }
+    withdrawalHandler := &adapters.WithdrawalHandler{Service: walletService}
+
+    paymentPoller := &core.PaymentPoller{
+        Deposits:    depositService,
+        Withdrawals: walletService,
+        Interval:    time.Duration(config.PaymentPollIntervalSeconds) * time.Second,
+    }
+    if err := paymentPoller.Start(context.Background()); err != nil {
+		log.Fatalf("Payment poller error : %s", err)
+	}
+
+    ledgerService := &core.LedgerService{LedgerRepo: repos.ledger, WalletRepo: repos.wallets}
+    ledgerHandler := &adapters.LedgerHandler{Service: ledgerService}
+    fxHandler := &adapters.FXHandler{
+        Service: &core.FXService{Repo: repos.fxConversions, UnitOfWork: repos.unitOfWork, Rates: config.FXRates},
+    }
+    statementHandler := &adapters.StatementHandler{
+        Service: &core.StatementService{
+            OrderRepo:      repos.orders,
+            ExecutionRepo:  repos.executions,
+            LedgerRepo:     repos.ledger,
+            DepositRepo:    repos.deposits,
+            WithdrawalRepo: repos.withdrawals,
+            TaxLotRepo:     repos.taxLots,
+            SymbolRepo:     repos.symbols,
+        },
+    }
+    confirmationHandler := &adapters.ConfirmationHandler{
+        Service: &core.ConfirmationService{Repo: repos.confirmations},
+    }
+    reconciliationJob := &core.ReconciliationJob{
+        Service:  ledgerService,
+        Interval: time.Duration(config.ReconciliationIntervalSeconds) * time.Second,
+    }
+    if err := reconciliationJob.Start(context.Background()); err != nil {
+		log.Fatalf("Reconciliation job error : %s", err)
+	}
 
-    router := initRouter(authHandler, orderHandler)
+    router := initRouter(authHandler, orderHandler, orderAPIHandler, portfolioHandler, taxLotHandler, depositHandler, withdrawalHandler, ledgerHandler, fxHandler, statementHandler, confirmationHandler)
     return router
 }
 
-func initDbConnection() (*adapters.SQLUserRepository, *adapters.SQLOrderRepository) {
+type repositories struct {
+	users      *adapters.SQLUserRepository
+	orders     *adapters.SQLOrderRepository
+	wallets    *adapters.SQLWalletRepository
+	ledger     *adapters.SQLLedgerRepository
+	positions  *adapters.SQLPositionRepository
+	executions *adapters.SQLExecutionRepository
+	taxLots    *adapters.SQLTaxLotRepository
+	deposits   *adapters.SQLDepositRepository
+	withdrawals *adapters.SQLWithdrawalRepository
+	symbols    *adapters.SQLSymbolRepository
+	fxConversions *adapters.SQLFXConversionRepository
+	confirmations *adapters.SQLConfirmationRepository
+	unitOfWork *adapters.SQLUnitOfWork
+}
+
+func initDbConnection() repositories {
 	db, e := sql.Open("mysql", config.DBUrl)
 	if err := db.Ping(); err != nil || e != nil {
 		log.Warnf("Db error : %s | %s", e, err)
 	}
-	return &adapters.SQLUserRepository{DB: db}, &adapters.SQLOrderRepository{DB: db}
+	return repositories{
+		users:      &adapters.SQLUserRepository{DB: db},
+		orders:     &adapters.SQLOrderRepository{DB: db},
+		wallets:    &adapters.SQLWalletRepository{DB: db},
+		ledger:     &adapters.SQLLedgerRepository{DB: db},
+		positions:  &adapters.SQLPositionRepository{DB: db},
+		executions: &adapters.SQLExecutionRepository{DB: db},
+		taxLots:    &adapters.SQLTaxLotRepository{DB: db},
+		deposits:   &adapters.SQLDepositRepository{DB: db},
+		withdrawals: &adapters.SQLWithdrawalRepository{DB: db},
+		symbols:    &adapters.SQLSymbolRepository{DB: db},
+		fxConversions: &adapters.SQLFXConversionRepository{DB: db},
+		confirmations: &adapters.SQLConfirmationRepository{DB: db},
+		unitOfWork: &adapters.SQLUnitOfWork{DB: db, Timeout: time.Duration(config.DBTimeoutSeconds) * time.Second},
+	}
 }
 
-func initRouter(authHandler *adapters.AuthHandler, orderHandler *adapters.OrderHandler) (*chi.Mux) {
+func initRouter(authHandler *adapters.AuthHandler, orderHandler *adapters.OrderHandler, orderAPIHandler *adapters.OrderAPIHandler, portfolioHandler *adapters.PortfolioHandler, taxLotHandler *adapters.TaxLotHandler, depositHandler *adapters.DepositHandler, withdrawalHandler *adapters.WithdrawalHandler, ledgerHandler *adapters.LedgerHandler, fxHandler *adapters.FXHandler, statementHandler *adapters.StatementHandler, confirmationHandler *adapters.ConfirmationHandler) (*chi.Mux) {
 	router := chi.NewRouter()
+    router.Use(middleware.RequestID)
     router.Use(middleware.Logger)
+    router.Use(dbTimeoutMiddleware(time.Duration(config.DBTimeoutSeconds) * time.Second))
     router.Use(noCacheMiddleware)
 
 	// Public static assets
@@ -91,7 +222,90 @@ func initRouter(authHandler *adapters.AuthHandler, orderHandler *adapters.OrderH
             renderTemplate(w, "order.html", map[string]string{"Email": userEmail})
         })
 
+        r.Get("/orders", func(w http.ResponseWriter, r *http.Request) {
+            userEmail := r.Context().Value(adapters.USER_EMAIL_KEY).(string)
+            renderTemplate(w, "orders.html", map[string]string{"Email": userEmail})
+        })
+
+        r.Get("/funds", func(w http.ResponseWriter, r *http.Request) {
+            userEmail := r.Context().Value(adapters.USER_EMAIL_KEY).(string)
+            renderTemplate(w, "funds.html", map[string]string{"Email": userEmail})
+        })
+
+        r.Get("/portfolio", func(w http.ResponseWriter, r *http.Request) {
+            userID := r.Context().Value(adapters.USER_ID_KEY).(string)
+            userEmail := r.Context().Value(adapters.USER_EMAIL_KEY).(string)
+            portfolio, err := portfolioHandler.Service.GetPortfolio(r.Context(), userID)
+            if err != nil {
+                http.Error(w, "failed to load portfolio", http.StatusInternalServerError)
+                return
+            }
+            renderTemplate(w, "portfolio.html", map[string]any{"Email": userEmail, "Portfolio": portfolio})
+        })
+
+        r.Get("/confirmations", func(w http.ResponseWriter, r *http.Request) {
+            userID := r.Context().Value(adapters.USER_ID_KEY).(string)
+            userEmail := r.Context().Value(adapters.USER_EMAIL_KEY).(string)
+            confirmations, err := confirmationHandler.Service.ListConfirmations(r.Context(), userID)
+            if err != nil {
+                http.Error(w, "failed to load trade confirmations", http.StatusInternalServerError)
+                return
+            }
+            renderTemplate(w, "confirmations.html", map[string]any{"Email": userEmail, "Confirmations": confirmations})
+        })
+
+        r.Get("/confirmations/{number}", func(w http.ResponseWriter, r *http.Request) {
+            userID := r.Context().Value(adapters.USER_ID_KEY).(string)
+            userEmail := r.Context().Value(adapters.USER_EMAIL_KEY).(string)
+            confirmation, err := confirmationHandler.Service.GetConfirmation(r.Context(), userID, chi.URLParam(r, "number"))
+            if errors.Is(err, ports.ErrConfirmationNotFound) || errors.Is(err, ports.ErrConfirmationNotOwned) {
+                http.Error(w, "trade confirmation not found", http.StatusNotFound)
+                return
+            }
+            if err != nil {
+                http.Error(w, "failed to load trade confirmation", http.StatusInternalServerError)
+                return
+            }
+            renderTemplate(w, "confirmation.html", map[string]any{"Email": userEmail, "Confirmation": confirmation})
+        })
+
         r.Post("/order/place", orderHandler.PlaceOrder)
+        r.Post("/order/{id}/cancel", orderHandler.CancelOrder)
+        r.Post("/order/{id}/modify", orderHandler.ModifyOrder)
+    })
+
+    // Protected JSON API routes
+    router.Route("/api/v1", func(r chi.Router) {
+        r.Use(authHandler.APIMiddleware)
+        r.Post("/orders", orderAPIHandler.CreateOrder)
+        r.Get("/orders", orderAPIHandler.ListOrders)
+        r.Get("/orders/{id}", orderAPIHandler.GetOrder)
+        r.Delete("/orders/{id}", orderAPIHandler.CancelOrder)
+        r.Get("/portfolio", portfolioHandler.GetPortfolio)
+        r.Get("/tax-lots", taxLotHandler.GetLots)
+        r.Get("/realized-gains", taxLotHandler.GetRealizedGains)
+        r.Put("/account/lot-relief-method", taxLotHandler.SetReliefMethod)
+        r.Post("/deposits", depositHandler.CreateDeposit)
+        r.Get("/deposits", depositHandler.ListDeposits)
+        r.Get("/deposits/{id}", depositHandler.GetDeposit)
+        r.Post("/withdrawals", withdrawalHandler.CreateWithdrawal)
+        r.Get("/withdrawals", withdrawalHandler.ListWithdrawals)
+        r.Get("/withdrawals/{id}", withdrawalHandler.GetWithdrawal)
+        r.Get("/ledger", ledgerHandler.ListEntries)
+        r.Get("/wallets", ledgerHandler.ListWallets)
+        r.Post("/fx/conversions", fxHandler.CreateConversion)
+        r.Get("/fx/conversions", fxHandler.ListConversions)
+        r.Get("/statements", statementHandler.GetStatement)
+        r.Get("/confirmations", confirmationHandler.ListConfirmations)
+        r.Get("/confirmations/{number}", confirmationHandler.GetConfirmation)
+
+        r.Route("/back-office", func(r chi.Router) {
+            r.Use(authHandler.BackOfficeMiddleware)
+            r.Get("/withdrawals", withdrawalHandler.ListPendingApprovals)
+            r.Post("/withdrawals/{id}/approve", withdrawalHandler.ApproveWithdrawal)
+            r.Post("/withdrawals/{id}/reject", withdrawalHandler.RejectWithdrawal)
+            r.Get("/reconciliation", ledgerHandler.Reconcile)
+        })
     })
 
     return router
@@ -106,6 +320,23 @@ func noCacheMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+// dbTimeoutMiddleware bounds the context of the request by the DB timeout, so that every
+// query made for the request gives up once it has passed. The units of work detached from
+// the request are bounded by the timeout of the SQLUnitOfWork instead.
+func dbTimeoutMiddleware(timeout time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if timeout <= 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+			ctx, cancel := context.WithTimeout(r.Context(), timeout)
+			defer cancel()
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
 func renderTemplate(w http.ResponseWriter, name string, data any) {
     tpl, err := template.ParseFiles(config.FrontendPath+"/templates/base.html", config.FrontendPath+"/templates/"+name)
     if err != nil {
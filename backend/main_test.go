@@ -6,6 +6,7 @@ import (
 	"net/http/httptest"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -30,6 +31,19 @@ func TestServerEndpoint(t *testing.T) {
     assert.Contains(t, string(body), "OK")
 }
 
+func TestDBTimeoutMiddleware(t *testing.T) {
+	var deadline time.Time
+	var bounded bool
+	handler := dbTimeoutMiddleware(5 * time.Second)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		deadline, bounded = r.Context().Deadline()
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/portfolio", nil))
+
+	assert.True(t, bounded)
+	assert.WithinDuration(t, time.Now().Add(5*time.Second), deadline, time.Second)
+}
+
 func TestCoverageJustification(t *testing.T) {
 	t.Skip("Full server tested externally via Postman")
 }
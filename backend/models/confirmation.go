@@ -0,0 +1,44 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Confirmation is the trade confirmation issued to one side of an execution. It is never
+// modified once issued.
+type Confirmation struct {
+	ID             int
+	Number         string
+	ExecutionID    int
+	OrderID        int
+	UserID         string
+	Symbol         string
+	Side           string // buy, sell
+	Quantity       int
+	Price          Money
+	Currency       string
+	Commission     Money
+	RegulatoryFee  Money // only charged to the seller
+	ExecutedAt     time.Time
+	SettlementDate time.Time
+	IssuedAt       sql.NullTime
+}
+
+// ConfirmationNumber identifies the confirmation of a side of an execution, for example
+// TC-20260918-00000042-S for the seller of execution 42.
+func ConfirmationNumber(execution *Execution, side string) string {
+	initial := ""
+	if side != "" {
+		initial = strings.ToUpper(side[:1])
+	}
+	return fmt.Sprintf("TC-%s-%08d-%s", execution.ExecutedAt.UTC().Format("20060102"), execution.ID, initial)
+}
+
+// NetAmount is the cash the trade moved for the user: the cost of a purchase with its
+// fees as a negative amount, or the proceeds of a sale net of its fees.
+func (confirmation *Confirmation) NetAmount() Money {
+	return netTradeAmount(confirmation.Side, confirmation.Quantity, confirmation.Price, confirmation.Commission, confirmation.RegulatoryFee)
+}
@@ -0,0 +1,23 @@
+package models
+
+import "database/sql"
+
+// Deposit adds funds to the wallet of a user through the payment provider. A deposit is
+// pending until the provider approves or declines the payment.
+type Deposit struct {
+	ID                int
+	UserID            string
+	Amount            Money
+	Status            string // pending, settled, failed
+	ProviderReference string
+	FailureReason     string // only set for failed
+	CreatedAt         sql.NullTime
+	UpdatedAt         sql.NullTime
+}
+
+// PaymentResult is the answer of the payment provider about a payment.
+type PaymentResult struct {
+	Reference string
+	Status    string // approved, declined, pending
+	Reason    string // only set for declined
+}
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// ExchangeRates is a table of exchange rates keyed by currency pair, e.g. "USD/CAD" for
+// the number of Canadian dollars one US dollar buys.
+type ExchangeRates map[string]*big.Rat
+
+// ParseExchangeRates parses a comma separated list of currency pairs and their rate, such
+// as "USD/CAD=1.37,EUR/USD=1.08".
+func ParseExchangeRates(value string) (ExchangeRates, error) {
+	rates := ExchangeRates{}
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		pair, text, hasRate := strings.Cut(item, "=")
+		from, to, isPair := strings.Cut(strings.TrimSpace(pair), "/")
+		rate, isRate := new(big.Rat).SetString(strings.TrimSpace(text))
+		if !hasRate || !isPair || !isRate || rate.Sign() <= 0 || from == to || !IsSupportedCurrency(from) || !IsSupportedCurrency(to) {
+			return nil, fmt.Errorf("invalid exchange rate %q", item)
+		}
+		rates[from+"/"+to] = rate
+	}
+	return rates, nil
+}
+
+// Rate returns the number of units of the to currency that one unit of the from currency
+// buys. A pair missing from the table is derived from the inverse pair.
+func (rates ExchangeRates) Rate(from string, to string) (*big.Rat, bool) {
+	if rate, ok := rates[from+"/"+to]; ok {
+		return rate, true
+	}
+	if rate, ok := rates[to+"/"+from]; ok {
+		return new(big.Rat).Inv(rate), true
+	}
+	return nil, false
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseExchangeRates(t *testing.T) {
+	rates, err := ParseExchangeRates(" USD/CAD=1.37, EUR/USD = 1.08 ,")
+	require.NoError(t, err)
+	require.Len(t, rates, 2)
+
+	rate, ok := rates.Rate("USD", "CAD")
+	require.True(t, ok)
+	require.Equal(t, "1.37", rate.FloatString(2))
+
+	// The inverse pair is derived
+	rate, ok = rates.Rate("USD", "EUR")
+	require.True(t, ok)
+	require.Equal(t, "0.92592593", rate.FloatString(8))
+
+	_, ok = rates.Rate("CAD", "EUR")
+	require.False(t, ok)
+
+	for _, value := range []string{"USD/CAD", "USDCAD=1.37", "USD/CAD=abc", "USD/CAD=0", "USD/CAD=-1", "USD/USD=1", "USD/XYZ=2"} {
+		_, err := ParseExchangeRates(value)
+		require.Error(t, err, value)
+	}
+}
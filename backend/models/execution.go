@@ -0,0 +1,22 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+type Execution struct {
+	ID             int
+	BuyOrderID     int
+	SellOrderID    int
+	Symbol         string
+	Quantity       int
+	Price          Money
+	LiquidityFlag  string // buyer_maker, seller_maker
+	BuyCommission  Money
+	SellCommission Money
+	RegulatoryFee  Money // charged to the seller
+	ExecutedAt     time.Time
+	SettlementDate time.Time // the trade settles on this date, at midnight UTC
+	SettledAt      sql.NullTime
+}
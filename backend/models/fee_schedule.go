@@ -0,0 +1,62 @@
+package models
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// FeeSchedule describes the commission charged to both sides of a trade and the
+// regulatory fee charged to the seller. The commission of an order is its per-order fee,
+// plus its per-share fee and its rate of the notional, kept between the minimum and the
+// maximum. A zero maximum leaves the commission uncapped. The fixed amounts are in the
+// trading currency of the order, the rates are fractions of the notional, e.g. 0.001 for
+// 0.1%.
+type FeeSchedule struct {
+	PerShare       Money
+	PerOrder       Money
+	Rate           *big.Rat
+	Minimum        Money
+	Maximum        Money
+	RegulatoryRate *big.Rat
+}
+
+// ParseRate parses a non-negative decimal rate such as "0.001".
+func ParseRate(value string) (*big.Rat, error) {
+	rate, ok := new(big.Rat).SetString(strings.TrimSpace(value))
+	if !ok || rate.Sign() < 0 {
+		return nil, fmt.Errorf("invalid rate %q", value)
+	}
+	return rate, nil
+}
+
+// Commission returns the commission of an order that filled the quantity for the
+// notional, rounded to the minor unit of the currency. An order that has not filled
+// costs nothing.
+func (schedule FeeSchedule) Commission(quantity int, notional Money, currency string) Money {
+	if quantity <= 0 {
+		return Money{}
+	}
+
+	commission := schedule.PerOrder.Add(schedule.PerShare.Mul(quantity)).Add(notional.MulRate(rateOrZero(schedule.Rate), RoundHalfEven))
+	if commission.LessThan(schedule.Minimum) {
+		commission = schedule.Minimum
+	}
+	if schedule.Maximum.IsPositive() && commission.GreaterThan(schedule.Maximum) {
+		commission = schedule.Maximum
+	}
+	return commission.RoundToCurrency(currency, RoundHalfEven)
+}
+
+// RegulatoryFee returns the regulatory fee of a sale for the notional, rounded up to the
+// minor unit of the currency.
+func (schedule FeeSchedule) RegulatoryFee(notional Money, currency string) Money {
+	return notional.MulRate(rateOrZero(schedule.RegulatoryRate), RoundUp).RoundToCurrency(currency, RoundUp)
+}
+
+func rateOrZero(rate *big.Rat) *big.Rat {
+	if rate == nil {
+		return new(big.Rat)
+	}
+	return rate
+}
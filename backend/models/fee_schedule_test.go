@@ -0,0 +1,61 @@
+package models
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFeeScheduleCommission(t *testing.T) {
+	schedule := FeeSchedule{
+		PerShare: MustParseMoney("0.005"),
+		PerOrder: NewMoney(2),
+		Rate:     big.NewRat(1, 1000),
+		Minimum:  NewMoney(3),
+		Maximum:  NewMoney(20),
+	}
+
+	cases := []struct {
+		quantity int
+		notional Money
+		currency string
+		expected string
+	}{
+		// 2 + 0.50 + 1.50, rounded half to even
+		{100, NewMoney(1500), "USD", "4.0000"},
+		{10, NewMoney(1500), "USD", "3.5500"},
+		// Kept at the minimum and the maximum
+		{1, NewMoney(100), "USD", "3.0000"},
+		{1000, NewMoney(50000), "USD", "20.0000"},
+		// 2 + 0.005 + 0.1 yen, rounded to the yen
+		{1, NewMoney(100), "JPY", "3.0000"},
+		{0, NewMoney(1500), "USD", "0.0000"},
+	}
+	for _, c := range cases {
+		require.Equal(t, c.expected, schedule.Commission(c.quantity, c.notional, c.currency).String(), "%d for %s", c.quantity, c.notional)
+	}
+
+	// An empty schedule charges nothing
+	require.True(t, FeeSchedule{}.Commission(100, NewMoney(1500), "USD").IsZero())
+}
+
+func TestFeeScheduleRegulatoryFee(t *testing.T) {
+	schedule := FeeSchedule{RegulatoryRate: big.NewRat(278, 10000000)}
+
+	// 1500 * 0.0000278 = 0.0417, rounded up to the cent
+	require.Equal(t, MustParseMoney("0.05"), schedule.RegulatoryFee(NewMoney(1500), "USD"))
+	require.Equal(t, NewMoney(1), schedule.RegulatoryFee(NewMoney(1500), "JPY"))
+	require.True(t, FeeSchedule{}.RegulatoryFee(NewMoney(1500), "USD").IsZero())
+}
+
+func TestParseRate(t *testing.T) {
+	rate, err := ParseRate(" 0.0000278 ")
+	require.NoError(t, err)
+	require.Equal(t, big.NewRat(278, 10000000), rate)
+
+	for _, value := range []string{"", "abc", "-0.1"} {
+		_, err := ParseRate(value)
+		require.Error(t, err, value)
+	}
+}
@@ -0,0 +1,17 @@
+package models
+
+import "database/sql"
+
+// FXConversion exchanges cash of a user from one currency to another at the rate of the
+// rate table. The converted amount is rounded down to the minor unit of the target
+// currency.
+type FXConversion struct {
+	ID              int
+	UserID          string
+	FromCurrency    string
+	ToCurrency      string
+	Amount          Money  // in FromCurrency
+	ConvertedAmount Money  // in ToCurrency
+	Rate            string // units of ToCurrency for one unit of FromCurrency
+	CreatedAt       sql.NullTime
+}
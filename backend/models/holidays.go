@@ -0,0 +1,51 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const dateLayout = "2006-01-02"
+
+// Holidays are the weekdays on which the market is closed and trades do not settle,
+// keyed by their date as YYYY-MM-DD.
+type Holidays map[string]bool
+
+// ParseHolidays parses a comma separated list of dates such as "2026-12-25,2027-01-01".
+func ParseHolidays(value string) (Holidays, error) {
+	holidays := Holidays{}
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		if _, err := time.Parse(dateLayout, item); err != nil {
+			return nil, fmt.Errorf("invalid holiday %q", item)
+		}
+		holidays[item] = true
+	}
+	return holidays, nil
+}
+
+// IsBusinessDay tells whether the market is open on the date.
+func (holidays Holidays) IsBusinessDay(date time.Time) bool {
+	if date.Weekday() == time.Saturday || date.Weekday() == time.Sunday {
+		return false
+	}
+	return !holidays[date.Format(dateLayout)]
+}
+
+// AddBusinessDays returns the date that comes the number of business days after the
+// calendar date of the time. The date is at midnight UTC so that it is stored as the
+// same calendar date whatever the time zone of the time.
+func (holidays Holidays) AddBusinessDays(from time.Time, days int) time.Time {
+	date := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	for days > 0 {
+		date = date.AddDate(0, 0, 1)
+		if holidays.IsBusinessDay(date) {
+			days--
+		}
+	}
+	return date
+}
@@ -0,0 +1,50 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseHolidays(t *testing.T) {
+	holidays, err := ParseHolidays(" 2026-12-25, 2027-01-01 ,")
+	require.NoError(t, err)
+	require.Equal(t, Holidays{"2026-12-25": true, "2027-01-01": true}, holidays)
+
+	for _, value := range []string{"2026-13-01", "25/12/2026", "christmas"} {
+		_, err := ParseHolidays(value)
+		require.Error(t, err, value)
+	}
+}
+
+func TestAddBusinessDays(t *testing.T) {
+	holidays := Holidays{"2026-12-25": true}
+	montreal, err := time.LoadLocation("America/Montreal")
+	require.NoError(t, err)
+
+	cases := []struct {
+		from     time.Time
+		days     int
+		expected string
+	}{
+		// Tuesday to Wednesday
+		{time.Date(2026, 10, 20, 15, 30, 0, 0, time.UTC), 1, "2026-10-21"},
+		// Friday to Monday
+		{time.Date(2026, 10, 23, 15, 30, 0, 0, time.UTC), 1, "2026-10-26"},
+		// Saturday to Monday
+		{time.Date(2026, 10, 24, 15, 30, 0, 0, time.UTC), 1, "2026-10-26"},
+		// Christmas Eve to the Monday after Christmas
+		{time.Date(2026, 12, 24, 15, 30, 0, 0, time.UTC), 1, "2026-12-28"},
+		{time.Date(2026, 10, 20, 15, 30, 0, 0, time.UTC), 2, "2026-10-22"},
+		{time.Date(2026, 10, 20, 15, 30, 0, 0, time.UTC), 0, "2026-10-20"},
+		// The calendar date is the one of the time zone of the time
+		{time.Date(2026, 10, 20, 22, 30, 0, 0, montreal), 1, "2026-10-21"},
+	}
+	for _, c := range cases {
+		date := holidays.AddBusinessDays(c.from, c.days)
+
+		require.Equal(t, c.expected, date.Format("2006-01-02"), "%s + %d", c.from, c.days)
+		require.Equal(t, time.UTC, date.Location())
+	}
+}
@@ -0,0 +1,47 @@
+package models
+
+import "database/sql"
+
+// JournalEntry is an append-only record of a movement of cash. The postings of an entry
+// always sum to zero in each currency: what is credited to an account is debited from
+// another one.
+type JournalEntry struct {
+	ID        int
+	Type      string // deposit, withdrawal, hold, release, trade, fee, settlement, fx_conversion, opening_balance
+	Reference string // what caused the entry, e.g. order:12 or deposit:3
+	Postings  []LedgerPosting
+	CreatedAt sql.NullTime
+}
+
+// LedgerPosting credits an amount to an account, or debits it when the amount is
+// negative. The available, on_hold and unsettled accounts belong to the wallet of a user,
+// their balance is what the user can spend, what is reserved for open orders and pending
+// withdrawals and the proceeds of sales waiting for their settlement date. The other
+// accounts belong to the broker.
+type LedgerPosting struct {
+	Account  string // available, on_hold, unsettled, payment_provider, fee_revenue, regulatory_fees, fx, opening_equity
+	UserID   string // only set for the available, on_hold and unsettled accounts
+	Currency string
+	Amount   Money
+}
+
+// LedgerBalance is the cash of the wallet of a user in a currency according to the ledger.
+type LedgerBalance struct {
+	UserID         string
+	Currency       string
+	AvailableFunds Money
+	OnHoldFunds    Money
+	UnsettledFunds Money
+}
+
+// WalletDiscrepancy reports a wallet whose stored balances disagree with the ledger.
+type WalletDiscrepancy struct {
+	UserID               string
+	Currency             string
+	StoredAvailableFunds Money
+	LedgerAvailableFunds Money
+	StoredOnHoldFunds    Money
+	LedgerOnHoldFunds    Money
+	StoredUnsettledFunds Money
+	LedgerUnsettledFunds Money
+}
@@ -0,0 +1,346 @@
+package models
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// moneyScale is the number of decimal places Money keeps. It matches the most precise
+// DECIMAL columns of the database, those holding average costs.
+const moneyScale = 4
+
+var scaleFactors = [moneyScale + 1]int64{1, 10, 100, 1000, 10000}
+
+// BaseCurrency is the currency of the wallets and of the prices.
+const BaseCurrency = "USD"
+
+// currencyScales is the number of decimal places of the minor unit of each currency.
+var currencyScales = map[string]int{
+	"USD": 2,
+	"EUR": 2,
+	"GBP": 2,
+	"CAD": 2,
+	"CHF": 2,
+	"JPY": 0,
+}
+
+// IsSupportedCurrency tells whether wallets can hold the currency.
+func IsSupportedCurrency(currency string) bool {
+	_, ok := currencyScales[currency]
+	return ok
+}
+
+// CurrencyScale returns the number of decimal places of the minor unit of the currency,
+// 2 for an unknown currency.
+func CurrencyScale(currency string) int {
+	if scale, ok := currencyScales[currency]; ok {
+		return scale
+	}
+	return 2
+}
+
+// RoundingMode tells how an amount that does not fit in the requested scale is rounded.
+type RoundingMode int
+
+const (
+	RoundHalfEven RoundingMode = iota // to the nearest, ties to the even neighbour
+	RoundHalfUp                       // to the nearest, ties away from zero
+	RoundDown                         // towards zero
+	RoundUp                           // away from zero
+)
+
+// Money is an exact decimal amount of cash or price. It is a fixed-point number with
+// four decimal places, so that sums and products by a quantity never lose a cent the
+// way float64 does. The zero value is zero.
+type Money struct {
+	units int64 // ten-thousandths
+}
+
+// NewMoney returns a whole amount.
+func NewMoney(whole int64) Money {
+	return Money{units: whole * scaleFactors[moneyScale]}
+}
+
+// ParseMoney parses a decimal amount such as "1500", "-12.5" or "148.2575". It fails on
+// more than four decimal places rather than rounding them silently.
+func ParseMoney(value string) (Money, error) {
+	text := strings.TrimSpace(value)
+	negative := strings.HasPrefix(text, "-")
+	text = strings.TrimPrefix(strings.TrimPrefix(text, "-"), "+")
+
+	whole, fraction, _ := strings.Cut(text, ".")
+	if whole == "" && fraction == "" {
+		return Money{}, fmt.Errorf("invalid amount %q", value)
+	}
+	if len(fraction) > moneyScale {
+		return Money{}, fmt.Errorf("invalid amount %q: more than %d decimal places", value, moneyScale)
+	}
+
+	units := int64(0)
+	for _, digits := range []string{whole, fraction + strings.Repeat("0", moneyScale-len(fraction))} {
+		for _, digit := range digits {
+			if digit < '0' || digit > '9' {
+				return Money{}, fmt.Errorf("invalid amount %q", value)
+			}
+			units = units*10 + int64(digit-'0')
+			if units < 0 {
+				return Money{}, fmt.Errorf("invalid amount %q: out of range", value)
+			}
+		}
+	}
+
+	if negative {
+		units = -units
+	}
+	return Money{units: units}, nil
+}
+
+// MustParseMoney is like ParseMoney but panics on an invalid amount. It is meant for
+// constants.
+func MustParseMoney(value string) Money {
+	money, err := ParseMoney(value)
+	if err != nil {
+		panic(err)
+	}
+	return money
+}
+
+func (m Money) Add(other Money) Money {
+	return Money{units: m.units + other.units}
+}
+
+func (m Money) Sub(other Money) Money {
+	return Money{units: m.units - other.units}
+}
+
+func (m Money) Neg() Money {
+	return Money{units: -m.units}
+}
+
+func (m Money) Abs() Money {
+	if m.units < 0 {
+		return m.Neg()
+	}
+	return m
+}
+
+// Mul multiplies the amount by a quantity. The product is exact.
+func (m Money) Mul(quantity int) Money {
+	return Money{units: m.units * int64(quantity)}
+}
+
+// Div divides the amount by a quantity, rounding the quotient to four decimal places.
+func (m Money) Div(quantity int, mode RoundingMode) Money {
+	return Money{units: divide(m.units, int64(quantity), mode)}
+}
+
+// MulRate multiplies the amount by a decimal rate, such as a fee rate or an exchange
+// rate, rounding the product to four decimal places.
+func (m Money) MulRate(rate *big.Rat, mode RoundingMode) Money {
+	return Money{units: roundRat(new(big.Rat).Mul(new(big.Rat).SetInt64(m.units), rate), mode)}
+}
+
+// Round rounds the amount to the number of decimal places.
+func (m Money) Round(scale int, mode RoundingMode) Money {
+	if scale >= moneyScale {
+		return m
+	}
+	factor := scaleFactors[moneyScale-scale]
+	return Money{units: divide(m.units, factor, mode) * factor}
+}
+
+// RoundToCurrency rounds the amount to the minor unit of the currency, e.g. to the cent
+// for USD and to the yen for JPY.
+func (m Money) RoundToCurrency(currency string, mode RoundingMode) Money {
+	return m.Round(CurrencyScale(currency), mode)
+}
+
+// FitsCurrency tells whether the amount is in whole minor units of the currency, e.g.
+// whole cents for USD.
+func (m Money) FitsCurrency(currency string) bool {
+	return m.RoundToCurrency(currency, RoundDown) == m
+}
+
+// Cmp returns -1, 0 or +1 when the amount is less than, equal to or greater than other.
+func (m Money) Cmp(other Money) int {
+	switch {
+	case m.units < other.units:
+		return -1
+	case m.units > other.units:
+		return 1
+	}
+	return 0
+}
+
+func (m Money) LessThan(other Money) bool {
+	return m.units < other.units
+}
+
+func (m Money) GreaterThan(other Money) bool {
+	return m.units > other.units
+}
+
+func (m Money) IsZero() bool {
+	return m.units == 0
+}
+
+func (m Money) IsNegative() bool {
+	return m.units < 0
+}
+
+func (m Money) IsPositive() bool {
+	return m.units > 0
+}
+
+// String formats the amount with its four decimal places, e.g. "1500.0000".
+func (m Money) String() string {
+	sign := ""
+	units := m.units
+	if units < 0 {
+		sign = "-"
+		units = -units
+	}
+	factor := scaleFactors[moneyScale]
+	return fmt.Sprintf("%s%d.%0*d", sign, units/factor, moneyScale, units%factor)
+}
+
+// StringFixed formats the amount rounded half even to the number of decimal places,
+// e.g. "1500.00" for 2.
+func (m Money) StringFixed(scale int) string {
+	text := m.Round(scale, RoundHalfEven).String()
+	switch {
+	case scale <= 0:
+		return strings.TrimSuffix(text, "."+strings.Repeat("0", moneyScale))
+	case scale < moneyScale:
+		return text[:len(text)-moneyScale+scale]
+	}
+	return text
+}
+
+// MarshalJSON writes the amount as a JSON number without trailing zeros, e.g. 148.25.
+func (m Money) MarshalJSON() ([]byte, error) {
+	text := strings.TrimRight(m.String(), "0")
+	return []byte(strings.TrimSuffix(text, ".")), nil
+}
+
+// UnmarshalJSON reads the amount from a JSON number or string without going through
+// float64.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	text := string(data)
+	if text == "null" {
+		return nil
+	}
+	if unquoted, err := strconv.Unquote(text); err == nil {
+		text = unquoted
+	}
+	money, err := parseNumber(text)
+	if err != nil {
+		return err
+	}
+	*m = money
+	return nil
+}
+
+// Scan reads the amount from a DECIMAL column, which the driver returns as text.
+func (m *Money) Scan(src any) error {
+	switch value := src.(type) {
+	case nil:
+		*m = Money{}
+		return nil
+	case []byte:
+		return m.scanText(string(value))
+	case string:
+		return m.scanText(value)
+	case int64:
+		*m = NewMoney(value)
+		return nil
+	case float64:
+		return m.scanText(strconv.FormatFloat(value, 'f', -1, 64))
+	}
+	return fmt.Errorf("cannot scan %T into Money", src)
+}
+
+func (m *Money) scanText(text string) error {
+	money, err := parseNumber(text)
+	if err != nil {
+		return err
+	}
+	*m = money
+	return nil
+}
+
+// Value writes the amount as text so that the database stores it as is.
+func (m Money) Value() (driver.Value, error) {
+	return m.String(), nil
+}
+
+// parseNumber parses a decimal number that may have more than four decimal places, such
+// as a SUM or an AVG computed by the database, rounding it half even.
+func parseNumber(text string) (Money, error) {
+	_, fraction, _ := strings.Cut(text, ".")
+	if len(fraction) <= moneyScale && !strings.ContainsAny(text, "eE") {
+		return ParseMoney(text)
+	}
+
+	rational, ok := new(big.Rat).SetString(strings.TrimSpace(text))
+	if !ok {
+		return Money{}, fmt.Errorf("invalid amount %q", text)
+	}
+	return Money{units: roundRat(rational.Mul(rational, big.NewRat(scaleFactors[moneyScale], 1)), RoundHalfEven)}, nil
+}
+
+// roundRat rounds a rational number of ten-thousandths to an integer with the rounding mode.
+func roundRat(rational *big.Rat, mode RoundingMode) int64 {
+	quotient, remainder := new(big.Int).QuoRem(rational.Num(), rational.Denom(), new(big.Int))
+	if remainder.Sign() == 0 {
+		return quotient.Int64()
+	}
+
+	away := big.NewInt(int64(rational.Sign()))
+	twiceRemainder := new(big.Int).Abs(remainder)
+	twiceRemainder.Lsh(twiceRemainder, 1)
+	half := twiceRemainder.Cmp(rational.Denom())
+
+	switch {
+	case mode == RoundUp,
+		mode == RoundHalfUp && half >= 0,
+		mode == RoundHalfEven && (half > 0 || half == 0 && quotient.Bit(0) == 1):
+		quotient.Add(quotient, away)
+	}
+	return quotient.Int64()
+}
+
+// divide returns numerator / denominator rounded to an integer with the rounding mode.
+func divide(numerator int64, denominator int64, mode RoundingMode) int64 {
+	if denominator < 0 {
+		numerator, denominator = -numerator, -denominator
+	}
+	quotient := numerator / denominator
+	remainder := numerator % denominator
+	if remainder == 0 {
+		return quotient
+	}
+
+	away := int64(1)
+	if numerator < 0 {
+		away = -1
+		remainder = -remainder
+	}
+
+	switch mode {
+	case RoundUp:
+		return quotient + away
+	case RoundHalfUp:
+		if 2*remainder >= denominator {
+			return quotient + away
+		}
+	case RoundHalfEven:
+		if 2*remainder > denominator || (2*remainder == denominator && quotient%2 != 0) {
+			return quotient + away
+		}
+	}
+	return quotient
+}
@@ -0,0 +1,127 @@
+package models
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMoney(t *testing.T) {
+	cases := []struct {
+		value    string
+		expected string
+	}{
+		{"1500", "1500.0000"},
+		{"148.25", "148.2500"},
+		{"-12.5", "-12.5000"},
+		{"0.0001", "0.0001"},
+		{".5", "0.5000"},
+	}
+	for _, c := range cases {
+		money, err := ParseMoney(c.value)
+		require.NoError(t, err, c.value)
+		require.Equal(t, c.expected, money.String())
+	}
+
+	for _, value := range []string{"", "-", "abc", "1.2.3", "1e3", "0.00001"} {
+		_, err := ParseMoney(value)
+		require.Error(t, err, value)
+	}
+}
+
+func TestMoneyArithmeticIsExact(t *testing.T) {
+	// 0.1 + 0.2 is 0.30000000000000004 in float64
+	require.Equal(t, MustParseMoney("0.3"), MustParseMoney("0.1").Add(MustParseMoney("0.2")))
+
+	// 1.15 * 100 is 114.99999999999999 in float64
+	require.Equal(t, NewMoney(115), MustParseMoney("1.15").Mul(100))
+
+	// Summing a cent ten thousand times drifts away from 100 in float64
+	total := Money{}
+	for range 10000 {
+		total = total.Add(MustParseMoney("0.01"))
+	}
+	require.Equal(t, NewMoney(100), total)
+
+	require.Equal(t, MustParseMoney("-0.01"), MustParseMoney("0.29").Sub(MustParseMoney("0.3")))
+}
+
+func TestMoneyRound(t *testing.T) {
+	cases := []struct {
+		value    string
+		mode     RoundingMode
+		expected string
+	}{
+		{"2.345", RoundHalfEven, "2.34"},
+		{"2.355", RoundHalfEven, "2.36"},
+		{"2.345", RoundHalfUp, "2.35"},
+		{"-2.345", RoundHalfUp, "-2.35"},
+		{"2.3451", RoundHalfEven, "2.35"},
+		{"2.349", RoundDown, "2.34"},
+		{"-2.349", RoundDown, "-2.34"},
+		{"2.341", RoundUp, "2.35"},
+		{"-2.341", RoundUp, "-2.35"},
+		{"2.34", RoundUp, "2.34"},
+	}
+	for _, c := range cases {
+		require.Equal(t, MustParseMoney(c.expected), MustParseMoney(c.value).Round(2, c.mode), c.value)
+	}
+}
+
+func TestMoneyDiv(t *testing.T) {
+	require.Equal(t, MustParseMoney("33.3333"), NewMoney(100).Div(3, RoundHalfEven))
+	require.Equal(t, MustParseMoney("66.6667"), NewMoney(200).Div(3, RoundHalfEven))
+	require.Equal(t, MustParseMoney("66.6666"), NewMoney(200).Div(3, RoundDown))
+	require.Equal(t, MustParseMoney("-0.0001"), MustParseMoney("-0.0001").Div(2, RoundUp))
+}
+
+func TestMoneyMulRate(t *testing.T) {
+	rate := big.NewRat(25, 10000) // 0.25%
+	require.Equal(t, MustParseMoney("3.7062"), MustParseMoney("1482.50").MulRate(rate, RoundHalfEven))
+	require.Equal(t, MustParseMoney("3.7063"), MustParseMoney("1482.50").MulRate(rate, RoundHalfUp))
+	require.Equal(t, MustParseMoney("3.71"), MustParseMoney("1482.50").MulRate(rate, RoundHalfEven).RoundToCurrency("USD", RoundHalfEven))
+}
+
+func TestMoneyCurrencyScale(t *testing.T) {
+	require.Equal(t, NewMoney(1235), MustParseMoney("1234.5").RoundToCurrency("JPY", RoundHalfUp))
+	require.Equal(t, MustParseMoney("1234.50"), MustParseMoney("1234.5").RoundToCurrency("USD", RoundHalfUp))
+	require.True(t, MustParseMoney("10.25").FitsCurrency("USD"))
+	require.False(t, MustParseMoney("10.255").FitsCurrency("USD"))
+	require.False(t, MustParseMoney("10.25").FitsCurrency("JPY"))
+	require.Equal(t, "10.26", MustParseMoney("10.255").StringFixed(2))
+	require.Equal(t, "10", MustParseMoney("10.255").StringFixed(0))
+}
+
+func TestMoneyJSON(t *testing.T) {
+	var body struct {
+		Amount Money `json:"amount"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(`{"amount":1500.10}`), &body))
+	require.Equal(t, MustParseMoney("1500.1"), body.Amount)
+	require.NoError(t, json.Unmarshal([]byte(`{"amount":"0.07"}`), &body))
+	require.Equal(t, MustParseMoney("0.07"), body.Amount)
+	require.Error(t, json.Unmarshal([]byte(`{"amount":true}`), &body))
+
+	encoded, err := json.Marshal(map[string]Money{"a": MustParseMoney("148.25"), "b": NewMoney(-3), "c": {}})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"a":148.25,"b":-3,"c":0}`, string(encoded))
+}
+
+func TestMoneyScan(t *testing.T) {
+	var money Money
+	require.NoError(t, money.Scan([]byte("1500.25")))
+	require.Equal(t, MustParseMoney("1500.25"), money)
+	require.NoError(t, money.Scan("-0.000051"))
+	require.Equal(t, MustParseMoney("-0.0001"), money)
+	require.NoError(t, money.Scan(int64(7)))
+	require.Equal(t, NewMoney(7), money)
+	require.NoError(t, money.Scan(nil))
+	require.True(t, money.IsZero())
+	require.Error(t, money.Scan(true))
+
+	value, err := MustParseMoney("12.3").Value()
+	require.NoError(t, err)
+	require.Equal(t, "12.3000", value)
+}
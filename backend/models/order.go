@@ -6,12 +6,22 @@ type Order struct {
 	ID        int
 	UserID    string `schema:"user_id"`
 	Symbol	  string `schema:"symbol"`
-	Type      string `schema:"type"`  // market, limit
+	Currency  string `schema:"-"` // trading currency of the symbol
+	Type      string `schema:"type"`  // market, limit, stop, stop_limit
 	Action	  string `schema:"action"`  // buy, sell
 	Quantity  int `schema:"quantity"`
-	UnitPrice float64 `schema:"unit_price"`
-	Timing	  string  `schema:"timing"` // day, ioc 	
-	Status	  string `schema:"status"` // open, partially filled, filled, canceled
+	UnitPrice Money `schema:"unit_price"`
+	StopPrice Money `schema:"stop_price"` // only set for stop, stop_limit
+	Timing	  string  `schema:"timing"` // day, ioc, gtc, gtd, fok
+	ExpiresAt sql.NullTime `schema:"expires_at"` // only set for gtd
+	LotID     int `schema:"lot_id"` // only set for sells that designate the tax lot to relieve
+	Status	  string `schema:"status"` // open, partially filled, filled, canceled, expired
+	FilledQuantity int `schema:"-"`
+	AverageFillPrice Money `schema:"-"`
+	Commission Money `schema:"-"` // commission charged on the fills so far
+	FeesOnHold Money `schema:"-"` // commission still held for the unfilled quantity of a buy order
+	Version   int `schema:"-"`
+	QueuedAt  sql.NullTime `schema:"-"` // time priority on the book: when the order was placed or last lost its priority
 	CreatedAt  sql.NullTime
 	UpdatedAt  sql.NullTime 
 }
\ No newline at end of file
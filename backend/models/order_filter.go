@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// OrderFilter narrows down the orders of a user. Zero values leave a criterion out.
+type OrderFilter struct {
+	Status string
+	Symbol string
+	Action string // buy, sell
+	From   time.Time // inclusive
+	To     time.Time // exclusive
+	Cursor int // only orders with a smaller ID, 0 for the first page
+	Limit  int
+}
+
+// OrderPage is a page of orders, most recent first. NextCursor is 0 on the last page.
+type OrderPage struct {
+	Orders     []*Order
+	NextCursor int
+}
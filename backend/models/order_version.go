@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+type OrderVersion struct {
+	OrderID   int
+	Version   int
+	Quantity  int
+	UnitPrice Money
+	CreatedAt time.Time
+}
@@ -0,0 +1,29 @@
+package models
+
+// Holding aggregates the positions of a user in a single symbol, in the currency the
+// symbol trades in. Symbols that never traded are valued at their average cost.
+type Holding struct {
+	Symbol            string
+	Currency          string
+	Quantity          int
+	UnsettledQuantity int // included in Quantity
+	AverageCost       Money
+	CostBasis         Money
+	LastPrice         Money
+	MarketValue       Money
+	UnrealizedPnL     Money
+}
+
+// PortfolioTotal sums the holdings and the realized P&L of a portfolio in one currency.
+type PortfolioTotal struct {
+	Currency      string
+	CostBasis     Money
+	MarketValue   Money
+	UnrealizedPnL Money
+	RealizedPnL   Money
+}
+
+type Portfolio struct {
+	Holdings []*Holding
+	Totals   []*PortfolioTotal // one per currency, sorted by currency
+}
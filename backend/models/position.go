@@ -1,9 +1,19 @@
 package models
 
+import "database/sql"
+
+// Position holds shares of a symbol bought at an average cost of UnitPrice. A position is
+// closed once all its shares are sold and is kept for history. Bought shares are
+// unsettled until the settlement date of their trade and cannot be sold before.
 type Position struct {
 	ID        int
 	UserId    string
 	Symbol    string
 	Quantity  int
-	UnitPrice float64
-}
\ No newline at end of file
+	ReservedQuantity int
+	UnsettledQuantity int
+	UnitPrice Money
+	RealizedPnL Money
+	Status    string
+	ClosedAt  sql.NullTime
+}
@@ -0,0 +1,78 @@
+package models
+
+import "time"
+
+// Statement is the account statement of a user over a period, from From inclusive to To
+// exclusive. Cash balances sum the available, on hold and unsettled funds of each wallet
+// according to the ledger. The holdings are those of the user at the end of the period,
+// valued at the last price of their symbol before it.
+type Statement struct {
+	UserID       string
+	From         time.Time
+	To           time.Time
+	GeneratedAt  time.Time
+	Cash         []*StatementCash
+	Deposits     []*Deposit
+	Withdrawals  []*Withdrawal
+	Trades       []*StatementTrade
+	Holdings     []*Holding
+	MarketValues []*StatementTotal // market value of the holdings per currency
+	Gains        []*StatementGain
+	RealizedPnL  []*StatementTotal // realized gains of the period per currency
+}
+
+// StatementCash is the cash of a wallet at the start and at the end of the period.
+type StatementCash struct {
+	Currency string
+	Opening  Money
+	Closing  Money
+}
+
+// StatementTotal is an amount of the statement summed in one currency.
+type StatementTotal struct {
+	Currency string
+	Amount   Money
+}
+
+// StatementGain is a gain realized during the period on the shares of a lot sold by an
+// execution, in the trading currency of its symbol.
+type StatementGain struct {
+	ExecutionID  int
+	RelievedAt   time.Time
+	Symbol       string
+	Currency     string
+	Quantity     int
+	CostBasis    Money
+	Proceeds     Money
+	RealizedGain Money
+	Term         string // short, long
+}
+
+// StatementTrade is a fill of an order of the user with the fees the user paid on it.
+type StatementTrade struct {
+	ExecutionID    int
+	OrderID        int
+	ExecutedAt     time.Time
+	SettlementDate time.Time
+	Symbol         string
+	Side           string // buy, sell
+	Quantity       int
+	Price          Money
+	Currency       string
+	Commission     Money
+	RegulatoryFee  Money // only charged to the seller
+}
+
+// Amount is the cash the trade moved for the user: the cost of a purchase with its fees
+// as a negative amount, or the proceeds of a sale net of its fees.
+func (trade *StatementTrade) Amount() Money {
+	return netTradeAmount(trade.Side, trade.Quantity, trade.Price, trade.Commission, trade.RegulatoryFee)
+}
+
+func netTradeAmount(side string, quantity int, price Money, commission Money, regulatoryFee Money) Money {
+	notional := price.Mul(quantity)
+	if side == "buy" {
+		return notional.Add(commission).Neg()
+	}
+	return notional.Sub(commission).Sub(regulatoryFee)
+}
@@ -0,0 +1,7 @@
+package models
+
+// Symbol is a listed security and the currency it trades in.
+type Symbol struct {
+	Symbol   string
+	Currency string
+}
@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// TaxLot is a block of shares bought at a single price and time. Sells relieve the lots of
+// the user in the order of the lot relief method of the account.
+type TaxLot struct {
+	ID                int
+	UserID            string
+	Symbol            string
+	Quantity          int
+	RemainingQuantity int
+	ReservedQuantity  int // designated by open sell orders
+	UnitPrice         Money
+	AcquiredAt        time.Time
+}
+
+// LotRelief records the shares of a lot sold by an execution and the gain realized on them.
+type LotRelief struct {
+	ID           int
+	LotID        int
+	ExecutionID  int
+	Symbol       string
+	Quantity     int
+	CostBasis    Money
+	Proceeds     Money
+	RealizedGain Money
+	Term         string // short, long
+	RelievedAt   time.Time
+}
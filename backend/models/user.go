@@ -8,4 +8,6 @@ type User struct {
 	Password       string
 	FailedAttempts int
 	LockedUntil    sql.NullTime
+	LotReliefMethod string // fifo, lifo, highest_cost, specific_lot
+	Role            string // customer, back_office
 }
\ No newline at end of file
@@ -1,8 +1,13 @@
 package models
 
+// Wallet holds the cash of a user in a single currency. A user has one wallet per
+// currency they have ever held. The proceeds of sales are unsettled until the settlement
+// date of their trade and cannot be spent nor withdrawn before.
 type Wallet struct {
 	ID             string
 	UserId         string
-	AvailableFunds float64
-	OnHoldFunds    float64
+	Currency       string
+	AvailableFunds Money
+	OnHoldFunds    Money
+	UnsettledFunds Money
 }
\ No newline at end of file
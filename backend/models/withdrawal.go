@@ -0,0 +1,18 @@
+package models
+
+import "database/sql"
+
+// Withdrawal pays funds of the wallet of a user out through the payment provider. The
+// amount stays on hold until the payout completes or is reversed. Withdrawals above the
+// approval threshold wait for a back-office review before being paid out.
+type Withdrawal struct {
+	ID                int
+	UserID            string
+	Amount            Money
+	Status            string // pending_approval, processing, completed, failed, rejected
+	ProviderReference string
+	FailureReason     string // only set for failed and rejected
+	ReviewedBy        string // back-office user who approved or rejected the withdrawal
+	CreatedAt         sql.NullTime
+	UpdatedAt         sql.NullTime
+}
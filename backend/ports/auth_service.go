@@ -1,7 +1,10 @@
 package ports
 
-import "brokerx/models"
+import (
+	"brokerx/models"
+	"context"
+)
 
 type AuthService interface {
-    Authenticate(email, password string) (*models.User, error)
+    Authenticate(ctx context.Context, email, password string) (*models.User, error)
 }
@@ -1,7 +1,11 @@
 package ports
 
-import "brokerx/models"
+import (
+	"brokerx/models"
+	"context"
+)
 
 type ComplianceService interface {
-	VerifyOrderCompliance(order *models.Order) error
+	VerifyOrderCompliance(ctx context.Context, order *models.Order) error
+	VerifyOrderModificationCompliance(ctx context.Context, order *models.Order, modified *models.Order) error
 }
\ No newline at end of file
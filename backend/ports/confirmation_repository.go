@@ -0,0 +1,14 @@
+package ports
+
+import (
+	"brokerx/models"
+	"context"
+)
+
+type ConfirmationRepository interface {
+	// CreateConfirmation issues the confirmation and returns its id. It fails when a
+	// confirmation was already issued under its number.
+	CreateConfirmation(ctx context.Context, confirmation *models.Confirmation) (int, error)
+	FindByNumber(ctx context.Context, number string) (*models.Confirmation, error)
+	FindByUserId(ctx context.Context, userId string) ([]*models.Confirmation, error)
+}
@@ -0,0 +1,11 @@
+package ports
+
+import (
+	"brokerx/models"
+	"context"
+)
+
+type ConfirmationService interface {
+	GetConfirmation(ctx context.Context, userID string, number string) (*models.Confirmation, error)
+	ListConfirmations(ctx context.Context, userID string) ([]*models.Confirmation, error)
+}
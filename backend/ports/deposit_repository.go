@@ -0,0 +1,16 @@
+package ports
+
+import (
+	"brokerx/models"
+	"context"
+)
+
+type DepositRepository interface {
+	CreateDeposit(ctx context.Context, deposit *models.Deposit) (int, error)
+	// UpdateDeposit saves a deposit that is still pending. It fails with
+	// ErrDepositNotPending once the deposit is settled or failed.
+	UpdateDeposit(ctx context.Context, deposit *models.Deposit) error
+	FindById(ctx context.Context, id int) (*models.Deposit, error)
+	FindByUserId(ctx context.Context, userId string) ([]*models.Deposit, error)
+	FindPending(ctx context.Context) ([]*models.Deposit, error)
+}
@@ -0,0 +1,13 @@
+package ports
+
+import (
+	"brokerx/models"
+	"context"
+)
+
+type DepositService interface {
+	InitiateDeposit(ctx context.Context, userID string, amount models.Money) (*models.Deposit, error)
+	GetDeposit(ctx context.Context, userID string, depositID int) (*models.Deposit, error)
+	ListDeposits(ctx context.Context, userID string) ([]*models.Deposit, error)
+	RefreshPendingDeposits(ctx context.Context) (int, error)
+}
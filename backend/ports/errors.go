@@ -0,0 +1,35 @@
+package ports
+
+import "errors"
+
+var (
+	ErrOrderNotFound        = errors.New("order not found")
+	ErrOrderNotOwned        = errors.New("order does not belong to user")
+	ErrOrderNotCancelable   = errors.New("order can no longer be canceled")
+	ErrOrderNotModifiable   = errors.New("order can no longer be modified")
+	ErrInvalidModification  = errors.New("invalid order modification")
+	ErrInsufficientFunds    = errors.New("not enough available funds")
+	ErrInsufficientShares   = errors.New("not enough owned stocks")
+	ErrPriceNotFound        = errors.New("symbol has never traded")
+	ErrLotNotFound          = errors.New("tax lot not found")
+	ErrInvalidReliefMethod  = errors.New("invalid lot relief method")
+	ErrInvalidAmount        = errors.New("amount must be positive")
+	ErrDepositNotFound      = errors.New("deposit not found")
+	ErrDepositNotOwned      = errors.New("deposit does not belong to user")
+	ErrDepositNotPending    = errors.New("deposit is no longer pending")
+	ErrDailyLimitExceeded   = errors.New("daily withdrawal limit exceeded")
+	ErrMonthlyLimitExceeded = errors.New("monthly withdrawal limit exceeded")
+	ErrWithdrawalNotFound   = errors.New("withdrawal not found")
+	ErrWithdrawalNotOwned   = errors.New("withdrawal does not belong to user")
+	ErrWithdrawalNotPending = errors.New("withdrawal is no longer pending")
+	ErrUnbalancedEntry      = errors.New("journal entry does not balance")
+	ErrFractionalCents      = errors.New("amount is not in whole cents")
+	ErrSymbolNotFound       = errors.New("symbol is not listed")
+	ErrUnsupportedCurrency  = errors.New("currency is not supported")
+	ErrRateNotFound         = errors.New("no exchange rate for currency pair")
+	ErrAlreadySettled       = errors.New("trade is already settled")
+	ErrInvalidPeriod        = errors.New("period must end after it starts")
+	ErrConfirmationNotFound = errors.New("trade confirmation not found")
+	ErrConfirmationNotOwned = errors.New("trade confirmation does not belong to user")
+	ErrConfirmationConflict = errors.New("trade confirmation differs from the one already issued")
+)
@@ -0,0 +1,21 @@
+package ports
+
+import (
+	"brokerx/models"
+	"context"
+	"time"
+)
+
+type ExecutionRepository interface {
+	CreateExecution(ctx context.Context, execution *models.Execution) (int, error)
+	FindByOrderId(ctx context.Context, orderId int) ([]*models.Execution, error)
+	FindLatestPrice(ctx context.Context, symbol string) (models.Money, error)
+	// FindLatestPriceBefore returns the price of the last execution of the symbol executed
+	// before the time.
+	FindLatestPriceBefore(ctx context.Context, symbol string, before time.Time) (models.Money, error)
+	// FindByUserIdAndPeriod returns the executions of the orders of the user executed from
+	// from inclusive to to exclusive, oldest first.
+	FindByUserIdAndPeriod(ctx context.Context, userId string, from time.Time, to time.Time) ([]*models.Execution, error)
+	FindUnsettled(ctx context.Context, settlementDate time.Time) ([]*models.Execution, error)
+	MarkSettled(ctx context.Context, executionId int, settledAt time.Time) error
+}
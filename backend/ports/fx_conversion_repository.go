@@ -0,0 +1,11 @@
+package ports
+
+import (
+	"brokerx/models"
+	"context"
+)
+
+type FXConversionRepository interface {
+	CreateConversion(ctx context.Context, conversion *models.FXConversion) (int, error)
+	FindByUserId(ctx context.Context, userId string) ([]*models.FXConversion, error)
+}
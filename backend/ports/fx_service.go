@@ -0,0 +1,13 @@
+package ports
+
+import (
+	"brokerx/models"
+	"context"
+)
+
+type FXService interface {
+	// Convert exchanges an amount of the available funds of the user from one currency to
+	// another at the rate of the rate table.
+	Convert(ctx context.Context, userID string, fromCurrency string, toCurrency string, amount models.Money) (*models.FXConversion, error)
+	ListConversions(ctx context.Context, userID string) ([]*models.FXConversion, error)
+}
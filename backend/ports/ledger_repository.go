@@ -0,0 +1,18 @@
+package ports
+
+import (
+	"brokerx/models"
+	"context"
+)
+
+type LedgerRepository interface {
+	// Post records the entry and applies its postings to the balances of the wallets. It
+	// fails with ErrUnbalancedEntry when the postings do not sum to zero in each currency,
+	// with ErrUnsupportedCurrency when a posting is in an unknown currency and with
+	// ErrInsufficientFunds when the balance of a wallet would become negative.
+	Post(ctx context.Context, entry *models.JournalEntry) error
+	// FindEntriesByUserId returns the entries that moved the cash of the user, most recent
+	// first, with only the postings to the accounts of the user.
+	FindEntriesByUserId(ctx context.Context, userId string) ([]*models.JournalEntry, error)
+	FindBalances(ctx context.Context) ([]*models.LedgerBalance, error)
+}
@@ -0,0 +1,14 @@
+package ports
+
+import (
+	"brokerx/models"
+	"context"
+)
+
+type LedgerService interface {
+	ListEntries(ctx context.Context, userID string) ([]*models.JournalEntry, error)
+	// ListWallets returns the balances of the user, one wallet per currency.
+	ListWallets(ctx context.Context, userID string) ([]*models.Wallet, error)
+	// Reconcile returns the wallets whose stored balances disagree with the ledger.
+	Reconcile(ctx context.Context) ([]*models.WalletDiscrepancy, error)
+}
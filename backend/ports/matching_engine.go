@@ -0,0 +1,11 @@
+package ports
+
+import "brokerx/models"
+
+type MatchingEngine interface {
+	Submit(order *models.Order) ([]*models.Execution, []*models.Order)
+	Cancel(order *models.Order) bool
+	Replace(order *models.Order) ([]*models.Execution, []*models.Order)
+	Load(order *models.Order) bool
+	Checkpoint(symbol string) func()
+}
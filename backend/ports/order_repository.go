@@ -1,7 +1,19 @@
 package ports
 
-import "brokerx/models"
+import (
+	"brokerx/models"
+	"context"
+	"time"
+)
 
 type OrderRepository interface {
-	CreateOrder(order *models.Order) (int, error)
+	CreateOrder(ctx context.Context, order *models.Order) (int, error)
+	UpdateOrder(ctx context.Context, order *models.Order) error
+	FindById(ctx context.Context, id int) (*models.Order, error)
+	FindByUserId(ctx context.Context, userId string, filter models.OrderFilter) ([]*models.Order, error)
+	FindExpirableOrders(ctx context.Context, timing string, createdBefore time.Time) ([]*models.Order, error)
+	FindExpiredGoodTillDateOrders(ctx context.Context, now time.Time) ([]*models.Order, error)
+	FindActiveOrders(ctx context.Context) ([]*models.Order, error)
+	SaveOrderVersion(ctx context.Context, order *models.Order) error
+	FindOrderVersions(ctx context.Context, orderId int) ([]*models.OrderVersion, error)
 }
\ No newline at end of file
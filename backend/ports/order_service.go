@@ -1,7 +1,17 @@
 package ports
 
-import "brokerx/models"
+import (
+	"brokerx/models"
+	"context"
+	"time"
+)
 
 type OrderService interface {
-    PlaceOrder(order *models.Order) error
+    PlaceOrder(ctx context.Context, order *models.Order) error
+    CancelOrder(ctx context.Context, userID string, orderID int) error
+    ModifyOrder(ctx context.Context, userID string, orderID int, quantity int, unitPrice models.Money) error
+    GetOrder(ctx context.Context, userID string, orderID int) (*models.Order, error)
+    ListOrders(ctx context.Context, userID string, filter models.OrderFilter) (*models.OrderPage, error)
+    ExpireDayOrders(ctx context.Context, sessionClose time.Time) (int, error)
+    ExpireGoodTillDateOrders(ctx context.Context, now time.Time) (int, error)
 }
@@ -0,0 +1,22 @@
+package ports
+
+import (
+	"brokerx/models"
+	"context"
+)
+
+// PaymentProvider moves funds between the wallets and an external payment service.
+type PaymentProvider interface {
+	// Charge asks the provider to collect the amount of the deposit. The provider either
+	// approves or declines the payment right away, or reports it as pending. A charge is
+	// keyed on the deposit: asking again for the same deposit returns the charge already
+	// made instead of charging twice.
+	Charge(ctx context.Context, deposit *models.Deposit) (*models.PaymentResult, error)
+	// Payout asks the provider to send the amount of the withdrawal to the user. The
+	// provider answers the same way as for Charge. A payout is keyed on the withdrawal:
+	// asking again for the same withdrawal returns the payout already made instead of
+	// paying twice.
+	Payout(ctx context.Context, withdrawal *models.Withdrawal) (*models.PaymentResult, error)
+	// Status returns the current state of a payment previously reported as pending.
+	Status(ctx context.Context, reference string) (*models.PaymentResult, error)
+}
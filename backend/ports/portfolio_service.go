@@ -0,0 +1,10 @@
+package ports
+
+import (
+	"brokerx/models"
+	"context"
+)
+
+type PortfolioService interface {
+	GetPortfolio(ctx context.Context, userID string) (*models.Portfolio, error)
+}
@@ -1,7 +1,16 @@
 package ports
 
-import "brokerx/models"
+import (
+	"brokerx/models"
+	"context"
+)
 
 type PositionRepository interface {
-	FindByUserIdAndSymbol(userId string, symbol string) ([]*models.Position, error)
+	FindByUserIdAndSymbol(ctx context.Context, userId string, symbol string) ([]*models.Position, error)
+	FindByUserId(ctx context.Context, userId string) ([]*models.Position, error)
+	ReserveShares(ctx context.Context, userId string, symbol string, quantity int) error
+	ReleaseShares(ctx context.Context, userId string, symbol string, quantity int) error
+	AddShares(ctx context.Context, userId string, symbol string, quantity int, unitPrice models.Money) error
+	ConsumeReservedShares(ctx context.Context, userId string, symbol string, quantity int, currency string, unitPrice models.Money) error
+	SettleShares(ctx context.Context, userId string, symbol string, quantity int) error
 }
\ No newline at end of file
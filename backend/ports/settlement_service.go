@@ -0,0 +1,10 @@
+package ports
+
+import (
+	"context"
+	"time"
+)
+
+type SettlementService interface {
+	SettleTrades(ctx context.Context, settlementDate time.Time) (int, error)
+}
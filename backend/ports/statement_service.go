@@ -0,0 +1,13 @@
+package ports
+
+import (
+	"brokerx/models"
+	"context"
+	"time"
+)
+
+type StatementService interface {
+	// GenerateStatement returns the statement of the user from from inclusive to to
+	// exclusive. It fails with ErrInvalidPeriod when to is not after from.
+	GenerateStatement(ctx context.Context, userID string, from time.Time, to time.Time) (*models.Statement, error)
+}
@@ -0,0 +1,11 @@
+package ports
+
+import (
+	"brokerx/models"
+	"context"
+)
+
+type SymbolRepository interface {
+	// FindBySymbol fails with ErrSymbolNotFound when the symbol is not listed.
+	FindBySymbol(ctx context.Context, symbol string) (*models.Symbol, error)
+}
@@ -0,0 +1,17 @@
+package ports
+
+import (
+	"brokerx/models"
+	"context"
+)
+
+type TaxLotRepository interface {
+	CreateLot(ctx context.Context, lot *models.TaxLot) (int, error)
+	FindById(ctx context.Context, id int) (*models.TaxLot, error)
+	FindByUserId(ctx context.Context, userId string) ([]*models.TaxLot, error)
+	FindOpenLots(ctx context.Context, userId string, symbol string) ([]*models.TaxLot, error)
+	ReserveLot(ctx context.Context, id int, quantity int) error
+	ReleaseLot(ctx context.Context, id int, quantity int) error
+	RelieveLot(ctx context.Context, relief *models.LotRelief) error
+	FindReliefsByUserId(ctx context.Context, userId string) ([]*models.LotRelief, error)
+}
@@ -0,0 +1,13 @@
+package ports
+
+import (
+	"brokerx/models"
+	"context"
+)
+
+type TaxLotService interface {
+	GetLots(ctx context.Context, userID string) ([]*models.TaxLot, error)
+	GetRealizedGains(ctx context.Context, userID string) ([]*models.LotRelief, error)
+	GetReliefMethod(ctx context.Context, userID string) (string, error)
+	SetReliefMethod(ctx context.Context, userID string, method string) error
+}
@@ -0,0 +1,24 @@
+package ports
+
+import "context"
+
+// Repositories groups the repositories that can take part in a unit of work.
+type Repositories struct {
+	Orders        OrderRepository
+	Executions    ExecutionRepository
+	Wallets       WalletRepository
+	Ledger        LedgerRepository
+	Positions     PositionRepository
+	TaxLots       TaxLotRepository
+	Users         UserRepository
+	Deposits      DepositRepository
+	Withdrawals   WithdrawalRepository
+	FXConversions FXConversionRepository
+	Confirmations ConfirmationRepository
+}
+
+type UnitOfWork interface {
+	// Execute runs fn with repositories bound to a single transaction. The transaction
+	// is committed when fn returns nil and rolled back otherwise.
+	Execute(ctx context.Context, fn func(repos Repositories) error) error
+}
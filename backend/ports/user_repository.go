@@ -1,8 +1,13 @@
 package ports
 
-import "brokerx/models"
+import (
+	"brokerx/models"
+	"context"
+)
 
 type UserRepository interface {
-	FindByEmail(email string) (*models.User, error)
-	Update(user *models.User) error
+	FindByEmail(ctx context.Context, email string) (*models.User, error)
+	FindById(ctx context.Context, id string) (*models.User, error)
+	UpdateLotReliefMethod(ctx context.Context, id string, method string) error
+	Update(ctx context.Context, user *models.User) error
 }
@@ -1,7 +1,17 @@
 package ports
 
-import "brokerx/models"
+import (
+	"brokerx/models"
+	"context"
+)
 
+// WalletRepository reads the balances of the wallets. The balances only change through
+// the entries posted to the LedgerRepository.
 type WalletRepository interface {
-	FindByUserId(userId string) (*models.Wallet, error)
-}
\ No newline at end of file
+	// FindByUserId returns the wallets of the user, one per currency.
+	FindByUserId(ctx context.Context, userId string) ([]*models.Wallet, error)
+	// FindByUserIdAndCurrency returns the wallet of the user in the currency, or an empty
+	// wallet when the user never held the currency.
+	FindByUserIdAndCurrency(ctx context.Context, userId string, currency string) (*models.Wallet, error)
+	FindAll(ctx context.Context) ([]*models.Wallet, error)
+}
@@ -0,0 +1,16 @@
+package ports
+
+import (
+	"brokerx/models"
+	"context"
+)
+
+type WalletService interface {
+	Withdraw(ctx context.Context, userID string, amount models.Money) (*models.Withdrawal, error)
+	GetWithdrawal(ctx context.Context, userID string, withdrawalID int) (*models.Withdrawal, error)
+	ListWithdrawals(ctx context.Context, userID string) ([]*models.Withdrawal, error)
+	ListPendingApprovals(ctx context.Context) ([]*models.Withdrawal, error)
+	ApproveWithdrawal(ctx context.Context, reviewerID string, withdrawalID int) (*models.Withdrawal, error)
+	RejectWithdrawal(ctx context.Context, reviewerID string, withdrawalID int, reason string) (*models.Withdrawal, error)
+	RefreshProcessingWithdrawals(ctx context.Context) (int, error)
+}
@@ -0,0 +1,20 @@
+package ports
+
+import (
+	"brokerx/models"
+	"context"
+	"time"
+)
+
+type WithdrawalRepository interface {
+	CreateWithdrawal(ctx context.Context, withdrawal *models.Withdrawal) (int, error)
+	// UpdateWithdrawal saves a withdrawal that is still in the previous status. It fails
+	// with ErrWithdrawalNotPending when the withdrawal moved on in the meantime.
+	UpdateWithdrawal(ctx context.Context, withdrawal *models.Withdrawal, previousStatus string) error
+	FindById(ctx context.Context, id int) (*models.Withdrawal, error)
+	FindByUserId(ctx context.Context, userId string) ([]*models.Withdrawal, error)
+	FindByStatus(ctx context.Context, status string) ([]*models.Withdrawal, error)
+	// SumWithdrawnSince returns the total amount of the withdrawals of the user requested
+	// at or after since, leaving out the failed and rejected ones.
+	SumWithdrawnSince(ctx context.Context, userId string, since time.Time) (models.Money, error)
+}
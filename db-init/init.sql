@@ -7,7 +7,9 @@ CREATE TABLE IF NOT EXISTS users (
     email VARCHAR(255) NOT NULL UNIQUE,
     password VARCHAR(255) NOT NULL,
     failed_attempts INT NOT NULL DEFAULT 0,
-    locked_until DATETIME NULL
+    locked_until DATETIME NULL,
+    lot_relief_method ENUM('fifo', 'lifo', 'highest_cost', 'specific_lot') NOT NULL DEFAULT 'fifo',
+    role ENUM('customer', 'back_office') NOT NULL DEFAULT 'customer'
 );
 CREATE UNIQUE INDEX idx_users_email ON users(email);
 CREATE UNIQUE INDEX idx_users_id ON users(id);
@@ -17,48 +19,238 @@ INSERT INTO users (id, email, password) VALUES
 (UUID(), 'buyer@email.com', '$2a$14$VWlwuLF38a4lcpkmsBk9Bulkanjd2mauqYDkU9Y5OziSgbA9CryZG'),
 (UUID(), 'seller@email.com', '$2a$14$VWlwuLF38a4lcpkmsBk9Bulkanjd2mauqYDkU9Y5OziSgbA9CryZG');
 
+INSERT INTO users (id, email, password, role) VALUES
+(UUID(), 'backoffice@email.com', '$2a$14$VWlwuLF38a4lcpkmsBk9Bulkanjd2mauqYDkU9Y5OziSgbA9CryZG', 'back_office');
+
 CREATE TABLE IF NOT EXISTS wallets (
     id CHAR(36) PRIMARY KEY,
     user_id CHAR(36) NOT NULL,
+    currency CHAR(3) NOT NULL DEFAULT 'USD',
     available_funds DECIMAL(10, 2) NOT NULL DEFAULT 0,
     funds_on_hold DECIMAL(10, 2) NOT NULL DEFAULT 0,
+    unsettled_funds DECIMAL(10, 2) NOT NULL DEFAULT 0,
     FOREIGN KEY (user_id) REFERENCES users(id)
 );
 CREATE UNIQUE INDEX idx_wallets_id ON wallets(id);
+CREATE UNIQUE INDEX idx_wallets_user_id_currency ON wallets(user_id, currency);
 
 INSERT INTO wallets (id, user_id, available_funds) VALUES
 (UUID(), (SELECT id FROM users WHERE email = 'email'), 0),
 (UUID(), (SELECT id FROM users WHERE email = 'buyer@email.com'), 1000),
 (UUID(), (SELECT id FROM users WHERE email = 'seller@email.com'), 300);
 
+CREATE TABLE IF NOT EXISTS symbols (
+    symbol VARCHAR(10) PRIMARY KEY,
+    currency CHAR(3) NOT NULL
+);
+
+-- Symbols that are not listed here trade in USD
+INSERT INTO symbols (symbol, currency) VALUES
+('AAPL', 'USD'),
+('MSFT', 'USD'),
+('SHOP', 'CAD'),
+('RY', 'CAD');
+
 CREATE TABLE IF NOT EXISTS orders (
     id INT PRIMARY KEY AUTO_INCREMENT,
     user_id CHAR(36) NOT NULL,
     symbol VARCHAR(10) NOT NULL,
-    type ENUM('buy', 'sell') NOT NULL,
-    action ENUM('market', 'limit') NOT NULL,
+    currency CHAR(3) NOT NULL DEFAULT 'USD',
+    type ENUM('market', 'limit', 'stop', 'stop_limit') NOT NULL,
+    action ENUM('buy', 'sell') NOT NULL,
     quantity INT NOT NULL,
     unit_price DECIMAL(10, 2) NOT NULL,
-    timing ENUM('day', 'ioc') NOT NULL,
+    stop_price DECIMAL(10, 2) NOT NULL DEFAULT 0,
+    timing ENUM('day', 'ioc', 'gtc', 'gtd', 'fok') NOT NULL,
+    expires_at DATETIME NULL,
+    lot_id INT NOT NULL DEFAULT 0,
     status VARCHAR(50) NOT NULL,
+    filled_quantity INT NOT NULL DEFAULT 0,
+    average_fill_price DECIMAL(12, 4) NOT NULL DEFAULT 0,
+    commission DECIMAL(10, 2) NOT NULL DEFAULT 0,
+    fees_on_hold DECIMAL(10, 2) NOT NULL DEFAULT 0,
+    version INT NOT NULL DEFAULT 1,
+    queued_at DATETIME(6) NULL,
     created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
     updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
-    FOREIGN KEY (user_id) REFERENCES users(id)
+    FOREIGN KEY (user_id) REFERENCES users(id),
+    INDEX idx_orders_user_id (user_id, id)
 );
 
+-- A past day order of the user, which holds no funds since it expired
 INSERT INTO orders (user_id, symbol, type, action, quantity, unit_price, timing, status) VALUES
-((SELECT id FROM users WHERE email = 'email'), 'AAPL', 'buy', 'market', 10, 150.00, 'day', 'open');
+((SELECT id FROM users WHERE email = 'email'), 'AAPL', 'market', 'buy', 10, 150.00, 'day', 'expired');
+
+CREATE TABLE IF NOT EXISTS order_versions (
+    order_id INT NOT NULL,
+    version INT NOT NULL,
+    quantity INT NOT NULL,
+    unit_price DECIMAL(10, 2) NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (order_id, version),
+    FOREIGN KEY (order_id) REFERENCES orders(id)
+);
 
 CREATE TABLE IF NOT EXISTS positions (
     id INT PRIMARY KEY AUTO_INCREMENT,
     user_id CHAR(36) NOT NULL,
     symbol VARCHAR(10) NOT NULL,
     quantity INT NOT NULL,
-    unit_price DECIMAL(10, 2) NOT NULL,
+    reserved_quantity INT NOT NULL DEFAULT 0,
+    unsettled_quantity INT NOT NULL DEFAULT 0,
+    unit_price DECIMAL(12, 4) NOT NULL,
+    realized_pnl DECIMAL(12, 2) NOT NULL DEFAULT 0,
+    status ENUM('open', 'closed') NOT NULL DEFAULT 'open',
+    closed_at DATETIME NULL,
     created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
     updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
     FOREIGN KEY (user_id) REFERENCES users(id)
 );
 
 INSERT INTO positions (user_id, symbol, quantity, unit_price) VALUES
-((SELECT id FROM users WHERE email = 'seller@email.com'), 'AAPL', 15, 400.00);
\ No newline at end of file
+((SELECT id FROM users WHERE email = 'seller@email.com'), 'AAPL', 15, 400.00);
+
+CREATE TABLE IF NOT EXISTS executions (
+    id INT PRIMARY KEY AUTO_INCREMENT,
+    buy_order_id INT NOT NULL,
+    sell_order_id INT NOT NULL,
+    symbol VARCHAR(10) NOT NULL,
+    quantity INT NOT NULL,
+    price DECIMAL(10, 2) NOT NULL,
+    liquidity_flag ENUM('buyer_maker', 'seller_maker') NOT NULL,
+    buy_commission DECIMAL(10, 2) NOT NULL DEFAULT 0,
+    sell_commission DECIMAL(10, 2) NOT NULL DEFAULT 0,
+    regulatory_fee DECIMAL(10, 2) NOT NULL DEFAULT 0,
+    executed_at DATETIME(6) NOT NULL,
+    settlement_date DATE NOT NULL,
+    settled_at DATETIME NULL,
+    FOREIGN KEY (buy_order_id) REFERENCES orders(id),
+    FOREIGN KEY (sell_order_id) REFERENCES orders(id)
+);
+CREATE INDEX idx_executions_buy_order_id ON executions(buy_order_id);
+CREATE INDEX idx_executions_sell_order_id ON executions(sell_order_id);
+CREATE INDEX idx_executions_settlement_date ON executions(settlement_date, settled_at);
+
+CREATE TABLE IF NOT EXISTS tax_lots (
+    id INT PRIMARY KEY AUTO_INCREMENT,
+    user_id CHAR(36) NOT NULL,
+    symbol VARCHAR(10) NOT NULL,
+    quantity INT NOT NULL,
+    remaining_quantity INT NOT NULL,
+    reserved_quantity INT NOT NULL DEFAULT 0,
+    unit_price DECIMAL(12, 4) NOT NULL,
+    acquired_at DATETIME(6) NOT NULL,
+    FOREIGN KEY (user_id) REFERENCES users(id),
+    INDEX idx_tax_lots_user_id (user_id, symbol)
+);
+
+INSERT INTO tax_lots (user_id, symbol, quantity, remaining_quantity, unit_price, acquired_at) VALUES
+((SELECT id FROM users WHERE email = 'seller@email.com'), 'AAPL', 15, 15, 400.00, CURRENT_TIMESTAMP(6));
+
+CREATE TABLE IF NOT EXISTS lot_reliefs (
+    id INT PRIMARY KEY AUTO_INCREMENT,
+    lot_id INT NOT NULL,
+    execution_id INT NOT NULL,
+    quantity INT NOT NULL,
+    cost_basis DECIMAL(12, 2) NOT NULL,
+    proceeds DECIMAL(12, 2) NOT NULL,
+    realized_gain DECIMAL(12, 2) NOT NULL,
+    term ENUM('short', 'long') NOT NULL,
+    relieved_at DATETIME(6) NOT NULL,
+    FOREIGN KEY (lot_id) REFERENCES tax_lots(id),
+    FOREIGN KEY (execution_id) REFERENCES executions(id)
+);
+
+CREATE TABLE IF NOT EXISTS deposits (
+    id INT PRIMARY KEY AUTO_INCREMENT,
+    user_id CHAR(36) NOT NULL,
+    amount DECIMAL(10, 2) NOT NULL,
+    status ENUM('pending', 'settled', 'failed') NOT NULL,
+    provider_reference VARCHAR(64) NOT NULL DEFAULT '',
+    failure_reason VARCHAR(255) NOT NULL DEFAULT '',
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id),
+    INDEX idx_deposits_user_id (user_id, id),
+    INDEX idx_deposits_status (status)
+);
+
+CREATE TABLE IF NOT EXISTS withdrawals (
+    id INT PRIMARY KEY AUTO_INCREMENT,
+    user_id CHAR(36) NOT NULL,
+    amount DECIMAL(10, 2) NOT NULL,
+    status ENUM('pending_approval', 'processing', 'completed', 'failed', 'rejected') NOT NULL,
+    provider_reference VARCHAR(64) NOT NULL DEFAULT '',
+    failure_reason VARCHAR(255) NOT NULL DEFAULT '',
+    reviewed_by CHAR(36) NOT NULL DEFAULT '',
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id),
+    INDEX idx_withdrawals_user_id (user_id, created_at),
+    INDEX idx_withdrawals_status (status)
+);
+
+CREATE TABLE IF NOT EXISTS journal_entries (
+    id INT PRIMARY KEY AUTO_INCREMENT,
+    type ENUM('deposit', 'withdrawal', 'hold', 'release', 'trade', 'fee', 'settlement', 'fx_conversion', 'opening_balance') NOT NULL,
+    reference VARCHAR(64) NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS ledger_postings (
+    id INT PRIMARY KEY AUTO_INCREMENT,
+    entry_id INT NOT NULL,
+    account ENUM('available', 'on_hold', 'unsettled', 'payment_provider', 'fee_revenue', 'regulatory_fees', 'fx', 'opening_equity') NOT NULL,
+    user_id CHAR(36) NULL,
+    currency CHAR(3) NOT NULL DEFAULT 'USD',
+    amount DECIMAL(12, 2) NOT NULL,
+    FOREIGN KEY (entry_id) REFERENCES journal_entries(id),
+    FOREIGN KEY (user_id) REFERENCES users(id),
+    INDEX idx_ledger_postings_user_id (user_id, entry_id)
+);
+
+CREATE TABLE IF NOT EXISTS fx_conversions (
+    id INT PRIMARY KEY AUTO_INCREMENT,
+    user_id CHAR(36) NOT NULL,
+    from_currency CHAR(3) NOT NULL,
+    to_currency CHAR(3) NOT NULL,
+    amount DECIMAL(12, 2) NOT NULL,
+    converted_amount DECIMAL(12, 2) NOT NULL,
+    rate DECIMAL(18, 8) NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id),
+    INDEX idx_fx_conversions_user_id (user_id, id)
+);
+
+CREATE TABLE IF NOT EXISTS trade_confirmations (
+    id INT PRIMARY KEY AUTO_INCREMENT,
+    number VARCHAR(32) NOT NULL UNIQUE,
+    execution_id INT NOT NULL,
+    order_id INT NOT NULL,
+    user_id CHAR(36) NOT NULL,
+    symbol VARCHAR(10) NOT NULL,
+    side ENUM('buy', 'sell') NOT NULL,
+    quantity INT NOT NULL,
+    price DECIMAL(10, 2) NOT NULL,
+    currency CHAR(3) NOT NULL,
+    commission DECIMAL(10, 2) NOT NULL,
+    regulatory_fee DECIMAL(10, 2) NOT NULL,
+    executed_at DATETIME(6) NOT NULL,
+    settlement_date DATE NOT NULL,
+    issued_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (execution_id) REFERENCES executions(id),
+    FOREIGN KEY (order_id) REFERENCES orders(id),
+    FOREIGN KEY (user_id) REFERENCES users(id),
+    INDEX idx_trade_confirmations_user_id (user_id, id)
+);
+
+-- Opening balances of the seeded wallets
+INSERT INTO journal_entries (id, type, reference) VALUES
+(1, 'opening_balance', 'seed'),
+(2, 'opening_balance', 'seed');
+
+INSERT INTO ledger_postings (entry_id, account, user_id, amount) VALUES
+(1, 'available', (SELECT id FROM users WHERE email = 'buyer@email.com'), 1000),
+(1, 'opening_equity', NULL, -1000),
+(2, 'available', (SELECT id FROM users WHERE email = 'seller@email.com'), 300),
+(2, 'opening_equity', NULL, -300);
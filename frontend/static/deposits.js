@@ -0,0 +1,57 @@
+// Add funds: initiates deposits through the JSON API and refreshes the list of deposits
+// periodically so that pending deposits show up as settled or failed.
+(function () {
+  const REFRESH_INTERVAL_MS = 5000;
+
+  const form = document.getElementById("deposit-form");
+  const message = document.getElementById("deposit-message");
+  const body = document.querySelector("#deposits-table tbody");
+
+  async function api(path, options) {
+    const response = await fetch(path, options);
+    if (response.status === 401) {
+      window.location.href = "/login";
+    }
+    return response.json();
+  }
+
+  function renderRow(deposit) {
+    const row = document.createElement("tr");
+    const cells = [
+      deposit.id,
+      deposit.amount.toFixed(2),
+      deposit.status,
+      deposit.failure_reason || "",
+      deposit.created_at ? new Date(deposit.created_at).toLocaleString() : "",
+    ];
+    for (const value of cells) {
+      const cell = document.createElement("td");
+      cell.textContent = value;
+      row.appendChild(cell);
+    }
+    return row;
+  }
+
+  async function load() {
+    const page = await api("/api/v1/deposits");
+    body.replaceChildren(...(page.deposits || []).map(renderRow));
+  }
+
+  form.addEventListener("submit", async (event) => {
+    event.preventDefault();
+    const amount = parseFloat(form.elements.amount.value);
+    const deposit = await api("/api/v1/deposits", {
+      method: "POST",
+      headers: { "Content-Type": "application/json" },
+      body: JSON.stringify({ amount: amount }),
+    });
+    message.textContent = deposit.error
+      ? deposit.error.message
+      : "Deposit " + deposit.id + " is " + deposit.status;
+    form.reset();
+    load();
+  });
+
+  load();
+  setInterval(load, REFRESH_INTERVAL_MS);
+})();
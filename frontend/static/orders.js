@@ -0,0 +1,95 @@
+// Order blotter: lists the orders of the user from the JSON API and refreshes the
+// loaded pages periodically so that statuses and fills stay live.
+(function () {
+  const REFRESH_INTERVAL_MS = 5000;
+
+  const form = document.getElementById("orders-filter");
+  const body = document.querySelector("#orders-table tbody");
+  const more = document.getElementById("orders-more");
+
+  let loadedPages = 1;
+  let nextCursor = 0;
+
+  function filterQuery() {
+    const params = new URLSearchParams();
+    for (const name of ["status", "symbol", "side"]) {
+      const value = form.elements[name].value.trim();
+      if (value) {
+        params.set(name, value);
+      }
+    }
+    for (const name of ["from", "to"]) {
+      const value = form.elements[name].value;
+      if (value) {
+        params.set(name, new Date(value + "T00:00:00").toISOString());
+      }
+    }
+    return params;
+  }
+
+  async function fetchPage(cursor) {
+    const params = filterQuery();
+    if (cursor) {
+      params.set("cursor", cursor);
+    }
+    const response = await fetch("/api/v1/orders?" + params.toString());
+    if (response.status === 401) {
+      window.location.href = "/login";
+      return { orders: [] };
+    }
+    return response.json();
+  }
+
+  function renderRow(order) {
+    const row = document.createElement("tr");
+    const cells = [
+      order.id,
+      order.symbol,
+      order.action,
+      order.type,
+      order.quantity,
+      order.unit_price.toFixed(2),
+      order.status,
+      order.filled_quantity,
+      order.filled_quantity > 0 ? order.average_fill_price.toFixed(2) : "-",
+      order.created_at ? new Date(order.created_at).toLocaleString() : "",
+    ];
+    for (const value of cells) {
+      const cell = document.createElement("td");
+      cell.textContent = value;
+      row.appendChild(cell);
+    }
+    return row;
+  }
+
+  // load fetches the first pages again, up to the number of pages already shown
+  async function load(pages) {
+    const rows = [];
+    let cursor = 0;
+    for (let i = 0; i < pages; i++) {
+      const page = await fetchPage(cursor);
+      rows.push(...page.orders.map(renderRow));
+      cursor = page.next_cursor || 0;
+      if (!cursor) {
+        break;
+      }
+    }
+    body.replaceChildren(...rows);
+    nextCursor = cursor;
+    more.hidden = !nextCursor;
+  }
+
+  form.addEventListener("submit", (event) => {
+    event.preventDefault();
+    loadedPages = 1;
+    load(loadedPages);
+  });
+
+  more.addEventListener("click", () => {
+    loadedPages++;
+    load(loadedPages);
+  });
+
+  load(loadedPages);
+  setInterval(() => load(loadedPages), REFRESH_INTERVAL_MS);
+})();
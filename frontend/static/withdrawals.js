@@ -0,0 +1,58 @@
+// Withdraw funds: requests withdrawals through the JSON API and refreshes the list of
+// withdrawals periodically so that processing withdrawals show up as completed or failed.
+(function () {
+  const REFRESH_INTERVAL_MS = 5000;
+  const STATUS_LABELS = { pending_approval: "pending approval" };
+
+  const form = document.getElementById("withdrawal-form");
+  const message = document.getElementById("withdrawal-message");
+  const body = document.querySelector("#withdrawals-table tbody");
+
+  async function api(path, options) {
+    const response = await fetch(path, options);
+    if (response.status === 401) {
+      window.location.href = "/login";
+    }
+    return response.json();
+  }
+
+  function renderRow(withdrawal) {
+    const row = document.createElement("tr");
+    const cells = [
+      withdrawal.id,
+      withdrawal.amount.toFixed(2),
+      STATUS_LABELS[withdrawal.status] || withdrawal.status,
+      withdrawal.failure_reason || "",
+      withdrawal.created_at ? new Date(withdrawal.created_at).toLocaleString() : "",
+    ];
+    for (const value of cells) {
+      const cell = document.createElement("td");
+      cell.textContent = value;
+      row.appendChild(cell);
+    }
+    return row;
+  }
+
+  async function load() {
+    const page = await api("/api/v1/withdrawals");
+    body.replaceChildren(...(page.withdrawals || []).map(renderRow));
+  }
+
+  form.addEventListener("submit", async (event) => {
+    event.preventDefault();
+    const amount = parseFloat(form.elements.amount.value);
+    const withdrawal = await api("/api/v1/withdrawals", {
+      method: "POST",
+      headers: { "Content-Type": "application/json" },
+      body: JSON.stringify({ amount: amount }),
+    });
+    message.textContent = withdrawal.error
+      ? withdrawal.error.message
+      : "Withdrawal " + withdrawal.id + " is " + (STATUS_LABELS[withdrawal.status] || withdrawal.status);
+    form.reset();
+    load();
+  });
+
+  load();
+  setInterval(load, REFRESH_INTERVAL_MS);
+})();